@@ -1,52 +1,193 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"flag"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"os"
+	"time"
+	// Embedded so ?tz= works on images without a zone database.
+	_ "time/tzdata"
 	"todo-app/internal/delivery/http/handler"
 	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+	"todo-app/internal/pkg/auth"
+	"todo-app/internal/pkg/blob"
+	"todo-app/internal/pkg/migrate"
 	"todo-app/internal/pkg/mysql"
-	repository "todo-app/internal/repository/mysql"
+	"todo-app/internal/pkg/notifier"
+	"todo-app/internal/pkg/postgres"
+	"todo-app/internal/pkg/scheduler"
+	"todo-app/internal/pkg/security"
+	"todo-app/internal/pkg/sqlite"
+	memoryrepo "todo-app/internal/repository/memory"
+	mysqlrepo "todo-app/internal/repository/mysql"
+	postgresrepo "todo-app/internal/repository/postgres"
+	sqliterepo "todo-app/internal/repository/sqlite"
 	"todo-app/internal/usecase"
 )
 
+const (
+	storageSQL    = "sql"
+	storageMemory = "memory"
+
+	driverMySQL    = "mysql"
+	driverPostgres = "postgres"
+	driverSQLite   = "sqlite"
+
+	notifierLog     = "log"
+	notifierWebhook = "webhook"
+
+	blobStoreLocal = "local"
+	blobStoreS3    = "s3"
+)
+
+// defaultPorts is used when -db-port is not given explicitly.
+var defaultPorts = map[string]string{
+	driverMySQL:    "3306",
+	driverPostgres: "5432",
+}
+
 type Config struct {
+	Storage    string
+	DBDriver   string
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
+	DBSSLMode  string
+	SQLitePath string
 	ServerPort string
+	Migrate    bool
+
+	SQLiteMigrationStaleLock time.Duration
+
+	ReminderInterval time.Duration
+	Notifier         string
+	WebhookURL       string
+	WebhookSecret    string
+
+	BlobStore         string
+	BlobDir           string
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKey       string
+	S3SecretKey       string
+	AttachmentMaxSize int64
+	AttachmentQuota   int64
+
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	AutoArchiveInterval time.Duration
+
+	JWTKeys       string
+	JWTKeysFile   string
+	JWTSigningKey string
+
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	TokenCleanupInterval time.Duration
+	RevocationCacheTTL   time.Duration
+
+	PasswordHashMemory      uint
+	PasswordHashIterations  uint
+	PasswordHashParallelism uint
 }
 
 func main() {
-	config := parseConfig()
-
-	db, err := mysql.NewConnection(
-		config.DBHost,
-		config.DBPort,
-		config.DBUser,
-		config.DBPassword,
-		config.DBName)
-	
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrateCommand(os.Args[2:])
+		return
+	}
+
+	config, _ := parseConfig(os.Args[0], os.Args[1:])
+
+	repos, closeRepos, err := openRepositories(config)
 	if err != nil {
-		log.Fatalf("Failed to connect to database : %v", err)
+		log.Fatalf("Failed to open storage : %v", err)
 	}
 
-	defer db.Close()
+	defer closeRepos()
 
-	userRepo := repository.NewMysqlUserRepository(db)
-	taskRepo := repository.NewMysqlTaskRepository(db)
+	keys, err := newKeySet(config)
+	if err != nil {
+		log.Fatalf("Failed to load JWT keys : %v", err)
+	}
+	tokens := auth.NewTokenService(keys, config.AccessTokenTTL)
+
+	if config.PasswordHashMemory > math.MaxUint32 || config.PasswordHashIterations > math.MaxUint32 {
+		log.Fatalf("Invalid password hash parameters : memory and iterations must be at most %d", uint32(math.MaxUint32))
+	}
+	if config.PasswordHashParallelism > math.MaxUint8 {
+		log.Fatalf("Invalid password hash parameters : parallelism must be at most %d", math.MaxUint8)
+	}
+	if err := security.SetParams(security.Params{
+		Memory:      uint32(config.PasswordHashMemory),
+		Iterations:  uint32(config.PasswordHashIterations),
+		Parallelism: uint8(config.PasswordHashParallelism),
+	}); err != nil {
+		log.Fatalf("Invalid password hash parameters : %v", err)
+	}
 
-	userUsecase := usecase.NewUserUsecase(userRepo)
-	taskUseCase := usecase.NewTaskUsecase(taskRepo)
+	revocationUsecase := usecase.NewTokenRevocationUsecase(repos.tokenRevocation, config.RevocationCacheTTL)
+	userUsecase := usecase.NewUserUsecase(repos.user, repos.refreshToken, revocationUsecase, tokens, config.RefreshTokenTTL)
+	taskUseCase := usecase.NewTaskUsecase(repos.task, repos.tag, repos.project, repos.share, repos.history)
+	tagUsecase := usecase.NewTagUsecase(repos.tag)
+	projectUsecase := usecase.NewProjectUsecase(repos.project, repos.task, repos.history)
+
+	reminderNotifier, err := newNotifier(config)
+	if err != nil {
+		log.Fatalf("Failed to set up notifier : %v", err)
+	}
+	shareUsecase := usecase.NewShareUsecase(repos.share, repos.user, repos.task, repos.project, repos.history)
+	assignmentUsecase := usecase.NewAssignmentUsecase(repos.assignment, repos.task, repos.share, repos.history)
+	commentUsecase := usecase.NewCommentUsecase(repos.comment, repos.task, repos.share)
+
+	blobStore, err := newBlobStore(config)
+	if err != nil {
+		log.Fatalf("Failed to set up blob store : %v", err)
+	}
+	attachmentUsecase := usecase.NewAttachmentUsecase(
+		repos.attachment,
+		repos.task,
+		repos.share,
+		blobStore,
+		config.AttachmentMaxSize,
+		config.AttachmentQuota,
+	)
+	trashUsecase := usecase.NewTrashUsecase(
+		repos.task,
+		repos.tag,
+		repos.attachment,
+		repos.history,
+		blobStore,
+		config.TrashRetention,
+	)
+	archivePolicyUsecase := usecase.NewArchivePolicyUsecase(repos.archivePolicy, repos.task)
+	reminderUsecase := usecase.NewReminderUsecase(repos.reminder, repos.task, repos.share, reminderNotifier)
 
 	userHandler := handler.NewUserHandler(userUsecase)
 	taskHandler := handler.NewTaskHandler(taskUseCase)
+	tagHandler := handler.NewTagHandler(tagUsecase)
+	projectHandler := handler.NewProjectHandler(projectUsecase)
+	reminderHandler := handler.NewReminderHandler(reminderUsecase)
+	shareHandler := handler.NewShareHandler(shareUsecase)
+	assignmentHandler := handler.NewAssignmentHandler(assignmentUsecase)
+	commentHandler := handler.NewCommentHandler(commentUsecase)
+	attachmentHandler := handler.NewAttachmentHandler(attachmentUsecase)
+	trashHandler := handler.NewTrashHandler(trashUsecase)
+	archiveHandler := handler.NewArchiveHandler(archivePolicyUsecase)
+	jwksHandler := handler.NewJWKSHandler(keys)
 
-	authMiddleware := middleware.NewAuthMiddleware()
+	authMiddleware := middleware.NewAuthMiddleware(tokens, revocationUsecase)
 
 	router := http.NewServeMux()
 
@@ -60,6 +201,30 @@ func main() {
 		middleware.CORS,
 		middleware.Logger))
 
+	router.HandleFunc("POST /api/token/refresh", middleware.Chain(
+		userHandler.Refresh,
+		middleware.CORS,
+		middleware.Logger))
+
+	router.HandleFunc("POST /api/logout", middleware.Chain(
+		userHandler.Logout,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/logout/all", middleware.Chain(
+		userHandler.LogoutEverywhere,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /.well-known/jwks.json", middleware.Chain(
+		jwksHandler.GetJWKS,
+		middleware.CORS,
+		middleware.Logger))
+
 	router.HandleFunc("POST /api/tasks", middleware.Chain(
         taskHandler.CreateTask,
         authMiddleware.Authenticate,
@@ -67,6 +232,384 @@ func main() {
         middleware.CORS,
     ))
 
+	router.HandleFunc("GET /api/tasks/overdue", middleware.Chain(
+		taskHandler.GetOverdueTasks,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/assigned", middleware.Chain(
+		taskHandler.GetAssignedTasks,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/today", middleware.Chain(
+		taskHandler.GetTasksDueToday,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/upcoming", middleware.Chain(
+		taskHandler.GetUpcomingTasks,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/tasks/{id}/tags/{tagID}", middleware.Chain(
+		taskHandler.AddTag,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/tasks/{id}/tags/{tagID}", middleware.Chain(
+		taskHandler.RemoveTag,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/complete", middleware.Chain(
+		taskHandler.CompleteTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/reopen", middleware.Chain(
+		taskHandler.ReopenTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/archive", middleware.Chain(
+		taskHandler.ArchiveCompletedTasks,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/archive", middleware.Chain(
+		taskHandler.ArchiveTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/unarchive", middleware.Chain(
+		taskHandler.UnarchiveTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/subtasks", middleware.Chain(
+		taskHandler.GetSubtasks,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/subtasks", middleware.Chain(
+		taskHandler.CreateSubtask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/tasks/{id}/subtasks/order", middleware.Chain(
+		taskHandler.ReorderSubtasks,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/tasks/{id}/project", middleware.Chain(
+		taskHandler.MoveTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/reminders", middleware.Chain(
+		reminderHandler.GetReminders,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/reminders", middleware.Chain(
+		reminderHandler.CreateReminder,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/tasks/{id}/reminders/{reminderID}", middleware.Chain(
+		reminderHandler.DeleteReminder,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/tasks/{id}/assignee", middleware.Chain(
+		assignmentHandler.AssignTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/assignments", middleware.Chain(
+		assignmentHandler.GetAssignmentHistory,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/comments", middleware.Chain(
+		commentHandler.GetComments,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/comments", middleware.Chain(
+		commentHandler.CreateComment,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/tasks/{id}/comments/{commentID}", middleware.Chain(
+		commentHandler.UpdateComment,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/tasks/{id}/comments/{commentID}", middleware.Chain(
+		commentHandler.DeleteComment,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/history", middleware.Chain(
+		taskHandler.GetTaskHistory,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/history/{version}/revert", middleware.Chain(
+		taskHandler.RevertTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/attachments", middleware.Chain(
+		attachmentHandler.GetAttachments,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/attachments", middleware.Chain(
+		attachmentHandler.UploadAttachment,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/attachments/{attachmentID}", middleware.Chain(
+		attachmentHandler.DownloadAttachment,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/tasks/{id}/attachments/{attachmentID}", middleware.Chain(
+		attachmentHandler.DeleteAttachment,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tasks/{id}/shares", middleware.Chain(
+		shareHandler.GetTaskShares,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tasks/{id}/shares", middleware.Chain(
+		shareHandler.ShareTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/tasks/{id}/shares/{userID}", middleware.Chain(
+		shareHandler.UnshareTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/trash", middleware.Chain(
+		trashHandler.GetTrash,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/trash/{id}/restore", middleware.Chain(
+		trashHandler.RestoreTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/trash/{id}", middleware.Chain(
+		trashHandler.PurgeTask,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/settings/archive", middleware.Chain(
+		archiveHandler.GetPolicy,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/settings/archive", middleware.Chain(
+		archiveHandler.SetPolicy,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/projects", middleware.Chain(
+		projectHandler.GetAllProjects,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/projects", middleware.Chain(
+		projectHandler.CreateProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/projects/{id}", middleware.Chain(
+		projectHandler.GetProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/projects/{id}", middleware.Chain(
+		projectHandler.UpdateProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/projects/{id}", middleware.Chain(
+		projectHandler.DeleteProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/projects/{id}/archive", middleware.Chain(
+		projectHandler.ArchiveProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/projects/{id}/unarchive", middleware.Chain(
+		projectHandler.UnarchiveProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/projects/{id}/tasks", middleware.Chain(
+		taskHandler.GetProjectTasks,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/projects/{id}/shares", middleware.Chain(
+		shareHandler.GetProjectShares,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/projects/{id}/shares", middleware.Chain(
+		shareHandler.ShareProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/projects/{id}/shares/{userID}", middleware.Chain(
+		shareHandler.UnshareProject,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tags", middleware.Chain(
+		tagHandler.GetAllTags,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("POST /api/tags", middleware.Chain(
+		tagHandler.CreateTag,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("GET /api/tags/{id}", middleware.Chain(
+		tagHandler.GetTag,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("PUT /api/tags/{id}", middleware.Chain(
+		tagHandler.UpdateTag,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
+	router.HandleFunc("DELETE /api/tags/{id}", middleware.Chain(
+		tagHandler.DeleteTag,
+		authMiddleware.Authenticate,
+		middleware.Logger,
+		middleware.CORS,
+	))
+
 	router.HandleFunc("/api/tasks/", middleware.Chain(
         func(w http.ResponseWriter, r *http.Request) {
             switch r.Method {
@@ -81,7 +624,7 @@ func main() {
             case http.MethodDelete:
                 taskHandler.DeleteTask(w, r)
             default:
-                http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+                response.Error(w, http.StatusMethodNotAllowed, "Method not allowed")
             }
         },
         authMiddleware.Authenticate,
@@ -89,22 +632,290 @@ func main() {
         middleware.CORS,
     ))
 
+	if config.ReminderInterval > 0 {
+		go scheduler.Every(context.Background(), config.ReminderInterval, "reminders", reminderUsecase.DispatchDue)
+	}
+	if config.TrashPurgeInterval > 0 {
+		go scheduler.Every(context.Background(), config.TrashPurgeInterval, "trash purge", trashUsecase.PurgeExpired)
+	}
+	if config.TokenCleanupInterval > 0 {
+		go scheduler.Every(context.Background(), config.TokenCleanupInterval, "token cleanup", userUsecase.PurgeExpiredTokens)
+	}
+	if config.AutoArchiveInterval > 0 {
+		go scheduler.Every(context.Background(), config.AutoArchiveInterval, "auto archive", archivePolicyUsecase.ApplyAll)
+	}
+
 	serverAddr := fmt.Sprintf(":%s", config.ServerPort)
     log.Printf("Server starting on %s", serverAddr)
     log.Fatal(http.ListenAndServe(serverAddr, router))
 }
 
-func parseConfig() *Config {
+func parseConfig(name string, args []string) (*Config, []string) {
 	config := &Config{}
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+
+	flags.StringVar(&config.Storage, "storage", storageSQL, "Storage backend: sql or memory")
+	flags.StringVar(&config.DBDriver, "db-driver", driverMySQL, "SQL database driver: mysql, postgres or sqlite")
+	flags.StringVar(&config.DBHost, "db-host", "localhost", "Database host")
+	flags.StringVar(&config.DBPort, "db-port", "", "Database port (default 3306 for mysql, 5432 for postgres)")
+	flags.StringVar(&config.DBUser, "db-user", "root", "Database user")
+	flags.StringVar(&config.DBPassword, "db-password", "", "Database password")
+	flags.StringVar(&config.DBName, "db-name", "go_todo", "Database name")
+	flags.StringVar(&config.DBSSLMode, "db-sslmode", "disable", "Postgres sslmode")
+	flags.StringVar(&config.SQLitePath, "sqlite-path", "todo.db", "SQLite database file, or :memory:")
+
+	flags.StringVar(&config.ServerPort, "port", "8080", "Server port")
+
+	flags.BoolVar(&config.Migrate, "migrate", false, "Apply pending schema migrations on startup")
+	flags.DurationVar(&config.SQLiteMigrationStaleLock, "sqlite-migration-stale-lock", migrate.DefaultSQLiteStaleLock, "Age after which a SQLite migration lock is taken to be left by a crashed process and taken over; keep it above the longest migration run")
+
+	flags.DurationVar(&config.ReminderInterval, "reminder-interval", 30*time.Second, "How often due reminders are sent, or 0 to not send them from this instance")
+	flags.StringVar(&config.Notifier, "notifier", notifierLog, "Reminder delivery: log or webhook")
+	flags.StringVar(&config.WebhookURL, "webhook-url", "", "URL reminders are POSTed to with -notifier=webhook")
+	flags.StringVar(&config.WebhookSecret, "webhook-secret", "", "Secret for the X-Signature HMAC of webhook bodies")
+
+	flags.StringVar(&config.BlobStore, "blob-store", blobStoreLocal, "Where attachment content is kept: local or s3")
+	flags.StringVar(&config.BlobDir, "blob-dir", "attachments", "Directory for attachment content with -blob-store=local")
+	flags.StringVar(&config.S3Endpoint, "s3-endpoint", "", "S3-compatible endpoint URL with -blob-store=s3, such as https://s3.us-east-1.amazonaws.com")
+	flags.StringVar(&config.S3Bucket, "s3-bucket", "", "Bucket for attachment content with -blob-store=s3")
+	flags.StringVar(&config.S3Region, "s3-region", "us-east-1", "Region requests to the S3 endpoint are signed for")
+	flags.StringVar(&config.S3AccessKey, "s3-access-key", "", "Access key for the S3 endpoint")
+	flags.StringVar(&config.S3SecretKey, "s3-secret-key", "", "Secret key for the S3 endpoint")
+	flags.Int64Var(&config.AttachmentMaxSize, "attachment-max-size", 10<<20, "Largest attachment accepted, in bytes")
+	flags.Int64Var(&config.AttachmentQuota, "attachment-quota", 100<<20, "Total size of the attachments each user may upload, in bytes")
+
+	flags.DurationVar(&config.TrashRetention, "trash-retention", 30*24*time.Hour, "How long deleted tasks stay in the trash before they are purged")
+	flags.DurationVar(&config.TrashPurgeInterval, "trash-purge-interval", time.Hour, "How often expired tasks are purged from the trash, or 0 to not purge them from this instance")
+	flags.DurationVar(&config.AutoArchiveInterval, "auto-archive-interval", time.Hour, "How often users' archive policies are applied, or 0 to not apply them from this instance")
+
+	flags.StringVar(&config.JWTKeys, "jwt-keys", os.Getenv("JWT_KEYS"), `JWT keys as a JSON array of {"kid", "alg" (HS256, EdDSA, RS256 or ES256), "secret" (base64, HS256), "private_key"/"private_key_file" or "public_key"/"public_key_file" (PEM), "retired"} (env JWT_KEYS)`)
+	flags.StringVar(&config.JWTKeysFile, "jwt-keys-file", os.Getenv("JWT_KEYS_FILE"), "File holding the JWT signing keys in the -jwt-keys format (env JWT_KEYS_FILE)")
+	flags.StringVar(&config.JWTSigningKey, "jwt-signing-key", os.Getenv("JWT_SIGNING_KEY"), "kid of the key new tokens are signed with, by default the first key not retired (env JWT_SIGNING_KEY)")
+	flags.DurationVar(&config.AccessTokenTTL, "access-token-ttl", 15*time.Minute, "How long access tokens are valid for")
+	flags.DurationVar(&config.RefreshTokenTTL, "refresh-token-ttl", 30*24*time.Hour, "How long a refresh token can be used for; each refresh issues a new one")
+	flags.DurationVar(&config.TokenCleanupInterval, "token-cleanup-interval", time.Hour, "How often expired refresh tokens and token revocations are deleted, or 0 to not delete them from this instance")
+	flags.DurationVar(&config.RevocationCacheTTL, "revocation-cache-ttl", 30*time.Second, "How long a token found not to be revoked is trusted without checking again, which bounds how long other instances take to reject a revoked token; 0 checks on every request")
+
+	flags.UintVar(&config.PasswordHashMemory, "password-hash-memory", uint(security.DefaultParams.Memory), "Memory argon2id password hashes use, in KiB; hashes made with other parameters are upgraded on login")
+	flags.UintVar(&config.PasswordHashIterations, "password-hash-iterations", uint(security.DefaultParams.Iterations), "Number of argon2id passes over the memory of a password hash")
+	flags.UintVar(&config.PasswordHashParallelism, "password-hash-parallelism", uint(security.DefaultParams.Parallelism), "Number of argon2id lanes of a password hash, at most 255")
+
+	flags.Parse(args)
+
+	if config.DBPort == "" {
+		config.DBPort = defaultPorts[config.DBDriver]
+	}
+
+	return config, flags.Args()
+}
+
+type repositories struct {
+	user    domain.UserRepository
+	task    domain.TaskRepository
+	tag     domain.TagRepository
+	project domain.ProjectRepository
+
+	reminder   domain.ReminderRepository
+	share      domain.ShareRepository
+	assignment domain.AssignmentRepository
+	comment    domain.CommentRepository
+	attachment domain.AttachmentRepository
+	history    domain.TaskHistoryRepository
+
+	archivePolicy domain.ArchivePolicyRepository
+	refreshToken  domain.RefreshTokenRepository
+
+	tokenRevocation domain.TokenRevocationRepository
+}
+
+// openRepositories builds the repositories for the configured storage
+// backend. The returned func releases whatever the backend holds open.
+func openRepositories(config *Config) (*repositories, func(), error) {
+	switch config.Storage {
+	case storageMemory:
+		store := memoryrepo.NewStore()
+		return &repositories{
+			user:    memoryrepo.NewMemoryUserRepository(store),
+			task:    memoryrepo.NewMemoryTaskRepository(store),
+			tag:     memoryrepo.NewMemoryTagRepository(store),
+			project: memoryrepo.NewMemoryProjectRepository(store),
+
+			reminder:   memoryrepo.NewMemoryReminderRepository(store),
+			share:      memoryrepo.NewMemoryShareRepository(store),
+			assignment: memoryrepo.NewMemoryAssignmentRepository(store),
+			comment:    memoryrepo.NewMemoryCommentRepository(store),
+			attachment: memoryrepo.NewMemoryAttachmentRepository(store),
+			history:    memoryrepo.NewMemoryTaskHistoryRepository(store),
+
+			archivePolicy: memoryrepo.NewMemoryArchivePolicyRepository(store),
+			refreshToken:  memoryrepo.NewMemoryRefreshTokenRepository(store),
+
+			tokenRevocation: memoryrepo.NewMemoryTokenRevocationRepository(store),
+		}, func() {}, nil
+	case storageSQL:
+		db, err := openDatabase(config)
+		if err != nil {
+			return nil, nil, err
+		}
+		if config.Migrate {
+			if err := migrateUp(config, db); err != nil {
+				db.Close()
+				return nil, nil, err
+			}
+		}
+		return newSQLRepositories(config.DBDriver, db), func() { db.Close() }, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown storage %q", config.Storage)
+	}
+}
 
-	flag.StringVar(&config.DBHost, "db-host", "localhost", "Database host")
-	flag.StringVar(&config.DBPort, "db-port", "3306", "Database port")
-	flag.StringVar(&config.DBUser, "db-user", "root", "Database user")
-	flag.StringVar(&config.DBPassword, "db-password", "", "Database password")
-	flag.StringVar(&config.DBName, "db-name", "go_todo", "Database name")
+func newSQLRepositories(driver string, db *sql.DB) *repositories {
+	switch driver {
+	case driverPostgres:
+		return &repositories{
+			user:    postgresrepo.NewPostgresUserRepository(db),
+			task:    postgresrepo.NewPostgresTaskRepository(db),
+			tag:     postgresrepo.NewPostgresTagRepository(db),
+			project: postgresrepo.NewPostgresProjectRepository(db),
 
-	flag.StringVar(&config.ServerPort, "port", "8080", "Server port")
+			reminder:   postgresrepo.NewPostgresReminderRepository(db),
+			share:      postgresrepo.NewPostgresShareRepository(db),
+			assignment: postgresrepo.NewPostgresAssignmentRepository(db),
+			comment:    postgresrepo.NewPostgresCommentRepository(db),
+			attachment: postgresrepo.NewPostgresAttachmentRepository(db),
+			history:    postgresrepo.NewPostgresTaskHistoryRepository(db),
 
-	flag.Parse()
-	return config
-}
\ No newline at end of file
+			archivePolicy: postgresrepo.NewPostgresArchivePolicyRepository(db),
+			refreshToken:  postgresrepo.NewPostgresRefreshTokenRepository(db),
+
+			tokenRevocation: postgresrepo.NewPostgresTokenRevocationRepository(db),
+		}
+	case driverSQLite:
+		return &repositories{
+			user:    sqliterepo.NewSqliteUserRepository(db),
+			task:    sqliterepo.NewSqliteTaskRepository(db),
+			tag:     sqliterepo.NewSqliteTagRepository(db),
+			project: sqliterepo.NewSqliteProjectRepository(db),
+
+			reminder:   sqliterepo.NewSqliteReminderRepository(db),
+			share:      sqliterepo.NewSqliteShareRepository(db),
+			assignment: sqliterepo.NewSqliteAssignmentRepository(db),
+			comment:    sqliterepo.NewSqliteCommentRepository(db),
+			attachment: sqliterepo.NewSqliteAttachmentRepository(db),
+			history:    sqliterepo.NewSqliteTaskHistoryRepository(db),
+
+			archivePolicy: sqliterepo.NewSqliteArchivePolicyRepository(db),
+			refreshToken:  sqliterepo.NewSqliteRefreshTokenRepository(db),
+
+			tokenRevocation: sqliterepo.NewSqliteTokenRevocationRepository(db),
+		}
+	default:
+		return &repositories{
+			user:    mysqlrepo.NewMysqlUserRepository(db),
+			task:    mysqlrepo.NewMysqlTaskRepository(db),
+			tag:     mysqlrepo.NewMysqlTagRepository(db),
+			project: mysqlrepo.NewMysqlProjectRepository(db),
+
+			reminder:   mysqlrepo.NewMysqlReminderRepository(db),
+			share:      mysqlrepo.NewMysqlShareRepository(db),
+			assignment: mysqlrepo.NewMysqlAssignmentRepository(db),
+			comment:    mysqlrepo.NewMysqlCommentRepository(db),
+			attachment: mysqlrepo.NewMysqlAttachmentRepository(db),
+			history:    mysqlrepo.NewMysqlTaskHistoryRepository(db),
+
+			archivePolicy: mysqlrepo.NewMysqlArchivePolicyRepository(db),
+			refreshToken:  mysqlrepo.NewMysqlRefreshTokenRepository(db),
+
+			tokenRevocation: mysqlrepo.NewMysqlTokenRevocationRepository(db),
+		}
+	}
+}
+
+// newKeySet loads the JWT keys from -jwt-keys or -jwt-keys-file. Without
+// either it signs with a random key, so tokens do not survive a restart and
+// are not accepted by other instances.
+func newKeySet(config *Config) (*auth.KeySet, error) {
+	var data []byte
+	switch {
+	case config.JWTKeys != "" && config.JWTKeysFile != "":
+		return nil, fmt.Errorf("-jwt-keys and -jwt-keys-file are mutually exclusive")
+	case config.JWTKeys != "":
+		data = []byte(config.JWTKeys)
+	case config.JWTKeysFile != "":
+		var err error
+		if data, err = os.ReadFile(config.JWTKeysFile); err != nil {
+			return nil, fmt.Errorf("error reading JWT keys: %w", err)
+		}
+	default:
+		log.Printf("No JWT keys configured, signing with a random key; tokens will not survive a restart")
+		key, err := auth.GenerateKey("ephemeral")
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewKeySet([]auth.Key{key}, "")
+	}
+
+	keys, err := auth.ParseKeys(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return auth.NewKeySet(keys, config.JWTSigningKey)
+}
+
+// newNotifier builds the configured reminder delivery channel.
+func newNotifier(config *Config) (domain.Notifier, error) {
+	switch config.Notifier {
+	case notifierLog:
+		return notifier.NewLogNotifier(), nil
+	case notifierWebhook:
+		if config.WebhookURL == "" {
+			return nil, fmt.Errorf("-notifier=%s requires -webhook-url", notifierWebhook)
+		}
+		return notifier.NewWebhookNotifier(config.WebhookURL, config.WebhookSecret, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", config.Notifier)
+	}
+}
+
+// newBlobStore builds the configured store for attachment content.
+func newBlobStore(config *Config) (domain.BlobStore, error) {
+	switch config.BlobStore {
+	case blobStoreLocal:
+		return blob.NewLocalStore(config.BlobDir)
+	case blobStoreS3:
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  config.S3Endpoint,
+			Bucket:    config.S3Bucket,
+			Region:    config.S3Region,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+		}, nil)
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", config.BlobStore)
+	}
+}
+
+func openDatabase(config *Config) (*sql.DB, error) {
+	switch config.DBDriver {
+	case driverMySQL:
+		return mysql.NewConnection(
+			config.DBHost,
+			config.DBPort,
+			config.DBUser,
+			config.DBPassword,
+			config.DBName)
+	case driverPostgres:
+		return postgres.NewConnection(
+			config.DBHost,
+			config.DBPort,
+			config.DBUser,
+			config.DBPassword,
+			config.DBName,
+			config.DBSSLMode)
+	case driverSQLite:
+		return sqlite.NewConnection(config.SQLitePath)
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", config.DBDriver)
+	}
+}
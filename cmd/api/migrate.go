@@ -0,0 +1,115 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"todo-app/internal/migrations"
+	"todo-app/internal/pkg/migrate"
+)
+
+const migrateUsage = `usage: %s migrate [flags] <command>
+
+commands:
+  up            apply every pending migration
+  down          roll back the most recent migration
+  status        list migrations and whether they are applied
+  to VERSION    migrate up or down to exactly VERSION (0 rolls back everything)
+`
+
+func runMigrateCommand(args []string) {
+	config, rest := parseConfig(os.Args[0]+" migrate", args)
+	if len(rest) == 0 {
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		os.Exit(2)
+	}
+
+	if config.Storage != storageSQL {
+		log.Fatalf("Migrations only apply to -storage=%s", storageSQL)
+	}
+
+	db, err := openDatabase(config)
+	if err != nil {
+		log.Fatalf("Failed to connect to database : %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := newMigrator(config, db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations : %v", err)
+	}
+
+	switch rest[0] {
+	case "up":
+		err = migrator.Up()
+	case "down":
+		err = migrator.Down()
+	case "to":
+		if len(rest) != 2 {
+			log.Fatalf("migrate to requires a version")
+		}
+		version, parseErr := strconv.ParseInt(rest[1], 10, 64)
+		if parseErr != nil {
+			log.Fatalf("Invalid version %q", rest[1])
+		}
+		err = migrator.To(version)
+	case "status":
+		err = printMigrationStatus(migrator)
+	default:
+		fmt.Fprintf(os.Stderr, migrateUsage, os.Args[0])
+		os.Exit(2)
+	}
+
+	if err != nil {
+		log.Fatalf("Migration failed : %v", err)
+	}
+}
+
+// newMigrator pairs the driver's migration files with its locking dialect.
+func newMigrator(config *Config, db *sql.DB) (*migrate.Migrator, error) {
+	dialects := map[string]migrate.Dialect{
+		driverMySQL:    migrate.MySQL,
+		driverPostgres: migrate.Postgres,
+		driverSQLite:   migrate.NewSQLite(config.SQLiteMigrationStaleLock),
+	}
+
+	dialect, ok := dialects[config.DBDriver]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for database driver %q", config.DBDriver)
+	}
+
+	files, err := migrations.For(config.DBDriver)
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.New(db, dialect, files)
+}
+
+func migrateUp(config *Config, db *sql.DB) error {
+	migrator, err := newMigrator(config, db)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Up()
+}
+
+func printMigrationStatus(migrator *migrate.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		appliedAt := "pending"
+		if status.Applied {
+			appliedAt = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%04d  %-30s  %s\n", status.Version, status.Name, appliedAt)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/request"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+)
+
+type ArchiveHandler struct {
+    archivePolicyUsecase domain.ArchivePolicyUsecase
+}
+
+func NewArchiveHandler(archivePolicyUsecase domain.ArchivePolicyUsecase) *ArchiveHandler {
+    return &ArchiveHandler{
+        archivePolicyUsecase: archivePolicyUsecase,
+    }
+}
+
+type archivePolicyRequest struct {
+    AfterDays int `json:"after_days"`
+}
+
+func (h *ArchiveHandler) GetPolicy(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    policy, err := h.archivePolicyUsecase.Get(claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Archive policy retrieved successfully", policy)
+}
+
+func (h *ArchiveHandler) SetPolicy(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    var req archivePolicyRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    policy, err := h.archivePolicyUsecase.Set(claims.UserID, req.AfterDays)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Archive policy updated successfully", policy)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/request"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+)
+
+type AssignmentHandler struct {
+    assignmentUsecase domain.AssignmentUsecase
+}
+
+func NewAssignmentHandler(assignmentUsecase domain.AssignmentUsecase) *AssignmentHandler {
+    return &AssignmentHandler{
+        assignmentUsecase: assignmentUsecase,
+    }
+}
+
+type assignRequest struct {
+    AssigneeID *int64 `json:"assignee_id"`
+}
+
+func (h *AssignmentHandler) AssignTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    var req assignRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    if err := h.assignmentUsecase.Assign(taskID, claims.UserID, req.AssigneeID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task assigned successfully", nil)
+}
+
+func (h *AssignmentHandler) GetAssignmentHistory(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    assignments, err := h.assignmentUsecase.GetHistory(taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Assignment history retrieved successfully", assignments)
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/request"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+)
+
+// attachmentField is the multipart form field an upload is sent in.
+const attachmentField = "file"
+
+type AttachmentHandler struct {
+    attachmentUsecase domain.AttachmentUsecase
+}
+
+func NewAttachmentHandler(attachmentUsecase domain.AttachmentUsecase) *AttachmentHandler {
+    return &AttachmentHandler{
+        attachmentUsecase: attachmentUsecase,
+    }
+}
+
+// UploadAttachment streams the file part of a multipart/form-data body to
+// the usecase, which stops reading once the upload is over the size limit.
+func (h *AttachmentHandler) UploadAttachment(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    reader, err := r.MultipartReader()
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Request body must be multipart/form-data")
+        return
+    }
+
+    for {
+        part, err := reader.NextPart()
+        if errors.Is(err, io.EOF) {
+            break
+        }
+        if err != nil {
+            response.Error(w, http.StatusBadRequest, "Invalid multipart body")
+            return
+        }
+        if part.FormName() != attachmentField {
+            part.Close()
+            continue
+        }
+
+        attachment, err := h.attachmentUsecase.Upload(r.Context(), taskID, claims.UserID, part.FileName(), part)
+        part.Close()
+        if err != nil {
+            response.FromError(w, err)
+            return
+        }
+
+        response.Success(w, http.StatusCreated, "Attachment uploaded successfully", attachment)
+        return
+    }
+
+    response.Error(w, http.StatusBadRequest, "Missing "+attachmentField+" field")
+}
+
+func (h *AttachmentHandler) GetAttachments(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    attachments, err := h.attachmentUsecase.GetByTaskID(taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Attachments retrieved successfully", attachments)
+}
+
+// DownloadAttachment sends the content itself rather than a JSON envelope.
+// It is always offered as a download, and browsers are told not to second
+// guess the sniffed type, so an uploaded page cannot run as this origin.
+func (h *AttachmentHandler) DownloadAttachment(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    attachmentID, err := request.PathID(r, "attachmentID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid attachment ID")
+        return
+    }
+
+    attachment, content, err := h.attachmentUsecase.Open(r.Context(), attachmentID, taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+    defer content.Close()
+
+    w.Header().Set("Content-Type", attachment.ContentType)
+    w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+    w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename}))
+    w.Header().Set("X-Content-Type-Options", "nosniff")
+    w.WriteHeader(http.StatusOK)
+
+    if _, err := io.Copy(w, content); err != nil {
+        log.Printf("error sending attachment %d: %v", attachment.ID, err)
+    }
+}
+
+func (h *AttachmentHandler) DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    attachmentID, err := request.PathID(r, "attachmentID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid attachment ID")
+        return
+    }
+
+    if err := h.attachmentUsecase.Delete(r.Context(), attachmentID, taskID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Attachment deleted successfully", nil)
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"net/http"
+	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/request"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+)
+
+type CommentHandler struct {
+    commentUsecase domain.CommentUsecase
+}
+
+func NewCommentHandler(commentUsecase domain.CommentUsecase) *CommentHandler {
+    return &CommentHandler{
+        commentUsecase: commentUsecase,
+    }
+}
+
+type commentRequest struct {
+    Body string `json:"body"`
+}
+
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    var req commentRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    comment, err := h.commentUsecase.Create(taskID, claims.UserID, req.Body)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusCreated, "Comment created successfully", comment)
+}
+
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    comments, err := h.commentUsecase.GetByTaskID(taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Comments retrieved successfully", comments)
+}
+
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    commentID, err := request.PathID(r, "commentID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid comment ID")
+        return
+    }
+
+    var req commentRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    comment, err := h.commentUsecase.Update(commentID, taskID, claims.UserID, req.Body)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Comment updated successfully", comment)
+}
+
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    commentID, err := request.PathID(r, "commentID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid comment ID")
+        return
+    }
+
+    if err := h.commentUsecase.Delete(commentID, taskID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Comment deleted successfully", nil)
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"todo-app/internal/pkg/auth"
+)
+
+// jwksCacheControl lets clients cache the key set for five minutes. A new
+// signing key should be published at least that long before tokens are
+// signed with it.
+const jwksCacheControl = "public, max-age=300"
+
+type JWKSHandler struct {
+    keys *auth.KeySet
+}
+
+func NewJWKSHandler(keys *auth.KeySet) *JWKSHandler {
+    return &JWKSHandler{keys: keys}
+}
+
+// GetJWKS serves the public keys as a bare JWK set rather than in the usual
+// response envelope, as JWT libraries expect.
+func (h *JWKSHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Cache-Control", jwksCacheControl)
+    json.NewEncoder(w).Encode(h.keys.JWKS())
+}
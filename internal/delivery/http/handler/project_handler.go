@@ -0,0 +1,199 @@
+package handler
+
+import (
+	"net/http"
+	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/request"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+)
+
+type ProjectHandler struct {
+    projectUsecase domain.ProjectUsecase
+}
+
+func NewProjectHandler(projectUsecase domain.ProjectUsecase) *ProjectHandler {
+    return &ProjectHandler{
+        projectUsecase: projectUsecase,
+    }
+}
+
+type projectRequest struct {
+    Name        string `json:"name"`
+    Description string `json:"description"`
+}
+
+func (h *ProjectHandler) CreateProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    var req projectRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    project, err := h.projectUsecase.Create(claims.UserID, domain.ProjectInput{
+        Name:        req.Name,
+        Description: req.Description,
+    })
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusCreated, "Project created successfully", project)
+}
+
+func (h *ProjectHandler) UpdateProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    var req projectRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    err = h.projectUsecase.Update(projectID, claims.UserID, domain.ProjectInput{
+        Name:        req.Name,
+        Description: req.Description,
+    })
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project updated successfully", nil)
+}
+
+// DeleteProject deletes a project. Its tasks are kept outside any project,
+// or deleted along with it when called with ?tasks=delete.
+func (h *ProjectHandler) DeleteProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    var deleteTasks bool
+    switch r.URL.Query().Get("tasks") {
+    case "", "keep":
+    case "delete":
+        deleteTasks = true
+    default:
+        response.Error(w, http.StatusBadRequest, "invalid tasks: must be keep or delete")
+        return
+    }
+
+    if err := h.projectUsecase.Delete(projectID, claims.UserID, deleteTasks); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project deleted successfully", nil)
+}
+
+func (h *ProjectHandler) GetProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    project, err := h.projectUsecase.GetByID(projectID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project retrieved successfully", project)
+}
+
+func (h *ProjectHandler) GetAllProjects(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    includeArchived, err := request.QueryBool(r, "include_archived")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    projects, err := h.projectUsecase.GetAllByUserID(claims.UserID, includeArchived != nil && *includeArchived)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Projects retrieved successfully", projects)
+}
+
+func (h *ProjectHandler) ArchiveProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    if err := h.projectUsecase.Archive(projectID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project archived successfully", nil)
+}
+
+func (h *ProjectHandler) UnarchiveProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    if err := h.projectUsecase.Unarchive(projectID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project unarchived successfully", nil)
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/request"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+)
+
+type ReminderHandler struct {
+    reminderUsecase domain.ReminderUsecase
+}
+
+func NewReminderHandler(reminderUsecase domain.ReminderUsecase) *ReminderHandler {
+    return &ReminderHandler{
+        reminderUsecase: reminderUsecase,
+    }
+}
+
+type reminderRequest struct {
+    RemindAt      *time.Time `json:"remind_at"`
+    MinutesBefore *int       `json:"minutes_before"`
+}
+
+func (h *ReminderHandler) CreateReminder(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    var req reminderRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    reminder, err := h.reminderUsecase.Create(taskID, claims.UserID, domain.ReminderInput{
+        RemindAt:      req.RemindAt,
+        MinutesBefore: req.MinutesBefore,
+    })
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusCreated, "Reminder created successfully", reminder)
+}
+
+func (h *ReminderHandler) GetReminders(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    reminders, err := h.reminderUsecase.GetByTaskID(taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Reminders retrieved successfully", reminders)
+}
+
+func (h *ReminderHandler) DeleteReminder(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    reminderID, err := request.PathID(r, "reminderID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid reminder ID")
+        return
+    }
+
+    if err := h.reminderUsecase.Delete(reminderID, taskID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Reminder deleted successfully", nil)
+}
@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"net/http"
+	"todo-app/internal/delivery/http/middleware"
+	"todo-app/internal/delivery/http/request"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
+)
+
+type ShareHandler struct {
+    shareUsecase domain.ShareUsecase
+}
+
+func NewShareHandler(shareUsecase domain.ShareUsecase) *ShareHandler {
+    return &ShareHandler{
+        shareUsecase: shareUsecase,
+    }
+}
+
+type shareRequest struct {
+    Username string            `json:"username"`
+    Role     domain.Permission `json:"role"`
+}
+
+func (h *ShareHandler) ShareTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    var req shareRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    share, err := h.shareUsecase.ShareTask(taskID, claims.UserID, req.Username, req.Role)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task shared successfully", share)
+}
+
+func (h *ShareHandler) UnshareTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    userID, err := request.PathID(r, "userID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    if err := h.shareUsecase.UnshareTask(taskID, claims.UserID, userID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task unshared successfully", nil)
+}
+
+func (h *ShareHandler) GetTaskShares(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    shares, err := h.shareUsecase.GetTaskShares(taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task shares retrieved successfully", shares)
+}
+
+func (h *ShareHandler) ShareProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    var req shareRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    share, err := h.shareUsecase.ShareProject(projectID, claims.UserID, req.Username, req.Role)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project shared successfully", share)
+}
+
+func (h *ShareHandler) UnshareProject(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    userID, err := request.PathID(r, "userID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid user ID")
+        return
+    }
+
+    if err := h.shareUsecase.UnshareProject(projectID, claims.UserID, userID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project unshared successfully", nil)
+}
+
+func (h *ShareHandler) GetProjectShares(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
+        return
+    }
+
+    shares, err := h.shareUsecase.GetProjectShares(projectID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Project shares retrieved successfully", shares)
+}
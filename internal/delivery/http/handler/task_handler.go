@@ -1,13 +1,19 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 	"todo-app/internal/delivery/http/middleware"
 	"todo-app/internal/delivery/http/request"
 	"todo-app/internal/delivery/http/response"
 	"todo-app/internal/domain"
 )
 
+// defaultUpcomingDays is the window of GET /api/tasks/upcoming without ?days.
+const defaultUpcomingDays = 7
+
 type TaskHandler struct {
     taskUsecase domain.TaskUsecase
 }
@@ -19,14 +25,49 @@ func NewTaskHandler(taskUsecase domain.TaskUsecase) *TaskHandler {
 }
 
 type createTaskRequest struct {
-    Title       string `json:"title"`
-    Description string `json:"description"`
+    ProjectID    *int64     `json:"project_id"`
+    Title        string     `json:"title"`
+    Description  string     `json:"description"`
+    Priority     string     `json:"priority"`
+    DueAt        *time.Time `json:"due_at"`
+    CompletedAt  *time.Time `json:"completed_at"`
+    Recurrence   string     `json:"recurrence"`
+    RecurrenceTZ string     `json:"recurrence_tz"`
+}
+
+type reorderSubtasksRequest struct {
+    SubtaskIDs []int64 `json:"subtask_ids"`
+}
+
+type moveTaskRequest struct {
+    ProjectID *int64 `json:"project_id"`
+}
+
+type archiveCompletedRequest struct {
+    OlderThanDays int `json:"older_than_days"`
 }
 
+type archiveCompletedResponse struct {
+    Archived int64 `json:"archived"`
+}
+
+type listMeta struct {
+    NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// updateTaskRequest replaces the task, except that leaving out due_at or
+// recurrence keeps the ones it has. A null due_at removes the due date and
+// an empty recurrence stops the task from recurring.
 type updateTaskRequest struct {
-    Title       string `json:"title"`
-    Description string `json:"description"`
-    Done        bool   `json:"done"`
+    ProjectID    *int64               `json:"project_id"`
+    Title        string               `json:"title"`
+    Description  string               `json:"description"`
+    Done         bool                 `json:"done"`
+    Priority     string               `json:"priority"`
+    DueAt        request.OptionalTime `json:"due_at"`
+    CompletedAt  *time.Time           `json:"completed_at"`
+    Recurrence   *string              `json:"recurrence"`
+    RecurrenceTZ string               `json:"recurrence_tz"`
 }
 
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -47,9 +88,24 @@ func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    err := h.taskUsecase.Create(claims.UserID, req.Title, req.Description)
+    priority, err := domain.ParsePriority(req.Priority)
     if err != nil {
-        response.Error(w, http.StatusInternalServerError, err.Error())
+        response.FromError(w, err)
+        return
+    }
+
+    err = h.taskUsecase.Create(claims.UserID, domain.TaskInput{
+        ProjectID:    req.ProjectID,
+        Title:        req.Title,
+        Description:  req.Description,
+        Priority:     priority,
+        DueAt:        req.DueAt,
+        CompletedAt:  req.CompletedAt,
+        Recurrence:   req.Recurrence,
+        RecurrenceTZ: req.RecurrenceTZ,
+    })
+    if err != nil {
+        response.FromError(w, err)
         return
     }
 
@@ -80,9 +136,31 @@ func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    err = h.taskUsecase.Update(taskID, claims.UserID, req.Title, req.Description, req.Done)
+    priority, err := domain.ParsePriority(req.Priority)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    input := domain.TaskInput{
+        ProjectID:      req.ProjectID,
+        Title:          req.Title,
+        Description:    req.Description,
+        Done:           req.Done,
+        Priority:       priority,
+        DueAt:          req.DueAt.Value,
+        CompletedAt:    req.CompletedAt,
+        RecurrenceTZ:   req.RecurrenceTZ,
+        KeepDueAt:      !req.DueAt.Set,
+        KeepRecurrence: req.Recurrence == nil,
+    }
+    if req.Recurrence != nil {
+        input.Recurrence = *req.Recurrence
+    }
+
+    err = h.taskUsecase.Update(taskID, claims.UserID, input)
     if err != nil {
-        response.Error(w, http.StatusInternalServerError, err.Error())
+        response.FromError(w, err)
         return
     }
 
@@ -107,9 +185,15 @@ func (h *TaskHandler) DeleteTask(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    err = h.taskUsecase.Delete(taskID, claims.UserID)
+    cascade, err := request.QueryBool(r, "cascade")
     if err != nil {
-        response.Error(w, http.StatusInternalServerError, err.Error())
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    err = h.taskUsecase.Delete(taskID, claims.UserID, cascade != nil && *cascade)
+    if err != nil {
+        response.FromError(w, err)
         return
     }
 
@@ -136,7 +220,7 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 
     task, err := h.taskUsecase.GetByID(taskID, claims.UserID)
     if err != nil {
-        response.Error(w, http.StatusInternalServerError, err.Error())
+        response.FromError(w, err)
         return
     }
 
@@ -155,11 +239,559 @@ func (h *TaskHandler) GetAllTasks(w http.ResponseWriter, r *http.Request) {
         return
     }
 
-    tasks, err := h.taskUsecase.GetAllByUserID(claims.UserID)
+    query, err := parseTaskQuery(r)
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    page, err := h.taskUsecase.GetAllByUserID(claims.UserID, query)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    meta := listMeta{NextCursor: page.NextCursor}
+    response.SuccessWithMeta(w, http.StatusOK, "Tasks retrieved successfully", page.Tasks, meta)
+}
+
+// CompleteTask marks a task done. With ?cascade=true its open subtasks are
+// completed too; without it a task with open subtasks is refused.
+func (h *TaskHandler) CompleteTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    cascade, err := request.QueryBool(r, "cascade")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    if err := h.taskUsecase.Complete(taskID, claims.UserID, cascade != nil && *cascade); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task completed successfully", nil)
+}
+
+func (h *TaskHandler) ReopenTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    if err := h.taskUsecase.Reopen(taskID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task reopened successfully", nil)
+}
+
+func (h *TaskHandler) ArchiveTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    if err := h.taskUsecase.Archive(taskID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task archived successfully", nil)
+}
+
+func (h *TaskHandler) UnarchiveTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    if err := h.taskUsecase.Unarchive(taskID, claims.UserID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task unarchived successfully", nil)
+}
+
+func (h *TaskHandler) ArchiveCompletedTasks(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    var req archiveCompletedRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    archived, err := h.taskUsecase.ArchiveCompleted(claims.UserID, req.OlderThanDays)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Completed tasks archived successfully", archiveCompletedResponse{Archived: archived})
+}
+
+func (h *TaskHandler) CreateSubtask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    var req createTaskRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    priority, err := domain.ParsePriority(req.Priority)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    err = h.taskUsecase.AddSubtask(taskID, claims.UserID, domain.TaskInput{
+        ProjectID:    req.ProjectID,
+        Title:        req.Title,
+        Description:  req.Description,
+        Priority:     priority,
+        DueAt:        req.DueAt,
+        CompletedAt:  req.CompletedAt,
+        Recurrence:   req.Recurrence,
+        RecurrenceTZ: req.RecurrenceTZ,
+    })
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusCreated, "Subtask created successfully", nil)
+}
+
+func (h *TaskHandler) GetSubtasks(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    subtasks, err := h.taskUsecase.GetSubtasks(taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Subtasks retrieved successfully", subtasks)
+}
+
+func (h *TaskHandler) ReorderSubtasks(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    var req reorderSubtasksRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    if err := h.taskUsecase.ReorderSubtasks(taskID, claims.UserID, req.SubtaskIDs); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Subtasks reordered successfully", nil)
+}
+
+func (h *TaskHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    var req moveTaskRequest
+    if err := request.ParseJSON(r, &req); err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid request body")
+        return
+    }
+
+    if err := h.taskUsecase.MoveToProject(taskID, claims.UserID, req.ProjectID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task moved successfully", nil)
+}
+
+func (h *TaskHandler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    versions, err := h.taskUsecase.GetHistory(taskID, claims.UserID)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task history retrieved successfully", versions)
+}
+
+func (h *TaskHandler) RevertTask(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return
+    }
+
+    version, err := strconv.Atoi(r.PathValue("version"))
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid version")
+        return
+    }
+
+    if err := h.taskUsecase.Revert(taskID, claims.UserID, version); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Task reverted successfully", nil)
+}
+
+func (h *TaskHandler) GetProjectTasks(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    projectID, err := request.PathID(r, "id")
     if err != nil {
-        response.Error(w, http.StatusInternalServerError, err.Error())
+        response.Error(w, http.StatusBadRequest, "Invalid project ID")
         return
     }
 
-    response.Success(w, http.StatusOK, "Tasks retrieved successfully", tasks)
-}
\ No newline at end of file
+    query, err := parseTaskQuery(r)
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    page, err := h.taskUsecase.ListByProject(claims.UserID, projectID, query)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    meta := listMeta{NextCursor: page.NextCursor}
+    response.SuccessWithMeta(w, http.StatusOK, "Tasks retrieved successfully", page.Tasks, meta)
+}
+
+func (h *TaskHandler) AddTag(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, tagID, ok := taskTagIDs(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.taskUsecase.AddTag(taskID, claims.UserID, tagID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Tag added successfully", nil)
+}
+
+func (h *TaskHandler) RemoveTag(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    taskID, tagID, ok := taskTagIDs(w, r)
+    if !ok {
+        return
+    }
+
+    if err := h.taskUsecase.RemoveTag(taskID, claims.UserID, tagID); err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    response.Success(w, http.StatusOK, "Tag removed successfully", nil)
+}
+
+// taskTagIDs reads the {id} and {tagID} wildcards of the task tag routes,
+// writing a 400 and returning false when either is malformed.
+func taskTagIDs(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+    taskID, err := request.PathID(r, "id")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid task ID")
+        return 0, 0, false
+    }
+
+    tagID, err := request.PathID(r, "tagID")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, "Invalid tag ID")
+        return 0, 0, false
+    }
+
+    return taskID, tagID, true
+}
+
+func (h *TaskHandler) GetOverdueTasks(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    query, err := parseTaskQuery(r)
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    page, err := h.taskUsecase.ListOverdue(claims.UserID, query)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    meta := listMeta{NextCursor: page.NextCursor}
+    response.SuccessWithMeta(w, http.StatusOK, "Tasks retrieved successfully", page.Tasks, meta)
+}
+
+func (h *TaskHandler) GetAssignedTasks(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    query, err := parseTaskQuery(r)
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    page, err := h.taskUsecase.ListAssignedTo(claims.UserID, query)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    meta := listMeta{NextCursor: page.NextCursor}
+    response.SuccessWithMeta(w, http.StatusOK, "Tasks retrieved successfully", page.Tasks, meta)
+}
+
+func (h *TaskHandler) GetTasksDueToday(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    query, err := parseTaskQuery(r)
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    loc, err := request.QueryLocation(r, "tz")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    page, err := h.taskUsecase.ListDueToday(claims.UserID, loc, query)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    meta := listMeta{NextCursor: page.NextCursor}
+    response.SuccessWithMeta(w, http.StatusOK, "Tasks retrieved successfully", page.Tasks, meta)
+}
+
+func (h *TaskHandler) GetUpcomingTasks(w http.ResponseWriter, r *http.Request) {
+    claims, ok := middleware.GetUserFromContext(r.Context())
+    if !ok {
+        response.Error(w, http.StatusUnauthorized, "Unauthorized")
+        return
+    }
+
+    query, err := parseTaskQuery(r)
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    loc, err := request.QueryLocation(r, "tz")
+    if err != nil {
+        response.Error(w, http.StatusBadRequest, err.Error())
+        return
+    }
+
+    days := defaultUpcomingDays
+    if r.URL.Query().Has("days") {
+        if days, err = request.QueryInt(r, "days"); err != nil {
+            response.Error(w, http.StatusBadRequest, err.Error())
+            return
+        }
+    }
+
+    page, err := h.taskUsecase.ListDueWithin(claims.UserID, days, loc, query)
+    if err != nil {
+        response.FromError(w, err)
+        return
+    }
+
+    meta := listMeta{NextCursor: page.NextCursor}
+    response.SuccessWithMeta(w, http.StatusOK, "Tasks retrieved successfully", page.Tasks, meta)
+}
+
+// parseTaskQuery reads the listing parameters of GET /api/tasks/. Range and
+// enum checks are left to the usecase.
+func parseTaskQuery(r *http.Request) (domain.TaskQuery, error) {
+    values := r.URL.Query()
+    query := domain.TaskQuery{
+        Cursor:   values.Get("cursor"),
+        Sort:     domain.TaskSort(values.Get("sort")),
+        Order:    domain.SortOrder(values.Get("order")),
+        Archived: domain.ArchivedFilter(values.Get("archived")),
+    }
+
+    var err error
+    if query.Limit, err = request.QueryInt(r, "limit"); err != nil {
+        return query, err
+    }
+    includeSubtasks, err := request.QueryBool(r, "include_subtasks")
+    if err != nil {
+        return query, err
+    }
+    query.IncludeSubtasks = includeSubtasks != nil && *includeSubtasks
+    if query.ProjectID, err = request.QueryID(r, "project_id"); err != nil {
+        return query, err
+    }
+    if query.AssigneeID, err = request.QueryID(r, "assignee_id"); err != nil {
+        return query, err
+    }
+    if query.Done, err = request.QueryBool(r, "done"); err != nil {
+        return query, err
+    }
+    for _, name := range request.QueryList(r, "priority") {
+        priority, err := domain.ParsePriority(name)
+        if err != nil {
+            return query, fmt.Errorf("invalid priority: %q", name)
+        }
+        query.Priorities = append(query.Priorities, priority)
+    }
+    query.Tags = request.QueryList(r, "tag")
+    query.TagMatch = domain.TagMatch(values.Get("tag_match"))
+    if query.CreatedAfter, err = request.QueryTime(r, "created_after"); err != nil {
+        return query, err
+    }
+    if query.CreatedBefore, err = request.QueryTime(r, "created_before"); err != nil {
+        return query, err
+    }
+    if query.UpdatedAfter, err = request.QueryTime(r, "updated_after"); err != nil {
+        return query, err
+    }
+    if query.UpdatedBefore, err = request.QueryTime(r, "updated_before"); err != nil {
+        return query, err
+    }
+    if query.DueFrom, err = request.QueryTime(r, "due_from"); err != nil {
+        return query, err
+    }
+    if query.DueBefore, err = request.QueryTime(r, "due_before"); err != nil {
+        return query, err
+    }
+
+    return query, nil
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"net/http"
+	"time"
+	"todo-app/internal/delivery/http/middleware"
 	"todo-app/internal/delivery/http/request"
 	"todo-app/internal/delivery/http/response"
 	"todo-app/internal/domain"
@@ -28,8 +30,24 @@ type loginRequest struct {
 }
 
 
-type loginResponse struct {
-	Token string `json:"token"`
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// tokenResponse carries a token pair. Token is the access token, under the
+// name it had before refresh tokens existed.
+type tokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func newTokenResponse(pair *domain.TokenPair) tokenResponse {
+	return tokenResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+	}
 }
 
 func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request){
@@ -42,14 +60,14 @@ func (h *UserHandler) Register(w http.ResponseWriter, r *http.Request){
 	}
 
 	if req.Username == "" || req.Password == "" {	
-		response.Error(w, http.StatusBadRequest, "Username and Password field are required")
+		response.Error(w, http.StatusUnprocessableEntity, "Username and Password field are required")
 		return
 	}
 
 	err := h.userUseCase.Register(req.Username, req.Password)
 
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err.Error())
+		response.FromError(w, err)
 		return
 	}
 
@@ -67,16 +85,69 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request){
 
 	
 	if req.Username == "" || req.Password == "" {	
-		response.Error(w, http.StatusBadRequest, "Username and Password field are required")
+		response.Error(w, http.StatusUnprocessableEntity, "Username and Password field are required")
 		return
 	}
 
-	token, err := h.userUseCase.Login(req.Username, req.Password)
+	pair, err := h.userUseCase.Login(req.Username, req.Password)
 
 	if err != nil {
-		response.Error(w, http.StatusInternalServerError, err.Error())
+		response.FromError(w, err)
 		return
 	}
 
-	response.Success(w, http.StatusOK, "Login Success", loginResponse{Token: token})
-}
\ No newline at end of file
+	response.Success(w, http.StatusOK, "Login Success", newTokenResponse(pair))
+}
+
+func (h *UserHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+
+	if err := request.ParseJSON(r, &req); err != nil {
+		response.Error(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.RefreshToken == "" {
+		response.Error(w, http.StatusUnprocessableEntity, "refresh_token field is required")
+		return
+	}
+
+	pair, err := h.userUseCase.Refresh(req.RefreshToken)
+	if err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, "Token refreshed successfully", newTokenResponse(pair))
+}
+
+func (h *UserHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	if err := h.userUseCase.Logout(claims.UserID, claims.ID, claims.SessionID, expiresAt); err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, "Logged out successfully", nil)
+}
+
+func (h *UserHandler) LogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	claims, ok := middleware.GetUserFromContext(r.Context())
+	if !ok {
+		response.Error(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.userUseCase.LogoutEverywhere(claims.UserID); err != nil {
+		response.FromError(w, err)
+		return
+	}
+
+	response.Success(w, http.StatusOK, "Logged out of all sessions", nil)
+}
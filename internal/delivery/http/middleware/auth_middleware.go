@@ -4,37 +4,53 @@ import (
 	"context"
 	"net/http"
 	"strings"
+	"todo-app/internal/delivery/http/response"
+	"todo-app/internal/domain"
 	"todo-app/internal/pkg/auth"
 )
 
 type contextKey string
+
 const UserContextKey contextKey = "user"
 
-type AuthMiddleware struct{}
+type AuthMiddleware struct {
+    tokens      *auth.TokenService
+    revocations domain.TokenRevocationUsecase
+}
 
-func NewAuthMiddleware() *AuthMiddleware {
-    return &AuthMiddleware{}
+func NewAuthMiddleware(tokens *auth.TokenService, revocations domain.TokenRevocationUsecase) *AuthMiddleware {
+    return &AuthMiddleware{tokens: tokens, revocations: revocations}
 }
 
 func (m *AuthMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
     return func(w http.ResponseWriter, r *http.Request) {
         authHeader := r.Header.Get("Authorization")
         if authHeader == "" {
-            http.Error(w, "Authorization header required", http.StatusUnauthorized)
+            response.Error(w, http.StatusUnauthorized, "Authorization header required")
             return
         }
 
         parts := strings.Split(authHeader, " ")
         if len(parts) != 2 || parts[0] != "Bearer" {
-            http.Error(w, "Invalid authorization header format", http.StatusUnauthorized)
+            response.Error(w, http.StatusUnauthorized, "Invalid authorization header format")
             return
         }
 
         token := parts[1]
 
-        claims, err := auth.ValidateToken(token)
+        claims, err := m.tokens.ValidateToken(token)
         if err != nil {
-            http.Error(w, "Invalid token: "+err.Error(), http.StatusUnauthorized)
+            response.Error(w, http.StatusUnauthorized, "Invalid token: "+err.Error())
+            return
+        }
+
+        revoked, err := m.revocations.IsRevoked(claims.ID, claims.SessionID)
+        if err != nil {
+            response.FromError(w, err)
+            return
+        }
+        if revoked {
+            response.Error(w, http.StatusUnauthorized, "Invalid token: token has been revoked")
             return
         }
 
@@ -46,4 +62,4 @@ func (m *AuthMiddleware) Authenticate(next http.HandlerFunc) http.HandlerFunc {
 func GetUserFromContext(ctx context.Context) (*auth.Claims, bool) {
     claims, ok := ctx.Value(UserContextKey).(*auth.Claims)
     return claims, ok
-}
\ No newline at end of file
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/pkg/auth"
+	memoryrepo "todo-app/internal/repository/memory"
+	"todo-app/internal/usecase"
+)
+
+func newTestAuthMiddleware(t *testing.T) (*AuthMiddleware, *auth.TokenService, domain.TokenRevocationUsecase) {
+    t.Helper()
+
+    key, err := auth.GenerateKey("test")
+    if err != nil {
+        t.Fatal(err)
+    }
+    keys, err := auth.NewKeySet([]auth.Key{key}, "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    tokens := auth.NewTokenService(keys, time.Minute)
+    revocations := usecase.NewTokenRevocationUsecase(memoryrepo.NewMemoryTokenRevocationRepository(memoryrepo.NewStore()), time.Minute)
+
+    return NewAuthMiddleware(tokens, revocations), tokens, revocations
+}
+
+// authenticate runs a request with the given Authorization header through
+// m and returns the status and the claims the handler saw.
+func authenticate(m *AuthMiddleware, authorization string) (int, *auth.Claims) {
+    var seen *auth.Claims
+    handler := m.Authenticate(func(w http.ResponseWriter, r *http.Request) {
+        seen, _ = GetUserFromContext(r.Context())
+        w.WriteHeader(http.StatusNoContent)
+    })
+
+    req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+    if authorization != "" {
+        req.Header.Set("Authorization", authorization)
+    }
+    rec := httptest.NewRecorder()
+    handler(rec, req)
+    return rec.Code, seen
+}
+
+func TestAuthenticate(t *testing.T) {
+    m, tokens, _ := newTestAuthMiddleware(t)
+    token, err := tokens.GenerateToken(7, "alice", "session")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    status, claims := authenticate(m, "Bearer "+token)
+    if status != http.StatusNoContent || claims == nil || claims.UserID != 7 {
+        t.Fatalf("status %d, claims %+v", status, claims)
+    }
+
+    for _, authorization := range []string{"", token, "Basic " + token, "Bearer", "Bearer " + token + "x"} {
+        if status, claims := authenticate(m, authorization); status != http.StatusUnauthorized || claims != nil {
+            t.Errorf("Authorization %q: status %d, claims %+v", authorization, status, claims)
+        }
+    }
+}
+
+func TestAuthenticateRejectsRevokedTokens(t *testing.T) {
+    m, tokens, revocations := newTestAuthMiddleware(t)
+
+    newToken := func(session string) (string, *auth.Claims) {
+        token, err := tokens.GenerateToken(7, "alice", session)
+        if err != nil {
+            t.Fatal(err)
+        }
+        claims, err := tokens.ValidateToken(token)
+        if err != nil {
+            t.Fatal(err)
+        }
+        // Let the cache remember the token as good, as it would once used.
+        if status, _ := authenticate(m, "Bearer "+token); status != http.StatusNoContent {
+            t.Fatalf("fresh token refused with %d", status)
+        }
+        return token, claims
+    }
+
+    revokedToken, revokedClaims := newToken("one")
+    sameSession, _ := newToken("one")
+    revokedSession, revokedSessionClaims := newToken("two")
+    unrelated, _ := newToken("three")
+
+    until := time.Now().Add(time.Minute)
+    if err := revocations.Revoke([]string{revokedClaims.ID}, 7, until); err != nil {
+        t.Fatal(err)
+    }
+    if err := revocations.Revoke([]string{revokedSessionClaims.SessionID}, 7, until); err != nil {
+        t.Fatal(err)
+    }
+
+    tests := []struct {
+        name  string
+        token string
+        want  int
+    }{
+        {"revoked jti", revokedToken, http.StatusUnauthorized},
+        {"other token of the session", sameSession, http.StatusNoContent},
+        {"revoked sid", revokedSession, http.StatusUnauthorized},
+        {"unrelated session", unrelated, http.StatusNoContent},
+    }
+    for _, test := range tests {
+        if status, claims := authenticate(m, "Bearer "+test.token); status != test.want || (status != http.StatusNoContent) != (claims == nil) {
+            t.Errorf("%s: status %d, claims %+v; want %d", test.name, status, claims, test.want)
+        }
+    }
+
+    // A token issued to the revoked session later is refused as well.
+    late, err := tokens.GenerateToken(7, "alice", "two")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if status, _ := authenticate(m, "Bearer "+late); status != http.StatusUnauthorized {
+        t.Errorf("new token of a revoked session: status %d", status)
+    }
+}
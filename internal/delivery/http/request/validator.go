@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func ParseJSON(r *http.Request, v interface{}) error {
@@ -17,6 +18,18 @@ func ParseJSON(r *http.Request, v interface{}) error {
     return json.NewDecoder(r.Body).Decode(v)
 }
 
+// OptionalTime is a nullable time in a request body that tells a field left
+// out from one set to null, which unmarshaling into a pointer cannot.
+type OptionalTime struct {
+    Set   bool
+    Value *time.Time
+}
+
+func (t *OptionalTime) UnmarshalJSON(data []byte) error {
+    t.Set = true
+    return json.Unmarshal(data, &t.Value)
+}
+
 func GetIDParam(r *http.Request) (int64, error) {
     parts := strings.Split(r.URL.Path, "/")
     if len(parts) == 0 {
@@ -30,4 +43,101 @@ func GetIDParam(r *http.Request) (int64, error) {
     }
 
     return id, nil
-}
\ No newline at end of file
+}
+
+// PathID returns the named wildcard of the route pattern as an ID.
+func PathID(r *http.Request, name string) (int64, error) {
+    id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+    if err != nil {
+        return 0, fmt.Errorf("invalid ID format")
+    }
+
+    return id, nil
+}
+
+// QueryInt returns the named query parameter as an int, or 0 when absent.
+func QueryInt(r *http.Request, name string) (int, error) {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+        return 0, nil
+    }
+
+    n, err := strconv.Atoi(value)
+    if err != nil {
+        return 0, fmt.Errorf("invalid %s: must be an integer", name)
+    }
+
+    return n, nil
+}
+
+// QueryList splits a comma-separated query parameter, dropping empty items.
+func QueryList(r *http.Request, name string) []string {
+    var items []string
+    for _, item := range strings.Split(r.URL.Query().Get(name), ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+// QueryBool returns the named query parameter as a bool, or nil when absent.
+func QueryBool(r *http.Request, name string) (*bool, error) {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+        return nil, nil
+    }
+
+    b, err := strconv.ParseBool(value)
+    if err != nil {
+        return nil, fmt.Errorf("invalid %s: must be true or false", name)
+    }
+
+    return &b, nil
+}
+
+// QueryID returns the named query parameter as an ID, or nil when absent.
+func QueryID(r *http.Request, name string) (*int64, error) {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+        return nil, nil
+    }
+
+    id, err := strconv.ParseInt(value, 10, 64)
+    if err != nil {
+        return nil, fmt.Errorf("invalid %s: must be an ID", name)
+    }
+
+    return &id, nil
+}
+
+// QueryTime returns the named RFC 3339 query parameter, or nil when absent.
+func QueryTime(r *http.Request, name string) (*time.Time, error) {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+        return nil, nil
+    }
+
+    t, err := time.Parse(time.RFC3339, value)
+    if err != nil {
+        return nil, fmt.Errorf("invalid %s: must be an RFC 3339 timestamp", name)
+    }
+
+    return &t, nil
+}
+
+// QueryLocation returns the IANA time zone named by the query parameter, or
+// UTC when absent.
+func QueryLocation(r *http.Request, name string) (*time.Location, error) {
+    value := r.URL.Query().Get(name)
+    if value == "" {
+        return time.UTC, nil
+    }
+
+    loc, err := time.LoadLocation(value)
+    if err != nil {
+        return nil, fmt.Errorf("invalid %s: unknown time zone", name)
+    }
+
+    return loc, nil
+}
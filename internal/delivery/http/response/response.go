@@ -2,7 +2,23 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"todo-app/internal/domain"
+)
+
+// Stable, machine-readable error codes sent in the Code field.
+const (
+    CodeBadRequest       = "bad_request"
+    CodeUnauthorized     = "unauthorized"
+    CodeForbidden        = "forbidden"
+    CodeNotFound         = "not_found"
+    CodeMethodNotAllowed = "method_not_allowed"
+    CodeConflict         = "conflict"
+    CodeValidation       = "validation_failed"
+    CodeTooLarge         = "payload_too_large"
+    CodeInternal         = "internal_error"
 )
 
 type Response struct {
@@ -10,6 +26,8 @@ type Response struct {
     Message string      `json:"message,omitempty"`
     Data    interface{} `json:"data,omitempty"`
     Error   string      `json:"error,omitempty"`
+    Code    string      `json:"code,omitempty"`
+    Meta    interface{} `json:"meta,omitempty"`
 }
 
 func JSON(w http.ResponseWriter, code int, response Response) {
@@ -22,10 +40,25 @@ func Error(w http.ResponseWriter, code int, message string) {
     response := Response{
         Status: "error",
         Error:  message,
+        Code:   codeForStatus(code),
     }
     JSON(w, code, response)
 }
 
+// FromError writes err using the status and code of the domain error kind it
+// wraps. Anything else is logged and reported as an opaque internal error so
+// storage details never reach the client.
+func FromError(w http.ResponseWriter, err error) {
+    var domainErr *domain.Error
+    if !errors.As(err, &domainErr) {
+        log.Printf("internal error: %v", err)
+        Error(w, http.StatusInternalServerError, "Internal server error")
+        return
+    }
+
+    Error(w, statusForKind(domainErr.Kind), domainErr.Message)
+}
+
 func Success(w http.ResponseWriter, code int, message string, data interface{}) {
     response := Response{
         Status:  "success",
@@ -33,4 +66,57 @@ func Success(w http.ResponseWriter, code int, message string, data interface{})
         Data:    data,
     }
     JSON(w, code, response)
-}
\ No newline at end of file
+}
+
+// SuccessWithMeta is Success with listing metadata such as pagination.
+func SuccessWithMeta(w http.ResponseWriter, code int, message string, data interface{}, meta interface{}) {
+    response := Response{
+        Status:  "success",
+        Message: message,
+        Data:    data,
+        Meta:    meta,
+    }
+    JSON(w, code, response)
+}
+
+func statusForKind(kind error) int {
+    switch {
+    case errors.Is(kind, domain.ErrNotFound):
+        return http.StatusNotFound
+    case errors.Is(kind, domain.ErrConflict):
+        return http.StatusConflict
+    case errors.Is(kind, domain.ErrValidation):
+        return http.StatusUnprocessableEntity
+    case errors.Is(kind, domain.ErrUnauthorized):
+        return http.StatusUnauthorized
+    case errors.Is(kind, domain.ErrForbidden):
+        return http.StatusForbidden
+    case errors.Is(kind, domain.ErrTooLarge):
+        return http.StatusRequestEntityTooLarge
+    default:
+        return http.StatusInternalServerError
+    }
+}
+
+func codeForStatus(status int) string {
+    switch status {
+    case http.StatusBadRequest:
+        return CodeBadRequest
+    case http.StatusUnauthorized:
+        return CodeUnauthorized
+    case http.StatusForbidden:
+        return CodeForbidden
+    case http.StatusNotFound:
+        return CodeNotFound
+    case http.StatusMethodNotAllowed:
+        return CodeMethodNotAllowed
+    case http.StatusConflict:
+        return CodeConflict
+    case http.StatusUnprocessableEntity:
+        return CodeValidation
+    case http.StatusRequestEntityTooLarge:
+        return CodeTooLarge
+    default:
+        return CodeInternal
+    }
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// ArchivePolicy archives the completed tasks of a user once they have been
+// done for AfterDays days. A user without a policy archives nothing
+// automatically.
+type ArchivePolicy struct {
+    UserID    int64     `json:"user_id"`
+    AfterDays int       `json:"after_days"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ArchivePolicyRepository interface {
+    // Get returns nil when the user has no policy.
+    Get(userID int64) (*ArchivePolicy, error)
+    // Save creates the policy of a user or replaces it.
+    Save(policy *ArchivePolicy) error
+    Delete(userID int64) error
+    // GetAll lists every policy.
+    GetAll() ([]ArchivePolicy, error)
+}
+
+type ArchivePolicyUsecase interface {
+    // Get returns a policy with AfterDays 0 when the user has none.
+    Get(userID int64) (*ArchivePolicy, error)
+    // Set sets the policy of a user; an afterDays of 0 turns automatic
+    // archiving off.
+    Set(userID int64, afterDays int) (*ArchivePolicy, error)
+    // ApplyAll archives the tasks each policy covers.
+    ApplyAll(ctx context.Context) error
+}
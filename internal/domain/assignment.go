@@ -0,0 +1,30 @@
+package domain
+
+import "time"
+
+// Assignment records a task being handed from one user to another. A nil
+// FromUserID means the task was unassigned before; a nil ToUserID means it
+// was unassigned.
+type Assignment struct {
+    ID         int64     `json:"id"`
+    TaskID     int64     `json:"task_id"`
+    AssignedBy int64     `json:"assigned_by"`
+    FromUserID *int64    `json:"from_user_id"`
+    ToUserID   *int64    `json:"to_user_id"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+type AssignmentRepository interface {
+    // Assign sets the assignee of a task of ownerID to assignment.ToUserID
+    // and records assignment in the task's history, both or neither.
+    Assign(ownerID int64, assignment *Assignment) error
+    // GetByTaskID returns the assignment history of a task, oldest first.
+    GetByTaskID(taskID int64) ([]Assignment, error)
+}
+
+type AssignmentUsecase interface {
+    // Assign assigns a task to assigneeID, or unassigns it when assigneeID
+    // is nil. The assignee must have access to the task.
+    Assign(taskID, userID int64, assigneeID *int64) error
+    GetHistory(taskID, userID int64) ([]Assignment, error)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Attachment is a file uploaded to a task. The content lives in a BlobStore
+// under StorageKey; the row only describes it. ContentType is sniffed from
+// the content rather than taken from the client.
+type Attachment struct {
+    ID          int64     `json:"id"`
+    TaskID      int64     `json:"task_id"`
+    UserID      int64     `json:"user_id"`
+    Filename    string    `json:"filename"`
+    ContentType string    `json:"content_type"`
+    Size        int64     `json:"size"`
+    StorageKey  string    `json:"-"`
+    CreatedAt   time.Time `json:"created_at"`
+}
+
+// BlobStore keeps the content of attachments. Keys are slash-separated
+// paths chosen by the usecase, never by clients.
+type BlobStore interface {
+    Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error
+    Get(ctx context.Context, key string) (io.ReadCloser, error)
+    // Delete removes a blob. Deleting a blob that does not exist is not an
+    // error.
+    Delete(ctx context.Context, key string) error
+}
+
+type AttachmentRepository interface {
+    Create(attachment *Attachment) error
+    Delete(id, taskID int64) error
+    GetByID(id, taskID int64) (*Attachment, error)
+    // GetByTaskID returns the attachments of a task, oldest first.
+    GetByTaskID(taskID int64) ([]Attachment, error)
+    // GetByTaskTree returns the attachments of a task and of its subtasks,
+    // whether or not they are in the trash.
+    GetByTaskTree(taskID int64) ([]Attachment, error)
+    // UsageByUserID returns the total size of the attachments userID
+    // uploaded, including those of tasks in the trash.
+    UsageByUserID(userID int64) (int64, error)
+}
+
+type AttachmentUsecase interface {
+    // Upload stores content as an attachment of a task, counted against
+    // userID's quota.
+    Upload(ctx context.Context, taskID, userID int64, filename string, content io.Reader) (*Attachment, error)
+    // Open returns an attachment with its content, which the caller closes.
+    Open(ctx context.Context, id, taskID, userID int64) (*Attachment, io.ReadCloser, error)
+    Delete(ctx context.Context, id, taskID, userID int64) error
+    GetByTaskID(taskID, userID int64) ([]Attachment, error)
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// Comment is a message in the discussion of a task. EditedAt is set the last
+// time the author changed the body and nil until then.
+type Comment struct {
+    ID        int64      `json:"id"`
+    TaskID    int64      `json:"task_id"`
+    UserID    int64      `json:"user_id"`
+    Username  string     `json:"username"`
+    Body      string     `json:"body"`
+    CreatedAt time.Time  `json:"created_at"`
+    EditedAt  *time.Time `json:"edited_at"`
+}
+
+type CommentRepository interface {
+    Create(comment *Comment) error
+    // Update changes the body of a comment by comment.UserID and stamps
+    // EditedAt.
+    Update(comment *Comment) error
+    Delete(id, taskID int64) error
+    GetByID(id, taskID int64) (*Comment, error)
+    // GetByTaskID returns the comments on a task, oldest first.
+    GetByTaskID(taskID int64) ([]Comment, error)
+}
+
+type CommentUsecase interface {
+    Create(taskID, userID int64, body string) (*Comment, error)
+    // Update edits a comment; only its author may.
+    Update(id, taskID, userID int64, body string) (*Comment, error)
+    // Delete removes a comment; its author and the owner of the task may.
+    Delete(id, taskID, userID int64) error
+    GetByTaskID(taskID, userID int64) ([]Comment, error)
+}
@@ -0,0 +1,96 @@
+package domain
+
+import "errors"
+
+// Error kinds. Every error returned by a usecase that the client is expected
+// to act on wraps exactly one of these, so callers can branch with errors.Is.
+var (
+    ErrNotFound     = errors.New("not found")
+    ErrConflict     = errors.New("conflict")
+    ErrValidation   = errors.New("validation failed")
+    ErrUnauthorized = errors.New("unauthorized")
+    ErrForbidden    = errors.New("forbidden")
+    ErrTooLarge     = errors.New("too large")
+)
+
+// Error is a domain failure with a message that is safe to show to clients.
+type Error struct {
+    Kind    error
+    Message string
+}
+
+func (e *Error) Error() string {
+    return e.Message
+}
+
+func (e *Error) Unwrap() error {
+    return e.Kind
+}
+
+func NewError(kind error, message string) *Error {
+    return &Error{Kind: kind, Message: message}
+}
+
+func NotFoundError(message string) error {
+    return NewError(ErrNotFound, message)
+}
+
+func ConflictError(message string) error {
+    return NewError(ErrConflict, message)
+}
+
+func ValidationError(message string) error {
+    return NewError(ErrValidation, message)
+}
+
+func UnauthorizedError(message string) error {
+    return NewError(ErrUnauthorized, message)
+}
+
+func ForbiddenError(message string) error {
+    return NewError(ErrForbidden, message)
+}
+
+var (
+    ErrTaskNotFound         = NewError(ErrNotFound, "task not found")
+    ErrTaskTitleRequired    = NewError(ErrValidation, "title is required")
+    ErrTaskCompletedNotDone = NewError(ErrValidation, "completed_at requires done to be true")
+    ErrTaskHasOpenSubtasks  = NewError(ErrConflict, "task has open subtasks")
+    ErrSubtaskNested        = NewError(ErrValidation, "subtasks cannot have subtasks")
+    ErrSubtaskOrder         = NewError(ErrValidation, "subtask_ids must list every subtask exactly once")
+    ErrRecurrenceNeedsDueAt = NewError(ErrValidation, "recurrence requires due_at")
+    ErrSubtaskRecurrence    = NewError(ErrValidation, "subtasks cannot recur")
+    ErrInvalidRecurrenceTZ  = NewError(ErrValidation, "recurrence_tz must be an IANA time zone")
+    ErrInvalidPriority      = NewError(ErrValidation, "priority must be one of none, low, medium, high, urgent")
+    ErrTagNotFound          = NewError(ErrNotFound, "tag not found")
+    ErrTagNameRequired      = NewError(ErrValidation, "tag name is required")
+    ErrTagNameTooLong       = NewError(ErrValidation, "tag name must be at most 64 characters")
+    ErrTagExists            = NewError(ErrConflict, "tag already exists")
+    ErrProjectNotFound      = NewError(ErrNotFound, "project not found")
+    ErrProjectNameRequired  = NewError(ErrValidation, "project name is required")
+    ErrProjectArchived      = NewError(ErrConflict, "project is archived")
+    ErrReminderNotFound     = NewError(ErrNotFound, "reminder not found")
+    ErrReminderTimeRequired = NewError(ErrValidation, "one of remind_at or minutes_before is required")
+    ErrReminderNeedsDueAt   = NewError(ErrValidation, "minutes_before requires the task to have a due_at")
+    ErrTaskReadOnly         = NewError(ErrForbidden, "task is shared with you read-only")
+    ErrTaskOwnerOnly        = NewError(ErrForbidden, "only the owner of the task can do this")
+    ErrProjectOwnerOnly     = NewError(ErrForbidden, "only the owner of the project can do this")
+    ErrInvalidShareRole     = NewError(ErrValidation, "role must be viewer or editor")
+    ErrShareWithOwner       = NewError(ErrValidation, "cannot share with the owner")
+    ErrShareNotFound        = NewError(ErrNotFound, "share not found")
+    ErrUserNotFound         = NewError(ErrNotFound, "user not found")
+    ErrAssigneeNoAccess     = NewError(ErrValidation, "assignee must have access to the task")
+    ErrCommentNotFound      = NewError(ErrNotFound, "comment not found")
+    ErrCommentBodyRequired  = NewError(ErrValidation, "comment body is required")
+    ErrCommentBodyTooLong   = NewError(ErrValidation, "comment body must be at most 10000 characters")
+    ErrCommentAuthorOnly    = NewError(ErrForbidden, "only the author of the comment can do this")
+    ErrAttachmentNotFound   = NewError(ErrNotFound, "attachment not found")
+    ErrAttachmentEmpty      = NewError(ErrValidation, "attachment is empty")
+    ErrAttachmentTooLarge   = NewError(ErrTooLarge, "attachment exceeds the maximum size")
+    ErrAttachmentQuota      = NewError(ErrTooLarge, "attachment would exceed your storage quota")
+    ErrTaskVersionNotFound  = NewError(ErrNotFound, "task version not found")
+    ErrTaskParentTrashed    = NewError(ErrConflict, "parent task is in the trash")
+    ErrUsernameTaken        = NewError(ErrConflict, "username already exists")
+    ErrInvalidCredentials   = NewError(ErrUnauthorized, "invalid username or password")
+    ErrInvalidRefreshToken  = NewError(ErrUnauthorized, "invalid or expired refresh token")
+)
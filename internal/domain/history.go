@@ -0,0 +1,60 @@
+package domain
+
+import "time"
+
+type HistoryAction string
+
+const (
+    HistoryCreated  HistoryAction = "created"
+    HistoryUpdated  HistoryAction = "updated"
+    HistoryDeleted  HistoryAction = "deleted"
+    HistoryReverted HistoryAction = "reverted"
+    HistoryRestored HistoryAction = "restored"
+)
+
+// TaskSnapshot is the state of the fields of a task that its history
+// tracks. Subtask order, tags and archiving are not tracked.
+type TaskSnapshot struct {
+    ProjectID    *int64     `json:"project_id"`
+    Title        string     `json:"title"`
+    Description  string     `json:"description"`
+    Done         bool       `json:"done"`
+    Priority     Priority   `json:"priority"`
+    DueAt        *time.Time `json:"due_at"`
+    CompletedAt  *time.Time `json:"completed_at"`
+    Recurrence   string     `json:"recurrence"`
+    RecurrenceTZ string     `json:"recurrence_tz"`
+    AssigneeID   *int64     `json:"assignee_id"`
+}
+
+// FieldChange is one field of a task going from Old to New.
+type FieldChange struct {
+    Field string      `json:"field"`
+    Old   interface{} `json:"old"`
+    New   interface{} `json:"new"`
+}
+
+// TaskVersion is an entry in the history of a task: who did what, and the
+// state of the task afterwards. Versions count up from 1 for each task. A
+// deleted entry keeps the last state the task had.
+type TaskVersion struct {
+    ID        int64         `json:"id"`
+    TaskID    int64         `json:"task_id"`
+    Version   int           `json:"version"`
+    Action    HistoryAction `json:"action"`
+    ActorID   int64         `json:"actor_id"`
+    Changes   []FieldChange `json:"changes"`
+    Snapshot  TaskSnapshot  `json:"snapshot"`
+    CreatedAt time.Time     `json:"created_at"`
+}
+
+// TaskHistoryRepository keeps the history of tasks. Entries outlive the
+// tasks they describe.
+type TaskHistoryRepository interface {
+    // Append stores entry as the next version of its task, setting its ID,
+    // Version and CreatedAt.
+    Append(entry *TaskVersion) error
+    // GetByTaskID returns the history of a task, oldest first.
+    GetByTaskID(taskID int64) ([]TaskVersion, error)
+    GetVersion(taskID int64, version int) (*TaskVersion, error)
+}
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Priority is stored as a small integer so it sorts naturally, and travels
+// as its name in JSON.
+type Priority int
+
+const (
+    PriorityNone Priority = iota
+    PriorityLow
+    PriorityMedium
+    PriorityHigh
+    PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+func (p Priority) String() string {
+    if p < PriorityNone || p > PriorityUrgent {
+        return fmt.Sprintf("Priority(%d)", int(p))
+    }
+    return priorityNames[p]
+}
+
+// ParsePriority accepts a priority name. The empty string is PriorityNone.
+func ParsePriority(name string) (Priority, error) {
+    if name == "" {
+        return PriorityNone, nil
+    }
+    for i, candidate := range priorityNames {
+        if candidate == name {
+            return Priority(i), nil
+        }
+    }
+    return PriorityNone, ErrInvalidPriority
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+    return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+    var name string
+    if err := json.Unmarshal(data, &name); err != nil {
+        return err
+    }
+
+    parsed, err := ParsePriority(name)
+    if err != nil {
+        return err
+    }
+
+    *p = parsed
+    return nil
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Project groups a user's tasks. An archived project keeps its tasks but is
+// left out of project listings and cannot receive new tasks.
+type Project struct {
+    ID          int64      `json:"id"`
+    UserID      int64      `json:"user_id"`
+    Name        string     `json:"name"`
+    Description string     `json:"description"`
+    ArchivedAt  *time.Time `json:"archived_at"`
+    CreatedAt   time.Time  `json:"created_at"`
+    UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type ProjectInput struct {
+    Name        string
+    Description string
+}
+
+type ProjectRepository interface {
+    Create(project *Project) error
+    Update(project *Project) error
+    // Delete removes a project, moving its tasks and their subtasks to the
+    // trash when deleteTasks is set, and otherwise out of any project.
+    Delete(id, userID int64, deleteTasks bool) error
+    GetByID(id, userID int64) (*Project, error)
+    GetAllByUserID(userID int64, includeArchived bool) ([]Project, error)
+}
+
+type ProjectUsecase interface {
+    Create(userID int64, input ProjectInput) (*Project, error)
+    Update(id, userID int64, input ProjectInput) error
+    Delete(id, userID int64, deleteTasks bool) error
+    GetByID(id, userID int64) (*Project, error)
+    GetAllByUserID(userID int64, includeArchived bool) ([]Project, error)
+    Archive(id, userID int64) error
+    Unarchive(id, userID int64) error
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Reminder asks for a notification about a task at RemindAt. SentAt is set
+// once the notification has gone out; failed attempts are counted and
+// retried until the scheduler gives up.
+type Reminder struct {
+    ID        int64      `json:"id"`
+    TaskID    int64      `json:"task_id"`
+    UserID    int64      `json:"user_id"`
+    RemindAt  time.Time  `json:"remind_at"`
+    SentAt    *time.Time `json:"sent_at"`
+    Attempts  int        `json:"attempts"`
+    LastError string     `json:"last_error,omitempty"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+// ReminderInput sets a reminder either at RemindAt or MinutesBefore the
+// task's due date.
+type ReminderInput struct {
+    RemindAt      *time.Time
+    MinutesBefore *int
+}
+
+// Notification is what a Notifier delivers for a due reminder.
+type Notification struct {
+    Reminder  Reminder
+    TaskTitle string
+    TaskDueAt *time.Time
+    TaskDone  bool
+}
+
+type Notifier interface {
+    Notify(ctx context.Context, notification Notification) error
+}
+
+type ReminderRepository interface {
+    Create(reminder *Reminder) error
+    Delete(id, taskID, userID int64) error
+    GetByTaskID(taskID, userID int64) ([]Reminder, error)
+    // ProcessDue claims up to limit unsent reminders due by now that have
+    // been tried fewer than maxAttempts times, counting the attempt, and
+    // calls deliver for each. No other instance claims them again until
+    // claimTimeout has passed, in case this one dies before recording the
+    // outcome. A nil error marks the reminder sent; any other error is
+    // recorded against it for a later retry. Reminders whose user can no
+    // longer see the task are left unsent.
+    ProcessDue(now time.Time, limit, maxAttempts int, claimTimeout time.Duration, deliver func(Notification) error) error
+}
+
+type ReminderUsecase interface {
+    Create(taskID, userID int64, input ReminderInput) (*Reminder, error)
+    Delete(id, taskID, userID int64) error
+    GetByTaskID(taskID, userID int64) ([]Reminder, error)
+    // DispatchDue sends the notifications of reminders that have come due.
+    DispatchDue(ctx context.Context) error
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "time"
+
+// Permission is what a user may do with a task. Owners may do anything,
+// editors may change the task and its subtasks, viewers may only read them.
+type Permission string
+
+const (
+    PermissionViewer Permission = "viewer"
+    PermissionEditor Permission = "editor"
+    PermissionOwner  Permission = "owner"
+)
+
+var permissionRanks = map[Permission]int{
+    PermissionViewer: 1,
+    PermissionEditor: 2,
+    PermissionOwner:  3,
+}
+
+// Allows reports whether p includes everything need does.
+func (p Permission) Allows(need Permission) bool {
+    return permissionRanks[p] > 0 && permissionRanks[p] >= permissionRanks[need]
+}
+
+// Max returns the broader of p and other.
+func (p Permission) Max(other Permission) Permission {
+    if permissionRanks[other] > permissionRanks[p] {
+        return other
+    }
+    return p
+}
+
+// Share gives a user a role on a task or a project. A task share covers the
+// task's subtasks; a project share covers every task in the project.
+type Share struct {
+    UserID    int64      `json:"user_id"`
+    Username  string     `json:"username"`
+    Role      Permission `json:"role"`
+    CreatedAt time.Time  `json:"created_at"`
+}
+
+type ShareRepository interface {
+    // SaveTaskShare gives userID role on a task, replacing any earlier role.
+    SaveTaskShare(taskID, userID int64, role Permission) error
+    // DeleteTaskShare takes a task away from userID and, in the same
+    // transaction, unassigns them from each task of unassign they are still
+    // assigned, recording it in the assignment history. The recorded
+    // assignments get their ID and CreatedAt; the others keep a zero ID.
+    DeleteTaskShare(taskID, userID int64, unassign []Assignment) error
+    GetTaskShares(taskID int64) ([]Share, error)
+    // GetTaskRoles returns the roles userID was given on any of taskIDs.
+    GetTaskRoles(userID int64, taskIDs []int64) (map[int64]Permission, error)
+    SaveProjectShare(projectID, userID int64, role Permission) error
+    // DeleteProjectShare is DeleteTaskShare for a project share.
+    DeleteProjectShare(projectID, userID int64, unassign []Assignment) error
+    GetProjectShares(projectID int64) ([]Share, error)
+    // GetProjectRoles returns the roles userID was given on any of
+    // projectIDs.
+    GetProjectRoles(userID int64, projectIDs []int64) (map[int64]Permission, error)
+}
+
+type ShareUsecase interface {
+    // ShareTask gives the user called username role on a task of ownerID,
+    // viewer when role is empty. Sharing again changes the role.
+    ShareTask(taskID, ownerID int64, username string, role Permission) (*Share, error)
+    // UnshareTask takes a task away from userID. The owner may unshare
+    // anyone; collaborators may only remove themselves. The tasks userID can
+    // no longer see are unassigned from them along with it.
+    UnshareTask(taskID, callerID, userID int64) error
+    GetTaskShares(taskID, userID int64) ([]Share, error)
+    ShareProject(projectID, ownerID int64, username string, role Permission) (*Share, error)
+    UnshareProject(projectID, callerID, userID int64) error
+    GetProjectShares(projectID, userID int64) ([]Share, error)
+}
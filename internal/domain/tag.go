@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+type Tag struct {
+    ID        int64     `json:"id"`
+    UserID    int64     `json:"user_id"`
+    Name      string    `json:"name"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+type TagRepository interface {
+    Create(tag *Tag) error
+    Update(tag *Tag) error
+    Delete(id, userID int64) error
+    GetByID(id, userID int64) (*Tag, error)
+    GetAllByUserID(userID int64) ([]Tag, error)
+    // AttachToTask and DetachFromTask are idempotent. Callers check that
+    // the task and tag belong to the same user.
+    AttachToTask(taskID, tagID int64) error
+    DetachFromTask(taskID, tagID int64) error
+    // GetByTaskIDs returns the tags of each task, ordered by name.
+    GetByTaskIDs(taskIDs []int64) (map[int64][]Tag, error)
+}
+
+type TagUsecase interface {
+    Create(userID int64, name string) (*Tag, error)
+    Update(id, userID int64, name string) error
+    Delete(id, userID int64) error
+    GetByID(id, userID int64) (*Tag, error)
+    GetAllByUserID(userID int64) ([]Tag, error)
+}
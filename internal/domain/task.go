@@ -2,28 +2,287 @@ package domain
 
 import "time"
 
+// Task is a to-do item. A task with a ParentID is a subtask; Position orders
+// it among its siblings. Subtasks do not nest. Completing a task with a
+// Recurrence creates its next occurrence.
 type Task struct {
-    ID          int64     `json:"id"`
-    UserID      int64     `json:"user_id"`
-    Title       string    `json:"title"`
-    Description string    `json:"description"`
-    Done        bool      `json:"done"`
-    CreatedAt   time.Time `json:"created_at"`
-    UpdatedAt   time.Time `json:"updated_at"`
+    ID          int64      `json:"id"`
+    UserID      int64      `json:"user_id"`
+    ProjectID   *int64     `json:"project_id"`
+    ParentID    *int64     `json:"parent_id"`
+    Position    int        `json:"position"`
+    Title       string     `json:"title"`
+    Description string     `json:"description"`
+    Done        bool       `json:"done"`
+    Priority    Priority   `json:"priority"`
+    DueAt       *time.Time `json:"due_at"`
+    CompletedAt *time.Time `json:"completed_at"`
+    // Recurrence is an RRULE; its dates are worked out in the IANA zone
+    // RecurrenceTZ.
+    Recurrence   string `json:"recurrence"`
+    RecurrenceTZ string `json:"recurrence_tz"`
+    // AssigneeID is the user the task is assigned to, if any.
+    AssigneeID *int64 `json:"assignee_id"`
+    // ArchivedAt is set while the task is archived. Archived tasks are left
+    // out of listings unless asked for, but can still be read and changed.
+    ArchivedAt *time.Time `json:"archived_at"`
+    // DeletedAt is set while the task is in the trash.
+    DeletedAt *time.Time `json:"deleted_at,omitempty"`
+    // Tags and Progress are filled in by the usecase, not stored with the
+    // task. Progress is only set on tasks that have subtasks.
+    Tags      []Tag            `json:"tags"`
+    Progress  *SubtaskProgress `json:"progress,omitempty"`
+    CreatedAt time.Time        `json:"created_at"`
+    UpdatedAt time.Time        `json:"updated_at"`
+    // Permission is what the requesting user may do with the task, filled
+    // in by the usecase.
+    Permission Permission `json:"permission"`
 }
 
+// SubtaskProgress rolls up the subtasks of a task.
+type SubtaskProgress struct {
+    Done  int `json:"done"`
+    Total int `json:"total"`
+}
+
+// TaskInput carries the client-settable fields of a task on create and
+// update. Update replaces every field except an empty Title.
+type TaskInput struct {
+    ProjectID   *int64
+    Title       string
+    Description string
+    Done        bool
+    Priority    Priority
+    DueAt       *time.Time
+    // CompletedAt backdates completion. It implies Done; when omitted on a
+    // done task the completion time is kept, or set to now.
+    CompletedAt *time.Time
+    // Recurrence is an RRULE and needs DueAt. RecurrenceTZ is an IANA zone
+    // name, UTC when empty.
+    Recurrence   string
+    RecurrenceTZ string
+    // KeepDueAt and KeepRecurrence leave the due date, and the recurrence
+    // with its time zone, of the task as they are on update, ignoring
+    // DueAt or Recurrence and RecurrenceTZ, for a request that did not
+    // mention them.
+    KeepDueAt      bool
+    KeepRecurrence bool
+}
+
+// TaskRepository leaves tasks in the trash out of everything but the trash
+// methods: they cannot be read, listed or changed until restored.
 type TaskRepository interface {
     Create(task *Task) error
     Update(task *Task) error
+    // Delete moves a task to the trash together with its subtasks, which
+    // are given the same deletion time.
     Delete(id, userID int64) error
     GetByID(id, userID int64) (*Task, error)
-    GetAllByUserID(userID int64) ([]Task, error)
+    // FindByID returns a task whoever owns it, leaving the access check to
+    // the caller.
+    FindByID(id int64) (*Task, error)
+    // GetAllByUserID lists the tasks userID owns or that were shared with
+    // them, directly, through their parent task or through their project.
+    GetAllByUserID(userID int64, filter TaskFilter) ([]Task, error)
+    // GetSubtasks returns the subtasks of a task ordered by position.
+    GetSubtasks(parentID, userID int64) ([]Task, error)
+    // ReorderSubtasks gives the subtasks listed in ids the positions of their
+    // index in ids.
+    ReorderSubtasks(parentID, userID int64, ids []int64) error
+    GetSubtaskProgress(taskIDs []int64) (map[int64]SubtaskProgress, error)
+    // GetByProjectID returns the tasks of userID in a project.
+    GetByProjectID(projectID, userID int64) ([]Task, error)
+    // SetArchivedAt archives a task, or unarchives it when archivedAt is
+    // nil.
+    SetArchivedAt(id, userID int64, archivedAt *time.Time) error
+    // ArchiveCompleted archives the done tasks of userID completed before
+    // the given time, and returns how many it archived.
+    ArchiveCompleted(userID int64, completedBefore, now time.Time) (int64, error)
+
+    // GetTrash lists the tasks of userID in the trash, most recently
+    // deleted first. Subtasks whose parent is in the trash too are left
+    // out, as they are restored and purged with it.
+    GetTrash(userID int64) ([]Task, error)
+    GetTrashedByID(id, userID int64) (*Task, error)
+    // Restore takes a task out of the trash, along with the subtasks that
+    // went into it at the same time.
+    Restore(id, userID int64) error
+    // Purge removes a task in the trash for good, with its subtasks.
+    Purge(id, userID int64) error
+    // GetTrashedBefore lists up to limit tasks of any user that went into
+    // the trash before the given time, leaving out subtasks as GetTrash
+    // does.
+    GetTrashedBefore(before time.Time, limit int) ([]Task, error)
 }
 
 type TaskUsecase interface {
-    Create(userID int64, title, description string) error
-    Update(id, userID int64, title, description string, done bool) error
-    Delete(id, userID int64) error
+    Create(userID int64, input TaskInput) error
+    Update(id, userID int64, input TaskInput) error
+    // Delete moves a task to the trash. It refuses a task with open
+    // subtasks unless cascade is set. Subtasks go with their parent.
+    Delete(id, userID int64, cascade bool) error
     GetByID(id, userID int64) (*Task, error)
-    GetAllByUserID(userID int64) ([]Task, error)
-}
\ No newline at end of file
+    GetAllByUserID(userID int64, query TaskQuery) (*TaskPage, error)
+    // Complete marks a task done. A task with open subtasks is only
+    // completed with cascade, which completes the subtasks too.
+    Complete(id, userID int64, cascade bool) error
+    Reopen(id, userID int64) error
+    AddSubtask(parentID, userID int64, input TaskInput) error
+    GetSubtasks(parentID, userID int64) ([]Task, error)
+    ReorderSubtasks(parentID, userID int64, ids []int64) error
+    AddTag(id, userID, tagID int64) error
+    RemoveTag(id, userID, tagID int64) error
+    // MoveToProject moves a task into a project, or out of any project when
+    // projectID is nil.
+    MoveToProject(id, userID int64, projectID *int64) error
+    ListByProject(userID, projectID int64, query TaskQuery) (*TaskPage, error)
+    // ListAssignedTo lists the tasks assigned to userID that they can see.
+    ListAssignedTo(userID int64, query TaskQuery) (*TaskPage, error)
+    // ListOverdue lists open tasks whose due date has passed.
+    ListOverdue(userID int64, query TaskQuery) (*TaskPage, error)
+    // ListDueToday lists open tasks due during the current day in loc.
+    ListDueToday(userID int64, loc *time.Location, query TaskQuery) (*TaskPage, error)
+    // ListDueWithin lists open tasks due from now until the end of the day
+    // that is days days from today in loc.
+    ListDueWithin(userID int64, days int, loc *time.Location, query TaskQuery) (*TaskPage, error)
+    GetHistory(id, userID int64) ([]TaskVersion, error)
+    // Revert puts the tracked fields of a task back to how they were at
+    // version, except its assignee, which has its own rules. The project is
+    // only restored when the caller owns the task.
+    Revert(id, userID int64, version int) error
+    // Archive and Unarchive do not change whether the task is done.
+    Archive(id, userID int64) error
+    Unarchive(id, userID int64) error
+    // ArchiveCompleted archives the tasks userID owns that were completed
+    // more than olderThanDays days ago, and returns how many it archived.
+    ArchiveCompleted(userID int64, olderThanDays int) (int64, error)
+}
+
+type TaskSort string
+
+const (
+    TaskSortCreatedAt TaskSort = "created_at"
+    TaskSortUpdatedAt TaskSort = "updated_at"
+    TaskSortTitle     TaskSort = "title"
+    // TaskSortDueAt lists tasks without a due date last in either order,
+    // and breaks ties by priority, most urgent first.
+    TaskSortDueAt TaskSort = "due_at"
+    // TaskSortPriority breaks ties by due date, soonest first.
+    TaskSortPriority TaskSort = "priority"
+)
+
+// TagMatch says whether a tag filter keeps tasks carrying any or all of the
+// named tags.
+type TagMatch string
+
+const (
+    TagMatchAny TagMatch = "any"
+    TagMatchAll TagMatch = "all"
+)
+
+// ArchivedFilter says whether a listing leaves out, includes or only lists
+// archived tasks.
+type ArchivedFilter string
+
+const (
+    ArchivedExclude ArchivedFilter = "exclude"
+    ArchivedInclude ArchivedFilter = "include"
+    ArchivedOnly    ArchivedFilter = "only"
+)
+
+type SortOrder string
+
+const (
+    SortAsc  SortOrder = "asc"
+    SortDesc SortOrder = "desc"
+)
+
+// TaskQuery is a task listing request as the client sent it. Zero values
+// mean "use the default".
+type TaskQuery struct {
+    Limit         int
+    Cursor        string
+    ProjectID     *int64
+    Done          *bool
+    Priorities    []Priority
+    Tags          []string
+    TagMatch      TagMatch
+    CreatedAfter  *time.Time
+    CreatedBefore *time.Time
+    UpdatedAfter  *time.Time
+    UpdatedBefore *time.Time
+    // DueFrom and DueBefore bound due_at as a half-open range. Either one
+    // excludes tasks without a due date.
+    DueFrom   *time.Time
+    DueBefore *time.Time
+    Sort      TaskSort
+    Order     SortOrder
+    // IncludeSubtasks lists subtasks alongside top-level tasks.
+    IncludeSubtasks bool
+    // AssigneeID keeps only the tasks assigned to this user.
+    AssigneeID *int64
+    // Archived defaults to leaving archived tasks out.
+    Archived ArchivedFilter
+}
+
+// TaskFilter is a validated TaskQuery as handed to the repository. Sort and
+// Order are always set, and ID breaks ties so the ordering is total.
+type TaskFilter struct {
+    ProjectID     *int64
+    Done          *bool
+    Priorities    []Priority
+    Tags          []string
+    TagMatch      TagMatch
+    CreatedAfter  *time.Time
+    CreatedBefore *time.Time
+    UpdatedAfter  *time.Time
+    UpdatedBefore *time.Time
+    DueFrom       *time.Time
+    DueBefore     *time.Time
+    Sort          TaskSort
+    Order         SortOrder
+    // TopLevel leaves subtasks out of the listing.
+    TopLevel   bool
+    AssigneeID *int64
+    Archived   ArchivedFilter
+    // After restricts the listing to tasks strictly after this position.
+    After *TaskCursor
+    Limit int
+}
+
+// TaskCursor is the position of a task in a listing: the ID of the final
+// task of the previous page and the keys Sort orders it by. The other keys
+// are left unset, so the cursor only carries what the listing needs.
+type TaskCursor struct {
+    Sort      TaskSort   `json:"s"`
+    Order     SortOrder  `json:"o"`
+    ID        int64      `json:"id"`
+    CreatedAt *time.Time `json:"c,omitempty"`
+    UpdatedAt *time.Time `json:"u,omitempty"`
+    Title     string     `json:"t,omitempty"`
+    DueAt     *time.Time `json:"d,omitempty"`
+    Priority  Priority   `json:"p,omitempty"`
+}
+
+// Last returns the task the cursor points after, with only its ID and sort
+// keys set, to compare listed tasks against.
+func (c *TaskCursor) Last() Task {
+    task := Task{
+        ID:       c.ID,
+        Title:    c.Title,
+        DueAt:    c.DueAt,
+        Priority: c.Priority,
+    }
+    if c.CreatedAt != nil {
+        task.CreatedAt = *c.CreatedAt
+    }
+    if c.UpdatedAt != nil {
+        task.UpdatedAt = *c.UpdatedAt
+    }
+    return task
+}
+
+type TaskPage struct {
+    Tasks      []Task
+    NextCursor string
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TokenPair is what a user is given on login and on refresh: a short-lived
+// access token to call the API with, and a refresh token to get the next
+// pair with once it expires.
+type TokenPair struct {
+    AccessToken  string
+    RefreshToken string
+    // ExpiresIn is how many seconds the access token is valid for.
+    ExpiresIn int64
+}
+
+// RefreshToken is a refresh token as stored, by the SHA-256 of the token
+// only. Each refresh uses up a token and issues its successor in the same
+// family, which starts at login. A used or revoked token is never accepted
+// again; presenting a used one means it was copied, so its whole family is
+// revoked.
+type RefreshToken struct {
+    ID        int64
+    UserID    int64
+    FamilyID  string
+    TokenHash string
+    ExpiresAt time.Time
+    UsedAt    *time.Time
+    RevokedAt *time.Time
+    CreatedAt time.Time
+}
+
+type RefreshTokenRepository interface {
+    Create(token *RefreshToken) error
+    // GetByHash returns nil when no token has the hash.
+    GetByHash(hash string) (*RefreshToken, error)
+    // MarkUsed records that a token was exchanged for its successor. It
+    // reports false when the token was used or revoked already, so two
+    // refreshes racing with the same token cannot both succeed.
+    MarkUsed(id int64, usedAt time.Time) (bool, error)
+    // RevokeFamily revokes every token of a family not revoked yet.
+    RevokeFamily(familyID string, revokedAt time.Time) error
+    // GetActiveFamilies lists the families of userID that still hold a
+    // token that can be exchanged at now.
+    GetActiveFamilies(userID int64, now time.Time) ([]string, error)
+    // RevokeByUserID revokes every token of userID not revoked yet.
+    RevokeByUserID(userID int64, revokedAt time.Time) error
+    // DeleteExpired deletes the tokens that expired before the given time
+    // and returns how many it deleted.
+    DeleteExpired(before time.Time) (int64, error)
+}
+
+// TokenRevocationRepository records the IDs of access tokens, and of the
+// sessions they belong to, that were revoked before they expired.
+type TokenRevocationRepository interface {
+    // Revoke records ids as revoked until expiresAt, once no token they
+    // stand for can be valid any more. IDs revoked already are left alone.
+    Revoke(ids []string, userID int64, expiresAt time.Time) error
+    IsRevoked(id string) (bool, error)
+    // DeleteExpired deletes the revocations that expired before the given
+    // time and returns how many it deleted.
+    DeleteExpired(before time.Time) (int64, error)
+}
+
+// TokenRevocationUsecase answers whether a token was revoked without a
+// round trip to the database for every request.
+type TokenRevocationUsecase interface {
+    Revoke(ids []string, userID int64, expiresAt time.Time) error
+    // IsRevoked reports whether any of ids was revoked.
+    IsRevoked(ids ...string) (bool, error)
+    // PurgeExpired deletes the revocations that have expired.
+    PurgeExpired(ctx context.Context) error
+}
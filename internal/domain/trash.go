@@ -0,0 +1,17 @@
+package domain
+
+import "context"
+
+// TrashUsecase manages deleted tasks. Tasks stay in the trash until they
+// are restored, purged by their owner, or purged once the retention period
+// has passed.
+type TrashUsecase interface {
+    List(userID int64) ([]Task, error)
+    Restore(id, userID int64) error
+    // Purge deletes a task in the trash for good, with its subtasks and the
+    // content of their attachments.
+    Purge(ctx context.Context, id, userID int64) error
+    // PurgeExpired purges the tasks that have been in the trash longer than
+    // the retention period.
+    PurgeExpired(ctx context.Context) error
+}
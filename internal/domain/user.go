@@ -1,11 +1,14 @@
 package domain
 
-import "time"
+import (
+	"context"
+	"time"
+)
 
 type User struct {
     ID        int64     `json:"id"`
     Username  string    `json:"username"`
-    Password  string    `json:"-"` 
+    Password  string    `json:"-"`
     CreatedAt time.Time `json:"created_at"`
     UpdatedAt time.Time `json:"updated_at"`
 }
@@ -14,9 +17,21 @@ type UserRepository interface {
     Create(user *User) error
     GetByUsername(username string) (*User, error)
     GetByID(id int64) (*User, error)
+    // UpdatePassword replaces the password hash of a user.
+    UpdatePassword(id int64, password string) error
 }
 
 type UserUsecase interface {
     Register(username, password string) error
-    Login(username, password string) (string, error) 
-}
\ No newline at end of file
+    Login(username, password string) (*TokenPair, error)
+    // Refresh exchanges a refresh token for a new token pair.
+    Refresh(refreshToken string) (*TokenPair, error)
+    // Logout revokes the access token tokenID, which expires at expiresAt,
+    // and ends its session so no token of it is accepted or refreshed.
+    Logout(userID int64, tokenID, sessionID string, expiresAt time.Time) error
+    // LogoutEverywhere ends every session of userID.
+    LogoutEverywhere(userID int64) error
+    // PurgeExpiredTokens deletes the refresh tokens and the revocations
+    // that have expired.
+    PurgeExpiredTokens(ctx context.Context) error
+}
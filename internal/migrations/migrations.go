@@ -0,0 +1,17 @@
+// Package migrations embeds the versioned schema migrations for every
+// supported database. Each dialect has its own directory of
+// NNNN_name.up.sql / NNNN_name.down.sql pairs.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+// For returns the migration files for the named dialect directory.
+func For(dialect string) (fs.FS, error) {
+    return fs.Sub(files, dialect)
+}
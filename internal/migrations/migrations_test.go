@@ -0,0 +1,56 @@
+package migrations
+
+import (
+	"fmt"
+	"testing"
+	"todo-app/internal/pkg/migrate"
+)
+
+var dialects = []string{"mysql", "postgres", "sqlite"}
+
+// TestDialectsMatch checks that every dialect has the same migrations, by
+// version and name, each with an up and a down script.
+func TestDialectsMatch(t *testing.T) {
+    lists := make(map[string][]string)
+    for _, dialect := range dialects {
+        files, err := For(dialect)
+        if err != nil {
+            t.Fatalf("%s: %v", dialect, err)
+        }
+        // Load refuses a migration without both an up and a down file.
+        migrations, err := migrate.Load(files)
+        if err != nil {
+            t.Fatalf("%s: %v", dialect, err)
+        }
+        if len(migrations) == 0 {
+            t.Fatalf("%s has no migrations", dialect)
+        }
+
+        for i, migration := range migrations {
+            if migration.Version != int64(i+1) {
+                t.Errorf("%s: migration %d_%s follows version %d", dialect, migration.Version, migration.Name, i)
+            }
+            if migration.Up == "" || migration.Down == "" {
+                t.Errorf("%s: migration %d_%s has an empty script", dialect, migration.Version, migration.Name)
+            }
+            lists[dialect] = append(lists[dialect], fmt.Sprintf("%04d_%s", migration.Version, migration.Name))
+        }
+    }
+
+    want := lists[dialects[0]]
+    for _, dialect := range dialects[1:] {
+        got := lists[dialect]
+        for i := 0; i < len(got) || i < len(want); i++ {
+            var a, b string
+            if i < len(want) {
+                a = want[i]
+            }
+            if i < len(got) {
+                b = got[i]
+            }
+            if a != b {
+                t.Errorf("%s has %q where %s has %q", dialects[0], a, dialect, b)
+            }
+        }
+    }
+}
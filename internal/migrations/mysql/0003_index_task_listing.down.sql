@@ -0,0 +1,2 @@
+DROP INDEX idx_tasks_user_title ON tasks;
+DROP INDEX idx_tasks_user_updated ON tasks;
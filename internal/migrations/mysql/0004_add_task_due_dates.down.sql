@@ -0,0 +1,5 @@
+DROP INDEX idx_tasks_user_due ON tasks;
+
+ALTER TABLE tasks
+    DROP COLUMN completed_at,
+    DROP COLUMN due_at;
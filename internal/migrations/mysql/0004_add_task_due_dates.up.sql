@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    ADD COLUMN due_at DATETIME(6) NULL,
+    ADD COLUMN completed_at DATETIME(6) NULL;
+
+CREATE INDEX idx_tasks_user_due ON tasks (user_id, due_at);
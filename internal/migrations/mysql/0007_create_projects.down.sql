@@ -0,0 +1,7 @@
+ALTER TABLE tasks DROP FOREIGN KEY fk_tasks_project;
+
+DROP INDEX idx_tasks_project ON tasks;
+
+ALTER TABLE tasks DROP COLUMN project_id;
+
+DROP TABLE projects;
@@ -0,0 +1,7 @@
+ALTER TABLE tasks DROP FOREIGN KEY fk_tasks_parent;
+
+DROP INDEX idx_tasks_parent_position ON tasks;
+
+ALTER TABLE tasks
+    DROP COLUMN position,
+    DROP COLUMN parent_id;
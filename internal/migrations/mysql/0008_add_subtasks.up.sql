@@ -0,0 +1,7 @@
+ALTER TABLE tasks
+    ADD COLUMN parent_id BIGINT NULL,
+    ADD COLUMN position INT NOT NULL DEFAULT 0;
+
+ALTER TABLE tasks ADD CONSTRAINT fk_tasks_parent FOREIGN KEY (parent_id) REFERENCES tasks (id) ON DELETE CASCADE;
+
+CREATE INDEX idx_tasks_parent_position ON tasks (parent_id, position);
@@ -0,0 +1,3 @@
+ALTER TABLE tasks
+    DROP COLUMN recurrence_tz,
+    DROP COLUMN recurrence;
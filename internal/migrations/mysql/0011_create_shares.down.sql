@@ -0,0 +1,3 @@
+DROP TABLE project_shares;
+
+DROP TABLE task_shares;
@@ -0,0 +1,21 @@
+CREATE TABLE task_shares (
+    task_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (task_id, user_id),
+    KEY idx_task_shares_user (user_id),
+    CONSTRAINT fk_task_shares_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_shares_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE project_shares (
+    project_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (project_id, user_id),
+    KEY idx_project_shares_user (user_id),
+    CONSTRAINT fk_project_shares_project FOREIGN KEY (project_id) REFERENCES projects (id) ON DELETE CASCADE,
+    CONSTRAINT fk_project_shares_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,7 @@
+DROP TABLE task_assignments;
+
+ALTER TABLE tasks DROP FOREIGN KEY fk_tasks_assignee;
+
+DROP INDEX idx_tasks_assignee ON tasks;
+
+ALTER TABLE tasks DROP COLUMN assignee_id;
@@ -0,0 +1,20 @@
+ALTER TABLE tasks ADD COLUMN assignee_id BIGINT NULL;
+
+ALTER TABLE tasks ADD CONSTRAINT fk_tasks_assignee FOREIGN KEY (assignee_id) REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_assignee ON tasks (assignee_id);
+
+CREATE TABLE task_assignments (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    task_id BIGINT NOT NULL,
+    assigned_by BIGINT NOT NULL,
+    from_user_id BIGINT NULL,
+    to_user_id BIGINT NULL,
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (id),
+    KEY idx_task_assignments_task (task_id),
+    CONSTRAINT fk_task_assignments_task FOREIGN KEY (task_id) REFERENCES tasks (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_assignments_by FOREIGN KEY (assigned_by) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_task_assignments_from FOREIGN KEY (from_user_id) REFERENCES users (id) ON DELETE SET NULL,
+    CONSTRAINT fk_task_assignments_to FOREIGN KEY (to_user_id) REFERENCES users (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+CREATE TABLE task_history (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    task_id BIGINT NOT NULL,
+    version INT NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor_id BIGINT NOT NULL,
+    changes TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_task_history_version (task_id, version)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
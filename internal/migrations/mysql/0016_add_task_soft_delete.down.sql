@@ -0,0 +1,5 @@
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+
+DROP INDEX idx_tasks_deleted ON tasks;
+
+ALTER TABLE tasks DROP COLUMN deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN deleted_at DATETIME(6) NULL;
+
+CREATE INDEX idx_tasks_deleted ON tasks (deleted_at);
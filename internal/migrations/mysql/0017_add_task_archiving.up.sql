@@ -0,0 +1,11 @@
+ALTER TABLE tasks ADD COLUMN archived_at DATETIME(6) NULL;
+
+CREATE INDEX idx_tasks_user_archived ON tasks (user_id, archived_at);
+
+CREATE TABLE archive_policies (
+    user_id BIGINT NOT NULL,
+    after_days INT NOT NULL,
+    updated_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (user_id),
+    CONSTRAINT fk_archive_policies_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+CREATE TABLE revoked_tokens (
+    token_id CHAR(32) NOT NULL,
+    user_id BIGINT NOT NULL,
+    expires_at DATETIME(6) NOT NULL,
+    created_at DATETIME(6) NOT NULL,
+    PRIMARY KEY (token_id),
+    KEY idx_revoked_tokens_expires (expires_at),
+    CONSTRAINT fk_revoked_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,2 @@
+CREATE INDEX idx_tasks_user_updated ON tasks (user_id, updated_at);
+CREATE INDEX idx_tasks_user_title ON tasks (user_id, title);
@@ -0,0 +1,5 @@
+ALTER TABLE tasks
+    ADD COLUMN due_at TIMESTAMPTZ,
+    ADD COLUMN completed_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tasks_user_due ON tasks (user_id, due_at);
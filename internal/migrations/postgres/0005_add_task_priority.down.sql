@@ -0,0 +1,3 @@
+DROP INDEX idx_tasks_user_priority;
+
+ALTER TABLE tasks DROP COLUMN priority;
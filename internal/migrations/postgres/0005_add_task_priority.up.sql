@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_tasks_user_priority ON tasks (user_id, priority);
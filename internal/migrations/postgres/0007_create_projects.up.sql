@@ -0,0 +1,15 @@
+CREATE TABLE projects (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    archived_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_projects_user_name ON projects (user_id, name);
+
+ALTER TABLE tasks ADD COLUMN project_id BIGINT NULL REFERENCES projects (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_project ON tasks (project_id);
@@ -0,0 +1,2 @@
+ALTER TABLE tasks DROP COLUMN recurrence_tz;
+ALTER TABLE tasks DROP COLUMN recurrence;
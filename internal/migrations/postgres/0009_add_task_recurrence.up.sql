@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN recurrence VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN recurrence_tz VARCHAR(64) NOT NULL DEFAULT '';
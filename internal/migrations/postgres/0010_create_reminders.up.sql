@@ -0,0 +1,14 @@
+CREATE TABLE reminders (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    remind_at TIMESTAMPTZ NOT NULL,
+    sent_at TIMESTAMPTZ NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error VARCHAR(1024) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_reminders_task ON reminders (task_id);
+
+CREATE INDEX idx_reminders_due ON reminders (sent_at, remind_at);
@@ -0,0 +1,14 @@
+ALTER TABLE tasks ADD COLUMN assignee_id BIGINT NULL REFERENCES users (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_assignee ON tasks (assignee_id);
+
+CREATE TABLE task_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    assigned_by BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    from_user_id BIGINT NULL REFERENCES users (id) ON DELETE SET NULL,
+    to_user_id BIGINT NULL REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_task_assignments_task ON task_assignments (task_id);
@@ -0,0 +1,11 @@
+CREATE TABLE task_history (
+    id BIGSERIAL PRIMARY KEY,
+    task_id BIGINT NOT NULL,
+    version INTEGER NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    actor_id BIGINT NOT NULL,
+    changes TEXT NOT NULL,
+    snapshot TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT uq_task_history_version UNIQUE (task_id, version)
+);
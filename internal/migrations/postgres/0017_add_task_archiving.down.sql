@@ -0,0 +1,5 @@
+DROP TABLE archive_policies;
+
+DROP INDEX idx_tasks_user_archived;
+
+ALTER TABLE tasks DROP COLUMN archived_at;
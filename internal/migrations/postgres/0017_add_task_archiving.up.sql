@@ -0,0 +1,9 @@
+ALTER TABLE tasks ADD COLUMN archived_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tasks_user_archived ON tasks (user_id, archived_at);
+
+CREATE TABLE archive_policies (
+    user_id BIGINT PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    after_days INTEGER NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
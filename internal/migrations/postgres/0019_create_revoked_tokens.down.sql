@@ -0,0 +1,3 @@
+DROP INDEX idx_refresh_tokens_user;
+
+DROP TABLE revoked_tokens;
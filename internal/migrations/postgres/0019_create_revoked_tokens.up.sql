@@ -0,0 +1,11 @@
+CREATE TABLE revoked_tokens (
+    token_id CHAR(32) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_revoked_tokens_expires ON revoked_tokens (expires_at);
+
+-- MySQL indexes the column for its foreign key already.
+CREATE INDEX idx_refresh_tokens_user ON refresh_tokens (user_id);
@@ -0,0 +1,4 @@
+DROP INDEX idx_tasks_user_due;
+
+ALTER TABLE tasks DROP COLUMN completed_at;
+ALTER TABLE tasks DROP COLUMN due_at;
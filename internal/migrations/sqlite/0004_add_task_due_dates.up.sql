@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN due_at DATETIME;
+ALTER TABLE tasks ADD COLUMN completed_at DATETIME;
+
+CREATE INDEX idx_tasks_user_due ON tasks (user_id, due_at);
@@ -0,0 +1,5 @@
+DROP INDEX idx_tasks_project;
+
+ALTER TABLE tasks DROP COLUMN project_id;
+
+DROP TABLE projects;
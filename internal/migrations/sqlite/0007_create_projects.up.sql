@@ -0,0 +1,15 @@
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL,
+    archived_at DATETIME NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_projects_user_name ON projects (user_id, name);
+
+ALTER TABLE tasks ADD COLUMN project_id INTEGER NULL REFERENCES projects (id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_project ON tasks (project_id);
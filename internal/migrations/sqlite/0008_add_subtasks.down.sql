@@ -0,0 +1,4 @@
+DROP INDEX idx_tasks_parent_position;
+
+ALTER TABLE tasks DROP COLUMN position;
+ALTER TABLE tasks DROP COLUMN parent_id;
@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN parent_id INTEGER NULL REFERENCES tasks (id) ON DELETE CASCADE;
+ALTER TABLE tasks ADD COLUMN position INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_tasks_parent_position ON tasks (parent_id, position);
@@ -0,0 +1,2 @@
+ALTER TABLE tasks ADD COLUMN recurrence TEXT NOT NULL DEFAULT '';
+ALTER TABLE tasks ADD COLUMN recurrence_tz TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,14 @@
+CREATE TABLE reminders (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id INTEGER NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    remind_at DATETIME NOT NULL,
+    sent_at DATETIME NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX idx_reminders_task ON reminders (task_id);
+
+CREATE INDEX idx_reminders_due ON reminders (sent_at, remind_at);
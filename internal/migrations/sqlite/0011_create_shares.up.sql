@@ -0,0 +1,19 @@
+CREATE TABLE task_shares (
+    task_id INTEGER NOT NULL REFERENCES tasks (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX idx_task_shares_user ON task_shares (user_id);
+
+CREATE TABLE project_shares (
+    project_id INTEGER NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_shares_user ON project_shares (user_id);
@@ -0,0 +1,5 @@
+DROP TABLE task_assignments;
+
+DROP INDEX idx_tasks_assignee;
+
+ALTER TABLE tasks DROP COLUMN assignee_id;
@@ -0,0 +1 @@
+ALTER TABLE reminders ADD COLUMN claimed_at DATETIME;
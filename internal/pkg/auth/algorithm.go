@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+)
+
+// The JWS algorithms tokens can be signed with.
+const (
+    AlgHS256 = "HS256"
+    AlgEdDSA = "EdDSA"
+    AlgRS256 = "RS256"
+    AlgES256 = "ES256"
+)
+
+const (
+    // minSecretSize is the shortest HMAC secret accepted, the size of the
+    // SHA-256 output.
+    minSecretSize = 32
+    minRSABits    = 2048
+    // es256PartSize is the size of each of r and s in an ES256 signature.
+    es256PartSize = 32
+)
+
+// checkKey makes sure the material of key suits its algorithm, and fills in
+// the public half of an asymmetric key from its private half.
+func checkKey(key *Key) error {
+    if key.Algorithm == AlgHS256 {
+        if len(key.Secret) < minSecretSize {
+            return fmt.Errorf("secret of key %q is shorter than %d bytes", key.ID, minSecretSize)
+        }
+        return nil
+    }
+
+    if key.PrivateKey != nil {
+        key.PublicKey = key.PrivateKey.Public()
+    }
+    if key.PublicKey == nil {
+        return fmt.Errorf("key %q has neither a private nor a public key", key.ID)
+    }
+
+    switch public := key.PublicKey.(type) {
+    case ed25519.PublicKey:
+        if key.Algorithm != AlgEdDSA {
+            return fmt.Errorf("key %q is an Ed25519 key, not %s", key.ID, key.Algorithm)
+        }
+    case *rsa.PublicKey:
+        if key.Algorithm != AlgRS256 {
+            return fmt.Errorf("key %q is an RSA key, not %s", key.ID, key.Algorithm)
+        }
+        if public.N.BitLen() < minRSABits {
+            return fmt.Errorf("RSA key %q is shorter than %d bits", key.ID, minRSABits)
+        }
+    case *ecdsa.PublicKey:
+        if key.Algorithm != AlgES256 || public.Curve != elliptic.P256() {
+            return fmt.Errorf("key %q is not a P-256 key for %s", key.ID, key.Algorithm)
+        }
+    default:
+        return fmt.Errorf("key %q has an unsupported type %T", key.ID, key.PublicKey)
+    }
+
+    return nil
+}
+
+// canSign reports whether key holds what it takes to sign tokens.
+func (k *Key) canSign() bool {
+    return k.Algorithm == AlgHS256 || k.PrivateKey != nil
+}
+
+func sign(key *Key, data []byte) ([]byte, error) {
+    switch key.Algorithm {
+    case AlgHS256:
+        return hmacSHA256(key.Secret, data), nil
+    case AlgEdDSA:
+        return ed25519.Sign(key.PrivateKey.(ed25519.PrivateKey), data), nil
+    case AlgRS256:
+        digest := sha256.Sum256(data)
+        return rsa.SignPKCS1v15(rand.Reader, key.PrivateKey.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+    case AlgES256:
+        // JWS wants r and s side by side rather than the ASN.1 encoding
+        // the standard library produces.
+        digest := sha256.Sum256(data)
+        r, s, err := ecdsa.Sign(rand.Reader, key.PrivateKey.(*ecdsa.PrivateKey), digest[:])
+        if err != nil {
+            return nil, err
+        }
+        signature := make([]byte, 2*es256PartSize)
+        r.FillBytes(signature[:es256PartSize])
+        s.FillBytes(signature[es256PartSize:])
+        return signature, nil
+    default:
+        return nil, fmt.Errorf("unsupported algorithm %q", key.Algorithm)
+    }
+}
+
+func verify(key *Key, data, signature []byte) bool {
+    switch key.Algorithm {
+    case AlgHS256:
+        return hmac.Equal(hmacSHA256(key.Secret, data), signature)
+    case AlgEdDSA:
+        return ed25519.Verify(key.PublicKey.(ed25519.PublicKey), data, signature)
+    case AlgRS256:
+        digest := sha256.Sum256(data)
+        return rsa.VerifyPKCS1v15(key.PublicKey.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+    case AlgES256:
+        if len(signature) != 2*es256PartSize {
+            return false
+        }
+        digest := sha256.Sum256(data)
+        r := new(big.Int).SetBytes(signature[:es256PartSize])
+        s := new(big.Int).SetBytes(signature[es256PartSize:])
+        return ecdsa.Verify(key.PublicKey.(*ecdsa.PublicKey), digest[:], r, s)
+    default:
+        return false
+    }
+}
+
+func hmacSHA256(secret, data []byte) []byte {
+    h := hmac.New(sha256.New, secret)
+    h.Write(data)
+    return h.Sum(nil)
+}
@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKeyPairs generates one key pair per asymmetric algorithm. RSA keys
+// are slow to generate, so they are made once.
+var testKeyPairs = func() map[string]crypto.Signer {
+    _, edKey, err := ed25519.GenerateKey(rand.Reader)
+    if err != nil {
+        panic(err)
+    }
+    rsaKey, err := rsa.GenerateKey(rand.Reader, minRSABits)
+    if err != nil {
+        panic(err)
+    }
+    ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+    if err != nil {
+        panic(err)
+    }
+    return map[string]crypto.Signer{AlgEdDSA: edKey, AlgRS256: rsaKey, AlgES256: ecKey}
+}()
+
+var asymmetricAlgorithms = []string{AlgEdDSA, AlgRS256, AlgES256}
+
+const hsKeyID = "hs"
+
+func privatePEM(t *testing.T, key crypto.Signer) string {
+    t.Helper()
+
+    der, err := x509.MarshalPKCS8PrivateKey(key)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func publicPEM(t *testing.T, key crypto.Signer) string {
+    t.Helper()
+
+    der, err := x509.MarshalPKIXPublicKey(key.Public())
+    if err != nil {
+        t.Fatal(err)
+    }
+    return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+// parseKeyConfigs runs configs through ParseKeys as the server would read
+// them.
+func parseKeyConfigs(t *testing.T, configs ...keyConfig) []Key {
+    t.Helper()
+
+    data, err := json.Marshal(configs)
+    if err != nil {
+        t.Fatal(err)
+    }
+    keys, err := ParseKeys(data)
+    if err != nil {
+        t.Fatalf("ParseKeys: %v", err)
+    }
+    return keys
+}
+
+func TestAsymmetricRoundTrip(t *testing.T) {
+    for _, alg := range asymmetricAlgorithms {
+        t.Run(alg, func(t *testing.T) {
+            private := testKeyPairs[alg]
+            keys := parseKeyConfigs(t, keyConfig{ID: "k", Algorithm: alg, PrivateKey: privatePEM(t, private)})
+            tokens := NewTokenService(mustKeySet(t, keys, ""), time.Minute)
+
+            token := mustGenerateToken(t, tokens)
+            if h := tokenHeader(t, token); h.Algorithm != alg || h.KeyID != "k" {
+                t.Errorf("header = %+v", h)
+            }
+            if alg == AlgES256 {
+                signature, _ := base64.RawURLEncoding.DecodeString(token[strings.LastIndex(token, ".")+1:])
+                if len(signature) != 2*es256PartSize {
+                    t.Errorf("ES256 signature is %d bytes, want r||s of %d", len(signature), 2*es256PartSize)
+                }
+            }
+            if _, err := tokens.ValidateToken(token); err != nil {
+                t.Fatalf("ValidateToken: %v", err)
+            }
+
+            // A service holding only the public key validates it but cannot
+            // sign.
+            verifierKeys := parseKeyConfigs(t,
+                keyConfig{ID: "k", Algorithm: alg, PublicKey: publicPEM(t, private)},
+                keyConfig{ID: "local", Secret: base64.StdEncoding.EncodeToString(make([]byte, minSecretSize))})
+            if verifierKeys[0].canSign() {
+                t.Error("a public key can sign")
+            }
+            verifier := NewTokenService(mustKeySet(t, verifierKeys, ""), time.Minute)
+            if _, err := verifier.ValidateToken(token); err != nil {
+                t.Errorf("ValidateToken with the public key: %v", err)
+            }
+            if _, err := NewKeySet(verifierKeys[:1], "k"); err == nil {
+                t.Error("NewKeySet signs with a public key")
+            }
+
+            // Any change to the signed part breaks the signature.
+            parts := strings.Split(token, ".")
+            signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+            signature[len(signature)/2] ^= 1
+            parts[2] = base64.RawURLEncoding.EncodeToString(signature)
+            if _, err := tokens.ValidateToken(strings.Join(parts, ".")); err == nil {
+                t.Error("a token with a flipped signature bit validated")
+            }
+        })
+    }
+}
+
+func TestKeyFiles(t *testing.T) {
+    dir := t.TempDir()
+    private := testKeyPairs[AlgEdDSA]
+    privateFile, publicFile := filepath.Join(dir, "key.pem"), filepath.Join(dir, "key.pub")
+    if err := os.WriteFile(privateFile, []byte(privatePEM(t, private)), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.WriteFile(publicFile, []byte(publicPEM(t, private)), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    keys := parseKeyConfigs(t,
+        keyConfig{ID: "signer", Algorithm: AlgEdDSA, PrivateKeyFile: privateFile},
+        keyConfig{ID: "verifier", Algorithm: AlgEdDSA, PublicKeyFile: publicFile})
+    if _, err := NewKeySet(keys, ""); err != nil {
+        t.Fatalf("NewKeySet: %v", err)
+    }
+    if !keys[0].PrivateKey.Public().(ed25519.PublicKey).Equal(keys[1].PublicKey) {
+        t.Error("the key files hold different keys")
+    }
+
+    if _, err := ParseKeys([]byte(`[{"kid": "k", "alg": "EdDSA", "private_key_file": "` + filepath.Join(dir, "missing") + `"}]`)); err == nil {
+        t.Error("ParseKeys succeeded with a missing key file")
+    }
+}
+
+func TestCheckKeyRejectsMismatchedMaterial(t *testing.T) {
+    smallRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+    if err != nil {
+        t.Fatal(err)
+    }
+    p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    tests := map[string]Key{
+        "Ed25519 as RS256": {Algorithm: AlgRS256, PrivateKey: testKeyPairs[AlgEdDSA]},
+        "RSA as ES256":     {Algorithm: AlgES256, PrivateKey: testKeyPairs[AlgRS256]},
+        "P-256 as EdDSA":   {Algorithm: AlgEdDSA, PrivateKey: testKeyPairs[AlgES256]},
+        "P-384 as ES256":   {Algorithm: AlgES256, PrivateKey: p384},
+        "1024 bit RSA":     {Algorithm: AlgRS256, PrivateKey: smallRSA},
+        "no key material":  {Algorithm: AlgEdDSA},
+    }
+    for name, key := range tests {
+        key.ID = "k"
+        if _, err := NewKeySet([]Key{key, mustGenerateKey(t, "hs")}, "hs"); err == nil {
+            t.Errorf("%s: NewKeySet succeeded", name)
+        }
+    }
+}
+
+// An attacker who knows a public key can HMAC a token with it and claim
+// HS256, hoping it is checked as a shared secret. The key's own algorithm
+// decides, so this and every other switch of algorithm fails.
+func TestValidateTokenRejectsCrossAlgorithm(t *testing.T) {
+    keys := []Key{mustGenerateKey(t, hsKeyID)}
+    for _, alg := range asymmetricAlgorithms {
+        keys = append(keys, Key{ID: alg, Algorithm: alg, PrivateKey: testKeyPairs[alg]})
+    }
+    tokens := NewTokenService(mustKeySet(t, keys, hsKeyID), time.Minute)
+
+    for _, alg := range asymmetricAlgorithms {
+        private := testKeyPairs[alg]
+        for _, material := range [][]byte{[]byte(publicPEM(t, private)), mustMarshalPKIX(t, private.Public())} {
+            hmacKey := &Key{Algorithm: AlgHS256, Secret: material}
+            token := forgeToken(t, header{Algorithm: AlgHS256, Type: "JWT", KeyID: alg}, hmacKey)
+            if _, err := tokens.ValidateToken(token); err == nil {
+                t.Errorf("%s: an HS256 token keyed with the public key validated", alg)
+            }
+        }
+
+        // A genuine signature under the wrong algorithm's name fails too.
+        for _, other := range append([]string{AlgHS256}, asymmetricAlgorithms...) {
+            if other == alg {
+                continue
+            }
+            token := forgeToken(t, header{Algorithm: other, Type: "JWT", KeyID: alg}, &keys[indexOf(keys, alg)])
+            if _, err := tokens.ValidateToken(token); err == nil {
+                t.Errorf("a %s token claiming %s validated", alg, other)
+            }
+        }
+    }
+
+    // Nor does the HS256 secret pass for an asymmetric key's signature.
+    for _, alg := range asymmetricAlgorithms {
+        token := forgeToken(t, header{Algorithm: alg, Type: "JWT", KeyID: alg}, &keys[0])
+        if _, err := tokens.ValidateToken(token); err == nil {
+            t.Errorf("an HMAC claiming %s validated", alg)
+        }
+    }
+}
+
+func mustMarshalPKIX(t *testing.T, public crypto.PublicKey) []byte {
+    t.Helper()
+
+    der, err := x509.MarshalPKIXPublicKey(public)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return der
+}
+
+func indexOf(keys []Key, id string) int {
+    for i, key := range keys {
+        if key.ID == id {
+            return i
+        }
+    }
+    return -1
+}
+
+func TestJWKS(t *testing.T) {
+    hs := mustGenerateKey(t, "hs")
+    retired := Key{ID: "retired", Algorithm: AlgEdDSA, PublicKey: testKeyPairs[AlgEdDSA].Public(), Retired: true}
+    keys := []Key{hs, retired}
+    for _, alg := range asymmetricAlgorithms {
+        keys = append(keys, Key{ID: alg, Algorithm: alg, PrivateKey: testKeyPairs[alg]})
+    }
+    set := mustKeySet(t, keys, "")
+
+    data, err := json.Marshal(set.JWKS())
+    if err != nil {
+        t.Fatal(err)
+    }
+    var parsed struct {
+        Keys []map[string]string `json:"keys"`
+    }
+    if err := json.Unmarshal(data, &parsed); err != nil {
+        t.Fatalf("parsing %s: %v", data, err)
+    }
+
+    // Listed in configured order, without the HS256 and retired keys.
+    var ids []string
+    for _, jwk := range parsed.Keys {
+        ids = append(ids, jwk["kid"])
+    }
+    if strings.Join(ids, ",") != strings.Join(asymmetricAlgorithms, ",") {
+        t.Errorf("JWKS lists %v, want %v", ids, asymmetricAlgorithms)
+    }
+
+    b64 := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+    edPublic := testKeyPairs[AlgEdDSA].Public().(ed25519.PublicKey)
+    rsaPublic := testKeyPairs[AlgRS256].Public().(*rsa.PublicKey)
+    ecPublic := testKeyPairs[AlgES256].Public().(*ecdsa.PublicKey)
+    want := map[string]map[string]string{
+        AlgEdDSA: {"kty": "OKP", "kid": AlgEdDSA, "alg": AlgEdDSA, "use": "sig", "crv": "Ed25519", "x": b64(edPublic)},
+        AlgRS256: {"kty": "RSA", "kid": AlgRS256, "alg": AlgRS256, "use": "sig",
+            "n": b64(rsaPublic.N.Bytes()), "e": b64(big.NewInt(int64(rsaPublic.E)).Bytes())},
+        AlgES256: {"kty": "EC", "kid": AlgES256, "alg": AlgES256, "use": "sig", "crv": "P-256",
+            "x": b64(ecPublic.X.FillBytes(make([]byte, 32))), "y": b64(ecPublic.Y.FillBytes(make([]byte, 32)))},
+    }
+    for _, jwk := range parsed.Keys {
+        expected := want[jwk["kid"]]
+        if len(jwk) != len(expected) {
+            t.Errorf("%s: members %v, want exactly %v", jwk["kid"], jwk, expected)
+        }
+        for member, value := range expected {
+            if jwk[member] != value {
+                t.Errorf("%s: %s = %q, want %q", jwk["kid"], member, jwk[member], value)
+            }
+        }
+    }
+    if len(parsed.Keys) > 1 && parsed.Keys[1]["e"] != "AQAB" {
+        t.Errorf("RSA e = %q, want AQAB", parsed.Keys[1]["e"])
+    }
+
+    // No secret leaks, in any encoding.
+    document := string(data)
+    for _, secret := range [][]byte{hs.Secret, testKeyPairs[AlgEdDSA].(ed25519.PrivateKey).Seed(),
+        testKeyPairs[AlgRS256].(*rsa.PrivateKey).D.Bytes(), testKeyPairs[AlgES256].(*ecdsa.PrivateKey).D.Bytes()} {
+        for _, encoded := range []string{b64(secret), base64.StdEncoding.EncodeToString(secret), base64.RawStdEncoding.EncodeToString(secret)} {
+            if strings.Contains(document, encoded) {
+                t.Errorf("JWKS contains private material: %s", document)
+            }
+        }
+    }
+    for _, member := range []string{`"d"`, `"p"`, `"q"`, `"dp"`, `"dq"`, `"qi"`, `"k"`} {
+        if strings.Contains(document, member) {
+            t.Errorf("JWKS has a private member %s", member)
+        }
+    }
+
+    empty, _ := json.Marshal(mustKeySet(t, []Key{hs}, "").JWKS())
+    if string(empty) != `{"keys":[]}` {
+        t.Errorf("JWKS of an HS256 key set = %s", empty)
+    }
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+)
+
+// JWK is the public half of a key as RFC 7517 describes it. Only the
+// members of its key type are set.
+type JWK struct {
+    KeyType   string `json:"kty"`
+    KeyID     string `json:"kid"`
+    Algorithm string `json:"alg"`
+    Use       string `json:"use"`
+    Curve     string `json:"crv,omitempty"`
+    X         string `json:"x,omitempty"`
+    Y         string `json:"y,omitempty"`
+    N         string `json:"n,omitempty"`
+    E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+    Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys tokens may be validated with, so other
+// services can check them. HS256 keys are secret and never listed, nor are
+// retired keys.
+func (s *KeySet) JWKS() JWKSet {
+    set := JWKSet{Keys: []JWK{}}
+    for _, id := range s.order {
+        key := s.keys[id]
+        if key.Retired || key.Algorithm == AlgHS256 {
+            continue
+        }
+
+        jwk := JWK{KeyID: key.ID, Algorithm: key.Algorithm, Use: "sig"}
+        switch public := key.PublicKey.(type) {
+        case ed25519.PublicKey:
+            jwk.KeyType = "OKP"
+            jwk.Curve = "Ed25519"
+            jwk.X = encodeBytes(public)
+        case *rsa.PublicKey:
+            jwk.KeyType = "RSA"
+            jwk.N = encodeBytes(public.N.Bytes())
+            jwk.E = encodeBytes(big.NewInt(int64(public.E)).Bytes())
+        case *ecdsa.PublicKey:
+            jwk.KeyType = "EC"
+            jwk.Curve = "P-256"
+            jwk.X = encodeBytes(public.X.FillBytes(make([]byte, es256PartSize)))
+            jwk.Y = encodeBytes(public.Y.FillBytes(make([]byte, es256PartSize)))
+        }
+        set.Keys = append(set.Keys, jwk)
+    }
+
+    return set
+}
+
+func encodeBytes(b []byte) string {
+    return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -1,8 +1,6 @@
 package auth
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -10,92 +8,141 @@ import (
 	"time"
 )
 
-var secretKey = []byte("381c64015c0073c9a112cd4f0ff395ad9ac957ebd67b7f3ac2bcbef5a0f2ec99") 
-
 type Claims struct {
+    // ID is unique to each token, so a single token can be revoked.
+    ID string `json:"jti"`
+    // SessionID ties the token to the login it came from, so every token
+    // of a session can be revoked at once.
+    SessionID string `json:"sid"`
     UserID    int64  `json:"user_id"`
     Username  string `json:"username"`
     ExpiresAt int64  `json:"exp"`
 }
 
-func GenerateToken(userID int64, username string) (string, error) {
+type header struct {
+    Algorithm string `json:"alg"`
+    Type      string `json:"typ"`
+    KeyID     string `json:"kid"`
+}
+
+// TokenService issues and validates the JWTs users authenticate with, using
+// the keys of a KeySet.
+type TokenService struct {
+    keys *KeySet
+    // ttl is how long the tokens it issues are valid for.
+    ttl time.Duration
+}
+
+func NewTokenService(keys *KeySet, ttl time.Duration) *TokenService {
+    return &TokenService{keys: keys, ttl: ttl}
+}
+
+func (s *TokenService) TTL() time.Duration {
+    return s.ttl
+}
+
+func (s *TokenService) GenerateToken(userID int64, username, sessionID string) (string, error) {
     // Create header
-    header := map[string]string{
-        "alg": "HS256",
-        "typ": "JWT",
-    }
-    
-    headerJSON, err := json.Marshal(header)
+    key := s.keys.signing
+    headerJSON, err := json.Marshal(header{
+        Algorithm: key.Algorithm,
+        Type:      "JWT",
+        KeyID:     key.ID,
+    })
     if err != nil {
         return "", err
     }
-    
+
     // Create claims
+    id, err := randomID()
+    if err != nil {
+        return "", err
+    }
     claims := Claims{
+        ID:        id,
+        SessionID: sessionID,
         UserID:    userID,
         Username:  username,
-        ExpiresAt: time.Now().Add(24 * time.Hour).Unix(), // Token expires in 24 hours
+        ExpiresAt: time.Now().Add(s.ttl).Unix(),
     }
-    
+
     claimsJSON, err := json.Marshal(claims)
     if err != nil {
         return "", err
     }
-    
+
     // Create signature
     encodedHeader := base64.RawURLEncoding.EncodeToString(headerJSON)
     encodedClaims := base64.RawURLEncoding.EncodeToString(claimsJSON)
-    
+
     signatureInput := encodedHeader + "." + encodedClaims
-    signature := generateSignature([]byte(signatureInput))
-    
+    signature, err := sign(key, []byte(signatureInput))
+    if err != nil {
+        return "", fmt.Errorf("error signing token: %w", err)
+    }
+
     // Combine to create token
     token := signatureInput + "." + base64.RawURLEncoding.EncodeToString(signature)
-    
+
     return token, nil
 }
 
-func ValidateToken(token string) (*Claims, error) {
+// ValidateToken checks the signature of a token against the key named in
+// its kid header, which must not be retired. The alg header has to name the
+// algorithm of that key, so a token cannot pass off, say, an HMAC made with
+// a public key as valid.
+func (s *TokenService) ValidateToken(token string) (*Claims, error) {
     // Split token into parts
     parts := strings.Split(token, ".")
     if len(parts) != 3 {
         return nil, fmt.Errorf("invalid token format")
     }
-    
+
+    // Find the key from the header
+    headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+    if err != nil {
+        return nil, fmt.Errorf("invalid header encoding")
+    }
+
+    var h header
+    if err := json.Unmarshal(headerJSON, &h); err != nil {
+        return nil, fmt.Errorf("invalid header format")
+    }
+
+    key, ok := s.keys.lookup(h.KeyID)
+    if !ok {
+        return nil, fmt.Errorf("unknown key")
+    }
+    if h.Algorithm != key.Algorithm {
+        return nil, fmt.Errorf("algorithm mismatch")
+    }
+
     // Verify signature
     signatureInput := parts[0] + "." + parts[1]
-    expectedSignature := generateSignature([]byte(signatureInput))
-    
-    actualSignature, err := base64.RawURLEncoding.DecodeString(parts[2])
+    signature, err := base64.RawURLEncoding.DecodeString(parts[2])
     if err != nil {
         return nil, fmt.Errorf("invalid signature encoding")
     }
-    
-    if !hmac.Equal(expectedSignature, actualSignature) {
+
+    if !verify(key, []byte(signatureInput), signature) {
         return nil, fmt.Errorf("invalid signature")
     }
-    
+
     // Decode claims
     claimsJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
     if err != nil {
         return nil, fmt.Errorf("invalid claims encoding")
     }
-    
+
     var claims Claims
     if err := json.Unmarshal(claimsJSON, &claims); err != nil {
         return nil, fmt.Errorf("invalid claims format")
     }
-    
+
     // Verify expiration
     if claims.ExpiresAt < time.Now().Unix() {
         return nil, fmt.Errorf("token has expired")
     }
-    
+
     return &claims, nil
 }
-
-func generateSignature(data []byte) []byte {
-    h := hmac.New(sha256.New, secretKey)
-    h.Write(data)
-    return h.Sum(nil)
-}
\ No newline at end of file
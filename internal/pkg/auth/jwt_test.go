@@ -0,0 +1,277 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustGenerateKey(t *testing.T, id string) Key {
+    t.Helper()
+
+    key, err := GenerateKey(id)
+    if err != nil {
+        t.Fatalf("GenerateKey: %v", err)
+    }
+    return key
+}
+
+func mustKeySet(t *testing.T, keys []Key, signingID string) *KeySet {
+    t.Helper()
+
+    set, err := NewKeySet(keys, signingID)
+    if err != nil {
+        t.Fatalf("NewKeySet: %v", err)
+    }
+    return set
+}
+
+func mustGenerateToken(t *testing.T, tokens *TokenService) string {
+    t.Helper()
+
+    token, err := tokens.GenerateToken(7, "alice", "session")
+    if err != nil {
+        t.Fatalf("GenerateToken: %v", err)
+    }
+    return token
+}
+
+// tokenHeader decodes the header of token.
+func tokenHeader(t *testing.T, token string) header {
+    t.Helper()
+
+    data, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[0])
+    if err != nil {
+        t.Fatalf("decoding header: %v", err)
+    }
+    var h header
+    if err := json.Unmarshal(data, &h); err != nil {
+        t.Fatalf("parsing header: %v", err)
+    }
+    return h
+}
+
+// forgeToken builds a token with header h signed by key, whatever the
+// header says, as an attacker would.
+func forgeToken(t *testing.T, h header, key *Key) string {
+    t.Helper()
+
+    headerJSON, err := json.Marshal(h)
+    if err != nil {
+        t.Fatal(err)
+    }
+    claimsJSON, err := json.Marshal(Claims{ID: "forged", UserID: 1, Username: "admin", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    input := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+    signature, err := sign(key, []byte(input))
+    if err != nil {
+        t.Fatalf("sign: %v", err)
+    }
+    return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestTokenRoundTrip(t *testing.T) {
+    tokens := NewTokenService(mustKeySet(t, []Key{mustGenerateKey(t, "k1")}, ""), time.Minute)
+
+    token := mustGenerateToken(t, tokens)
+    if h := tokenHeader(t, token); h.Algorithm != AlgHS256 || h.KeyID != "k1" || h.Type != "JWT" {
+        t.Errorf("header = %+v", h)
+    }
+
+    claims, err := tokens.ValidateToken(token)
+    if err != nil {
+        t.Fatalf("ValidateToken: %v", err)
+    }
+    if claims.UserID != 7 || claims.Username != "alice" || claims.SessionID != "session" || claims.ID == "" {
+        t.Errorf("claims = %+v", claims)
+    }
+
+    other := mustGenerateToken(t, tokens)
+    if otherClaims, _ := tokens.ValidateToken(other); otherClaims == nil || otherClaims.ID == claims.ID {
+        t.Error("two tokens share a jti")
+    }
+}
+
+func TestValidateTokenLooksUpKeyByKid(t *testing.T) {
+    k1, k2 := mustGenerateKey(t, "k1"), mustGenerateKey(t, "k2")
+    first := NewTokenService(mustKeySet(t, []Key{k1, k2}, "k1"), time.Minute)
+    second := NewTokenService(mustKeySet(t, []Key{k1, k2}, "k2"), time.Minute)
+
+    fromFirst, fromSecond := mustGenerateToken(t, first), mustGenerateToken(t, second)
+    if kid := tokenHeader(t, fromSecond).KeyID; kid != "k2" {
+        t.Fatalf("signed with %q, want k2", kid)
+    }
+
+    // Each service validates the tokens of the other, whichever key it signs
+    // with.
+    for _, tokens := range []*TokenService{first, second} {
+        for _, token := range []string{fromFirst, fromSecond} {
+            if _, err := tokens.ValidateToken(token); err != nil {
+                t.Errorf("ValidateToken: %v", err)
+            }
+        }
+    }
+
+    // A token claiming the other key fails its signature check.
+    h := tokenHeader(t, fromFirst)
+    h.KeyID = "k2"
+    if _, err := first.ValidateToken(forgeToken(t, h, &k1)); err == nil {
+        t.Error("a token signed with k1 validated as k2")
+    }
+}
+
+func TestRotatedKeysKeepValidating(t *testing.T) {
+    old := mustGenerateKey(t, "old")
+    before := NewTokenService(mustKeySet(t, []Key{old}, ""), time.Minute)
+    token := mustGenerateToken(t, before)
+
+    // The new key signs, the old one still validates what it signed.
+    after := NewTokenService(mustKeySet(t, []Key{old, mustGenerateKey(t, "new")}, "new"), time.Minute)
+    if _, err := after.ValidateToken(token); err != nil {
+        t.Errorf("a token of the previous key: %v", err)
+    }
+    if kid := tokenHeader(t, mustGenerateToken(t, after)).KeyID; kid != "new" {
+        t.Errorf("signed with %q after rotating, want new", kid)
+    }
+}
+
+func TestRetiredKeys(t *testing.T) {
+    old, current := mustGenerateKey(t, "old"), mustGenerateKey(t, "current")
+    token := mustGenerateToken(t, NewTokenService(mustKeySet(t, []Key{old}, ""), time.Minute))
+
+    retired := old
+    retired.Retired = true
+
+    // A retired key is never picked to sign, first in the list or named.
+    set := mustKeySet(t, []Key{retired, current}, "")
+    if set.signing.ID != "current" {
+        t.Errorf("signing with %q, want current", set.signing.ID)
+    }
+    if _, err := NewKeySet([]Key{retired, current}, "old"); err == nil {
+        t.Error("NewKeySet signs with a retired key")
+    }
+    if _, err := NewKeySet([]Key{retired}, ""); err == nil {
+        t.Error("NewKeySet succeeded with only a retired key")
+    }
+
+    // Nor do the tokens it signed validate any longer.
+    if _, err := NewTokenService(set, time.Minute).ValidateToken(token); err == nil {
+        t.Error("a token of a retired key validated")
+    }
+    if _, ok := set.lookup("old"); ok {
+        t.Error("lookup found a retired key")
+    }
+}
+
+func TestValidateTokenRejectsUnknownKid(t *testing.T) {
+    key := mustGenerateKey(t, "k1")
+    tokens := NewTokenService(mustKeySet(t, []Key{key}, ""), time.Minute)
+
+    for _, kid := range []string{"", "k2", "K1"} {
+        token := forgeToken(t, header{Algorithm: AlgHS256, Type: "JWT", KeyID: kid}, &key)
+        if _, err := tokens.ValidateToken(token); err == nil || err.Error() != "unknown key" {
+            t.Errorf("kid %q: err = %v, want unknown key", kid, err)
+        }
+    }
+
+    // A key dropped from the configuration takes its tokens with it.
+    token := mustGenerateToken(t, tokens)
+    without := NewTokenService(mustKeySet(t, []Key{mustGenerateKey(t, "k2")}, ""), time.Minute)
+    if _, err := without.ValidateToken(token); err == nil {
+        t.Error("a token of a removed key validated")
+    }
+}
+
+func TestValidateTokenRejectsAlgorithmMismatch(t *testing.T) {
+    key := mustGenerateKey(t, "k1")
+    tokens := NewTokenService(mustKeySet(t, []Key{key}, ""), time.Minute)
+
+    for _, alg := range []string{"none", "", "hs256", AlgRS256, AlgEdDSA, "HS512"} {
+        token := forgeToken(t, header{Algorithm: alg, Type: "JWT", KeyID: "k1"}, &key)
+        if _, err := tokens.ValidateToken(token); err == nil || err.Error() != "algorithm mismatch" {
+            t.Errorf("alg %q: err = %v, want algorithm mismatch", alg, err)
+        }
+    }
+
+    // An unsigned token is refused whatever its header.
+    unsigned := strings.Join(strings.Split(mustGenerateToken(t, tokens), ".")[:2], ".") + "."
+    if _, err := tokens.ValidateToken(unsigned); err == nil {
+        t.Error("a token without a signature validated")
+    }
+}
+
+func TestValidateTokenRejectsTampering(t *testing.T) {
+    tokens := NewTokenService(mustKeySet(t, []Key{mustGenerateKey(t, "k1")}, ""), time.Minute)
+    parts := strings.Split(mustGenerateToken(t, tokens), ".")
+
+    claims, _ := json.Marshal(Claims{ID: "x", UserID: 1, Username: "admin", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+    tampered := parts[0] + "." + base64.RawURLEncoding.EncodeToString(claims) + "." + parts[2]
+    if _, err := tokens.ValidateToken(tampered); err == nil {
+        t.Error("a token with altered claims validated")
+    }
+
+    for _, malformed := range []string{"", "a.b", "a.b.c.d", "!.!.!"} {
+        if _, err := tokens.ValidateToken(malformed); err == nil {
+            t.Errorf("ValidateToken(%q) succeeded", malformed)
+        }
+    }
+}
+
+func TestValidateTokenRejectsExpired(t *testing.T) {
+    tokens := NewTokenService(mustKeySet(t, []Key{mustGenerateKey(t, "k1")}, ""), -time.Minute)
+
+    if _, err := tokens.ValidateToken(mustGenerateToken(t, tokens)); err == nil || err.Error() != "token has expired" {
+        t.Errorf("err = %v, want token has expired", err)
+    }
+}
+
+func TestNewKeySetValidates(t *testing.T) {
+    key := mustGenerateKey(t, "k1")
+    short := Key{ID: "short", Algorithm: AlgHS256, Secret: make([]byte, minSecretSize-1)}
+
+    tests := map[string]struct {
+        keys      []Key
+        signingID string
+    }{
+        "no keys":         {nil, ""},
+        "no kid":          {[]Key{{Algorithm: AlgHS256, Secret: key.Secret}}, ""},
+        "duplicate kid":   {[]Key{key, key}, ""},
+        "short secret":    {[]Key{key, short}, ""},
+        "missing signing": {[]Key{key}, "k2"},
+    }
+    for name, test := range tests {
+        if _, err := NewKeySet(test.keys, test.signingID); err == nil {
+            t.Errorf("%s: NewKeySet succeeded", name)
+        }
+    }
+}
+
+func TestParseKeys(t *testing.T) {
+    keys, err := ParseKeys([]byte(`[
+        {"kid": "a", "secret": "` + base64.StdEncoding.EncodeToString(make([]byte, 32)) + `"},
+        {"kid": "b", "alg": "HS256", "secret": "` + base64.StdEncoding.EncodeToString(make([]byte, 48)) + `", "retired": true}
+    ]`))
+    if err != nil {
+        t.Fatalf("ParseKeys: %v", err)
+    }
+    if len(keys) != 2 || keys[0].Algorithm != AlgHS256 || len(keys[0].Secret) != 32 || !keys[1].Retired || keys[0].Retired {
+        t.Errorf("keys = %+v", keys)
+    }
+
+    for _, data := range []string{
+        `{}`,
+        `[{"kid": "a", "secret": "not base64!"}]`,
+        `[{"kid": "a", "alg": "PS256"}]`,
+        `[{"kid": "a", "alg": "EdDSA", "private_key": "x", "private_key_file": "y"}]`,
+        `[{"kid": "a", "alg": "EdDSA", "private_key": "not pem"}]`,
+    } {
+        if _, err := ParseKeys([]byte(data)); err == nil {
+            t.Errorf("ParseKeys(%s) succeeded", data)
+        }
+    }
+}
@@ -0,0 +1,236 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// Key signs tokens and validates them, named in their kid header. An HS256
+// key is a shared Secret; the others are key pairs, and a key with only its
+// PublicKey validates tokens without being able to sign them. A retired key
+// no longer validates tokens; it can be kept in the configuration to record
+// that it was taken out of use.
+type Key struct {
+    ID         string
+    Algorithm  string
+    Secret     []byte
+    PrivateKey crypto.Signer
+    PublicKey  crypto.PublicKey
+    Retired    bool
+}
+
+// keyConfig is a key as written in the configuration. The secret is base64
+// encoded; the key pairs are PEM, inline or in a file.
+type keyConfig struct {
+    ID             string `json:"kid"`
+    Algorithm      string `json:"alg"`
+    Secret         string `json:"secret"`
+    PrivateKey     string `json:"private_key"`
+    PrivateKeyFile string `json:"private_key_file"`
+    PublicKey      string `json:"public_key"`
+    PublicKeyFile  string `json:"public_key_file"`
+    Retired        bool   `json:"retired"`
+}
+
+// ParseKeys reads a JSON array of keys such as
+//
+//	[
+//	    {"kid": "2026-10", "alg": "EdDSA", "private_key_file": "/etc/todo/2026-10.pem"},
+//	    {"kid": "2026-04", "alg": "HS256", "secret": "<base64>", "retired": true}
+//	]
+//
+// alg defaults to HS256. Private keys are PKCS #8, PKCS #1 or SEC 1 PEM and
+// public keys PKIX PEM.
+func ParseKeys(data []byte) ([]Key, error) {
+    var configs []keyConfig
+    if err := json.Unmarshal(data, &configs); err != nil {
+        return nil, fmt.Errorf("error parsing keys: %w", err)
+    }
+
+    keys := make([]Key, len(configs))
+    for i, config := range configs {
+        key, err := parseKey(config)
+        if err != nil {
+            return nil, fmt.Errorf("error loading key %q: %w", config.ID, err)
+        }
+        keys[i] = key
+    }
+
+    return keys, nil
+}
+
+func parseKey(config keyConfig) (Key, error) {
+    key := Key{ID: config.ID, Algorithm: config.Algorithm, Retired: config.Retired}
+    if key.Algorithm == "" {
+        key.Algorithm = AlgHS256
+    }
+
+    switch key.Algorithm {
+    case AlgHS256:
+        secret, err := base64.StdEncoding.DecodeString(config.Secret)
+        if err != nil {
+            return key, fmt.Errorf("error decoding secret: %w", err)
+        }
+        key.Secret = secret
+    case AlgEdDSA, AlgRS256, AlgES256:
+        privatePEM, err := pemData(config.PrivateKey, config.PrivateKeyFile)
+        if err != nil {
+            return key, err
+        }
+        if privatePEM != nil {
+            if key.PrivateKey, err = parsePrivateKey(privatePEM); err != nil {
+                return key, err
+            }
+            break
+        }
+
+        publicPEM, err := pemData(config.PublicKey, config.PublicKeyFile)
+        if err != nil {
+            return key, err
+        }
+        if publicPEM != nil {
+            if key.PublicKey, err = parsePublicKey(publicPEM); err != nil {
+                return key, err
+            }
+        }
+    default:
+        return key, fmt.Errorf("unsupported algorithm %q", key.Algorithm)
+    }
+
+    return key, nil
+}
+
+// pemData returns the inline PEM, or the content of file, or nil when
+// neither is set.
+func pemData(inline, file string) ([]byte, error) {
+    switch {
+    case inline != "" && file != "":
+        return nil, fmt.Errorf("both a key and a key file are set")
+    case inline != "":
+        return []byte(inline), nil
+    case file != "":
+        data, err := os.ReadFile(file)
+        if err != nil {
+            return nil, fmt.Errorf("error reading key file: %w", err)
+        }
+        return data, nil
+    default:
+        return nil, nil
+    }
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+    block, _ := pem.Decode(data)
+    if block == nil {
+        return nil, fmt.Errorf("private key is not PEM")
+    }
+
+    var parsed interface{}
+    var err error
+    switch block.Type {
+    case "PRIVATE KEY":
+        parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+    case "RSA PRIVATE KEY":
+        parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+    case "EC PRIVATE KEY":
+        parsed, err = x509.ParseECPrivateKey(block.Bytes)
+    default:
+        return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error parsing private key: %w", err)
+    }
+
+    signer, ok := parsed.(crypto.Signer)
+    if !ok {
+        return nil, fmt.Errorf("unsupported private key type %T", parsed)
+    }
+    return signer, nil
+}
+
+func parsePublicKey(data []byte) (crypto.PublicKey, error) {
+    block, _ := pem.Decode(data)
+    if block == nil || block.Type != "PUBLIC KEY" {
+        return nil, fmt.Errorf("public key is not a PUBLIC KEY PEM block")
+    }
+
+    public, err := x509.ParsePKIXPublicKey(block.Bytes)
+    if err != nil {
+        return nil, fmt.Errorf("error parsing public key: %w", err)
+    }
+    return public, nil
+}
+
+// GenerateKey returns a random HS256 key for when none is configured.
+// Tokens signed with it do not outlive the process.
+func GenerateKey(id string) (Key, error) {
+    secret := make([]byte, minSecretSize)
+    if _, err := rand.Read(secret); err != nil {
+        return Key{}, fmt.Errorf("error generating key: %w", err)
+    }
+
+    return Key{ID: id, Algorithm: AlgHS256, Secret: secret}, nil
+}
+
+// KeySet holds the keys tokens are validated against and the one new
+// tokens are signed with. Rotating means adding a key and signing with it
+// while the previous key keeps validating the tokens it signed, then
+// retiring the previous key once those have expired.
+type KeySet struct {
+    signing *Key
+    keys    map[string]*Key
+    // order lists the key IDs as configured, for JWKS.
+    order []string
+}
+
+// NewKeySet builds a key set signing with the key named signingID, or the
+// first key able to sign that is not retired when signingID is empty.
+func NewKeySet(keys []Key, signingID string) (*KeySet, error) {
+    set := &KeySet{keys: make(map[string]*Key, len(keys))}
+
+    for i := range keys {
+        key := &keys[i]
+        if key.ID == "" {
+            return nil, fmt.Errorf("key %d has no kid", i)
+        }
+        if _, ok := set.keys[key.ID]; ok {
+            return nil, fmt.Errorf("duplicate kid %q", key.ID)
+        }
+        if err := checkKey(key); err != nil {
+            return nil, err
+        }
+        set.keys[key.ID] = key
+        set.order = append(set.order, key.ID)
+
+        if key.Retired || !key.canSign() {
+            continue
+        }
+        if set.signing == nil && signingID == "" || key.ID == signingID {
+            set.signing = key
+        }
+    }
+
+    if set.signing == nil {
+        if signingID != "" {
+            return nil, fmt.Errorf("signing key %q is missing, retired or has no private key", signingID)
+        }
+        return nil, fmt.Errorf("no key to sign with")
+    }
+
+    return set, nil
+}
+
+// lookup returns the key named id if it may validate tokens.
+func (s *KeySet) lookup(id string) (*Key, bool) {
+    key, ok := s.keys[id]
+    if !ok || key.Retired {
+        return nil, false
+    }
+    return key, true
+}
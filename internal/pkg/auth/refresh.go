@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+    refreshTokenSize = 32
+    // idSize gives IDs of 32 hex characters.
+    idSize = 16
+)
+
+// NewRefreshToken returns a random opaque refresh token and the hash it is
+// stored under.
+func NewRefreshToken() (string, string, error) {
+    b := make([]byte, refreshTokenSize)
+    if _, err := rand.Read(b); err != nil {
+        return "", "", fmt.Errorf("error generating refresh token: %w", err)
+    }
+
+    token := base64.RawURLEncoding.EncodeToString(b)
+    return token, HashRefreshToken(token), nil
+}
+
+// HashRefreshToken returns the hex SHA-256 of a refresh token. The token is
+// random enough that it needs no salt or stretching.
+func HashRefreshToken(token string) string {
+    sum := sha256.Sum256([]byte(token))
+    return hex.EncodeToString(sum[:])
+}
+
+// NewTokenFamily returns a random ID for the family of refresh tokens that
+// starts at a login. It doubles as the session ID of the access tokens
+// issued alongside them.
+func NewTokenFamily() (string, error) {
+    return randomID()
+}
+
+// randomID returns a random ID for a token or token family.
+func randomID() (string, error) {
+    b := make([]byte, idSize)
+    if _, err := rand.Read(b); err != nil {
+        return "", fmt.Errorf("error generating ID: %w", err)
+    }
+
+    return hex.EncodeToString(b), nil
+}
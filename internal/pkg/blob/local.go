@@ -0,0 +1,93 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore keeps blobs as files under a directory, one file per key.
+type LocalStore struct {
+    dir string
+}
+
+// NewLocalStore returns a store rooted at dir, creating it if needed.
+func NewLocalStore(dir string) (*LocalStore, error) {
+    if err := os.MkdirAll(dir, 0o750); err != nil {
+        return nil, fmt.Errorf("error creating blob directory: %w", err)
+    }
+    return &LocalStore{dir: dir}, nil
+}
+
+// Put writes content to a temporary file next to its destination and
+// renames it into place, so a reader never sees a partial blob.
+func (s *LocalStore) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+    path, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+        return fmt.Errorf("error creating blob directory: %w", err)
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+    if err != nil {
+        return fmt.Errorf("error creating blob file: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+
+    if _, err := io.Copy(tmp, content); err != nil {
+        tmp.Close()
+        return fmt.Errorf("error writing blob: %w", err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("error writing blob: %w", err)
+    }
+
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        return fmt.Errorf("error storing blob: %w", err)
+    }
+
+    return nil
+}
+
+func (s *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+    path, err := s.path(key)
+    if err != nil {
+        return nil, err
+    }
+
+    file, err := os.Open(path)
+    if err != nil {
+        return nil, fmt.Errorf("error opening blob: %w", err)
+    }
+
+    return file, nil
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+    path, err := s.path(key)
+    if err != nil {
+        return err
+    }
+
+    if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+        return fmt.Errorf("error deleting blob: %w", err)
+    }
+
+    return nil
+}
+
+// path maps key to a file under the store's directory, refusing keys that
+// would point outside it.
+func (s *LocalStore) path(key string) (string, error) {
+    name := filepath.FromSlash(key)
+    if !filepath.IsLocal(name) {
+        return "", fmt.Errorf("invalid blob key %q", key)
+    }
+    return filepath.Join(s.dir, name), nil
+}
@@ -0,0 +1,99 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readBlob(t *testing.T, store *LocalStore, key string) string {
+    t.Helper()
+
+    body, err := store.Get(context.Background(), key)
+    if err != nil {
+        t.Fatalf("Get %q: %v", key, err)
+    }
+    defer body.Close()
+
+    content, err := io.ReadAll(body)
+    if err != nil {
+        t.Fatalf("reading %q: %v", key, err)
+    }
+    return string(content)
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+    dir := filepath.Join(t.TempDir(), "blobs")
+    store, err := NewLocalStore(dir)
+    if err != nil {
+        t.Fatalf("NewLocalStore: %v", err)
+    }
+    ctx := context.Background()
+
+    key := "tasks/7/my file ü.txt"
+    for _, content := range []string{"first", "second, replacing the first"} {
+        if err := store.Put(ctx, key, "text/plain", strings.NewReader(content), int64(len(content))); err != nil {
+            t.Fatalf("Put: %v", err)
+        }
+        if got := readBlob(t, store, key); got != content {
+            t.Errorf("Get = %q, want %q", got, content)
+        }
+    }
+
+    if _, err := os.Stat(filepath.Join(dir, "tasks", "7", "my file ü.txt")); err != nil {
+        t.Errorf("blob not stored under its key: %v", err)
+    }
+    leftovers, _ := filepath.Glob(filepath.Join(dir, "tasks", "7", ".upload-*"))
+    if len(leftovers) != 0 {
+        t.Errorf("temporary files left behind: %v", leftovers)
+    }
+
+    if err := store.Delete(ctx, key); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if _, err := store.Get(ctx, key); !errors.Is(err, fs.ErrNotExist) {
+        t.Errorf("Get after Delete: err = %v, want fs.ErrNotExist", err)
+    }
+    if err := store.Delete(ctx, key); err != nil {
+        t.Errorf("Delete of a missing blob: %v", err)
+    }
+}
+
+func TestLocalStoreRejectsKeysOutsideItsDirectory(t *testing.T) {
+    root := t.TempDir()
+    store, err := NewLocalStore(filepath.Join(root, "blobs"))
+    if err != nil {
+        t.Fatalf("NewLocalStore: %v", err)
+    }
+    ctx := context.Background()
+
+    outside := filepath.Join(root, "outside.txt")
+    if err := os.WriteFile(outside, []byte("keep me"), 0o600); err != nil {
+        t.Fatal(err)
+    }
+
+    for _, key := range []string{"", "../outside.txt", "a/../../outside.txt", "/etc/passwd", "../../../../tmp/x"} {
+        if err := store.Put(ctx, key, "text/plain", strings.NewReader("x"), 1); err == nil {
+            t.Errorf("Put %q succeeded", key)
+        }
+        if body, err := store.Get(ctx, key); err == nil {
+            body.Close()
+            t.Errorf("Get %q succeeded", key)
+        }
+        if err := store.Delete(ctx, key); err == nil {
+            t.Errorf("Delete %q succeeded", key)
+        }
+    }
+
+    if content, err := os.ReadFile(outside); err != nil || string(content) != "keep me" {
+        t.Errorf("file outside the store = %q, %v; want it untouched", content, err)
+    }
+    if entries, _ := os.ReadDir(filepath.Join(root, "blobs")); len(entries) != 0 {
+        t.Errorf("store holds %d entries after rejected writes", len(entries))
+    }
+}
@@ -0,0 +1,253 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+    defaultS3Timeout = 30 * time.Second
+
+    // unsignedPayload is sent as the payload hash so uploads can stream
+    // instead of being hashed up front. Every S3-compatible server accepts
+    // it.
+    unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// S3Config addresses a bucket on an S3-compatible server. Objects are
+// requested path-style, {Endpoint}/{Bucket}/{key}, which AWS and the common
+// self-hosted servers all support.
+type S3Config struct {
+    Endpoint  string
+    Bucket    string
+    Region    string
+    AccessKey string
+    SecretKey string
+}
+
+// S3Store keeps blobs as objects in a bucket, signing each request with AWS
+// Signature Version 4.
+type S3Store struct {
+    endpoint *url.URL
+    config   S3Config
+    client   *http.Client
+}
+
+// NewS3Store returns a store for the bucket in config. A nil client uses one
+// with a 30 second timeout.
+func NewS3Store(config S3Config, client *http.Client) (*S3Store, error) {
+    endpoint, err := url.Parse(config.Endpoint)
+    if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+        return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+    }
+    if config.Bucket == "" {
+        return nil, fmt.Errorf("S3 bucket is required")
+    }
+    if config.Region == "" {
+        config.Region = "us-east-1"
+    }
+    if client == nil {
+        client = &http.Client{Timeout: defaultS3Timeout}
+    }
+
+    return &S3Store{endpoint: endpoint, config: config, client: client}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+    req, err := s.newRequest(ctx, http.MethodPut, key, content)
+    if err != nil {
+        return err
+    }
+    req.ContentLength = size
+    req.Header.Set("Content-Type", contentType)
+
+    resp, err := s.do(req)
+    if err != nil {
+        return fmt.Errorf("error uploading blob: %w", err)
+    }
+    resp.Body.Close()
+
+    return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+    req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+    if err != nil {
+        return nil, err
+    }
+
+    resp, err := s.do(req)
+    if err != nil {
+        return nil, fmt.Errorf("error downloading blob: %w", err)
+    }
+
+    return resp.Body, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+    req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+    if err != nil {
+        return err
+    }
+
+    // S3 answers 204 whether or not the object existed, but not every
+    // compatible server does.
+    resp, err := s.do(req)
+    var statusErr *statusError
+    if errors.As(err, &statusErr) && statusErr.status == http.StatusNotFound {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("error deleting blob: %w", err)
+    }
+    resp.Body.Close()
+
+    return nil
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+    target := *s.endpoint
+    target.Path = strings.TrimSuffix(s.endpoint.Path, "/") + "/" + s.config.Bucket + "/" + key
+    target.RawPath = strings.TrimSuffix(s.endpoint.EscapedPath(), "/") + "/" + uriEncode(s.config.Bucket, false) + "/" + uriEncode(key, true)
+
+    req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+    if err != nil {
+        return nil, fmt.Errorf("error building S3 request: %w", err)
+    }
+
+    return req, nil
+}
+
+// statusError is a non-2xx response from the server.
+type statusError struct {
+    status int
+    detail string
+}
+
+func (e *statusError) Error() string {
+    return fmt.Sprintf("S3 responded with status %d: %s", e.status, e.detail)
+}
+
+// do signs and sends req, turning any non-2xx response into a statusError.
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+    s.sign(req, time.Now().UTC())
+
+    resp, err := s.client.Do(req)
+    if err != nil {
+        return nil, err
+    }
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        // The body is an XML error document; a little of it helps whoever
+        // reads the log.
+        detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+        resp.Body.Close()
+        return nil, &statusError{status: resp.StatusCode, detail: strings.TrimSpace(string(detail))}
+    }
+
+    return resp, nil
+}
+
+// sign adds the AWS Signature Version 4 headers for req at now. Only the
+// host and the x-amz-* headers are signed, which is what S3 requires.
+func (s *S3Store) sign(req *http.Request, now time.Time) {
+    amzDate := now.Format("20060102T150405Z")
+    date := now.Format("20060102")
+
+    req.Header.Set("X-Amz-Date", amzDate)
+    req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+    headers := map[string]string{
+        "host":                 req.URL.Host,
+        "x-amz-content-sha256": unsignedPayload,
+        "x-amz-date":           amzDate,
+    }
+    names := make([]string, 0, len(headers))
+    for name := range headers {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    var canonicalHeaders strings.Builder
+    for _, name := range names {
+        canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+    }
+    signedHeaders := strings.Join(names, ";")
+
+    canonicalRequest := strings.Join([]string{
+        req.Method,
+        req.URL.EscapedPath(),
+        canonicalQuery(req.URL.Query()),
+        canonicalHeaders.String(),
+        signedHeaders,
+        unsignedPayload,
+    }, "\n")
+
+    scope := date + "/" + s.config.Region + "/s3/aws4_request"
+    stringToSign := strings.Join([]string{
+        "AWS4-HMAC-SHA256",
+        amzDate,
+        scope,
+        hexSHA256(canonicalRequest),
+    }, "\n")
+
+    key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+    key = hmacSHA256(key, s.config.Region)
+    key = hmacSHA256(key, "s3")
+    key = hmacSHA256(key, "aws4_request")
+    signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+    req.Header.Set("Authorization", fmt.Sprintf(
+        "AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+        s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(values url.Values) string {
+    var pairs []string
+    for name, list := range values {
+        for _, value := range list {
+            pairs = append(pairs, uriEncode(name, false)+"="+uriEncode(value, false))
+        }
+    }
+    sort.Strings(pairs)
+    return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters, as
+// Signature Version 4 specifies, leaving slashes alone in object keys.
+func uriEncode(value string, keepSlash bool) string {
+    var encoded strings.Builder
+    for _, b := range []byte(value) {
+        switch {
+        case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+            b == '-', b == '_', b == '.', b == '~':
+            encoded.WriteByte(b)
+        case b == '/' && keepSlash:
+            encoded.WriteByte(b)
+        default:
+            fmt.Fprintf(&encoded, "%%%02X", b)
+        }
+    }
+    return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+    mac := hmac.New(sha256.New, key)
+    mac.Write([]byte(data))
+    return mac.Sum(nil)
+}
+
+func hexSHA256(data string) string {
+    sum := sha256.Sum256([]byte(data))
+    return hex.EncodeToString(sum[:])
+}
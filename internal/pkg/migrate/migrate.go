@@ -0,0 +1,467 @@
+// Package migrate applies and rolls back versioned SQL schema migrations,
+// recording what has been applied in a schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const lockName = "todo_app_schema_migrations"
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is one schema change loaded from a NNNN_name.up.sql and
+// NNNN_name.down.sql pair.
+type Migration struct {
+    Version int64
+    Name    string
+    Up      string
+    Down    string
+}
+
+// Status reports whether a known migration has been applied.
+type Status struct {
+    Version   int64
+    Name      string
+    Applied   bool
+    AppliedAt time.Time
+}
+
+// Dialect covers the parts of running migrations that differ between
+// databases.
+type Dialect interface {
+    // CreateVersionTable returns the DDL creating schema_migrations if it
+    // does not exist yet.
+    CreateVersionTable() string
+    // Placeholder returns the bind parameter for the n-th (1-based) argument.
+    Placeholder(n int) string
+    // Lock blocks until conn holds the migration lock, so only one process
+    // migrates a database at a time. Unlock releases it.
+    Lock(ctx context.Context, conn *sql.Conn) error
+    Unlock(ctx context.Context, conn *sql.Conn) error
+}
+
+type Migrator struct {
+    db         *sql.DB
+    dialect    Dialect
+    migrations []Migration
+}
+
+func New(db *sql.DB, dialect Dialect, files fs.FS) (*Migrator, error) {
+    migrations, err := Load(files)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Migrator{
+        db:         db,
+        dialect:    dialect,
+        migrations: migrations,
+    }, nil
+}
+
+// Load reads every migration in the root of files, ordered by version.
+func Load(files fs.FS) ([]Migration, error) {
+    entries, err := fs.ReadDir(files, ".")
+    if err != nil {
+        return nil, fmt.Errorf("error reading migrations: %w", err)
+    }
+
+    byVersion := make(map[int64]*Migration)
+    for _, entry := range entries {
+        if entry.IsDir() {
+            continue
+        }
+
+        match := fileNamePattern.FindStringSubmatch(entry.Name())
+        if match == nil {
+            return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+        }
+
+        version, err := strconv.ParseInt(match[1], 10, 64)
+        if err != nil || version <= 0 {
+            return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+        }
+
+        contents, err := fs.ReadFile(files, entry.Name())
+        if err != nil {
+            return nil, fmt.Errorf("error reading migration %q: %w", entry.Name(), err)
+        }
+
+        migration, ok := byVersion[version]
+        if !ok {
+            migration = &Migration{Version: version, Name: match[2]}
+            byVersion[version] = migration
+        }
+        if migration.Name != match[2] {
+            return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, migration.Name, match[2])
+        }
+
+        if match[3] == "up" {
+            migration.Up = string(contents)
+        } else {
+            migration.Down = string(contents)
+        }
+    }
+
+    migrations := make([]Migration, 0, len(byVersion))
+    for _, migration := range byVersion {
+        if migration.Up == "" || migration.Down == "" {
+            return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+        }
+        migrations = append(migrations, *migration)
+    }
+
+    sort.Slice(migrations, func(i, j int) bool {
+        return migrations[i].Version < migrations[j].Version
+    })
+
+    return migrations, nil
+}
+
+// Latest returns the highest known version, or 0 when there are none.
+func (m *Migrator) Latest() int64 {
+    if len(m.migrations) == 0 {
+        return 0
+    }
+    return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up() error {
+    return m.To(m.Latest())
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down() error {
+    return m.withLock(func(conn *sql.Conn) error {
+        applied, err := m.applied(conn)
+        if err != nil {
+            return err
+        }
+
+        for i := len(m.migrations) - 1; i >= 0; i-- {
+            if _, ok := applied[m.migrations[i].Version]; ok {
+                return m.rollback(conn, m.migrations[i])
+            }
+        }
+
+        return nil
+    })
+}
+
+// To migrates up or down until exactly the migrations up to and including
+// version are applied. To(0) rolls everything back.
+func (m *Migrator) To(version int64) error {
+    if version != 0 && !m.known(version) {
+        return fmt.Errorf("unknown migration version %d", version)
+    }
+
+    return m.withLock(func(conn *sql.Conn) error {
+        applied, err := m.applied(conn)
+        if err != nil {
+            return err
+        }
+
+        for i := len(m.migrations) - 1; i >= 0; i-- {
+            migration := m.migrations[i]
+            if _, ok := applied[migration.Version]; ok && migration.Version > version {
+                if err := m.rollback(conn, migration); err != nil {
+                    return err
+                }
+            }
+        }
+
+        for _, migration := range m.migrations {
+            if _, ok := applied[migration.Version]; !ok && migration.Version <= version {
+                if err := m.apply(conn, migration); err != nil {
+                    return err
+                }
+            }
+        }
+
+        return nil
+    })
+}
+
+// Status lists every known migration and whether it has been applied.
+func (m *Migrator) Status() ([]Status, error) {
+    var statuses []Status
+    err := m.withLock(func(conn *sql.Conn) error {
+        applied, err := m.applied(conn)
+        if err != nil {
+            return err
+        }
+
+        for _, migration := range m.migrations {
+            appliedAt, ok := applied[migration.Version]
+            statuses = append(statuses, Status{
+                Version:   migration.Version,
+                Name:      migration.Name,
+                Applied:   ok,
+                AppliedAt: appliedAt,
+            })
+        }
+
+        return nil
+    })
+
+    return statuses, err
+}
+
+func (m *Migrator) known(version int64) bool {
+    for _, migration := range m.migrations {
+        if migration.Version == version {
+            return true
+        }
+    }
+    return false
+}
+
+// withLock runs fn on a single connection holding the migration lock. The
+// connection is pinned because the locks some databases offer are scoped to
+// the session that took them.
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) error {
+    ctx := context.Background()
+
+    conn, err := m.db.Conn(ctx)
+    if err != nil {
+        return fmt.Errorf("error acquiring connection: %w", err)
+    }
+    defer conn.Close()
+
+    if err := m.dialect.Lock(ctx, conn); err != nil {
+        return fmt.Errorf("error acquiring migration lock: %w", err)
+    }
+    defer m.dialect.Unlock(ctx, conn)
+
+    if _, err := conn.ExecContext(ctx, m.dialect.CreateVersionTable()); err != nil {
+        return fmt.Errorf("error creating schema_migrations: %w", err)
+    }
+
+    return fn(conn)
+}
+
+func (m *Migrator) applied(conn *sql.Conn) (map[int64]time.Time, error) {
+    rows, err := conn.QueryContext(context.Background(), `SELECT version, applied_at FROM schema_migrations`)
+    if err != nil {
+        return nil, fmt.Errorf("error querying schema_migrations: %w", err)
+    }
+    defer rows.Close()
+
+    applied := make(map[int64]time.Time)
+    for rows.Next() {
+        var version int64
+        var appliedAt time.Time
+        if err := rows.Scan(&version, &appliedAt); err != nil {
+            return nil, fmt.Errorf("error scanning schema_migrations: %w", err)
+        }
+        applied[version] = appliedAt
+    }
+
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating schema_migrations: %w", err)
+    }
+
+    return applied, nil
+}
+
+func (m *Migrator) apply(conn *sql.Conn, migration Migration) error {
+    query := fmt.Sprintf(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)`,
+        m.dialect.Placeholder(1), m.dialect.Placeholder(2), m.dialect.Placeholder(3))
+
+    return m.run(conn, migration, migration.Up, query, migration.Version, migration.Name, time.Now().UTC())
+}
+
+func (m *Migrator) rollback(conn *sql.Conn, migration Migration) error {
+    query := fmt.Sprintf(`DELETE FROM schema_migrations WHERE version = %s`, m.dialect.Placeholder(1))
+
+    return m.run(conn, migration, migration.Down, query, migration.Version)
+}
+
+// run executes script and the schema_migrations bookkeeping in one
+// transaction. Databases that auto-commit DDL (MySQL) cannot roll a failed
+// script back, but the version row is still only written once every
+// statement has succeeded.
+func (m *Migrator) run(conn *sql.Conn, migration Migration, script, record string, args ...interface{}) error {
+    ctx := context.Background()
+
+    tx, err := conn.BeginTx(ctx, nil)
+    if err != nil {
+        return fmt.Errorf("error starting migration %d: %w", migration.Version, err)
+    }
+    defer tx.Rollback()
+
+    for _, statement := range splitStatements(script) {
+        if _, err := tx.ExecContext(ctx, statement); err != nil {
+            return fmt.Errorf("error running migration %d_%s: %w", migration.Version, migration.Name, err)
+        }
+    }
+
+    if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+        return fmt.Errorf("error recording migration %d: %w", migration.Version, err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing migration %d: %w", migration.Version, err)
+    }
+
+    return nil
+}
+
+// splitStatements splits a script into the statements ending in its
+// semicolons. Semicolons inside quotes, comments, PostgreSQL dollar-quoted
+// bodies and the BEGIN ... END body of a trigger do not end a statement.
+// Comments are dropped, so a script of comments runs nothing.
+func splitStatements(script string) []string {
+    var statements []string
+    var current strings.Builder
+    // depth counts the BEGIN and CASE blocks open in a trigger body.
+    depth := 0
+
+    flush := func() {
+        if statement := strings.TrimSpace(current.String()); statement != "" {
+            statements = append(statements, statement)
+        }
+        current.Reset()
+        depth = 0
+    }
+
+    for i := 0; i < len(script); {
+        c := script[i]
+        switch {
+        case c == '-' && strings.HasPrefix(script[i:], "--"):
+            end := strings.IndexByte(script[i:], '\n')
+            if end < 0 {
+                end = len(script) - i
+            }
+            i += end
+        case c == '/' && strings.HasPrefix(script[i:], "/*"):
+            end := strings.Index(script[i+2:], "*/")
+            if end < 0 {
+                end = len(script) - i - 4
+            }
+            current.WriteByte(' ')
+            i += end + 4
+        case c == '\'' || c == '"' || c == '`':
+            end := quoteEnd(script, i)
+            current.WriteString(script[i:end])
+            i = end
+        case c == '$':
+            tag := dollarTag(script[i:])
+            if tag == "" {
+                current.WriteByte(c)
+                i++
+                continue
+            }
+            end := strings.Index(script[i+len(tag):], tag)
+            if end < 0 {
+                end = len(script) - i - 2*len(tag)
+            }
+            current.WriteString(script[i : i+2*len(tag)+end])
+            i += 2*len(tag) + end
+        case isWordByte(c):
+            start := i
+            for i < len(script) && isWordByte(script[i]) {
+                i++
+            }
+            word := script[start:i]
+            depth += blockDepthChange(word, script[i:], current.String())
+            current.WriteString(word)
+        case c == ';' && depth <= 0:
+            flush()
+            i++
+        default:
+            current.WriteByte(c)
+            i++
+        }
+    }
+    flush()
+
+    return statements
+}
+
+// quoteEnd returns the index just past the quoted string, identifier or
+// backtick name starting at script[start]. A doubled quote is part of it.
+func quoteEnd(script string, start int) int {
+    quote := script[start]
+    for i := start + 1; i < len(script); i++ {
+        if script[i] != quote {
+            continue
+        }
+        if i+1 < len(script) && script[i+1] == quote {
+            i++
+            continue
+        }
+        return i + 1
+    }
+    return len(script)
+}
+
+// dollarTag returns the $tag$ or $$ that s starts with, or "" when s does
+// not start a dollar-quoted string, as with the $1 of a parameter.
+func dollarTag(s string) string {
+    for i := 1; i < len(s); i++ {
+        switch {
+        case s[i] == '$':
+            return s[:i+1]
+        case !isWordByte(s[i]) || (i == 1 && s[i] >= '0' && s[i] <= '9'):
+            return ""
+        }
+    }
+    return ""
+}
+
+func isWordByte(c byte) bool {
+    return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+var triggerPattern = regexp.MustCompile(`(?i)^\s*CREATE\s+(TEMP\s+|TEMPORARY\s+)?TRIGGER\b`)
+
+// blockDepthChange returns how word, followed by rest, changes the depth of
+// the blocks of a trigger whose statement so far is statement. BEGIN and
+// CASE open a block and END closes one, except for the END of a MySQL IF,
+// LOOP, WHILE or REPEAT, whose opening is not counted either. Outside a
+// trigger, BEGIN starts a transaction and nothing is counted.
+func blockDepthChange(word, rest, statement string) int {
+    word = strings.ToUpper(word)
+    if word != "BEGIN" && word != "CASE" && word != "END" || !triggerPattern.MatchString(statement) {
+        return 0
+    }
+
+    switch word {
+    case "BEGIN":
+        return 1
+    case "CASE":
+        // The CASE of END CASE closes the block its END already counted.
+        if fields := strings.Fields(statement); len(fields) > 0 && strings.EqualFold(fields[len(fields)-1], "END") {
+            return 0
+        }
+        return 1
+    case "END":
+        next := strings.ToUpper(firstWord(rest))
+        if next == "IF" || next == "LOOP" || next == "WHILE" || next == "REPEAT" {
+            return 0
+        }
+        return -1
+    }
+    return 0
+}
+
+// firstWord returns the word s starts with after any white space.
+func firstWord(s string) string {
+    s = strings.TrimLeft(s, " \t\r\n")
+    i := 0
+    for i < len(s) && isWordByte(s[i]) {
+        i++
+    }
+    return s[:i]
+}
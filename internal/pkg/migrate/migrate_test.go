@@ -0,0 +1,295 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"testing/fstest"
+	"time"
+	"todo-app/internal/migrations"
+	"todo-app/internal/pkg/sqlite"
+)
+
+func TestSplitStatements(t *testing.T) {
+    tests := []struct {
+        name   string
+        script string
+        want   []string
+    }{
+        {
+            name:   "one statement per line",
+            script: "CREATE TABLE a (id INTEGER);\nCREATE TABLE b (id INTEGER);\n",
+            want:   []string{"CREATE TABLE a (id INTEGER)", "CREATE TABLE b (id INTEGER)"},
+        },
+        {
+            name:   "several statements on a line",
+            script: "DROP TABLE a; DROP TABLE b;",
+            want:   []string{"DROP TABLE a", "DROP TABLE b"},
+        },
+        {
+            name:   "a statement over several lines",
+            script: "CREATE TABLE a (\n    id INTEGER\n);",
+            want:   []string{"CREATE TABLE a (\n    id INTEGER\n)"},
+        },
+        {
+            name:   "no final semicolon",
+            script: "DROP TABLE a;\nDROP TABLE b\n",
+            want:   []string{"DROP TABLE a", "DROP TABLE b"},
+        },
+        {
+            name:   "semicolons in strings",
+            script: "INSERT INTO a (s) VALUES ('x;y');\nINSERT INTO a (s) VALUES ('it''s; fine');",
+            want:   []string{"INSERT INTO a (s) VALUES ('x;y')", "INSERT INTO a (s) VALUES ('it''s; fine')"},
+        },
+        {
+            name:   "semicolons in quoted names",
+            script: "CREATE TABLE \"a;b\" (`c;d` INTEGER);",
+            want:   []string{"CREATE TABLE \"a;b\" (`c;d` INTEGER)"},
+        },
+        {
+            name:   "comments are dropped",
+            script: "-- users;\nCREATE TABLE a (id INTEGER); -- trailing;\n/* block; comment */ DROP TABLE b;\n-- the end",
+            want:   []string{"CREATE TABLE a (id INTEGER)", "DROP TABLE b"},
+        },
+        {
+            name:   "comment markers in strings",
+            script: "INSERT INTO a (s) VALUES ('--not a comment; /*nor this*/');",
+            want:   []string{"INSERT INTO a (s) VALUES ('--not a comment; /*nor this*/')"},
+        },
+        {
+            name:   "only comments",
+            script: "-- nothing to do;\n/* ; */\n",
+        },
+        {
+            name: "trigger body",
+            script: "CREATE TRIGGER touch AFTER UPDATE ON a\nBEGIN\n    UPDATE a SET n = n + 1;\n    " +
+                "UPDATE b SET m = CASE WHEN m > 0 THEN 1 ELSE 0 END;\nEND;\nDROP TABLE c;",
+            want: []string{
+                "CREATE TRIGGER touch AFTER UPDATE ON a\nBEGIN\n    UPDATE a SET n = n + 1;\n    " +
+                    "UPDATE b SET m = CASE WHEN m > 0 THEN 1 ELSE 0 END;\nEND",
+                "DROP TABLE c",
+            },
+        },
+        {
+            name: "MySQL trigger with IF and CASE statements",
+            script: "CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN\n" +
+                "IF NEW.n < 0 THEN SET NEW.n = 0; END IF;\nCASE NEW.k WHEN 1 THEN SET NEW.m = 1; ELSE SET NEW.m = 2; END CASE;\nEND;\n" +
+                "SELECT 1;",
+            want: []string{
+                "CREATE TRIGGER t BEFORE INSERT ON a FOR EACH ROW BEGIN\n" +
+                    "IF NEW.n < 0 THEN SET NEW.n = 0; END IF;\nCASE NEW.k WHEN 1 THEN SET NEW.m = 1; ELSE SET NEW.m = 2; END CASE;\nEND",
+                "SELECT 1",
+            },
+        },
+        {
+            name:   "BEGIN outside a trigger",
+            script: "BEGIN;\nUPDATE a SET n = 1;\nCOMMIT;",
+            want:   []string{"BEGIN", "UPDATE a SET n = 1", "COMMIT"},
+        },
+        {
+            name: "dollar-quoted function body",
+            script: "CREATE FUNCTION f() RETURNS trigger AS $body$\nBEGIN\n    NEW.n := 1;\n    RETURN NEW;\nEND;\n$body$ LANGUAGE plpgsql;\n" +
+                "SELECT $$a;b$$, $1;",
+            want: []string{
+                "CREATE FUNCTION f() RETURNS trigger AS $body$\nBEGIN\n    NEW.n := 1;\n    RETURN NEW;\nEND;\n$body$ LANGUAGE plpgsql",
+                "SELECT $$a;b$$, $1",
+            },
+        },
+    }
+
+    for _, test := range tests {
+        if got := splitStatements(test.script); !reflect.DeepEqual(got, test.want) {
+            t.Errorf("%s: splitStatements = %q, want %q", test.name, got, test.want)
+        }
+    }
+}
+
+func newSQLiteDB(t *testing.T) *sql.DB {
+    t.Helper()
+
+    db, err := sqlite.NewConnection(":memory:")
+    if err != nil {
+        t.Fatalf("opening sqlite: %v", err)
+    }
+    t.Cleanup(func() { db.Close() })
+    return db
+}
+
+// schema returns the SQL of every table, index and trigger in db other than
+// the migrator's own and SQLite's, by name.
+func schema(t *testing.T, db *sql.DB) map[string]string {
+    t.Helper()
+
+    rows, err := db.Query(`
+        SELECT name, sql FROM sqlite_master
+        WHERE sql IS NOT NULL AND name NOT LIKE 'schema_migrations%' AND name NOT LIKE 'sqlite_%'
+    `)
+    if err != nil {
+        t.Fatalf("reading the schema: %v", err)
+    }
+    defer rows.Close()
+
+    objects := make(map[string]string)
+    for rows.Next() {
+        var name, definition string
+        if err := rows.Scan(&name, &definition); err != nil {
+            t.Fatalf("reading the schema: %v", err)
+        }
+        objects[name] = definition
+    }
+    if err := rows.Err(); err != nil {
+        t.Fatalf("reading the schema: %v", err)
+    }
+    return objects
+}
+
+func appliedVersions(t *testing.T, migrator *Migrator) []int64 {
+    t.Helper()
+
+    statuses, err := migrator.Status()
+    if err != nil {
+        t.Fatalf("Status: %v", err)
+    }
+    versions := []int64{}
+    for _, status := range statuses {
+        if status.Applied {
+            versions = append(versions, status.Version)
+        }
+    }
+    return versions
+}
+
+// TestSQLiteRoundTrip applies the SQLite migrations one at a time, then
+// rolls them back one at a time, checking that each down migration leaves
+// the schema exactly as it was before its up migration.
+func TestSQLiteRoundTrip(t *testing.T) {
+    files, err := migrations.For("sqlite")
+    if err != nil {
+        t.Fatal(err)
+    }
+    db := newSQLiteDB(t)
+    migrator, err := New(db, SQLite, files)
+    if err != nil {
+        t.Fatalf("New: %v", err)
+    }
+
+    before := map[int64]map[string]string{}
+    for _, migration := range migrator.migrations {
+        before[migration.Version] = schema(t, db)
+        if err := migrator.To(migration.Version); err != nil {
+            t.Fatalf("To(%d): %v", migration.Version, err)
+        }
+    }
+    latest := schema(t, db)
+    if got := appliedVersions(t, migrator); len(got) != len(migrator.migrations) {
+        t.Fatalf("applied %v after migrating up", got)
+    }
+
+    for i := len(migrator.migrations) - 1; i >= 0; i-- {
+        migration := migrator.migrations[i]
+        if err := migrator.Down(); err != nil {
+            t.Fatalf("Down from %d: %v", migration.Version, err)
+        }
+        if got := schema(t, db); !reflect.DeepEqual(got, before[migration.Version]) {
+            t.Errorf("rolling back %d_%s left\n%v\nwant\n%v", migration.Version, migration.Name, got, before[migration.Version])
+        }
+    }
+    if got := appliedVersions(t, migrator); len(got) != 0 {
+        t.Errorf("applied %v after rolling everything back", got)
+    }
+
+    // Up again in one go, then To down across several versions.
+    if err := migrator.Up(); err != nil {
+        t.Fatalf("Up: %v", err)
+    }
+    if got := schema(t, db); !reflect.DeepEqual(got, latest) {
+        t.Errorf("Up after a full rollback gives a different schema")
+    }
+    middle := migrator.migrations[len(migrator.migrations)/2].Version
+    if err := migrator.To(middle); err != nil {
+        t.Fatalf("To(%d): %v", middle, err)
+    }
+    if got := appliedVersions(t, migrator); got[len(got)-1] != middle || int64(len(got)) != middle {
+        t.Errorf("applied %v after To(%d)", got, middle)
+    }
+    if err := migrator.To(0); err != nil {
+        t.Fatalf("To(0): %v", err)
+    }
+    if got := schema(t, db); len(got) != 0 {
+        t.Errorf("To(0) left %v", got)
+    }
+}
+
+func TestMigratorRunsTriggers(t *testing.T) {
+    files := fstest.MapFS{
+        "0001_counts.up.sql": {Data: []byte(`
+            CREATE TABLE counts (n INTEGER NOT NULL); -- one row
+            INSERT INTO counts (n) VALUES (0);
+            CREATE TABLE items (name TEXT NOT NULL);
+            CREATE TRIGGER count_items AFTER INSERT ON items
+            BEGIN
+                UPDATE counts SET n = n + 1;
+                UPDATE counts SET n = CASE WHEN NEW.name = 'a;b' THEN n + 10 ELSE n END;
+            END;
+        `)},
+        "0001_counts.down.sql": {Data: []byte("DROP TRIGGER count_items;\nDROP TABLE items;\nDROP TABLE counts;\n")},
+    }
+    db := newSQLiteDB(t)
+    migrator, err := New(db, SQLite, files)
+    if err != nil {
+        t.Fatalf("New: %v", err)
+    }
+    if err := migrator.Up(); err != nil {
+        t.Fatalf("Up: %v", err)
+    }
+
+    if _, err := db.Exec(`INSERT INTO items (name) VALUES ('x'), ('a;b')`); err != nil {
+        t.Fatal(err)
+    }
+    var n int
+    if err := db.QueryRow(`SELECT n FROM counts`).Scan(&n); err != nil || n != 12 {
+        t.Errorf("count = %d (%v), want 12 from both statements of the trigger", n, err)
+    }
+
+    if err := migrator.Down(); err != nil {
+        t.Fatalf("Down: %v", err)
+    }
+    if got := schema(t, db); len(got) != 0 {
+        t.Errorf("Down left %v", got)
+    }
+}
+
+func TestSQLiteLockTakesOverStaleLock(t *testing.T) {
+    db := newSQLiteDB(t)
+    ctx := context.Background()
+    conn, err := db.Conn(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer conn.Close()
+
+    // A lock row left behind by a crashed process.
+    if err := SQLite.Lock(ctx, conn); err != nil {
+        t.Fatalf("Lock: %v", err)
+    }
+    if _, err := conn.ExecContext(ctx, `UPDATE schema_migrations_lock SET locked_at = ?`,
+        time.Now().UTC().Add(-time.Hour)); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := NewSQLite(time.Minute).Lock(ctx, conn); err != nil {
+        t.Fatalf("Lock over a stale lock: %v", err)
+    }
+
+    var lockedAt time.Time
+    if err := conn.QueryRowContext(ctx, `SELECT locked_at FROM schema_migrations_lock`).Scan(&lockedAt); err != nil {
+        t.Fatal(err)
+    }
+    if time.Since(lockedAt) > time.Minute {
+        t.Errorf("lock row still dated %v after the take over", lockedAt)
+    }
+    if got := SQLite.(sqliteDialect).staleLock; got != DefaultSQLiteStaleLock {
+        t.Errorf("SQLite takes over locks after %v, want %v", got, DefaultSQLiteStaleLock)
+    }
+}
@@ -0,0 +1,48 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// lockTimeoutSeconds bounds how long GET_LOCK waits for another instance to
+// finish migrating.
+const lockTimeoutSeconds = 60
+
+type mysqlDialect struct{}
+
+// MySQL serialises migrations with GET_LOCK, which is held by the session.
+var MySQL Dialect = mysqlDialect{}
+
+func (mysqlDialect) CreateVersionTable() string {
+    return `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version BIGINT NOT NULL,
+            name VARCHAR(255) NOT NULL,
+            applied_at DATETIME(6) NOT NULL,
+            PRIMARY KEY (version)
+        ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+    `
+}
+
+func (mysqlDialect) Placeholder(int) string {
+    return "?"
+}
+
+func (mysqlDialect) Lock(ctx context.Context, conn *sql.Conn) error {
+    var acquired sql.NullInt64
+    err := conn.QueryRowContext(ctx, `SELECT GET_LOCK(?, ?)`, lockName, lockTimeoutSeconds).Scan(&acquired)
+    if err != nil {
+        return err
+    }
+    if !acquired.Valid || acquired.Int64 != 1 {
+        return fmt.Errorf("timed out after %ds waiting for another migration", lockTimeoutSeconds)
+    }
+    return nil
+}
+
+func (mysqlDialect) Unlock(ctx context.Context, conn *sql.Conn) error {
+    _, err := conn.ExecContext(ctx, `SELECT RELEASE_LOCK(?)`, lockName)
+    return err
+}
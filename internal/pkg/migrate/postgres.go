@@ -0,0 +1,55 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// postgresLockTimeout bounds how long Lock waits for another instance.
+const postgresLockTimeout = 60 * time.Second
+
+type postgresDialect struct{}
+
+// Postgres serialises migrations with a session-level advisory lock.
+var Postgres Dialect = postgresDialect{}
+
+func (postgresDialect) CreateVersionTable() string {
+    return `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version BIGINT PRIMARY KEY,
+            name VARCHAR(255) NOT NULL,
+            applied_at TIMESTAMPTZ NOT NULL
+        )
+    `
+}
+
+func (postgresDialect) Placeholder(n int) string {
+    return "$" + strconv.Itoa(n)
+}
+
+func (postgresDialect) Lock(ctx context.Context, conn *sql.Conn) error {
+    deadline := time.Now().Add(postgresLockTimeout)
+    for {
+        var acquired bool
+        err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtext($1))`, lockName).Scan(&acquired)
+        if err != nil {
+            return err
+        }
+        if acquired {
+            return nil
+        }
+
+        if time.Now().After(deadline) {
+            return fmt.Errorf("timed out after %s waiting for another migration", postgresLockTimeout)
+        }
+        time.Sleep(500 * time.Millisecond)
+    }
+}
+
+func (postgresDialect) Unlock(ctx context.Context, conn *sql.Conn) error {
+    _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock(hashtext($1))`, lockName)
+    return err
+}
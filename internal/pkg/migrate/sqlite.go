@@ -0,0 +1,95 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const (
+    // sqliteLockTimeout bounds how long Lock waits for another process.
+    sqliteLockTimeout = 60 * time.Second
+    // DefaultSQLiteStaleLock is the age after which SQLite takes over a
+    // lock row, unless NewSQLite is given another.
+    DefaultSQLiteStaleLock = 10 * time.Minute
+)
+
+type sqliteDialect struct {
+    staleLock time.Duration
+}
+
+// SQLite has no advisory locks, so the lock is a single row in
+// schema_migrations_lock that only one process can insert.
+var SQLite = NewSQLite(DefaultSQLiteStaleLock)
+
+// NewSQLite returns the SQLite dialect taking over a lock row older than
+// staleLock, as left behind by a process that crashed while migrating. The
+// row is not refreshed while it is held, so a run of migrations that takes
+// longer than staleLock can have its lock taken by a second process; pick a
+// staleLock well above the longest run. Each migration still holds SQLite's
+// write lock while it runs, so the two never write at the same time, but
+// one of them fails on a migration the other has applied meanwhile.
+func NewSQLite(staleLock time.Duration) Dialect {
+    return sqliteDialect{staleLock: staleLock}
+}
+
+func (sqliteDialect) CreateVersionTable() string {
+    return `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name TEXT NOT NULL,
+            applied_at DATETIME NOT NULL
+        )
+    `
+}
+
+func (sqliteDialect) Placeholder(int) string {
+    return "?"
+}
+
+func (d sqliteDialect) Lock(ctx context.Context, conn *sql.Conn) error {
+    _, err := conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations_lock (
+            id INTEGER PRIMARY KEY CHECK (id = 1),
+            locked_at DATETIME NOT NULL
+        )
+    `)
+    if err != nil {
+        return err
+    }
+
+    deadline := time.Now().Add(sqliteLockTimeout)
+    for {
+        now := time.Now().UTC()
+        _, err := conn.ExecContext(ctx,
+            `DELETE FROM schema_migrations_lock WHERE locked_at < ?`, now.Add(-d.staleLock))
+        if err != nil {
+            return err
+        }
+
+        result, err := conn.ExecContext(ctx,
+            `INSERT OR IGNORE INTO schema_migrations_lock (id, locked_at) VALUES (1, ?)`, now)
+        if err != nil {
+            return err
+        }
+
+        acquired, err := result.RowsAffected()
+        if err != nil {
+            return err
+        }
+        if acquired == 1 {
+            return nil
+        }
+
+        if time.Now().After(deadline) {
+            return fmt.Errorf("timed out after %s waiting for another migration", sqliteLockTimeout)
+        }
+        time.Sleep(500 * time.Millisecond)
+    }
+}
+
+func (sqliteDialect) Unlock(ctx context.Context, conn *sql.Conn) error {
+    _, err := conn.ExecContext(ctx, `DELETE FROM schema_migrations_lock`)
+    return err
+}
@@ -14,7 +14,7 @@ func NewConnection(host, port, user, password, dbname string) (*sql.DB, error) {
     
     db, err := sql.Open("mysql", dsn)
     if err != nil {
-        return nil, fmt.Errorf("error opening database: %v", err)
+        return nil, fmt.Errorf("error opening database: %w", err)
     }
 
     db.SetMaxIdleConns(10)
@@ -23,7 +23,7 @@ func NewConnection(host, port, user, password, dbname string) (*sql.DB, error) {
 
     err = db.Ping()
     if err != nil {
-        return nil, fmt.Errorf("error connecting to the database: %v", err)
+        return nil, fmt.Errorf("error connecting to the database: %w", err)
     }
 
     return db, nil
@@ -0,0 +1,26 @@
+// Package notifier delivers task reminders.
+package notifier
+
+import (
+	"context"
+	"log"
+	"todo-app/internal/domain"
+)
+
+// LogNotifier writes reminders to the standard logger. It is the default
+// when no delivery channel is configured.
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+    return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+    log.Printf("reminder %d: task %d %q for user %d is due at %v",
+        notification.Reminder.ID,
+        notification.Reminder.TaskID,
+        notification.TaskTitle,
+        notification.Reminder.UserID,
+        notification.TaskDueAt)
+    return nil
+}
@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const defaultWebhookTimeout = 10 * time.Second
+
+// WebhookNotifier POSTs each reminder as JSON to a URL. When a secret is
+// set, the body is signed with HMAC-SHA256 in the X-Signature header as
+// "sha256=<hex>", so the receiver can check where it came from.
+//
+// Delivery is at least once: a reminder whose outcome could not be recorded,
+// because the webhook timed out after the receiver had acted on it or the
+// instance stopped, is sent again. Every attempt carries the same
+// idempotency_key, which receivers should use to drop repeats.
+type WebhookNotifier struct {
+    url    string
+    secret []byte
+    client *http.Client
+}
+
+// webhookPayload is the body sent for a reminder.
+type webhookPayload struct {
+    IdempotencyKey string     `json:"idempotency_key"`
+    ReminderID     int64      `json:"reminder_id"`
+    TaskID         int64      `json:"task_id"`
+    UserID         int64      `json:"user_id"`
+    RemindAt       time.Time  `json:"remind_at"`
+    TaskTitle      string     `json:"task_title"`
+    TaskDueAt      *time.Time `json:"task_due_at"`
+}
+
+// NewWebhookNotifier returns a notifier for url. A nil client uses one with
+// a 10 second timeout.
+func NewWebhookNotifier(url, secret string, client *http.Client) *WebhookNotifier {
+    if client == nil {
+        client = &http.Client{Timeout: defaultWebhookTimeout}
+    }
+    return &WebhookNotifier{
+        url:    url,
+        secret: []byte(secret),
+        client: client,
+    }
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+    body, err := json.Marshal(webhookPayload{
+        IdempotencyKey: idempotencyKey(notification.Reminder),
+        ReminderID:     notification.Reminder.ID,
+        TaskID:         notification.Reminder.TaskID,
+        UserID:         notification.Reminder.UserID,
+        RemindAt:       notification.Reminder.RemindAt,
+        TaskTitle:      notification.TaskTitle,
+        TaskDueAt:      notification.TaskDueAt,
+    })
+    if err != nil {
+        return fmt.Errorf("error encoding webhook payload: %w", err)
+    }
+
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+    if err != nil {
+        return fmt.Errorf("error building webhook request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    if len(n.secret) > 0 {
+        req.Header.Set("X-Signature", "sha256="+n.sign(body))
+    }
+
+    resp, err := n.client.Do(req)
+    if err != nil {
+        return fmt.Errorf("error calling webhook: %w", err)
+    }
+    defer resp.Body.Close()
+    // Drain the body so the connection can be reused.
+    io.Copy(io.Discard, resp.Body)
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+    }
+
+    return nil
+}
+
+// idempotencyKey identifies a reminder and the time it is due, the same on
+// every attempt to deliver it.
+func idempotencyKey(reminder domain.Reminder) string {
+    return fmt.Sprintf("reminder-%d-%d", reminder.ID, reminder.RemindAt.Unix())
+}
+
+func (n *WebhookNotifier) sign(body []byte) string {
+    mac := hmac.New(sha256.New, n.secret)
+    mac.Write(body)
+    return hex.EncodeToString(mac.Sum(nil))
+}
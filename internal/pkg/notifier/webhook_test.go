@@ -0,0 +1,178 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func testNotification() domain.Notification {
+    dueAt := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+    return domain.Notification{
+        Reminder: domain.Reminder{
+            ID:       7,
+            TaskID:   3,
+            UserID:   2,
+            RemindAt: time.Date(2026, 10, 18, 11, 45, 0, 0, time.UTC),
+        },
+        TaskTitle: "Water the plants",
+        TaskDueAt: &dueAt,
+    }
+}
+
+func TestWebhookNotifierSendsSignedPayload(t *testing.T) {
+    var (
+        method      string
+        contentType string
+        signature   string
+        body        []byte
+    )
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        method = r.Method
+        contentType = r.Header.Get("Content-Type")
+        signature = r.Header.Get("X-Signature")
+        body, _ = io.ReadAll(r.Body)
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    defer server.Close()
+
+    notifier := NewWebhookNotifier(server.URL, "secret", nil)
+    if err := notifier.Notify(context.Background(), testNotification()); err != nil {
+        t.Fatalf("Notify: %v", err)
+    }
+
+    if method != http.MethodPost {
+        t.Errorf("method = %q, want POST", method)
+    }
+    if contentType != "application/json" {
+        t.Errorf("Content-Type = %q, want application/json", contentType)
+    }
+
+    mac := hmac.New(sha256.New, []byte("secret"))
+    mac.Write(body)
+    if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); signature != want {
+        t.Errorf("X-Signature = %q, want %q", signature, want)
+    }
+
+    var payload map[string]interface{}
+    if err := json.Unmarshal(body, &payload); err != nil {
+        t.Fatalf("decoding payload: %v", err)
+    }
+    want := map[string]interface{}{
+        "idempotency_key": "reminder-7-1792323900",
+        "reminder_id":     7.0,
+        "task_id":         3.0,
+        "user_id":         2.0,
+        "remind_at":       "2026-10-18T11:45:00Z",
+        "task_title":      "Water the plants",
+        "task_due_at":     "2026-10-18T12:00:00Z",
+    }
+    if len(payload) != len(want) {
+        t.Errorf("payload = %v, want %v", payload, want)
+    }
+    for key, value := range want {
+        if payload[key] != value {
+            t.Errorf("payload[%q] = %v, want %v", key, payload[key], value)
+        }
+    }
+}
+
+func TestWebhookNotifierWithoutSecretSendsNoSignature(t *testing.T) {
+    signed := true
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        _, signed = r.Header["X-Signature"]
+    }))
+    defer server.Close()
+
+    if err := NewWebhookNotifier(server.URL, "", nil).Notify(context.Background(), testNotification()); err != nil {
+        t.Fatalf("Notify: %v", err)
+    }
+    if signed {
+        t.Error("X-Signature set without a secret")
+    }
+}
+
+func TestWebhookNotifierFailsOnNon2xx(t *testing.T) {
+    for _, status := range []int{http.StatusMovedPermanently, http.StatusBadRequest, http.StatusInternalServerError} {
+        server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            w.WriteHeader(status)
+            io.WriteString(w, "nope")
+        }))
+
+        err := NewWebhookNotifier(server.URL, "", nil).Notify(context.Background(), testNotification())
+        server.Close()
+
+        if err == nil {
+            t.Errorf("status %d: Notify succeeded", status)
+            continue
+        }
+        if want := fmt.Sprintf("status %d", status); !strings.Contains(err.Error(), want) {
+            t.Errorf("error %q does not mention %q", err, want)
+        }
+    }
+}
+
+func TestWebhookNotifierTimesOut(t *testing.T) {
+    release := make(chan struct{})
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        select {
+        case <-release:
+        case <-r.Context().Done():
+        }
+    }))
+    defer server.Close()
+    defer close(release)
+
+    client := &http.Client{Timeout: 50 * time.Millisecond}
+    start := time.Now()
+    err := NewWebhookNotifier(server.URL, "", client).Notify(context.Background(), testNotification())
+    if err == nil {
+        t.Fatal("Notify succeeded against a server that never answers")
+    }
+    if elapsed := time.Since(start); elapsed > 5*time.Second {
+        t.Errorf("Notify took %v to time out", elapsed)
+    }
+}
+
+func TestNewWebhookNotifierDefaultsTimeout(t *testing.T) {
+    notifier := NewWebhookNotifier("http://example.invalid", "", nil)
+    if notifier.client.Timeout != defaultWebhookTimeout {
+        t.Errorf("client timeout = %v, want %v", notifier.client.Timeout, defaultWebhookTimeout)
+    }
+}
+
+func TestWebhookNotifierRepeatsIdempotencyKey(t *testing.T) {
+    var keys []interface{}
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var payload map[string]interface{}
+        json.NewDecoder(r.Body).Decode(&payload)
+        keys = append(keys, payload["idempotency_key"])
+    }))
+    defer server.Close()
+
+    // A retry carries the key of the first attempt, whatever else changed.
+    notifier := NewWebhookNotifier(server.URL, "", nil)
+    first := testNotification()
+    retry := testNotification()
+    retry.Reminder.Attempts = 2
+    retry.TaskTitle = "Water the garden"
+    for _, notification := range []domain.Notification{first, retry} {
+        if err := notifier.Notify(context.Background(), notification); err != nil {
+            t.Fatalf("Notify: %v", err)
+        }
+    }
+
+    if len(keys) != 2 || keys[0] == nil || keys[0] != keys[1] {
+        t.Errorf("idempotency keys = %v, want the same key twice", keys)
+    }
+}
@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func NewConnection(host, port, user, password, dbname, sslmode string) (*sql.DB, error) {
+    dsn := url.URL{
+        Scheme:   "postgres",
+        User:     url.UserPassword(user, password),
+        Host:     net.JoinHostPort(host, port),
+        Path:     dbname,
+        RawQuery: url.Values{"sslmode": {sslmode}}.Encode(),
+    }
+
+    db, err := sql.Open("pgx", dsn.String())
+    if err != nil {
+        return nil, fmt.Errorf("error opening database: %w", err)
+    }
+
+    db.SetMaxIdleConns(10)
+    db.SetMaxOpenConns(100)
+    db.SetConnMaxLifetime(time.Hour)
+
+    err = db.Ping()
+    if err != nil {
+        return nil, fmt.Errorf("error connecting to the database: %w", err)
+    }
+
+    return db, nil
+}
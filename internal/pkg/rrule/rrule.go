@@ -0,0 +1,256 @@
+// Package rrule implements the subset of RFC 5545 recurrence rules used for
+// recurring tasks: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY
+// with weekly rules, and COUNT or UNTIL.
+package rrule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+    Daily   Frequency = "DAILY"
+    Weekly  Frequency = "WEEKLY"
+    Monthly Frequency = "MONTHLY"
+    Yearly  Frequency = "YEARLY"
+)
+
+// maxSkips bounds the search for a month or year that has the start's day,
+// e.g. the next February 29th.
+const maxSkips = 1000
+
+var weekdays = map[string]time.Weekday{
+    "MO": time.Monday,
+    "TU": time.Tuesday,
+    "WE": time.Wednesday,
+    "TH": time.Thursday,
+    "FR": time.Friday,
+    "SA": time.Saturday,
+    "SU": time.Sunday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Rule is a parsed recurrence rule. Count, when set, is the number of
+// occurrences left in the series including the current one.
+type Rule struct {
+    Freq     Frequency
+    Interval int
+    ByDay    []time.Weekday
+    Count    int
+    Until    *time.Time
+    // untilDate marks an UNTIL given as a date, which covers that whole day
+    // wherever the occurrences fall.
+    untilDate bool
+}
+
+// Parse reads a rule such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH;COUNT=10".
+// An optional "RRULE:" prefix is accepted.
+func Parse(s string) (*Rule, error) {
+    s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+    if s == "" {
+        return nil, fmt.Errorf("empty rule")
+    }
+
+    rule := &Rule{Interval: 1}
+    seen := make(map[string]bool)
+    for _, part := range strings.Split(s, ";") {
+        name, value, ok := strings.Cut(part, "=")
+        if !ok || value == "" {
+            return nil, fmt.Errorf("malformed part %q", part)
+        }
+        name = strings.ToUpper(name)
+        if seen[name] {
+            return nil, fmt.Errorf("%s given twice", name)
+        }
+        seen[name] = true
+
+        var err error
+        switch name {
+        case "FREQ":
+            rule.Freq = Frequency(strings.ToUpper(value))
+            switch rule.Freq {
+            case Daily, Weekly, Monthly, Yearly:
+            default:
+                return nil, fmt.Errorf("unsupported FREQ %q", value)
+            }
+        case "INTERVAL":
+            if rule.Interval, err = strconv.Atoi(value); err != nil || rule.Interval < 1 {
+                return nil, fmt.Errorf("INTERVAL must be a positive integer")
+            }
+        case "COUNT":
+            if rule.Count, err = strconv.Atoi(value); err != nil || rule.Count < 1 {
+                return nil, fmt.Errorf("COUNT must be a positive integer")
+            }
+        case "UNTIL":
+            if err = rule.parseUntil(value); err != nil {
+                return nil, err
+            }
+        case "BYDAY":
+            for _, day := range strings.Split(strings.ToUpper(value), ",") {
+                weekday, ok := weekdays[day]
+                if !ok {
+                    return nil, fmt.Errorf("unsupported BYDAY value %q", day)
+                }
+                rule.ByDay = append(rule.ByDay, weekday)
+            }
+        default:
+            return nil, fmt.Errorf("unsupported part %s", name)
+        }
+    }
+
+    if rule.Freq == "" {
+        return nil, fmt.Errorf("FREQ is required")
+    }
+    if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+        return nil, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+    }
+    if rule.Count > 0 && rule.Until != nil {
+        return nil, fmt.Errorf("COUNT and UNTIL cannot be combined")
+    }
+
+    return rule, nil
+}
+
+func (r *Rule) parseUntil(value string) error {
+    if t, err := time.Parse("20060102T150405Z", value); err == nil {
+        r.Until = &t
+        return nil
+    }
+    if t, err := time.Parse("20060102", value); err == nil {
+        r.Until = &t
+        r.untilDate = true
+        return nil
+    }
+    return fmt.Errorf("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+}
+
+// String formats the rule in a canonical form that Parse reads back.
+func (r *Rule) String() string {
+    parts := []string{"FREQ=" + string(r.Freq)}
+    if r.Interval > 1 {
+        parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+    }
+    if len(r.ByDay) > 0 {
+        days := make([]string, len(r.ByDay))
+        for i, day := range r.ByDay {
+            days[i] = weekdayNames[day]
+        }
+        parts = append(parts, "BYDAY="+strings.Join(days, ","))
+    }
+    if r.Count > 0 {
+        parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+    }
+    if r.Until != nil {
+        if r.untilDate {
+            parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+        } else {
+            parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+        }
+    }
+    return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence following current, which is an occurrence of
+// the rule, along with the rule for the rest of the series. Wall-clock time
+// is kept in current's location across daylight saving changes. ok is false
+// once the series is over.
+func (r *Rule) Next(current time.Time) (next time.Time, rest *Rule, ok bool) {
+    if r.Count == 1 {
+        return time.Time{}, nil, false
+    }
+
+    next, ok = r.after(current)
+    if !ok || r.pastUntil(next) {
+        return time.Time{}, nil, false
+    }
+
+    rest = r.clone()
+    if rest.Count > 0 {
+        rest.Count--
+    }
+    return next, rest, true
+}
+
+func (r *Rule) after(t time.Time) (time.Time, bool) {
+    switch r.Freq {
+    case Daily:
+        return t.AddDate(0, 0, r.Interval), true
+    case Weekly:
+        return r.nextWeekly(t), true
+    case Monthly:
+        return r.nextWithDay(t, func(k int) (int, time.Month) {
+            return t.Year(), t.Month() + time.Month(k*r.Interval)
+        })
+    default:
+        return r.nextWithDay(t, func(k int) (int, time.Month) {
+            return t.Year() + k*r.Interval, t.Month()
+        })
+    }
+}
+
+// nextWeekly finds the next listed weekday later in t's week, or else the
+// first listed weekday of the week Interval weeks on. Weeks start on Monday.
+func (r *Rule) nextWeekly(t time.Time) time.Time {
+    if len(r.ByDay) == 0 {
+        return t.AddDate(0, 0, 7*r.Interval)
+    }
+
+    offset := mondayOffset(t.Weekday())
+    best := -1
+    first := 7
+    for _, day := range r.ByDay {
+        dayOffset := mondayOffset(day)
+        if dayOffset > offset && (best == -1 || dayOffset < best) {
+            best = dayOffset
+        }
+        if dayOffset < first {
+            first = dayOffset
+        }
+    }
+
+    if best != -1 {
+        return t.AddDate(0, 0, best-offset)
+    }
+    return t.AddDate(0, 0, 7*r.Interval-offset+first)
+}
+
+// nextWithDay steps months or years until one has t's day of the month, as
+// RFC 5545 skips e.g. months without a 31st rather than clamping.
+func (r *Rule) nextWithDay(t time.Time, step func(k int) (int, time.Month)) (time.Time, bool) {
+    hour, min, sec := t.Clock()
+    for k := 1; k <= maxSkips; k++ {
+        year, month := step(k)
+        candidate := time.Date(year, month, t.Day(), hour, min, sec, t.Nanosecond(), t.Location())
+        if candidate.Day() == t.Day() {
+            return candidate, true
+        }
+    }
+    return time.Time{}, false
+}
+
+func (r *Rule) pastUntil(t time.Time) bool {
+    if r.Until == nil {
+        return false
+    }
+    if r.untilDate {
+        year, month, day := r.Until.Date()
+        end := time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+        return !t.Before(end)
+    }
+    return t.After(*r.Until)
+}
+
+func (r *Rule) clone() *Rule {
+    c := *r
+    c.ByDay = append([]time.Weekday(nil), r.ByDay...)
+    return &c
+}
+
+func mondayOffset(day time.Weekday) int {
+    return (int(day) + 6) % 7
+}
@@ -0,0 +1,301 @@
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+    t.Helper()
+
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        t.Fatalf("LoadLocation(%q): %v", name, err)
+    }
+    return loc
+}
+
+// occurrences returns the occurrences of rule following start, at most n.
+func occurrences(t *testing.T, rule string, start time.Time, n int) []time.Time {
+    t.Helper()
+
+    r, err := Parse(rule)
+    if err != nil {
+        t.Fatalf("Parse(%q): %v", rule, err)
+    }
+
+    var got []time.Time
+    current := start
+    for len(got) < n {
+        next, rest, ok := r.Next(current)
+        if !ok {
+            break
+        }
+        got = append(got, next)
+        current, r = next, rest
+    }
+    return got
+}
+
+func TestNext(t *testing.T) {
+    utc := time.UTC
+    berlin := mustLoadLocation(t, "Europe/Berlin")
+    newYork := mustLoadLocation(t, "America/New_York")
+    date := func(loc *time.Location, year int, month time.Month, day, hour int) time.Time {
+        return time.Date(year, month, day, hour, 0, 0, 0, loc)
+    }
+
+    tests := []struct {
+        name  string
+        rule  string
+        start time.Time
+        want  []time.Time
+    }{
+        {
+            name:  "daily",
+            rule:  "FREQ=DAILY;INTERVAL=3",
+            start: date(utc, 2026, time.January, 30, 9),
+            want:  []time.Time{date(utc, 2026, time.February, 2, 9), date(utc, 2026, time.February, 5, 9)},
+        },
+        {
+            name:  "weekly without BYDAY",
+            rule:  "FREQ=WEEKLY",
+            start: date(utc, 2026, time.January, 7, 9), // Wednesday
+            want:  []time.Time{date(utc, 2026, time.January, 14, 9), date(utc, 2026, time.January, 21, 9)},
+        },
+        {
+            name:  "BYDAY within the week and into the next",
+            rule:  "FREQ=WEEKLY;BYDAY=FR,MO,WE",
+            start: date(utc, 2026, time.January, 5, 9), // Monday
+            want: []time.Time{
+                date(utc, 2026, time.January, 7, 9),
+                date(utc, 2026, time.January, 9, 9),
+                date(utc, 2026, time.January, 12, 9),
+                date(utc, 2026, time.January, 14, 9),
+            },
+        },
+        {
+            name:  "BYDAY every other week",
+            rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=TU,TH",
+            start: date(utc, 2026, time.January, 8, 9), // Thursday
+            want: []time.Time{
+                date(utc, 2026, time.January, 20, 9),
+                date(utc, 2026, time.January, 22, 9),
+                date(utc, 2026, time.February, 3, 9),
+            },
+        },
+        {
+            name:  "BYDAY with Sunday ending the week",
+            rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=SU,MO",
+            start: date(utc, 2026, time.January, 5, 9), // Monday
+            want: []time.Time{
+                date(utc, 2026, time.January, 11, 9),
+                date(utc, 2026, time.January, 19, 9),
+                date(utc, 2026, time.January, 25, 9),
+            },
+        },
+        {
+            name:  "monthly on the 31st skips shorter months",
+            rule:  "FREQ=MONTHLY",
+            start: date(utc, 2026, time.January, 31, 9),
+            want: []time.Time{
+                date(utc, 2026, time.March, 31, 9),
+                date(utc, 2026, time.May, 31, 9),
+                date(utc, 2026, time.July, 31, 9),
+                date(utc, 2026, time.August, 31, 9),
+            },
+        },
+        {
+            name:  "monthly on the 29th skips February of a common year",
+            rule:  "FREQ=MONTHLY",
+            start: date(utc, 2027, time.January, 29, 9),
+            want:  []time.Time{date(utc, 2027, time.March, 29, 9)},
+        },
+        {
+            name:  "monthly on the 29th keeps February of a leap year",
+            rule:  "FREQ=MONTHLY",
+            start: date(utc, 2028, time.January, 29, 9),
+            want:  []time.Time{date(utc, 2028, time.February, 29, 9)},
+        },
+        {
+            name:  "yearly on February 29th",
+            rule:  "FREQ=YEARLY",
+            start: date(utc, 2024, time.February, 29, 9),
+            want:  []time.Time{date(utc, 2028, time.February, 29, 9), date(utc, 2032, time.February, 29, 9)},
+        },
+        {
+            name:  "yearly on February 29th every third year",
+            rule:  "FREQ=YEARLY;INTERVAL=3",
+            start: date(utc, 2024, time.February, 29, 9),
+            want:  []time.Time{date(utc, 2036, time.February, 29, 9)},
+        },
+        {
+            name:  "COUNT counts the start",
+            rule:  "FREQ=DAILY;COUNT=3",
+            start: date(utc, 2026, time.January, 1, 9),
+            want:  []time.Time{date(utc, 2026, time.January, 2, 9), date(utc, 2026, time.January, 3, 9)},
+        },
+        {
+            name:  "COUNT of one has nothing after the start",
+            rule:  "FREQ=DAILY;COUNT=1",
+            start: date(utc, 2026, time.January, 1, 9),
+        },
+        {
+            name:  "UNTIL as a timestamp is inclusive",
+            rule:  "FREQ=DAILY;UNTIL=20260103T090000Z",
+            start: date(utc, 2026, time.January, 1, 9),
+            want:  []time.Time{date(utc, 2026, time.January, 2, 9), date(utc, 2026, time.January, 3, 9)},
+        },
+        {
+            name:  "UNTIL as a timestamp stops a second short",
+            rule:  "FREQ=DAILY;UNTIL=20260103T085959Z",
+            start: date(utc, 2026, time.January, 1, 9),
+            want:  []time.Time{date(utc, 2026, time.January, 2, 9)},
+        },
+        {
+            name:  "UNTIL as a timestamp is an instant",
+            rule:  "FREQ=DAILY;UNTIL=20260103T120000Z",
+            start: date(newYork, 2026, time.January, 1, 9), // 14:00 UTC
+            want:  []time.Time{date(newYork, 2026, time.January, 2, 9)},
+        },
+        {
+            name:  "UNTIL as a date covers the whole local day",
+            rule:  "FREQ=DAILY;UNTIL=20260103",
+            start: date(newYork, 2026, time.January, 1, 22), // 03:00 UTC the next day
+            want:  []time.Time{date(newYork, 2026, time.January, 2, 22), date(newYork, 2026, time.January, 3, 22)},
+        },
+        {
+            name:  "UNTIL before the next occurrence",
+            rule:  "FREQ=WEEKLY;UNTIL=20260107",
+            start: date(utc, 2026, time.January, 1, 9),
+        },
+        {
+            name:  "daily across the start of summer time keeps the wall clock",
+            rule:  "FREQ=DAILY",
+            start: date(berlin, 2026, time.March, 28, 9),
+            want:  []time.Time{date(berlin, 2026, time.March, 29, 9), date(berlin, 2026, time.March, 30, 9)},
+        },
+        {
+            name:  "weekly across the end of summer time keeps the wall clock",
+            rule:  "FREQ=WEEKLY;BYDAY=SA",
+            start: date(newYork, 2026, time.October, 31, 9),
+            want:  []time.Time{date(newYork, 2026, time.November, 7, 9)},
+        },
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            // Asking for one more shows that a limited series ends where
+            // it should; an open-ended one goes on, and only the first
+            // occurrences are checked.
+            got := occurrences(t, test.rule, test.start, len(test.want)+1)
+            if !limited(test.rule) && len(got) > len(test.want) {
+                got = got[:len(test.want)]
+            }
+            if len(got) != len(test.want) {
+                t.Fatalf("occurrences = %v, want %v", got, test.want)
+            }
+            for i := range got {
+                if !got[i].Equal(test.want[i]) || got[i].Location() != test.start.Location() {
+                    t.Errorf("occurrence %d = %v, want %v", i, got[i], test.want[i])
+                }
+            }
+        })
+    }
+}
+
+// limited reports whether rule has a COUNT or UNTIL, so its series ends.
+func limited(rule string) bool {
+    r, err := Parse(rule)
+    return err == nil && (r.Count > 0 || r.Until != nil)
+}
+
+func TestNextAcrossDSTKeepsLocalTime(t *testing.T) {
+    berlin := mustLoadLocation(t, "Europe/Berlin")
+    start := time.Date(2026, time.March, 28, 9, 0, 0, 0, berlin)
+
+    next := occurrences(t, "FREQ=DAILY", start, 1)[0]
+    if got := next.Sub(start); got != 23*time.Hour {
+        t.Errorf("a day across the change is %v, want 23h", got)
+    }
+    if next.UTC().Hour() != 7 || start.UTC().Hour() != 8 {
+        t.Errorf("UTC hours %d then %d, want 8 then 7", start.UTC().Hour(), next.UTC().Hour())
+    }
+}
+
+func TestCountDecrements(t *testing.T) {
+    r, err := Parse("FREQ=WEEKLY;COUNT=3")
+    if err != nil {
+        t.Fatal(err)
+    }
+    current := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+    for _, want := range []string{"FREQ=WEEKLY;COUNT=2", "FREQ=WEEKLY;COUNT=1"} {
+        next, rest, ok := r.Next(current)
+        if !ok {
+            t.Fatalf("series ended before %s", want)
+        }
+        if rest.String() != want {
+            t.Errorf("rest = %s, want %s", rest, want)
+        }
+        if r.Count == rest.Count {
+            t.Error("Next changed the rule it was called on")
+        }
+        current, r = next, rest
+    }
+
+    if _, _, ok := r.Next(current); ok {
+        t.Error("series went on past COUNT")
+    }
+}
+
+func TestParseAndString(t *testing.T) {
+    tests := map[string]string{
+        "FREQ=DAILY":                        "FREQ=DAILY",
+        "RRULE:freq=weekly;byday=mo,fr":     "FREQ=WEEKLY;BYDAY=MO,FR",
+        "FREQ=WEEKLY;INTERVAL=1":            "FREQ=WEEKLY",
+        "FREQ=MONTHLY;INTERVAL=2;COUNT=5":   "FREQ=MONTHLY;INTERVAL=2;COUNT=5",
+        "FREQ=YEARLY;UNTIL=20300101":        "FREQ=YEARLY;UNTIL=20300101",
+        "FREQ=DAILY;UNTIL=20300101T120000Z": "FREQ=DAILY;UNTIL=20300101T120000Z",
+        " FREQ=WEEKLY;BYDAY=SU;INTERVAL=3 ": "FREQ=WEEKLY;INTERVAL=3;BYDAY=SU",
+    }
+    for input, want := range tests {
+        r, err := Parse(input)
+        if err != nil {
+            t.Errorf("Parse(%q): %v", input, err)
+            continue
+        }
+        if got := r.String(); got != want {
+            t.Errorf("Parse(%q).String() = %q, want %q", input, got, want)
+        }
+        again, err := Parse(r.String())
+        if err != nil || again.String() != want {
+            t.Errorf("%q does not read back: %v", want, err)
+        }
+    }
+}
+
+func TestParseRejects(t *testing.T) {
+    for _, input := range []string{
+        "",
+        "RRULE:",
+        "INTERVAL=2",
+        "FREQ=HOURLY",
+        "FREQ=DAILY;INTERVAL=0",
+        "FREQ=DAILY;INTERVAL=x",
+        "FREQ=DAILY;COUNT=0",
+        "FREQ=DAILY;COUNT=2;UNTIL=20300101",
+        "FREQ=DAILY;UNTIL=2030-01-01",
+        "FREQ=DAILY;BYDAY=MO",
+        "FREQ=WEEKLY;BYDAY=XX",
+        "FREQ=WEEKLY;BYDAY=1MO",
+        "FREQ=DAILY;FREQ=WEEKLY",
+        "FREQ=DAILY;BYMONTH=1",
+        "FREQ=DAILY;COUNT",
+        "FREQ=DAILY;COUNT=",
+    } {
+        if r, err := Parse(input); err == nil {
+            t.Errorf("Parse(%q) = %s, want an error", input, r)
+        }
+    }
+}
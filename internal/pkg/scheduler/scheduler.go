@@ -0,0 +1,27 @@
+// Package scheduler runs background jobs at a fixed interval.
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Every runs job once per interval until ctx is done. A failing run is
+// logged and the job is tried again at the next tick. Runs never overlap.
+func Every(ctx context.Context, interval time.Duration, name string, job func(context.Context) error) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        if err := job(ctx); err != nil {
+            log.Printf("%s: %v", name, err)
+        }
+
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+        }
+    }
+}
@@ -6,37 +6,168 @@ import (
 	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+    saltSize = 16
+    hashSize = 32
 )
 
+// The most a stored hash may ask for. A hash is only ever as costly as the
+// parameters it carries, so these keep one corrupt or planted row from
+// tying up a gigabyte of memory or a minute of CPU per login attempt.
 const (
-    saltSize    = 16
-    hashSize    = 32
+    maxMemory      = 1024 * 1024 // KiB
+    maxIterations  = 64
+    maxParallelism = 64
+    maxSaltSize    = 64
+    maxHashSize    = 64
 )
 
+// argon2idID names the scheme in a PHC string:
+//
+//	$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>
+//
+// with the salt and hash in unpadded base64.
+const argon2idID = "argon2id"
+
+// Params is the cost of an argon2id hash: Memory in KiB, the number of
+// passes over it and the number of lanes hashed in parallel.
+type Params struct {
+    Memory      uint32
+    Iterations  uint32
+    Parallelism uint8
+}
+
+// DefaultParams is the second recommended option of RFC 9106.
+var DefaultParams = Params{Memory: 64 * 1024, Iterations: 3, Parallelism: 4}
+
+// params is the cost new hashes are made with.
+var params = DefaultParams
+
+// SetParams changes the cost new hashes are made with. Hashes made with a
+// different cost still verify, and NeedsRehash reports them.
+func SetParams(p Params) error {
+    if p.Iterations < 1 || p.Parallelism < 1 {
+        return fmt.Errorf("argon2 iterations and parallelism must be at least 1")
+    }
+    if p.Memory < 8*uint32(p.Parallelism) {
+        return fmt.Errorf("argon2 memory must be at least 8 KiB per lane")
+    }
+    if p.Memory > maxMemory || p.Iterations > maxIterations || p.Parallelism > maxParallelism {
+        return fmt.Errorf("argon2 memory, iterations and parallelism must be at most %d KiB, %d and %d",
+            maxMemory, maxIterations, maxParallelism)
+    }
+    params = p
+    return nil
+}
+
+// HashPassword hashes a password with argon2id and a random salt, in PHC
+// format.
 func HashPassword(password string) (string, error) {
     salt := make([]byte, saltSize)
     _, err := rand.Read(salt)
     if err != nil {
-        return "", fmt.Errorf("error generating salt: %v", err)
+        return "", fmt.Errorf("error generating salt: %w", err)
     }
 
-    hash := sha256.New()
-    hash.Write([]byte(password))
-    hash.Write(salt)
-    hashedPassword := hash.Sum(nil)
-
-    // Combine salt and hash
-    final := make([]byte, saltSize+hashSize)
-    copy(final[:saltSize], salt)
-    copy(final[saltSize:], hashedPassword)
+    p := params
+    hash := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, hashSize)
 
-    return base64.StdEncoding.EncodeToString(final), nil
+    return fmt.Sprintf("$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+        argon2idID,
+        argon2.Version,
+        p.Memory,
+        p.Iterations,
+        p.Parallelism,
+        base64.RawStdEncoding.EncodeToString(salt),
+        base64.RawStdEncoding.EncodeToString(hash),
+    ), nil
 }
 
+// VerifyPassword checks a password against an argon2id hash, or against a
+// legacy hash: the base64 of a salt followed by the SHA-256 of the password
+// and that salt.
 func VerifyPassword(hashedPassword, password string) (bool, error) {
+    if !strings.HasPrefix(hashedPassword, "$") {
+        return verifyLegacyPassword(hashedPassword, password)
+    }
+
+    p, salt, expectedHash, err := decodeHash(hashedPassword)
+    if err != nil {
+        return false, err
+    }
+
+    actualHash := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(expectedHash)))
+
+    return subtle.ConstantTimeCompare(expectedHash, actualHash) == 1, nil
+}
+
+// NeedsRehash reports whether a hash is not argon2id at the current cost,
+// so it should be replaced the next time the password is at hand.
+func NeedsRehash(hashedPassword string) bool {
+    p, salt, hash, err := decodeHash(hashedPassword)
+    if err != nil {
+        return true
+    }
+
+    return p != params || len(salt) != saltSize || len(hash) != hashSize
+}
+
+func decodeHash(hashedPassword string) (Params, []byte, []byte, error) {
+    var p Params
+
+    // The string starts with "$", so the first part is empty.
+    parts := strings.Split(hashedPassword, "$")
+    if len(parts) != 6 || parts[0] != "" {
+        return p, nil, nil, fmt.Errorf("invalid hash format")
+    }
+    if parts[1] != argon2idID {
+        return p, nil, nil, fmt.Errorf("unsupported hash scheme %q", parts[1])
+    }
+
+    var version int
+    if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+        return p, nil, nil, fmt.Errorf("invalid hash version: %w", err)
+    }
+    if version != argon2.Version {
+        return p, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+    }
+
+    if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+        return p, nil, nil, fmt.Errorf("invalid hash parameters: %w", err)
+    }
+    if p.Iterations < 1 || p.Parallelism < 1 ||
+        p.Memory > maxMemory || p.Iterations > maxIterations || p.Parallelism > maxParallelism {
+        return p, nil, nil, fmt.Errorf("invalid hash parameters")
+    }
+
+    salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+    if err != nil {
+        return p, nil, nil, fmt.Errorf("error decoding salt: %w", err)
+    }
+    if len(salt) > maxSaltSize {
+        return p, nil, nil, fmt.Errorf("invalid salt length")
+    }
+
+    hash, err := base64.RawStdEncoding.DecodeString(parts[5])
+    if err != nil {
+        return p, nil, nil, fmt.Errorf("error decoding hash: %w", err)
+    }
+    if len(hash) == 0 || len(hash) > maxHashSize {
+        return p, nil, nil, fmt.Errorf("invalid hash length")
+    }
+
+    return p, salt, hash, nil
+}
+
+func verifyLegacyPassword(hashedPassword, password string) (bool, error) {
     decoded, err := base64.StdEncoding.DecodeString(hashedPassword)
     if err != nil {
-        return false, fmt.Errorf("error decoding hash: %v", err)
+        return false, fmt.Errorf("error decoding hash: %w", err)
     }
 
     if len(decoded) != saltSize+hashSize {
@@ -52,4 +183,4 @@ func VerifyPassword(hashedPassword, password string) (bool, error) {
     actualHash := hash.Sum(nil)
 
     return subtle.ConstantTimeCompare(expectedHash, actualHash) == 1, nil
-}
\ No newline at end of file
+}
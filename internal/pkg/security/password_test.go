@@ -0,0 +1,147 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// cheapParams keeps the tests fast; the cost does not change the logic.
+var cheapParams = Params{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func useParams(t *testing.T, p Params) {
+    t.Helper()
+
+    previous := params
+    if err := SetParams(p); err != nil {
+        t.Fatalf("SetParams(%+v): %v", p, err)
+    }
+    t.Cleanup(func() { params = previous })
+}
+
+// legacyHash builds a hash the way passwords were stored before argon2id.
+func legacyHash(password string, salt []byte) string {
+    sum := sha256.Sum256(append([]byte(password), salt...))
+    return base64.StdEncoding.EncodeToString(append(append([]byte{}, salt...), sum[:]...))
+}
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+    useParams(t, cheapParams)
+
+    hash, err := HashPassword("correct horse")
+    if err != nil {
+        t.Fatalf("HashPassword: %v", err)
+    }
+    if want := "$argon2id$v=19$m=64,t=1,p=1$"; !strings.HasPrefix(hash, want) {
+        t.Errorf("hash %q does not start with %q", hash, want)
+    }
+
+    again, err := HashPassword("correct horse")
+    if err != nil {
+        t.Fatalf("HashPassword: %v", err)
+    }
+    if again == hash {
+        t.Error("two hashes of the same password are equal; the salt is not random")
+    }
+
+    for password, want := range map[string]bool{"correct horse": true, "correct horse ": false, "": false} {
+        valid, err := VerifyPassword(hash, password)
+        if err != nil {
+            t.Fatalf("VerifyPassword(%q): %v", password, err)
+        }
+        if valid != want {
+            t.Errorf("VerifyPassword(%q) = %v, want %v", password, valid, want)
+        }
+    }
+}
+
+func TestVerifyLegacyPassword(t *testing.T) {
+    hash := legacyHash("hunter2", []byte("0123456789abcdef"))
+
+    for password, want := range map[string]bool{"hunter2": true, "hunter3": false} {
+        valid, err := VerifyPassword(hash, password)
+        if err != nil {
+            t.Fatalf("VerifyPassword(%q): %v", password, err)
+        }
+        if valid != want {
+            t.Errorf("VerifyPassword(%q) = %v, want %v", password, valid, want)
+        }
+    }
+
+    short := base64.StdEncoding.EncodeToString([]byte("too short"))
+    if _, err := VerifyPassword(short, "hunter2"); err == nil {
+        t.Error("VerifyPassword accepted a legacy hash of the wrong length")
+    }
+}
+
+func TestNeedsRehash(t *testing.T) {
+    useParams(t, cheapParams)
+
+    current, err := HashPassword("pw")
+    if err != nil {
+        t.Fatalf("HashPassword: %v", err)
+    }
+    if NeedsRehash(current) {
+        t.Error("a hash at the current cost needs rehashing")
+    }
+
+    if !NeedsRehash(legacyHash("pw", []byte("0123456789abcdef"))) {
+        t.Error("a legacy hash does not need rehashing")
+    }
+
+    useParams(t, Params{Memory: 128, Iterations: 2, Parallelism: 1})
+    if !NeedsRehash(current) {
+        t.Error("a hash at an older cost does not need rehashing")
+    }
+    // It still verifies at its own cost.
+    if valid, err := VerifyPassword(current, "pw"); err != nil || !valid {
+        t.Errorf("VerifyPassword of a hash at an older cost = %v, %v", valid, err)
+    }
+}
+
+func TestVerifyPasswordRejectsMalformedHashes(t *testing.T) {
+    salt := base64.RawStdEncoding.EncodeToString([]byte("0123456789abcdef"))
+    hash := base64.RawStdEncoding.EncodeToString(make([]byte, hashSize))
+    phc := func(scheme, version, parameters string) string {
+        return fmt.Sprintf("$%s$%s$%s$%s$%s", scheme, version, parameters, salt, hash)
+    }
+
+    tests := map[string]string{
+        "scheme":          phc("argon2i", "v=19", "m=64,t=1,p=1"),
+        "version":         phc("argon2id", "v=16", "m=64,t=1,p=1"),
+        "no iterations":   phc("argon2id", "v=19", "m=64,t=0,p=1"),
+        "memory ceiling":  phc("argon2id", "v=19", fmt.Sprintf("m=%d,t=1,p=1", maxMemory+1)),
+        "iteration limit": phc("argon2id", "v=19", fmt.Sprintf("m=64,t=%d,p=1", maxIterations+1)),
+        "lane limit":      phc("argon2id", "v=19", fmt.Sprintf("m=1024,t=1,p=%d", maxParallelism+1)),
+        "parts":           "$argon2id$v=19$m=64,t=1,p=1$" + salt,
+        "hash length": fmt.Sprintf("$argon2id$v=19$m=64,t=1,p=1$%s$%s", salt,
+            base64.RawStdEncoding.EncodeToString(make([]byte, maxHashSize+1))),
+    }
+
+    for name, malformed := range tests {
+        if _, err := VerifyPassword(malformed, "pw"); err == nil {
+            t.Errorf("%s: VerifyPassword accepted %q", name, malformed)
+        }
+        if !NeedsRehash(malformed) {
+            t.Errorf("%s: NeedsRehash(%q) = false", name, malformed)
+        }
+    }
+}
+
+func TestSetParamsValidates(t *testing.T) {
+    for _, p := range []Params{
+        {Memory: 64, Iterations: 0, Parallelism: 1},
+        {Memory: 64, Iterations: 1, Parallelism: 0},
+        {Memory: 8, Iterations: 1, Parallelism: 2},
+        {Memory: maxMemory + 1, Iterations: 1, Parallelism: 1},
+        {Memory: 1024, Iterations: maxIterations + 1, Parallelism: 1},
+        {Memory: 1024, Iterations: 1, Parallelism: maxParallelism + 1},
+    } {
+        if err := SetParams(p); err == nil {
+            params = DefaultParams
+            t.Errorf("SetParams(%+v) succeeded", p)
+        }
+    }
+}
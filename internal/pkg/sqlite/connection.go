@@ -0,0 +1,41 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	_ "modernc.org/sqlite"
+)
+
+// NewConnection opens the SQLite database file at path, creating it if
+// needed. Use ":memory:" for a throwaway database.
+func NewConnection(path string) (*sql.DB, error) {
+    params := url.Values{}
+    params.Add("_pragma", "foreign_keys(1)")
+    params.Add("_pragma", "busy_timeout(5000)")
+    params.Add("_pragma", "journal_mode(WAL)")
+    // Take the write lock when a transaction begins, so a transaction that
+    // reads and then writes cannot fail half way because another process
+    // wrote in between.
+    params.Add("_txlock", "immediate")
+    // Store times in a format SQLite's own date functions understand.
+    params.Add("_time_format", "sqlite")
+    dsn := fmt.Sprintf("file:%s?%s", path, params.Encode())
+
+    db, err := sql.Open("sqlite", dsn)
+    if err != nil {
+        return nil, fmt.Errorf("error opening database: %w", err)
+    }
+
+    // SQLite allows a single writer at a time, and every connection to
+    // ":memory:" would see its own empty database, so share one connection.
+    db.SetMaxOpenConns(1)
+
+    err = db.Ping()
+    if err != nil {
+        return nil, fmt.Errorf("error connecting to the database: %w", err)
+    }
+
+    return db, nil
+}
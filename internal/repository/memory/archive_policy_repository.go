@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryArchivePolicyRepository struct {
+    store *Store
+}
+
+func NewMemoryArchivePolicyRepository(store *Store) domain.ArchivePolicyRepository {
+    return &memoryArchivePolicyRepository{store}
+}
+
+func (r *memoryArchivePolicyRepository) Get(userID int64) (*domain.ArchivePolicy, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    policy, ok := r.store.archivePolicies[userID]
+    if !ok {
+        return nil, nil
+    }
+
+    return &policy, nil
+}
+
+func (r *memoryArchivePolicyRepository) Save(policy *domain.ArchivePolicy) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    policy.UpdatedAt = time.Now()
+    r.store.archivePolicies[policy.UserID] = *policy
+    return nil
+}
+
+func (r *memoryArchivePolicyRepository) Delete(userID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    delete(r.store.archivePolicies, userID)
+    return nil
+}
+
+func (r *memoryArchivePolicyRepository) GetAll() ([]domain.ArchivePolicy, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var policies []domain.ArchivePolicy
+    for _, policy := range r.store.archivePolicies {
+        policies = append(policies, policy)
+    }
+
+    sort.Slice(policies, func(i, j int) bool { return policies[i].UserID < policies[j].UserID })
+    return policies, nil
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryAssignmentRepository struct {
+    store *Store
+}
+
+func NewMemoryAssignmentRepository(store *Store) domain.AssignmentRepository {
+    return &memoryAssignmentRepository{store}
+}
+
+func (r *memoryAssignmentRepository) Assign(ownerID int64, assignment *domain.Assignment) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    task, ok := r.store.tasks[assignment.TaskID]
+    if !ok || task.UserID != ownerID || task.DeletedAt != nil {
+        return domain.ErrTaskNotFound
+    }
+
+    now := time.Now()
+    task.AssigneeID = assignment.ToUserID
+    task.UpdatedAt = now
+    r.store.tasks[task.ID] = task
+
+    r.store.lastAssignmentID++
+    assignment.ID = r.store.lastAssignmentID
+    assignment.CreatedAt = now
+    r.store.assignments[assignment.ID] = *assignment
+    return nil
+}
+
+func (r *memoryAssignmentRepository) GetByTaskID(taskID int64) ([]domain.Assignment, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var assignments []domain.Assignment
+    for _, assignment := range r.store.assignments {
+        if assignment.TaskID == taskID {
+            assignments = append(assignments, assignment)
+        }
+    }
+
+    sort.Slice(assignments, func(i, j int) bool {
+        return assignments[i].ID < assignments[j].ID
+    })
+    return assignments, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryAttachmentRepository struct {
+    store *Store
+}
+
+func NewMemoryAttachmentRepository(store *Store) domain.AttachmentRepository {
+    return &memoryAttachmentRepository{store}
+}
+
+func (r *memoryAttachmentRepository) Create(attachment *domain.Attachment) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    r.store.lastAttachmentID++
+    attachment.ID = r.store.lastAttachmentID
+    attachment.CreatedAt = time.Now()
+
+    r.store.attachments[attachment.ID] = *attachment
+    return nil
+}
+
+func (r *memoryAttachmentRepository) Delete(id, taskID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.attachments[id]
+    if !ok || existing.TaskID != taskID {
+        return domain.ErrAttachmentNotFound
+    }
+
+    delete(r.store.attachments, id)
+    return nil
+}
+
+func (r *memoryAttachmentRepository) GetByID(id, taskID int64) (*domain.Attachment, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    attachment, ok := r.store.attachments[id]
+    if !ok || attachment.TaskID != taskID {
+        return nil, nil
+    }
+
+    return &attachment, nil
+}
+
+func (r *memoryAttachmentRepository) GetByTaskID(taskID int64) ([]domain.Attachment, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var attachments []domain.Attachment
+    for _, attachment := range r.store.attachments {
+        if attachment.TaskID == taskID {
+            attachments = append(attachments, attachment)
+        }
+    }
+
+    sort.Slice(attachments, func(i, j int) bool {
+        return attachments[i].ID < attachments[j].ID
+    })
+    return attachments, nil
+}
+
+func (r *memoryAttachmentRepository) GetByTaskTree(taskID int64) ([]domain.Attachment, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var attachments []domain.Attachment
+    for _, attachment := range r.store.attachments {
+        task := r.store.tasks[attachment.TaskID]
+        if attachment.TaskID == taskID || task.ParentID != nil && *task.ParentID == taskID {
+            attachments = append(attachments, attachment)
+        }
+    }
+
+    sort.Slice(attachments, func(i, j int) bool {
+        return attachments[i].ID < attachments[j].ID
+    })
+    return attachments, nil
+}
+
+func (r *memoryAttachmentRepository) UsageByUserID(userID int64) (int64, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var usage int64
+    for _, attachment := range r.store.attachments {
+        if attachment.UserID == userID {
+            usage += attachment.Size
+        }
+    }
+
+    return usage, nil
+}
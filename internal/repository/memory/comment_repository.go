@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryCommentRepository struct {
+    store *Store
+}
+
+func NewMemoryCommentRepository(store *Store) domain.CommentRepository {
+    return &memoryCommentRepository{store}
+}
+
+func (r *memoryCommentRepository) Create(comment *domain.Comment) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    r.store.lastCommentID++
+    comment.ID = r.store.lastCommentID
+    comment.CreatedAt = time.Now()
+
+    r.store.comments[comment.ID] = *comment
+    return nil
+}
+
+func (r *memoryCommentRepository) Update(comment *domain.Comment) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.comments[comment.ID]
+    if !ok || existing.TaskID != comment.TaskID || existing.UserID != comment.UserID {
+        return domain.ErrCommentNotFound
+    }
+
+    now := time.Now()
+    existing.Body = comment.Body
+    existing.EditedAt = &now
+    r.store.comments[comment.ID] = existing
+
+    comment.EditedAt = &now
+    return nil
+}
+
+func (r *memoryCommentRepository) Delete(id, taskID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.comments[id]
+    if !ok || existing.TaskID != taskID {
+        return domain.ErrCommentNotFound
+    }
+
+    delete(r.store.comments, id)
+    return nil
+}
+
+func (r *memoryCommentRepository) GetByID(id, taskID int64) (*domain.Comment, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    comment, ok := r.store.comments[id]
+    if !ok || comment.TaskID != taskID {
+        return nil, nil
+    }
+
+    comment.Username = r.store.users[comment.UserID].Username
+    return &comment, nil
+}
+
+func (r *memoryCommentRepository) GetByTaskID(taskID int64) ([]domain.Comment, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var comments []domain.Comment
+    for _, comment := range r.store.comments {
+        if comment.TaskID == taskID {
+            comment.Username = r.store.users[comment.UserID].Username
+            comments = append(comments, comment)
+        }
+    }
+
+    sort.Slice(comments, func(i, j int) bool {
+        return comments[i].ID < comments[j].ID
+    })
+    return comments, nil
+}
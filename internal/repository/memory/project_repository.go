@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryProjectRepository struct {
+    store *Store
+}
+
+func NewMemoryProjectRepository(store *Store) domain.ProjectRepository {
+    return &memoryProjectRepository{store}
+}
+
+func (r *memoryProjectRepository) Create(project *domain.Project) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    now := time.Now()
+    r.store.lastProjectID++
+    project.ID = r.store.lastProjectID
+    project.CreatedAt = now
+    project.UpdatedAt = now
+
+    r.store.projects[project.ID] = *project
+    return nil
+}
+
+func (r *memoryProjectRepository) Update(project *domain.Project) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.projects[project.ID]
+    if !ok || existing.UserID != project.UserID {
+        return domain.ErrProjectNotFound
+    }
+
+    now := time.Now()
+    existing.Name = project.Name
+    existing.Description = project.Description
+    existing.ArchivedAt = project.ArchivedAt
+    existing.UpdatedAt = now
+    r.store.projects[project.ID] = existing
+
+    project.UpdatedAt = now
+    return nil
+}
+
+func (r *memoryProjectRepository) Delete(id, userID int64, deleteTasks bool) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.projects[id]
+    if !ok || existing.UserID != userID {
+        return domain.ErrProjectNotFound
+    }
+
+    now := time.Now()
+    if deleteTasks {
+        for taskID, task := range r.store.tasks {
+            if task.UserID == userID && task.DeletedAt == nil && task.ProjectID != nil && *task.ProjectID == id {
+                r.store.trashTask(taskID, now)
+            }
+        }
+    }
+
+    // Tasks in the trash leave the project too, as the foreign key sets
+    // them loose in the SQL backends.
+    for taskID, task := range r.store.tasks {
+        if task.ProjectID == nil || *task.ProjectID != id {
+            continue
+        }
+        task.ProjectID = nil
+        if !deleteTasks {
+            task.UpdatedAt = now
+        }
+        r.store.tasks[taskID] = task
+    }
+
+    delete(r.store.projects, id)
+    delete(r.store.projectShares, id)
+    return nil
+}
+
+func (r *memoryProjectRepository) GetByID(id, userID int64) (*domain.Project, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    project, ok := r.store.projects[id]
+    if !ok || project.UserID != userID {
+        return nil, nil
+    }
+
+    return &project, nil
+}
+
+func (r *memoryProjectRepository) GetAllByUserID(userID int64, includeArchived bool) ([]domain.Project, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var projects []domain.Project
+    for _, project := range r.store.projects {
+        if project.UserID != userID || (project.ArchivedAt != nil && !includeArchived) {
+            continue
+        }
+        projects = append(projects, project)
+    }
+
+    sort.Slice(projects, func(i, j int) bool {
+        if projects[i].Name != projects[j].Name {
+            return projects[i].Name < projects[j].Name
+        }
+        return projects[i].ID < projects[j].ID
+    })
+
+    return projects, nil
+}
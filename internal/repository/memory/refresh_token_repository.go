@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryRefreshTokenRepository struct {
+    store *Store
+}
+
+func NewMemoryRefreshTokenRepository(store *Store) domain.RefreshTokenRepository {
+    return &memoryRefreshTokenRepository{store}
+}
+
+func (r *memoryRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    r.store.lastRefreshTokenID++
+    token.ID = r.store.lastRefreshTokenID
+    token.CreatedAt = time.Now()
+
+    r.store.refreshTokens[token.ID] = *token
+    return nil
+}
+
+func (r *memoryRefreshTokenRepository) GetByHash(hash string) (*domain.RefreshToken, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    for _, token := range r.store.refreshTokens {
+        if token.TokenHash == hash {
+            return &token, nil
+        }
+    }
+
+    return nil, nil
+}
+
+func (r *memoryRefreshTokenRepository) MarkUsed(id int64, usedAt time.Time) (bool, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    token, ok := r.store.refreshTokens[id]
+    if !ok || token.UsedAt != nil || token.RevokedAt != nil {
+        return false, nil
+    }
+
+    token.UsedAt = &usedAt
+    r.store.refreshTokens[id] = token
+    return true, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for id, token := range r.store.refreshTokens {
+        if token.FamilyID == familyID && token.RevokedAt == nil {
+            token.RevokedAt = &revokedAt
+            r.store.refreshTokens[id] = token
+        }
+    }
+
+    return nil
+}
+
+func (r *memoryRefreshTokenRepository) GetActiveFamilies(userID int64, now time.Time) ([]string, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    seen := make(map[string]bool)
+    var families []string
+    for _, token := range r.store.refreshTokens {
+        if token.UserID != userID || token.UsedAt != nil || token.RevokedAt != nil || !token.ExpiresAt.After(now) {
+            continue
+        }
+        if !seen[token.FamilyID] {
+            seen[token.FamilyID] = true
+            families = append(families, token.FamilyID)
+        }
+    }
+
+    return families, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeByUserID(userID int64, revokedAt time.Time) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for id, token := range r.store.refreshTokens {
+        if token.UserID == userID && token.RevokedAt == nil {
+            token.RevokedAt = &revokedAt
+            r.store.refreshTokens[id] = token
+        }
+    }
+
+    return nil
+}
+
+func (r *memoryRefreshTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var deleted int64
+    for id, token := range r.store.refreshTokens {
+        if token.ExpiresAt.Before(before) {
+            delete(r.store.refreshTokens, id)
+            deleted++
+        }
+    }
+
+    return deleted, nil
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryReminderRepository struct {
+    store *Store
+}
+
+func NewMemoryReminderRepository(store *Store) domain.ReminderRepository {
+    return &memoryReminderRepository{store}
+}
+
+func (r *memoryReminderRepository) Create(reminder *domain.Reminder) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    r.store.lastReminderID++
+    reminder.ID = r.store.lastReminderID
+    reminder.CreatedAt = time.Now()
+
+    r.store.reminders[reminder.ID] = *reminder
+    return nil
+}
+
+func (r *memoryReminderRepository) Delete(id, taskID, userID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.reminders[id]
+    if !ok || existing.TaskID != taskID || existing.UserID != userID {
+        return domain.ErrReminderNotFound
+    }
+
+    delete(r.store.reminders, id)
+    return nil
+}
+
+func (r *memoryReminderRepository) GetByTaskID(taskID, userID int64) ([]domain.Reminder, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var reminders []domain.Reminder
+    for _, reminder := range r.store.reminders {
+        if reminder.TaskID == taskID && reminder.UserID == userID {
+            reminders = append(reminders, reminder)
+        }
+    }
+
+    sortReminders(reminders)
+    return reminders, nil
+}
+
+// ProcessDue needs no claims: the dispatch lock keeps a second run from
+// picking up the same reminders, and the store lock is not held while
+// delivering.
+func (r *memoryReminderRepository) ProcessDue(now time.Time, limit, maxAttempts int, claimTimeout time.Duration, deliver func(domain.Notification) error) error {
+    r.store.dispatch.Lock()
+    defer r.store.dispatch.Unlock()
+
+    r.store.mu.RLock()
+    var due []domain.Reminder
+    for _, reminder := range r.store.reminders {
+        task := r.store.tasks[reminder.TaskID]
+        if reminder.SentAt == nil && !reminder.RemindAt.After(now) && reminder.Attempts < maxAttempts &&
+            task.DeletedAt == nil && r.store.visibleTo(task, reminder.UserID) {
+            due = append(due, reminder)
+        }
+    }
+    sortReminders(due)
+    if len(due) > limit {
+        due = due[:limit]
+    }
+
+    notifications := make([]domain.Notification, 0, len(due))
+    for _, reminder := range due {
+        task := r.store.tasks[reminder.TaskID]
+        notifications = append(notifications, domain.Notification{
+            Reminder:  reminder,
+            TaskTitle: task.Title,
+            TaskDueAt: task.DueAt,
+            TaskDone:  task.Done,
+        })
+    }
+    r.store.mu.RUnlock()
+
+    for _, notification := range notifications {
+        deliverErr := deliver(notification)
+
+        r.store.mu.Lock()
+        // The reminder may have been deleted, with its task, while it was
+        // being delivered.
+        if reminder, ok := r.store.reminders[notification.Reminder.ID]; ok {
+            reminder.Attempts++
+            if deliverErr != nil {
+                reminder.LastError = deliverErr.Error()
+            } else {
+                sentAt := time.Now()
+                reminder.SentAt = &sentAt
+                reminder.LastError = ""
+            }
+            r.store.reminders[reminder.ID] = reminder
+        }
+        r.store.mu.Unlock()
+    }
+
+    return nil
+}
+
+func sortReminders(reminders []domain.Reminder) {
+    sort.Slice(reminders, func(i, j int) bool {
+        if !reminders[i].RemindAt.Equal(reminders[j].RemindAt) {
+            return reminders[i].RemindAt.Before(reminders[j].RemindAt)
+        }
+        return reminders[i].ID < reminders[j].ID
+    })
+}
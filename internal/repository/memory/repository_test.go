@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"testing"
+	"todo-app/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+    repotest.Run(t, func(t *testing.T) repotest.Repos {
+        store := NewStore()
+        return repotest.Repos{
+            Users:           NewMemoryUserRepository(store),
+            Tasks:           NewMemoryTaskRepository(store),
+            History:         NewMemoryTaskHistoryRepository(store),
+            Projects:        NewMemoryProjectRepository(store),
+            Tags:            NewMemoryTagRepository(store),
+            Shares:          NewMemoryShareRepository(store),
+            Assignments:     NewMemoryAssignmentRepository(store),
+            Comments:        NewMemoryCommentRepository(store),
+            Attachments:     NewMemoryAttachmentRepository(store),
+            Reminders:       NewMemoryReminderRepository(store),
+            RefreshTokens:   NewMemoryRefreshTokenRepository(store),
+            Revocations:     NewMemoryTokenRevocationRepository(store),
+            ArchivePolicies: NewMemoryArchivePolicyRepository(store),
+        }
+    })
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryShareRepository struct {
+    store *Store
+}
+
+func NewMemoryShareRepository(store *Store) domain.ShareRepository {
+    return &memoryShareRepository{store}
+}
+
+func (r *memoryShareRepository) SaveTaskShare(taskID, userID int64, role domain.Permission) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    saveShare(r.store.taskShares, taskID, userID, role)
+    return nil
+}
+
+func (r *memoryShareRepository) DeleteTaskShare(taskID, userID int64, unassign []domain.Assignment) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if err := deleteShare(r.store.taskShares, taskID, userID); err != nil {
+        return err
+    }
+
+    r.unassign(userID, unassign)
+    return nil
+}
+
+func (r *memoryShareRepository) GetTaskShares(taskID int64) ([]domain.Share, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return r.list(r.store.taskShares[taskID]), nil
+}
+
+func (r *memoryShareRepository) GetTaskRoles(userID int64, taskIDs []int64) (map[int64]domain.Permission, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return shareRoles(r.store.taskShares, userID, taskIDs), nil
+}
+
+func (r *memoryShareRepository) SaveProjectShare(projectID, userID int64, role domain.Permission) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    saveShare(r.store.projectShares, projectID, userID, role)
+    return nil
+}
+
+func (r *memoryShareRepository) DeleteProjectShare(projectID, userID int64, unassign []domain.Assignment) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if err := deleteShare(r.store.projectShares, projectID, userID); err != nil {
+        return err
+    }
+
+    r.unassign(userID, unassign)
+    return nil
+}
+
+func (r *memoryShareRepository) GetProjectShares(projectID int64) ([]domain.Share, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return r.list(r.store.projectShares[projectID]), nil
+}
+
+func (r *memoryShareRepository) GetProjectRoles(userID int64, projectIDs []int64) (map[int64]domain.Permission, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return shareRoles(r.store.projectShares, userID, projectIDs), nil
+}
+
+// unassign clears userID as the assignee of the tasks of unassign they are
+// still assigned, recording each in the assignment history. The caller holds
+// the lock.
+func (r *memoryShareRepository) unassign(userID int64, unassign []domain.Assignment) {
+    now := time.Now()
+    for i := range unassign {
+        assignment := &unassign[i]
+
+        task, ok := r.store.tasks[assignment.TaskID]
+        if !ok || task.AssigneeID == nil || *task.AssigneeID != userID {
+            continue
+        }
+        task.AssigneeID = nil
+        task.UpdatedAt = now
+        r.store.tasks[task.ID] = task
+
+        r.store.lastAssignmentID++
+        assignment.ID = r.store.lastAssignmentID
+        assignment.CreatedAt = now
+        r.store.assignments[assignment.ID] = *assignment
+    }
+}
+
+// list returns shares with their usernames, ordered by username. The caller
+// holds the lock.
+func (r *memoryShareRepository) list(byUser map[int64]domain.Share) []domain.Share {
+    var shares []domain.Share
+    for userID, share := range byUser {
+        share.Username = r.store.users[userID].Username
+        shares = append(shares, share)
+    }
+
+    sort.Slice(shares, func(i, j int) bool {
+        return shares[i].Username < shares[j].Username
+    })
+    return shares
+}
+
+func saveShare(shares map[int64]map[int64]domain.Share, id, userID int64, role domain.Permission) {
+    if shares[id] == nil {
+        shares[id] = make(map[int64]domain.Share)
+    }
+
+    share, ok := shares[id][userID]
+    if !ok {
+        share = domain.Share{UserID: userID, CreatedAt: time.Now()}
+    }
+    share.Role = role
+    shares[id][userID] = share
+}
+
+func deleteShare(shares map[int64]map[int64]domain.Share, id, userID int64) error {
+    if _, ok := shares[id][userID]; !ok {
+        return domain.ErrShareNotFound
+    }
+
+    delete(shares[id], userID)
+    return nil
+}
+
+func shareRoles(shares map[int64]map[int64]domain.Share, userID int64, ids []int64) map[int64]domain.Permission {
+    roles := make(map[int64]domain.Permission)
+    for _, id := range ids {
+        if share, ok := shares[id][userID]; ok {
+            roles[id] = share.Role
+        }
+    }
+    return roles
+}
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"sync"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// Store holds every table of the in-memory backend behind a single lock, so
+// repositories built from the same store observe each other's writes the way
+// they would against one database.
+type Store struct {
+    mu sync.RWMutex
+
+    users      map[int64]domain.User
+    lastUserID int64
+
+    tasks      map[int64]domain.Task
+    lastTaskID int64
+
+    tags      map[int64]domain.Tag
+    lastTagID int64
+
+    projects      map[int64]domain.Project
+    lastProjectID int64
+
+    reminders      map[int64]domain.Reminder
+    lastReminderID int64
+    // dispatch serializes the delivery of due reminders, which happens
+    // outside mu so a slow notifier does not block the other repositories.
+    dispatch sync.Mutex
+
+    assignments      map[int64]domain.Assignment
+    lastAssignmentID int64
+
+    comments      map[int64]domain.Comment
+    lastCommentID int64
+
+    attachments      map[int64]domain.Attachment
+    lastAttachmentID int64
+
+    // taskHistory holds the versions of each task in order. It is kept
+    // when the task is deleted.
+    taskHistory       map[int64][]domain.TaskVersion
+    lastTaskVersionID int64
+
+    // taskTags holds the tag IDs attached to each task.
+    taskTags map[int64]map[int64]bool
+
+    // taskShares and projectShares hold the shares of each task and
+    // project by user ID.
+    taskShares    map[int64]map[int64]domain.Share
+    projectShares map[int64]map[int64]domain.Share
+
+    // archivePolicies holds the archive policy of each user by user ID.
+    archivePolicies map[int64]domain.ArchivePolicy
+
+    refreshTokens      map[int64]domain.RefreshToken
+    lastRefreshTokenID int64
+    // revokedTokens holds the expiry of each revoked token ID.
+    revokedTokens map[string]time.Time
+}
+
+func NewStore() *Store {
+    return &Store{
+        users:     make(map[int64]domain.User),
+        tasks:     make(map[int64]domain.Task),
+        tags:      make(map[int64]domain.Tag),
+        taskTags:  make(map[int64]map[int64]bool),
+        projects:  make(map[int64]domain.Project),
+        reminders: make(map[int64]domain.Reminder),
+
+        assignments:   make(map[int64]domain.Assignment),
+        comments:      make(map[int64]domain.Comment),
+        attachments:   make(map[int64]domain.Attachment),
+        taskHistory:   make(map[int64][]domain.TaskVersion),
+        taskShares:    make(map[int64]map[int64]domain.Share),
+        projectShares: make(map[int64]map[int64]domain.Share),
+
+        archivePolicies: make(map[int64]domain.ArchivePolicy),
+        refreshTokens:   make(map[int64]domain.RefreshToken),
+        revokedTokens:   make(map[string]time.Time),
+    }
+}
+
+// taskTagNames returns the names of the tags attached to a task. The caller
+// holds the lock.
+func (s *Store) taskTagNames(taskID int64) []string {
+    var names []string
+    for tagID := range s.taskTags[taskID] {
+        names = append(names, s.tags[tagID].Name)
+    }
+    return names
+}
+
+// visibleTo reports whether userID owns task or was given it through a
+// share of the task, its parent or its project. The caller holds the lock.
+func (s *Store) visibleTo(task domain.Task, userID int64) bool {
+    if task.UserID == userID {
+        return true
+    }
+    if _, ok := s.taskShares[task.ID][userID]; ok {
+        return true
+    }
+    if task.ParentID != nil {
+        if _, ok := s.taskShares[*task.ParentID][userID]; ok {
+            return true
+        }
+    }
+    if task.ProjectID != nil {
+        if _, ok := s.projectShares[*task.ProjectID][userID]; ok {
+            return true
+        }
+    }
+    return false
+}
+
+// trashTask moves a task and its subtasks that are not in the trash yet to
+// the trash at now. The caller holds the lock.
+func (s *Store) trashTask(id int64, now time.Time) {
+    for taskID, task := range s.tasks {
+        if task.DeletedAt != nil {
+            continue
+        }
+        if taskID == id || task.ParentID != nil && *task.ParentID == id {
+            task.DeletedAt = &now
+            s.tasks[taskID] = task
+        }
+    }
+}
+
+// trashedTop reports whether task is in the trash and is not the subtask of
+// another task in the trash. The caller holds the lock.
+func (s *Store) trashedTop(task domain.Task) bool {
+    if task.DeletedAt == nil {
+        return false
+    }
+    if task.ParentID == nil {
+        return true
+    }
+    parent, ok := s.tasks[*task.ParentID]
+    return !ok || parent.DeletedAt == nil
+}
+
+// deleteTask removes a task with its tag links, shares, reminders,
+// assignment history, comments, attachment rows and subtasks, the way the
+// foreign keys cascade in the SQL backends. The caller holds the lock.
+func (s *Store) deleteTask(id int64) {
+    delete(s.tasks, id)
+    delete(s.taskTags, id)
+    delete(s.taskShares, id)
+    for assignmentID, assignment := range s.assignments {
+        if assignment.TaskID == id {
+            delete(s.assignments, assignmentID)
+        }
+    }
+    for commentID, comment := range s.comments {
+        if comment.TaskID == id {
+            delete(s.comments, commentID)
+        }
+    }
+    for attachmentID, attachment := range s.attachments {
+        if attachment.TaskID == id {
+            delete(s.attachments, attachmentID)
+        }
+    }
+    for reminderID, reminder := range s.reminders {
+        if reminder.TaskID == id {
+            delete(s.reminders, reminderID)
+        }
+    }
+    for childID, child := range s.tasks {
+        if child.ParentID != nil && *child.ParentID == id {
+            s.deleteTask(childID)
+        }
+    }
+}
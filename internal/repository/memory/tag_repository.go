@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryTagRepository struct {
+    store *Store
+}
+
+func NewMemoryTagRepository(store *Store) domain.TagRepository {
+    return &memoryTagRepository{store}
+}
+
+func (r *memoryTagRepository) Create(tag *domain.Tag) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if r.nameTaken(tag.UserID, tag.Name, 0) {
+        return domain.ErrTagExists
+    }
+
+    now := time.Now()
+    r.store.lastTagID++
+    tag.ID = r.store.lastTagID
+    tag.CreatedAt = now
+    tag.UpdatedAt = now
+
+    r.store.tags[tag.ID] = *tag
+    return nil
+}
+
+func (r *memoryTagRepository) Update(tag *domain.Tag) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.tags[tag.ID]
+    if !ok || existing.UserID != tag.UserID {
+        return domain.ErrTagNotFound
+    }
+    if r.nameTaken(tag.UserID, tag.Name, tag.ID) {
+        return domain.ErrTagExists
+    }
+
+    now := time.Now()
+    existing.Name = tag.Name
+    existing.UpdatedAt = now
+    r.store.tags[tag.ID] = existing
+
+    tag.UpdatedAt = now
+    return nil
+}
+
+func (r *memoryTagRepository) Delete(id, userID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.tags[id]
+    if !ok || existing.UserID != userID {
+        return domain.ErrTagNotFound
+    }
+
+    delete(r.store.tags, id)
+    for _, tagIDs := range r.store.taskTags {
+        delete(tagIDs, id)
+    }
+    return nil
+}
+
+func (r *memoryTagRepository) GetByID(id, userID int64) (*domain.Tag, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    tag, ok := r.store.tags[id]
+    if !ok || tag.UserID != userID {
+        return nil, nil
+    }
+
+    return &tag, nil
+}
+
+func (r *memoryTagRepository) GetAllByUserID(userID int64) ([]domain.Tag, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var tags []domain.Tag
+    for _, tag := range r.store.tags {
+        if tag.UserID == userID {
+            tags = append(tags, tag)
+        }
+    }
+
+    sortTags(tags)
+    return tags, nil
+}
+
+func (r *memoryTagRepository) AttachToTask(taskID, tagID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    if r.store.taskTags[taskID] == nil {
+        r.store.taskTags[taskID] = make(map[int64]bool)
+    }
+    r.store.taskTags[taskID][tagID] = true
+    return nil
+}
+
+func (r *memoryTagRepository) DetachFromTask(taskID, tagID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    delete(r.store.taskTags[taskID], tagID)
+    return nil
+}
+
+func (r *memoryTagRepository) GetByTaskIDs(taskIDs []int64) (map[int64][]domain.Tag, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    tags := make(map[int64][]domain.Tag)
+    for _, taskID := range taskIDs {
+        for tagID := range r.store.taskTags[taskID] {
+            tags[taskID] = append(tags[taskID], r.store.tags[tagID])
+        }
+        sortTags(tags[taskID])
+    }
+
+    return tags, nil
+}
+
+// nameTaken reports whether userID already has a tag called name other than
+// the tag exceptID. The caller holds the lock.
+func (r *memoryTagRepository) nameTaken(userID int64, name string, exceptID int64) bool {
+    for _, tag := range r.store.tags {
+        if tag.UserID == userID && tag.Name == name && tag.ID != exceptID {
+            return true
+        }
+    }
+    return false
+}
+
+func sortTags(tags []domain.Tag) {
+    sort.Slice(tags, func(i, j int) bool {
+        if tags[i].Name != tags[j].Name {
+            return tags[i].Name < tags[j].Name
+        }
+        return tags[i].ID < tags[j].ID
+    })
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"todo-app/internal/domain"
+)
+
+// matchesTaskFilter reports whether task, carrying the tags named tagNames,
+// belongs in the listing described by filter.
+func matchesTaskFilter(task domain.Task, tagNames []string, filter domain.TaskFilter) bool {
+    if filter.TopLevel && task.ParentID != nil {
+        return false
+    }
+    if filter.ProjectID != nil && (task.ProjectID == nil || *task.ProjectID != *filter.ProjectID) {
+        return false
+    }
+    if filter.AssigneeID != nil && (task.AssigneeID == nil || *task.AssigneeID != *filter.AssigneeID) {
+        return false
+    }
+    if filter.Done != nil && task.Done != *filter.Done {
+        return false
+    }
+    if filter.Archived == domain.ArchivedExclude && task.ArchivedAt != nil ||
+        filter.Archived == domain.ArchivedOnly && task.ArchivedAt == nil {
+        return false
+    }
+    if len(filter.Priorities) > 0 && !slices.Contains(filter.Priorities, task.Priority) {
+        return false
+    }
+    if len(filter.Tags) > 0 && !matchesTags(tagNames, filter.Tags, filter.TagMatch) {
+        return false
+    }
+    if filter.CreatedAfter != nil && !task.CreatedAt.After(*filter.CreatedAfter) {
+        return false
+    }
+    if filter.CreatedBefore != nil && !task.CreatedAt.Before(*filter.CreatedBefore) {
+        return false
+    }
+    if filter.UpdatedAfter != nil && !task.UpdatedAt.After(*filter.UpdatedAfter) {
+        return false
+    }
+    if filter.UpdatedBefore != nil && !task.UpdatedAt.Before(*filter.UpdatedBefore) {
+        return false
+    }
+
+    if filter.DueFrom != nil && (task.DueAt == nil || task.DueAt.Before(*filter.DueFrom)) {
+        return false
+    }
+    if filter.DueBefore != nil && (task.DueAt == nil || !task.DueAt.Before(*filter.DueBefore)) {
+        return false
+    }
+
+    if filter.After != nil && compareTasks(task, filter.After.Last(), filter.Sort, filter.Order) <= 0 {
+        return false
+    }
+
+    return true
+}
+
+func matchesTags(tagNames, wanted []string, match domain.TagMatch) bool {
+    has := func(name string) bool { return slices.Contains(tagNames, name) }
+    if match == domain.TagMatchAll {
+        return !slices.ContainsFunc(wanted, func(name string) bool { return !has(name) })
+    }
+    return slices.ContainsFunc(wanted, has)
+}
+
+// compareTasks orders a before b the way the SQL backends' ORDER BY does.
+func compareTasks(a, b domain.Task, sort domain.TaskSort, order domain.SortOrder) int {
+    for _, compare := range taskComparators(sort, order) {
+        if result := compare(a, b); result != 0 {
+            return result
+        }
+    }
+    return 0
+}
+
+type taskComparator func(a, b domain.Task) int
+
+// taskComparators mirrors taskSortKeys in the SQL backends: the sort key,
+// any secondary key, then the ID in the sort's direction.
+func taskComparators(sort domain.TaskSort, order domain.SortOrder) []taskComparator {
+    desc := order == domain.SortDesc
+    byID := directed(func(a, b domain.Task) int { return cmp.Compare(a.ID, b.ID) }, desc)
+
+    switch sort {
+    case domain.TaskSortUpdatedAt:
+        return []taskComparator{directed(byUpdatedAt, desc), byID}
+    case domain.TaskSortTitle:
+        return []taskComparator{directed(byTitle, desc), byID}
+    case domain.TaskSortDueAt:
+        return []taskComparator{byDueAt(desc), directed(byPriority, true), byID}
+    case domain.TaskSortPriority:
+        return []taskComparator{directed(byPriority, desc), byDueAt(false), byID}
+    default:
+        return []taskComparator{directed(byCreatedAt, desc), byID}
+    }
+}
+
+func directed(compare taskComparator, desc bool) taskComparator {
+    if !desc {
+        return compare
+    }
+    return func(a, b domain.Task) int { return -compare(a, b) }
+}
+
+func byCreatedAt(a, b domain.Task) int { return a.CreatedAt.Compare(b.CreatedAt) }
+func byUpdatedAt(a, b domain.Task) int { return a.UpdatedAt.Compare(b.UpdatedAt) }
+func byTitle(a, b domain.Task) int     { return strings.Compare(a.Title, b.Title) }
+func byPriority(a, b domain.Task) int  { return cmp.Compare(a.Priority, b.Priority) }
+
+// byDueAt sorts tasks without a due date last whatever the direction.
+func byDueAt(desc bool) taskComparator {
+    return func(a, b domain.Task) int {
+        switch {
+        case a.DueAt == nil && b.DueAt == nil:
+            return 0
+        case a.DueAt == nil:
+            return 1
+        case b.DueAt == nil:
+            return -1
+        case desc:
+            return b.DueAt.Compare(*a.DueAt)
+        default:
+            return a.DueAt.Compare(*b.DueAt)
+        }
+    }
+}
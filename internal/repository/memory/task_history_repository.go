@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryTaskHistoryRepository struct {
+    store *Store
+}
+
+func NewMemoryTaskHistoryRepository(store *Store) domain.TaskHistoryRepository {
+    return &memoryTaskHistoryRepository{store}
+}
+
+func (r *memoryTaskHistoryRepository) Append(entry *domain.TaskVersion) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    r.store.lastTaskVersionID++
+    entry.ID = r.store.lastTaskVersionID
+    entry.Version = len(r.store.taskHistory[entry.TaskID]) + 1
+    entry.CreatedAt = time.Now()
+
+    r.store.taskHistory[entry.TaskID] = append(r.store.taskHistory[entry.TaskID], *entry)
+    return nil
+}
+
+func (r *memoryTaskHistoryRepository) GetByTaskID(taskID int64) ([]domain.TaskVersion, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    return append([]domain.TaskVersion(nil), r.store.taskHistory[taskID]...), nil
+}
+
+func (r *memoryTaskHistoryRepository) GetVersion(taskID int64, version int) (*domain.TaskVersion, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    versions := r.store.taskHistory[taskID]
+    if version < 1 || version > len(versions) {
+        return nil, nil
+    }
+
+    entry := versions[version-1]
+    return &entry, nil
+}
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"sort"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryTaskRepository struct {
+    store *Store
+}
+
+func NewMemoryTaskRepository(store *Store) domain.TaskRepository {
+    return &memoryTaskRepository{store}
+}
+
+func (r *memoryTaskRepository) Create(task *domain.Task) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    now := time.Now()
+    r.store.lastTaskID++
+    task.ID = r.store.lastTaskID
+    task.CreatedAt = now
+    task.UpdatedAt = now
+
+    r.store.tasks[task.ID] = *task
+    return nil
+}
+
+func (r *memoryTaskRepository) Update(task *domain.Task) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.tasks[task.ID]
+    if !ok || existing.UserID != task.UserID || existing.DeletedAt != nil {
+        return domain.ErrTaskNotFound
+    }
+
+    now := time.Now()
+    existing.ProjectID = task.ProjectID
+    existing.Title = task.Title
+    existing.Description = task.Description
+    existing.Done = task.Done
+    existing.Priority = task.Priority
+    existing.DueAt = task.DueAt
+    existing.CompletedAt = task.CompletedAt
+    existing.Recurrence = task.Recurrence
+    existing.RecurrenceTZ = task.RecurrenceTZ
+    existing.UpdatedAt = now
+    r.store.tasks[task.ID] = existing
+
+    task.UpdatedAt = now
+    return nil
+}
+
+func (r *memoryTaskRepository) Delete(id, userID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.tasks[id]
+    if !ok || existing.UserID != userID || existing.DeletedAt != nil {
+        return domain.ErrTaskNotFound
+    }
+
+    r.store.trashTask(id, time.Now())
+    return nil
+}
+
+func (r *memoryTaskRepository) GetByID(id, userID int64) (*domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    task, ok := r.store.tasks[id]
+    if !ok || task.UserID != userID || task.DeletedAt != nil {
+        return nil, nil
+    }
+
+    return &task, nil
+}
+
+func (r *memoryTaskRepository) FindByID(id int64) (*domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    task, ok := r.store.tasks[id]
+    if !ok || task.DeletedAt != nil {
+        return nil, nil
+    }
+
+    return &task, nil
+}
+
+func (r *memoryTaskRepository) GetAllByUserID(userID int64, filter domain.TaskFilter) ([]domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var tasks []domain.Task
+    for _, task := range r.store.tasks {
+        if task.DeletedAt == nil && r.store.visibleTo(task, userID) && matchesTaskFilter(task, r.store.taskTagNames(task.ID), filter) {
+            tasks = append(tasks, task)
+        }
+    }
+
+    sort.Slice(tasks, func(i, j int) bool {
+        return compareTasks(tasks[i], tasks[j], filter.Sort, filter.Order) < 0
+    })
+
+    if len(tasks) > filter.Limit {
+        tasks = tasks[:filter.Limit]
+    }
+
+    return tasks, nil
+}
+
+func (r *memoryTaskRepository) GetSubtasks(parentID, userID int64) ([]domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var tasks []domain.Task
+    for _, task := range r.store.tasks {
+        if task.UserID == userID && task.DeletedAt == nil && task.ParentID != nil && *task.ParentID == parentID {
+            tasks = append(tasks, task)
+        }
+    }
+
+    sort.Slice(tasks, func(i, j int) bool {
+        if tasks[i].Position != tasks[j].Position {
+            return tasks[i].Position < tasks[j].Position
+        }
+        return tasks[i].ID < tasks[j].ID
+    })
+
+    return tasks, nil
+}
+
+func (r *memoryTaskRepository) ReorderSubtasks(parentID, userID int64, ids []int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for position, id := range ids {
+        task, ok := r.store.tasks[id]
+        if !ok || task.UserID != userID || task.DeletedAt != nil || task.ParentID == nil || *task.ParentID != parentID {
+            continue
+        }
+        task.Position = position + 1
+        r.store.tasks[id] = task
+    }
+
+    return nil
+}
+
+func (r *memoryTaskRepository) GetSubtaskProgress(taskIDs []int64) (map[int64]domain.SubtaskProgress, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    wanted := make(map[int64]bool, len(taskIDs))
+    for _, id := range taskIDs {
+        wanted[id] = true
+    }
+
+    progress := make(map[int64]domain.SubtaskProgress)
+    for _, task := range r.store.tasks {
+        if task.DeletedAt != nil || task.ParentID == nil || !wanted[*task.ParentID] {
+            continue
+        }
+        p := progress[*task.ParentID]
+        p.Total++
+        if task.Done {
+            p.Done++
+        }
+        progress[*task.ParentID] = p
+    }
+
+    return progress, nil
+}
+
+func (r *memoryTaskRepository) GetByProjectID(projectID, userID int64) ([]domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var tasks []domain.Task
+    for _, task := range r.store.tasks {
+        if task.UserID == userID && task.DeletedAt == nil && task.ProjectID != nil && *task.ProjectID == projectID {
+            tasks = append(tasks, task)
+        }
+    }
+
+    sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+    return tasks, nil
+}
+
+func (r *memoryTaskRepository) SetArchivedAt(id, userID int64, archivedAt *time.Time) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    task, ok := r.store.tasks[id]
+    if !ok || task.UserID != userID || task.DeletedAt != nil {
+        return domain.ErrTaskNotFound
+    }
+
+    task.ArchivedAt = archivedAt
+    task.UpdatedAt = time.Now()
+    r.store.tasks[id] = task
+    return nil
+}
+
+func (r *memoryTaskRepository) ArchiveCompleted(userID int64, completedBefore, now time.Time) (int64, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var archived int64
+    for id, task := range r.store.tasks {
+        if task.UserID != userID || !task.Done || task.CompletedAt == nil || !task.CompletedAt.Before(completedBefore) ||
+            task.ArchivedAt != nil || task.DeletedAt != nil {
+            continue
+        }
+        archivedAt := now
+        task.ArchivedAt = &archivedAt
+        task.UpdatedAt = now
+        r.store.tasks[id] = task
+        archived++
+    }
+
+    return archived, nil
+}
+
+func (r *memoryTaskRepository) GetTrash(userID int64) ([]domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var tasks []domain.Task
+    for _, task := range r.store.tasks {
+        if task.UserID == userID && r.store.trashedTop(task) {
+            tasks = append(tasks, task)
+        }
+    }
+
+    sort.Slice(tasks, func(i, j int) bool { return trashedBefore(tasks[j], tasks[i]) })
+    return tasks, nil
+}
+
+func (r *memoryTaskRepository) GetTrashedByID(id, userID int64) (*domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    task, ok := r.store.tasks[id]
+    if !ok || task.UserID != userID || task.DeletedAt == nil {
+        return nil, nil
+    }
+
+    return &task, nil
+}
+
+func (r *memoryTaskRepository) Restore(id, userID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.tasks[id]
+    if !ok || existing.UserID != userID || existing.DeletedAt == nil {
+        return domain.ErrTaskNotFound
+    }
+
+    deletedAt := *existing.DeletedAt
+    for taskID, task := range r.store.tasks {
+        if taskID == id || task.ParentID != nil && *task.ParentID == id && task.DeletedAt != nil && task.DeletedAt.Equal(deletedAt) {
+            task.DeletedAt = nil
+            r.store.tasks[taskID] = task
+        }
+    }
+
+    return nil
+}
+
+func (r *memoryTaskRepository) Purge(id, userID int64) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    existing, ok := r.store.tasks[id]
+    if !ok || existing.UserID != userID || existing.DeletedAt == nil {
+        return domain.ErrTaskNotFound
+    }
+
+    r.store.deleteTask(id)
+    return nil
+}
+
+func (r *memoryTaskRepository) GetTrashedBefore(before time.Time, limit int) ([]domain.Task, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    var tasks []domain.Task
+    for _, task := range r.store.tasks {
+        if r.store.trashedTop(task) && task.DeletedAt.Before(before) {
+            tasks = append(tasks, task)
+        }
+    }
+
+    sort.Slice(tasks, func(i, j int) bool { return trashedBefore(tasks[i], tasks[j]) })
+    if len(tasks) > limit {
+        tasks = tasks[:limit]
+    }
+
+    return tasks, nil
+}
+
+// trashedBefore orders tasks in the trash by deletion time, then ID.
+func trashedBefore(a, b domain.Task) bool {
+    if !a.DeletedAt.Equal(*b.DeletedAt) {
+        return a.DeletedAt.Before(*b.DeletedAt)
+    }
+    return a.ID < b.ID
+}
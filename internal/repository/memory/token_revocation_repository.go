@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryTokenRevocationRepository struct {
+    store *Store
+}
+
+func NewMemoryTokenRevocationRepository(store *Store) domain.TokenRevocationRepository {
+    return &memoryTokenRevocationRepository{store}
+}
+
+func (r *memoryTokenRevocationRepository) Revoke(ids []string, userID int64, expiresAt time.Time) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for _, id := range ids {
+        if _, ok := r.store.revokedTokens[id]; !ok {
+            r.store.revokedTokens[id] = expiresAt
+        }
+    }
+
+    return nil
+}
+
+func (r *memoryTokenRevocationRepository) IsRevoked(id string) (bool, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    _, ok := r.store.revokedTokens[id]
+    return ok, nil
+}
+
+func (r *memoryTokenRevocationRepository) DeleteExpired(before time.Time) (int64, error) {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    var deleted int64
+    for id, expiresAt := range r.store.revokedTokens {
+        if expiresAt.Before(before) {
+            delete(r.store.revokedTokens, id)
+            deleted++
+        }
+    }
+
+    return deleted, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+	"todo-app/internal/domain"
+)
+
+type memoryUserRepository struct {
+    store *Store
+}
+
+func NewMemoryUserRepository(store *Store) domain.UserRepository {
+    return &memoryUserRepository{store}
+}
+
+func (r *memoryUserRepository) Create(user *domain.User) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    for _, existing := range r.store.users {
+        if existing.Username == user.Username {
+            return domain.ErrUsernameTaken
+        }
+    }
+
+    now := time.Now()
+    r.store.lastUserID++
+    user.ID = r.store.lastUserID
+    user.CreatedAt = now
+    user.UpdatedAt = now
+
+    r.store.users[user.ID] = *user
+    return nil
+}
+
+func (r *memoryUserRepository) GetByUsername(username string) (*domain.User, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    for _, user := range r.store.users {
+        if user.Username == username {
+            return &user, nil
+        }
+    }
+
+    return nil, nil
+}
+
+func (r *memoryUserRepository) GetByID(id int64) (*domain.User, error) {
+    r.store.mu.RLock()
+    defer r.store.mu.RUnlock()
+
+    user, ok := r.store.users[id]
+    if !ok {
+        return nil, nil
+    }
+
+    return &user, nil
+}
+
+func (r *memoryUserRepository) UpdatePassword(id int64, password string) error {
+    r.store.mu.Lock()
+    defer r.store.mu.Unlock()
+
+    user, ok := r.store.users[id]
+    if !ok {
+        return domain.ErrUserNotFound
+    }
+
+    user.Password = password
+    user.UpdatedAt = time.Now()
+    r.store.users[id] = user
+    return nil
+}
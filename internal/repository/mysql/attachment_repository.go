@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const attachmentColumns = `id, task_id, user_id, filename, content_type, size, storage_key, created_at`
+
+type mysqlAttachmentRepository struct {
+    db *sql.DB
+}
+
+func NewMysqlAttachmentRepository(db *sql.DB) domain.AttachmentRepository {
+    return &mysqlAttachmentRepository{db}
+}
+
+func (r *mysqlAttachmentRepository) Create(attachment *domain.Attachment) error {
+    query := `
+        INSERT INTO attachments (task_id, user_id, filename, content_type, size, storage_key, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+    `
+
+    now := time.Now()
+    result, err := r.db.Exec(query,
+        attachment.TaskID,
+        attachment.UserID,
+        attachment.Filename,
+        attachment.ContentType,
+        attachment.Size,
+        attachment.StorageKey,
+        now,
+    )
+    if err != nil {
+        return fmt.Errorf("error creating attachment: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    attachment.ID = id
+    attachment.CreatedAt = now
+    return nil
+}
+
+func (r *mysqlAttachmentRepository) Delete(id, taskID int64) error {
+    result, err := r.db.Exec(`DELETE FROM attachments WHERE id = ? AND task_id = ?`, id, taskID)
+    if err != nil {
+        return fmt.Errorf("error deleting attachment: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrAttachmentNotFound
+    }
+
+    return nil
+}
+
+func (r *mysqlAttachmentRepository) GetByID(id, taskID int64) (*domain.Attachment, error) {
+    query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE id = ? AND task_id = ?
+    `
+
+    attachment := &domain.Attachment{}
+    err := scanAttachment(r.db.QueryRow(query, id, taskID), attachment)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting attachment: %w", err)
+    }
+
+    return attachment, nil
+}
+
+func (r *mysqlAttachmentRepository) GetByTaskID(taskID int64) ([]domain.Attachment, error) {
+    query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE task_id = ?
+        ORDER BY id
+    `
+
+    return r.query(query, taskID)
+}
+
+func (r *mysqlAttachmentRepository) GetByTaskTree(taskID int64) ([]domain.Attachment, error) {
+    query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE task_id = ? OR task_id IN (SELECT id FROM tasks WHERE parent_id = ?)
+        ORDER BY id
+    `
+
+    return r.query(query, taskID, taskID)
+}
+
+func (r *mysqlAttachmentRepository) query(query string, args ...interface{}) ([]domain.Attachment, error) {
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying attachments: %w", err)
+    }
+    defer rows.Close()
+
+    var attachments []domain.Attachment
+    for rows.Next() {
+        var attachment domain.Attachment
+        if err := scanAttachment(rows, &attachment); err != nil {
+            return nil, fmt.Errorf("error scanning attachment: %w", err)
+        }
+        attachments = append(attachments, attachment)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating attachments: %w", err)
+    }
+
+    return attachments, nil
+}
+
+func (r *mysqlAttachmentRepository) UsageByUserID(userID int64) (int64, error) {
+    var usage int64
+    err := r.db.QueryRow(`SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = ?`, userID).Scan(&usage)
+    if err != nil {
+        return 0, fmt.Errorf("error getting attachment usage: %w", err)
+    }
+
+    return usage, nil
+}
+
+// scanAttachment reads a row selected with attachmentColumns.
+func scanAttachment(row rowScanner, attachment *domain.Attachment) error {
+    return row.Scan(
+        &attachment.ID,
+        &attachment.TaskID,
+        &attachment.UserID,
+        &attachment.Filename,
+        &attachment.ContentType,
+        &attachment.Size,
+        &attachment.StorageKey,
+        &attachment.CreatedAt,
+    )
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const commentColumns = `c.id, c.task_id, c.user_id, u.username, c.body, c.created_at, c.edited_at`
+
+type mysqlCommentRepository struct {
+    db *sql.DB
+}
+
+func NewMysqlCommentRepository(db *sql.DB) domain.CommentRepository {
+    return &mysqlCommentRepository{db}
+}
+
+func (r *mysqlCommentRepository) Create(comment *domain.Comment) error {
+    query := `
+        INSERT INTO comments (task_id, user_id, body, created_at)
+        VALUES (?, ?, ?, ?)
+    `
+
+    now := time.Now()
+    result, err := r.db.Exec(query, comment.TaskID, comment.UserID, comment.Body, now)
+    if err != nil {
+        return fmt.Errorf("error creating comment: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    comment.ID = id
+    comment.CreatedAt = now
+    return nil
+}
+
+func (r *mysqlCommentRepository) Update(comment *domain.Comment) error {
+    query := `
+        UPDATE comments
+        SET body = ?, edited_at = ?
+        WHERE id = ? AND task_id = ? AND user_id = ?
+    `
+
+    now := time.Now()
+    result, err := r.db.Exec(query, comment.Body, now, comment.ID, comment.TaskID, comment.UserID)
+    if err != nil {
+        return fmt.Errorf("error updating comment: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrCommentNotFound
+    }
+
+    comment.EditedAt = &now
+    return nil
+}
+
+func (r *mysqlCommentRepository) Delete(id, taskID int64) error {
+    result, err := r.db.Exec(`DELETE FROM comments WHERE id = ? AND task_id = ?`, id, taskID)
+    if err != nil {
+        return fmt.Errorf("error deleting comment: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrCommentNotFound
+    }
+
+    return nil
+}
+
+func (r *mysqlCommentRepository) GetByID(id, taskID int64) (*domain.Comment, error) {
+    query := `
+        SELECT ` + commentColumns + `
+        FROM comments c
+        JOIN users u ON u.id = c.user_id
+        WHERE c.id = ? AND c.task_id = ?
+    `
+
+    comment := &domain.Comment{}
+    err := scanComment(r.db.QueryRow(query, id, taskID), comment)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting comment: %w", err)
+    }
+
+    return comment, nil
+}
+
+func (r *mysqlCommentRepository) GetByTaskID(taskID int64) ([]domain.Comment, error) {
+    query := `
+        SELECT ` + commentColumns + `
+        FROM comments c
+        JOIN users u ON u.id = c.user_id
+        WHERE c.task_id = ?
+        ORDER BY c.id
+    `
+
+    rows, err := r.db.Query(query, taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying comments: %w", err)
+    }
+    defer rows.Close()
+
+    var comments []domain.Comment
+    for rows.Next() {
+        var comment domain.Comment
+        if err := scanComment(rows, &comment); err != nil {
+            return nil, fmt.Errorf("error scanning comment: %w", err)
+        }
+        comments = append(comments, comment)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating comments: %w", err)
+    }
+
+    return comments, nil
+}
+
+// scanComment reads a row selected with commentColumns.
+func scanComment(row rowScanner, comment *domain.Comment) error {
+    return row.Scan(
+        &comment.ID,
+        &comment.TaskID,
+        &comment.UserID,
+        &comment.Username,
+        &comment.Body,
+        &comment.CreatedAt,
+        &comment.EditedAt,
+    )
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"errors"
+
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// erDupEntry is the MySQL error number for a unique key violation.
+const erDupEntry = 1062
+
+func isDuplicateKey(err error) bool {
+    var mysqlErr *driver.MySQLError
+    return errors.As(err, &mysqlErr) && mysqlErr.Number == erDupEntry
+}
+
+// erLockDeadlock is the MySQL error number for a transaction rolled back to
+// break a deadlock.
+const erLockDeadlock = 1213
+
+func isDeadlock(err error) bool {
+    var mysqlErr *driver.MySQLError
+    return errors.As(err, &mysqlErr) && mysqlErr.Number == erLockDeadlock
+}
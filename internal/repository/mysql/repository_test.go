@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+	"todo-app/internal/pkg/migrate"
+	"todo-app/internal/repository/repotest"
+
+	_ "github.com/go-sql-driver/mysql"
+)
+
+// TestConformance runs against the database in TODO_TEST_MYSQL_DSN, e.g.
+// "todo:secret@tcp(localhost:3306)/todo_test?parseTime=true", migrating it
+// first. The tests leave their rows behind, so use a scratch database.
+func TestConformance(t *testing.T) {
+    dsn := os.Getenv("TODO_TEST_MYSQL_DSN")
+    if dsn == "" {
+        t.Skip("TODO_TEST_MYSQL_DSN is not set")
+    }
+
+    db, err := sql.Open("mysql", dsn)
+    if err != nil {
+        t.Fatalf("opening mysql: %v", err)
+    }
+    defer db.Close()
+    repotest.Migrate(t, db, migrate.MySQL, "mysql")
+
+    repotest.Run(t, func(t *testing.T) repotest.Repos {
+        return repotest.Repos{
+            Users:           NewMysqlUserRepository(db),
+            Tasks:           NewMysqlTaskRepository(db),
+            History:         NewMysqlTaskHistoryRepository(db),
+            Projects:        NewMysqlProjectRepository(db),
+            Tags:            NewMysqlTagRepository(db),
+            Shares:          NewMysqlShareRepository(db),
+            Assignments:     NewMysqlAssignmentRepository(db),
+            Comments:        NewMysqlCommentRepository(db),
+            Attachments:     NewMysqlAttachmentRepository(db),
+            Reminders:       NewMysqlReminderRepository(db),
+            RefreshTokens:   NewMysqlRefreshTokenRepository(db),
+            Revocations:     NewMysqlTokenRevocationRepository(db),
+            ArchivePolicies: NewMysqlArchivePolicyRepository(db),
+        }
+    })
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type mysqlShareRepository struct {
+    db *sql.DB
+}
+
+func NewMysqlShareRepository(db *sql.DB) domain.ShareRepository {
+    return &mysqlShareRepository{db}
+}
+
+func (r *mysqlShareRepository) SaveTaskShare(taskID, userID int64, role domain.Permission) error {
+    return r.save("task_shares", "task_id", taskID, userID, role)
+}
+
+func (r *mysqlShareRepository) DeleteTaskShare(taskID, userID int64, unassign []domain.Assignment) error {
+    return r.delete("task_shares", "task_id", taskID, userID, unassign)
+}
+
+func (r *mysqlShareRepository) GetTaskShares(taskID int64) ([]domain.Share, error) {
+    return r.list("task_shares", "task_id", taskID)
+}
+
+func (r *mysqlShareRepository) GetTaskRoles(userID int64, taskIDs []int64) (map[int64]domain.Permission, error) {
+    return r.roles("task_shares", "task_id", userID, taskIDs)
+}
+
+func (r *mysqlShareRepository) SaveProjectShare(projectID, userID int64, role domain.Permission) error {
+    return r.save("project_shares", "project_id", projectID, userID, role)
+}
+
+func (r *mysqlShareRepository) DeleteProjectShare(projectID, userID int64, unassign []domain.Assignment) error {
+    return r.delete("project_shares", "project_id", projectID, userID, unassign)
+}
+
+func (r *mysqlShareRepository) GetProjectShares(projectID int64) ([]domain.Share, error) {
+    return r.list("project_shares", "project_id", projectID)
+}
+
+func (r *mysqlShareRepository) GetProjectRoles(userID int64, projectIDs []int64) (map[int64]domain.Permission, error) {
+    return r.roles("project_shares", "project_id", userID, projectIDs)
+}
+
+// The helpers below serve both share tables; table and column only ever
+// come from the methods above, never from input.
+
+func (r *mysqlShareRepository) save(table, column string, id, userID int64, role domain.Permission) error {
+    query := `
+        INSERT INTO ` + table + ` (` + column + `, user_id, role, created_at)
+        VALUES (?, ?, ?, ?)
+        ON DUPLICATE KEY UPDATE role = VALUES(role)
+    `
+
+    if _, err := r.db.Exec(query, id, userID, role, time.Now()); err != nil {
+        return fmt.Errorf("error saving share: %w", err)
+    }
+
+    return nil
+}
+
+// delete removes a share and unassigns userID from the tasks of unassign
+// they are still assigned, in one transaction.
+func (r *mysqlShareRepository) delete(table, column string, id, userID int64, unassign []domain.Assignment) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := `DELETE FROM ` + table + ` WHERE ` + column + ` = ? AND user_id = ?`
+
+    result, err := tx.Exec(query, id, userID)
+    if err != nil {
+        return fmt.Errorf("error deleting share: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrShareNotFound
+    }
+
+    now := time.Now()
+    for i := range unassign {
+        assignment := &unassign[i]
+
+        result, err := tx.Exec(`UPDATE tasks SET assignee_id = NULL, updated_at = ? WHERE id = ? AND assignee_id = ?`,
+            now, assignment.TaskID, userID)
+        if err != nil {
+            return fmt.Errorf("error unassigning task: %w", err)
+        }
+
+        affected, err := result.RowsAffected()
+        if err != nil {
+            return fmt.Errorf("error getting rows affected: %w", err)
+        }
+        if affected == 0 {
+            continue
+        }
+
+        result, err = tx.Exec(`
+            INSERT INTO task_assignments (task_id, assigned_by, from_user_id, to_user_id, created_at)
+            VALUES (?, ?, ?, ?, ?)
+        `,
+            assignment.TaskID,
+            assignment.AssignedBy,
+            assignment.FromUserID,
+            assignment.ToUserID,
+            now,
+        )
+        if err != nil {
+            return fmt.Errorf("error recording assignment: %w", err)
+        }
+
+        assignmentID, err := result.LastInsertId()
+        if err != nil {
+            return fmt.Errorf("error getting last insert id: %w", err)
+        }
+
+        assignment.ID = assignmentID
+        assignment.CreatedAt = now
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing share removal: %w", err)
+    }
+
+    return nil
+}
+
+func (r *mysqlShareRepository) list(table, column string, id int64) ([]domain.Share, error) {
+    query := `
+        SELECT s.user_id, u.username, s.role, s.created_at
+        FROM ` + table + ` s
+        JOIN users u ON u.id = s.user_id
+        WHERE s.` + column + ` = ?
+        ORDER BY u.username
+    `
+
+    rows, err := r.db.Query(query, id)
+    if err != nil {
+        return nil, fmt.Errorf("error querying shares: %w", err)
+    }
+    defer rows.Close()
+
+    var shares []domain.Share
+    for rows.Next() {
+        var share domain.Share
+        if err := rows.Scan(&share.UserID, &share.Username, &share.Role, &share.CreatedAt); err != nil {
+            return nil, fmt.Errorf("error scanning share: %w", err)
+        }
+        shares = append(shares, share)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating shares: %w", err)
+    }
+
+    return shares, nil
+}
+
+func (r *mysqlShareRepository) roles(table, column string, userID int64, ids []int64) (map[int64]domain.Permission, error) {
+    roles := make(map[int64]domain.Permission)
+    if len(ids) == 0 {
+        return roles, nil
+    }
+
+    placeholders := make([]string, len(ids))
+    args := []interface{}{userID}
+    for i, id := range ids {
+        placeholders[i] = "?"
+        args = append(args, id)
+    }
+
+    query := `
+        SELECT ` + column + `, role
+        FROM ` + table + `
+        WHERE user_id = ? AND ` + column + ` IN (` + strings.Join(placeholders, ", ") + `)
+    `
+
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying share roles: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var id int64
+        var role domain.Permission
+        if err := rows.Scan(&id, &role); err != nil {
+            return nil, fmt.Errorf("error scanning share role: %w", err)
+        }
+        roles[id] = role
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating share roles: %w", err)
+    }
+
+    return roles, nil
+}
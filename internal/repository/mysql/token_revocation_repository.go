@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type mysqlTokenRevocationRepository struct {
+    db *sql.DB
+}
+
+func NewMysqlTokenRevocationRepository(db *sql.DB) domain.TokenRevocationRepository {
+    return &mysqlTokenRevocationRepository{db}
+}
+
+func (r *mysqlTokenRevocationRepository) Revoke(ids []string, userID int64, expiresAt time.Time) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := `
+        INSERT IGNORE INTO revoked_tokens (token_id, user_id, expires_at, created_at)
+        VALUES (?, ?, ?, ?)
+    `
+
+    now := time.Now()
+    for _, id := range ids {
+        if _, err := tx.Exec(query, id, userID, expiresAt, now); err != nil {
+            return fmt.Errorf("error revoking token: %w", err)
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing revocations: %w", err)
+    }
+
+    return nil
+}
+
+func (r *mysqlTokenRevocationRepository) IsRevoked(id string) (bool, error) {
+    var revoked bool
+    err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = ?)`, id).Scan(&revoked)
+    if err != nil {
+        return false, fmt.Errorf("error checking token revocation: %w", err)
+    }
+
+    return revoked, nil
+}
+
+func (r *mysqlTokenRevocationRepository) DeleteExpired(before time.Time) (int64, error) {
+    result, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < ?`, before)
+    if err != nil {
+        return 0, fmt.Errorf("error deleting expired revocations: %w", err)
+    }
+
+    deleted, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("error getting rows affected: %w", err)
+    }
+
+    return deleted, nil
+}
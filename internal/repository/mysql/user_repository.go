@@ -28,13 +28,16 @@ func (r *mysqlUserRepository) Create(user *domain.User) error {
         now,
         now,
     )
+    if isDuplicateKey(err) {
+        return domain.ErrUsernameTaken
+    }
     if err != nil {
-        return fmt.Errorf("error creating user: %v", err)
+        return fmt.Errorf("error creating user: %w", err)
     }
 
     id, err := result.LastInsertId()
     if err != nil {
-        return fmt.Errorf("error getting last insert id: %v", err)
+        return fmt.Errorf("error getting last insert id: %w", err)
     }
 
     user.ID = id
@@ -63,7 +66,7 @@ func (r *mysqlUserRepository) GetByUsername(username string) (*domain.User, erro
         return nil, nil
     }
     if err != nil {
-        return nil, fmt.Errorf("error getting user by username: %v", err)
+        return nil, fmt.Errorf("error getting user by username: %w", err)
     }
 
     return user, nil
@@ -89,8 +92,27 @@ func (r *mysqlUserRepository) GetByID(id int64) (*domain.User, error) {
         return nil, nil
     }
     if err != nil {
-        return nil, fmt.Errorf("error getting user by id: %v", err)
+        return nil, fmt.Errorf("error getting user by id: %w", err)
     }
 
     return user, nil
-}
\ No newline at end of file
+}
+
+func (r *mysqlUserRepository) UpdatePassword(id int64, password string) error {
+    query := `UPDATE users SET password = ?, updated_at = ? WHERE id = ?`
+
+    result, err := r.db.Exec(query, password, time.Now(), id)
+    if err != nil {
+        return fmt.Errorf("error updating password: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrUserNotFound
+    }
+
+    return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type postgresArchivePolicyRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresArchivePolicyRepository(db *sql.DB) domain.ArchivePolicyRepository {
+    return &postgresArchivePolicyRepository{db}
+}
+
+func (r *postgresArchivePolicyRepository) Get(userID int64) (*domain.ArchivePolicy, error) {
+    query := `SELECT user_id, after_days, updated_at FROM archive_policies WHERE user_id = $1`
+
+    policy := &domain.ArchivePolicy{}
+    err := r.db.QueryRow(query, userID).Scan(&policy.UserID, &policy.AfterDays, &policy.UpdatedAt)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting archive policy: %w", err)
+    }
+
+    return policy, nil
+}
+
+func (r *postgresArchivePolicyRepository) Save(policy *domain.ArchivePolicy) error {
+    query := `
+        INSERT INTO archive_policies (user_id, after_days, updated_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id) DO UPDATE SET after_days = excluded.after_days, updated_at = excluded.updated_at
+    `
+
+    now := time.Now().UTC()
+    if _, err := r.db.Exec(query, policy.UserID, policy.AfterDays, now); err != nil {
+        return fmt.Errorf("error saving archive policy: %w", err)
+    }
+
+    policy.UpdatedAt = now
+    return nil
+}
+
+func (r *postgresArchivePolicyRepository) Delete(userID int64) error {
+    if _, err := r.db.Exec(`DELETE FROM archive_policies WHERE user_id = $1`, userID); err != nil {
+        return fmt.Errorf("error deleting archive policy: %w", err)
+    }
+
+    return nil
+}
+
+func (r *postgresArchivePolicyRepository) GetAll() ([]domain.ArchivePolicy, error) {
+    query := `SELECT user_id, after_days, updated_at FROM archive_policies ORDER BY user_id`
+
+    rows, err := r.db.Query(query)
+    if err != nil {
+        return nil, fmt.Errorf("error querying archive policies: %w", err)
+    }
+    defer rows.Close()
+
+    var policies []domain.ArchivePolicy
+    for rows.Next() {
+        var policy domain.ArchivePolicy
+        if err := rows.Scan(&policy.UserID, &policy.AfterDays, &policy.UpdatedAt); err != nil {
+            return nil, fmt.Errorf("error scanning archive policy: %w", err)
+        }
+        policies = append(policies, policy)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating archive policies: %w", err)
+    }
+
+    return policies, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type postgresAssignmentRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresAssignmentRepository(db *sql.DB) domain.AssignmentRepository {
+    return &postgresAssignmentRepository{db}
+}
+
+func (r *postgresAssignmentRepository) Assign(ownerID int64, assignment *domain.Assignment) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    now := time.Now().UTC()
+    result, err := tx.Exec(`UPDATE tasks SET assignee_id = $1, updated_at = $2 WHERE id = $3 AND user_id = $4 AND deleted_at IS NULL`,
+        assignment.ToUserID, now, assignment.TaskID, ownerID)
+    if err != nil {
+        return fmt.Errorf("error assigning task: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTaskNotFound
+    }
+
+    query := `
+        INSERT INTO task_assignments (task_id, assigned_by, from_user_id, to_user_id, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+
+    var id int64
+    err = tx.QueryRow(query,
+        assignment.TaskID,
+        assignment.AssignedBy,
+        assignment.FromUserID,
+        assignment.ToUserID,
+        now,
+    ).Scan(&id)
+    if err != nil {
+        return fmt.Errorf("error recording assignment: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing assignment: %w", err)
+    }
+
+    assignment.ID = id
+    assignment.CreatedAt = now
+    return nil
+}
+
+func (r *postgresAssignmentRepository) GetByTaskID(taskID int64) ([]domain.Assignment, error) {
+    query := `
+        SELECT id, task_id, assigned_by, from_user_id, to_user_id, created_at
+        FROM task_assignments
+        WHERE task_id = $1
+        ORDER BY id
+    `
+
+    rows, err := r.db.Query(query, taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying assignments: %w", err)
+    }
+    defer rows.Close()
+
+    var assignments []domain.Assignment
+    for rows.Next() {
+        var a domain.Assignment
+        if err := rows.Scan(&a.ID, &a.TaskID, &a.AssignedBy, &a.FromUserID, &a.ToUserID, &a.CreatedAt); err != nil {
+            return nil, fmt.Errorf("error scanning assignment: %w", err)
+        }
+        assignments = append(assignments, a)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating assignments: %w", err)
+    }
+
+    return assignments, nil
+}
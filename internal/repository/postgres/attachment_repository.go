@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const attachmentColumns = `id, task_id, user_id, filename, content_type, size, storage_key, created_at`
+
+type postgresAttachmentRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresAttachmentRepository(db *sql.DB) domain.AttachmentRepository {
+    return &postgresAttachmentRepository{db}
+}
+
+func (r *postgresAttachmentRepository) Create(attachment *domain.Attachment) error {
+    query := `
+        INSERT INTO attachments (task_id, user_id, filename, content_type, size, storage_key, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        RETURNING id
+    `
+
+    now := time.Now().UTC()
+    err := r.db.QueryRow(query,
+        attachment.TaskID,
+        attachment.UserID,
+        attachment.Filename,
+        attachment.ContentType,
+        attachment.Size,
+        attachment.StorageKey,
+        now,
+    ).Scan(&attachment.ID)
+    if err != nil {
+        return fmt.Errorf("error creating attachment: %w", err)
+    }
+
+    attachment.CreatedAt = now
+    return nil
+}
+
+func (r *postgresAttachmentRepository) Delete(id, taskID int64) error {
+    result, err := r.db.Exec(`DELETE FROM attachments WHERE id = $1 AND task_id = $2`, id, taskID)
+    if err != nil {
+        return fmt.Errorf("error deleting attachment: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrAttachmentNotFound
+    }
+
+    return nil
+}
+
+func (r *postgresAttachmentRepository) GetByID(id, taskID int64) (*domain.Attachment, error) {
+    query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE id = $1 AND task_id = $2
+    `
+
+    attachment := &domain.Attachment{}
+    err := scanAttachment(r.db.QueryRow(query, id, taskID), attachment)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting attachment: %w", err)
+    }
+
+    return attachment, nil
+}
+
+func (r *postgresAttachmentRepository) GetByTaskID(taskID int64) ([]domain.Attachment, error) {
+    query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE task_id = $1
+        ORDER BY id
+    `
+
+    return r.query(query, taskID)
+}
+
+func (r *postgresAttachmentRepository) GetByTaskTree(taskID int64) ([]domain.Attachment, error) {
+    query := `
+        SELECT ` + attachmentColumns + `
+        FROM attachments
+        WHERE task_id = $1 OR task_id IN (SELECT id FROM tasks WHERE parent_id = $1)
+        ORDER BY id
+    `
+
+    return r.query(query, taskID)
+}
+
+func (r *postgresAttachmentRepository) query(query string, args ...interface{}) ([]domain.Attachment, error) {
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying attachments: %w", err)
+    }
+    defer rows.Close()
+
+    var attachments []domain.Attachment
+    for rows.Next() {
+        var attachment domain.Attachment
+        if err := scanAttachment(rows, &attachment); err != nil {
+            return nil, fmt.Errorf("error scanning attachment: %w", err)
+        }
+        attachments = append(attachments, attachment)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating attachments: %w", err)
+    }
+
+    return attachments, nil
+}
+
+func (r *postgresAttachmentRepository) UsageByUserID(userID int64) (int64, error) {
+    var usage int64
+    err := r.db.QueryRow(`SELECT COALESCE(SUM(size), 0) FROM attachments WHERE user_id = $1`, userID).Scan(&usage)
+    if err != nil {
+        return 0, fmt.Errorf("error getting attachment usage: %w", err)
+    }
+
+    return usage, nil
+}
+
+// scanAttachment reads a row selected with attachmentColumns.
+func scanAttachment(row rowScanner, attachment *domain.Attachment) error {
+    return row.Scan(
+        &attachment.ID,
+        &attachment.TaskID,
+        &attachment.UserID,
+        &attachment.Filename,
+        &attachment.ContentType,
+        &attachment.Size,
+        &attachment.StorageKey,
+        &attachment.CreatedAt,
+    )
+}
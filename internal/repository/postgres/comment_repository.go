@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const commentColumns = `c.id, c.task_id, c.user_id, u.username, c.body, c.created_at, c.edited_at`
+
+type postgresCommentRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresCommentRepository(db *sql.DB) domain.CommentRepository {
+    return &postgresCommentRepository{db}
+}
+
+func (r *postgresCommentRepository) Create(comment *domain.Comment) error {
+    query := `
+        INSERT INTO comments (task_id, user_id, body, created_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `
+
+    now := time.Now().UTC()
+    err := r.db.QueryRow(query, comment.TaskID, comment.UserID, comment.Body, now).Scan(&comment.ID)
+    if err != nil {
+        return fmt.Errorf("error creating comment: %w", err)
+    }
+
+    comment.CreatedAt = now
+    return nil
+}
+
+func (r *postgresCommentRepository) Update(comment *domain.Comment) error {
+    query := `
+        UPDATE comments
+        SET body = $1, edited_at = $2
+        WHERE id = $3 AND task_id = $4 AND user_id = $5
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query, comment.Body, now, comment.ID, comment.TaskID, comment.UserID)
+    if err != nil {
+        return fmt.Errorf("error updating comment: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrCommentNotFound
+    }
+
+    comment.EditedAt = &now
+    return nil
+}
+
+func (r *postgresCommentRepository) Delete(id, taskID int64) error {
+    result, err := r.db.Exec(`DELETE FROM comments WHERE id = $1 AND task_id = $2`, id, taskID)
+    if err != nil {
+        return fmt.Errorf("error deleting comment: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrCommentNotFound
+    }
+
+    return nil
+}
+
+func (r *postgresCommentRepository) GetByID(id, taskID int64) (*domain.Comment, error) {
+    query := `
+        SELECT ` + commentColumns + `
+        FROM comments c
+        JOIN users u ON u.id = c.user_id
+        WHERE c.id = $1 AND c.task_id = $2
+    `
+
+    comment := &domain.Comment{}
+    err := scanComment(r.db.QueryRow(query, id, taskID), comment)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting comment: %w", err)
+    }
+
+    return comment, nil
+}
+
+func (r *postgresCommentRepository) GetByTaskID(taskID int64) ([]domain.Comment, error) {
+    query := `
+        SELECT ` + commentColumns + `
+        FROM comments c
+        JOIN users u ON u.id = c.user_id
+        WHERE c.task_id = $1
+        ORDER BY c.id
+    `
+
+    rows, err := r.db.Query(query, taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying comments: %w", err)
+    }
+    defer rows.Close()
+
+    var comments []domain.Comment
+    for rows.Next() {
+        var comment domain.Comment
+        if err := scanComment(rows, &comment); err != nil {
+            return nil, fmt.Errorf("error scanning comment: %w", err)
+        }
+        comments = append(comments, comment)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating comments: %w", err)
+    }
+
+    return comments, nil
+}
+
+// scanComment reads a row selected with commentColumns.
+func scanComment(row rowScanner, comment *domain.Comment) error {
+    return row.Scan(
+        &comment.ID,
+        &comment.TaskID,
+        &comment.UserID,
+        &comment.Username,
+        &comment.Body,
+        &comment.CreatedAt,
+        &comment.EditedAt,
+    )
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolation is the SQLSTATE for a unique constraint violation.
+const uniqueViolation = "23505"
+
+func isDuplicateKey(err error) bool {
+    var pgErr *pgconn.PgError
+    return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
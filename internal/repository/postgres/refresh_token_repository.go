@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type postgresRefreshTokenRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresRefreshTokenRepository(db *sql.DB) domain.RefreshTokenRepository {
+    return &postgresRefreshTokenRepository{db}
+}
+
+func (r *postgresRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+    query := `
+        INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id
+    `
+
+    now := time.Now().UTC()
+    err := r.db.QueryRow(query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt.UTC(), now).Scan(&token.ID)
+    if err != nil {
+        return fmt.Errorf("error creating refresh token: %w", err)
+    }
+
+    token.CreatedAt = now
+    return nil
+}
+
+func (r *postgresRefreshTokenRepository) GetByHash(hash string) (*domain.RefreshToken, error) {
+    query := `
+        SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+        FROM refresh_tokens
+        WHERE token_hash = $1
+    `
+
+    token := &domain.RefreshToken{}
+    err := r.db.QueryRow(query, hash).Scan(
+        &token.ID,
+        &token.UserID,
+        &token.FamilyID,
+        &token.TokenHash,
+        &token.ExpiresAt,
+        &token.UsedAt,
+        &token.RevokedAt,
+        &token.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting refresh token: %w", err)
+    }
+
+    return token, nil
+}
+
+func (r *postgresRefreshTokenRepository) MarkUsed(id int64, usedAt time.Time) (bool, error) {
+    query := `UPDATE refresh_tokens SET used_at = $1 WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL`
+
+    result, err := r.db.Exec(query, usedAt.UTC(), id)
+    if err != nil {
+        return false, fmt.Errorf("error marking refresh token used: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("error getting rows affected: %w", err)
+    }
+
+    return affected == 1, nil
+}
+
+func (r *postgresRefreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+    query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+
+    if _, err := r.db.Exec(query, revokedAt.UTC(), familyID); err != nil {
+        return fmt.Errorf("error revoking refresh tokens: %w", err)
+    }
+
+    return nil
+}
+
+func (r *postgresRefreshTokenRepository) GetActiveFamilies(userID int64, now time.Time) ([]string, error) {
+    query := `
+        SELECT DISTINCT family_id
+        FROM refresh_tokens
+        WHERE user_id = $1 AND used_at IS NULL AND revoked_at IS NULL AND expires_at > $2
+    `
+
+    rows, err := r.db.Query(query, userID, now.UTC())
+    if err != nil {
+        return nil, fmt.Errorf("error querying token families: %w", err)
+    }
+    defer rows.Close()
+
+    var families []string
+    for rows.Next() {
+        var family string
+        if err := rows.Scan(&family); err != nil {
+            return nil, fmt.Errorf("error scanning token family: %w", err)
+        }
+        families = append(families, family)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating token families: %w", err)
+    }
+
+    return families, nil
+}
+
+func (r *postgresRefreshTokenRepository) RevokeByUserID(userID int64, revokedAt time.Time) error {
+    query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL`
+
+    if _, err := r.db.Exec(query, revokedAt.UTC(), userID); err != nil {
+        return fmt.Errorf("error revoking refresh tokens: %w", err)
+    }
+
+    return nil
+}
+
+func (r *postgresRefreshTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+    result, err := r.db.Exec(`DELETE FROM refresh_tokens WHERE expires_at < $1`, before.UTC())
+    if err != nil {
+        return 0, fmt.Errorf("error deleting expired refresh tokens: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("error getting rows affected: %w", err)
+    }
+
+    return affected, nil
+}
@@ -0,0 +1,207 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const tagColumns = `id, user_id, name, created_at, updated_at`
+
+type postgresTagRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresTagRepository(db *sql.DB) domain.TagRepository {
+    return &postgresTagRepository{db}
+}
+
+func (r *postgresTagRepository) Create(tag *domain.Tag) error {
+    query := `
+        INSERT INTO tags (user_id, name, created_at, updated_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `
+
+    now := time.Now().UTC()
+    err := r.db.QueryRow(query, tag.UserID, tag.Name, now, now).Scan(&tag.ID)
+    if isDuplicateKey(err) {
+        return domain.ErrTagExists
+    }
+    if err != nil {
+        return fmt.Errorf("error creating tag: %w", err)
+    }
+
+    tag.CreatedAt = now
+    tag.UpdatedAt = now
+    return nil
+}
+
+func (r *postgresTagRepository) Update(tag *domain.Tag) error {
+    query := `
+        UPDATE tags
+        SET name = $1, updated_at = $2
+        WHERE id = $3 AND user_id = $4
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query, tag.Name, now, tag.ID, tag.UserID)
+    if isDuplicateKey(err) {
+        return domain.ErrTagExists
+    }
+    if err != nil {
+        return fmt.Errorf("error updating tag: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTagNotFound
+    }
+
+    tag.UpdatedAt = now
+    return nil
+}
+
+func (r *postgresTagRepository) Delete(id, userID int64) error {
+    query := `DELETE FROM tags WHERE id = $1 AND user_id = $2`
+
+    result, err := r.db.Exec(query, id, userID)
+    if err != nil {
+        return fmt.Errorf("error deleting tag: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTagNotFound
+    }
+
+    return nil
+}
+
+func (r *postgresTagRepository) GetByID(id, userID int64) (*domain.Tag, error) {
+    query := `
+        SELECT ` + tagColumns + `
+        FROM tags
+        WHERE id = $1 AND user_id = $2
+    `
+
+    tag := &domain.Tag{}
+    err := scanTag(r.db.QueryRow(query, id, userID), tag)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting tag: %w", err)
+    }
+
+    return tag, nil
+}
+
+func (r *postgresTagRepository) GetAllByUserID(userID int64) ([]domain.Tag, error) {
+    query := `
+        SELECT ` + tagColumns + `
+        FROM tags
+        WHERE user_id = $1
+        ORDER BY name, id
+    `
+
+    rows, err := r.db.Query(query, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying tags: %w", err)
+    }
+    defer rows.Close()
+
+    var tags []domain.Tag
+    for rows.Next() {
+        var tag domain.Tag
+        if err := scanTag(rows, &tag); err != nil {
+            return nil, fmt.Errorf("error scanning tag: %w", err)
+        }
+        tags = append(tags, tag)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating tags: %w", err)
+    }
+
+    return tags, nil
+}
+
+func (r *postgresTagRepository) AttachToTask(taskID, tagID int64) error {
+    query := `INSERT INTO task_tags (task_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`
+
+    if _, err := r.db.Exec(query, taskID, tagID); err != nil {
+        return fmt.Errorf("error attaching tag: %w", err)
+    }
+
+    return nil
+}
+
+func (r *postgresTagRepository) DetachFromTask(taskID, tagID int64) error {
+    query := `DELETE FROM task_tags WHERE task_id = $1 AND tag_id = $2`
+
+    if _, err := r.db.Exec(query, taskID, tagID); err != nil {
+        return fmt.Errorf("error detaching tag: %w", err)
+    }
+
+    return nil
+}
+
+func (r *postgresTagRepository) GetByTaskIDs(taskIDs []int64) (map[int64][]domain.Tag, error) {
+    tags := make(map[int64][]domain.Tag)
+    if len(taskIDs) == 0 {
+        return tags, nil
+    }
+
+    placeholders := make([]string, len(taskIDs))
+    args := make([]interface{}, len(taskIDs))
+    for i, id := range taskIDs {
+        placeholders[i] = "$" + strconv.Itoa(i+1)
+        args[i] = id
+    }
+
+    query := `
+        SELECT tt.task_id, t.id, t.user_id, t.name, t.created_at, t.updated_at
+        FROM task_tags tt
+        JOIN tags t ON t.id = tt.tag_id
+        WHERE tt.task_id IN (` + strings.Join(placeholders, ", ") + `)
+        ORDER BY t.name, t.id
+    `
+
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying task tags: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var taskID int64
+        var tag domain.Tag
+        err := rows.Scan(&taskID, &tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
+        if err != nil {
+            return nil, fmt.Errorf("error scanning task tag: %w", err)
+        }
+        tags[taskID] = append(tags[taskID], tag)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating task tags: %w", err)
+    }
+
+    return tags, nil
+}
+
+// scanTag reads a row selected with tagColumns.
+func scanTag(row rowScanner, tag *domain.Tag) error {
+    return row.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
+}
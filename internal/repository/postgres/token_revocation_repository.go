@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type postgresTokenRevocationRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresTokenRevocationRepository(db *sql.DB) domain.TokenRevocationRepository {
+    return &postgresTokenRevocationRepository{db}
+}
+
+func (r *postgresTokenRevocationRepository) Revoke(ids []string, userID int64, expiresAt time.Time) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := `
+        INSERT INTO revoked_tokens (token_id, user_id, expires_at, created_at)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (token_id) DO NOTHING
+    `
+
+    now := time.Now().UTC()
+    for _, id := range ids {
+        if _, err := tx.Exec(query, id, userID, expiresAt.UTC(), now); err != nil {
+            return fmt.Errorf("error revoking token: %w", err)
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing revocations: %w", err)
+    }
+
+    return nil
+}
+
+func (r *postgresTokenRevocationRepository) IsRevoked(id string) (bool, error) {
+    var revoked bool
+    err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE token_id = $1)`, id).Scan(&revoked)
+    if err != nil {
+        return false, fmt.Errorf("error checking token revocation: %w", err)
+    }
+
+    return revoked, nil
+}
+
+func (r *postgresTokenRevocationRepository) DeleteExpired(before time.Time) (int64, error) {
+    result, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < $1`, before.UTC())
+    if err != nil {
+        return 0, fmt.Errorf("error deleting expired revocations: %w", err)
+    }
+
+    deleted, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("error getting rows affected: %w", err)
+    }
+
+    return deleted, nil
+}
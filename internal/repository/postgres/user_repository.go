@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type postgresUserRepository struct {
+    db *sql.DB
+}
+
+func NewPostgresUserRepository(db *sql.DB) domain.UserRepository {
+    return &postgresUserRepository{db}
+}
+
+func (r *postgresUserRepository) Create(user *domain.User) error {
+    query := `
+        INSERT INTO users (username, password, created_at, updated_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id
+    `
+
+    now := time.Now().UTC()
+    err := r.db.QueryRow(query,
+        user.Username,
+        user.Password,
+        now,
+        now,
+    ).Scan(&user.ID)
+    if isDuplicateKey(err) {
+        return domain.ErrUsernameTaken
+    }
+    if err != nil {
+        return fmt.Errorf("error creating user: %w", err)
+    }
+
+    user.CreatedAt = now
+    user.UpdatedAt = now
+    return nil
+}
+
+func (r *postgresUserRepository) GetByUsername(username string) (*domain.User, error) {
+    query := `
+        SELECT id, username, password, created_at, updated_at
+        FROM users
+        WHERE username = $1
+    `
+
+    user := &domain.User{}
+    err := r.db.QueryRow(query, username).Scan(
+        &user.ID,
+        &user.Username,
+        &user.Password,
+        &user.CreatedAt,
+        &user.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting user by username: %w", err)
+    }
+
+    return user, nil
+}
+
+func (r *postgresUserRepository) GetByID(id int64) (*domain.User, error) {
+    query := `
+        SELECT id, username, password, created_at, updated_at
+        FROM users
+        WHERE id = $1
+    `
+
+    user := &domain.User{}
+    err := r.db.QueryRow(query, id).Scan(
+        &user.ID,
+        &user.Username,
+        &user.Password,
+        &user.CreatedAt,
+        &user.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting user by id: %w", err)
+    }
+
+    return user, nil
+}
+
+func (r *postgresUserRepository) UpdatePassword(id int64, password string) error {
+    query := `UPDATE users SET password = $1, updated_at = $2 WHERE id = $3`
+
+    result, err := r.db.Exec(query, password, time.Now().UTC(), id)
+    if err != nil {
+        return fmt.Errorf("error updating password: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrUserNotFound
+    }
+
+    return nil
+}
@@ -0,0 +1,71 @@
+package repotest
+
+import (
+	"testing"
+	"todo-app/internal/domain"
+)
+
+func testArchivePolicy(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    other := newUser(t, repos)
+
+    get := func(userID int64) *domain.ArchivePolicy {
+        t.Helper()
+        policy, err := repos.ArchivePolicies.Get(userID)
+        if err != nil {
+            t.Fatalf("Get: %v", err)
+        }
+        return policy
+    }
+    listed := func(userID int64) *domain.ArchivePolicy {
+        t.Helper()
+        policies, err := repos.ArchivePolicies.GetAll()
+        if err != nil {
+            t.Fatalf("GetAll: %v", err)
+        }
+        for i := range policies {
+            if policies[i].UserID == userID {
+                return &policies[i]
+            }
+        }
+        return nil
+    }
+
+    if policy := get(user.ID); policy != nil {
+        t.Errorf("Get before any Save = %+v", policy)
+    }
+
+    // Saving again replaces the policy.
+    for _, days := range []int{7, 30} {
+        policy := &domain.ArchivePolicy{UserID: user.ID, AfterDays: days}
+        if err := repos.ArchivePolicies.Save(policy); err != nil {
+            t.Fatalf("Save: %v", err)
+        }
+        if policy.UpdatedAt.IsZero() {
+            t.Error("Save left UpdatedAt unset")
+        }
+    }
+    if err := repos.ArchivePolicies.Save(&domain.ArchivePolicy{UserID: other.ID, AfterDays: 1}); err != nil {
+        t.Fatalf("Save: %v", err)
+    }
+
+    if policy := get(user.ID); policy == nil || policy.AfterDays != 30 || policy.UpdatedAt.IsZero() {
+        t.Errorf("Get = %+v, want 30 days", policy)
+    }
+    if policy := listed(user.ID); policy == nil || policy.AfterDays != 30 {
+        t.Errorf("GetAll lists %+v for the user, want 30 days", policy)
+    }
+
+    // Deleting is idempotent and leaves other users alone.
+    for i := 0; i < 2; i++ {
+        if err := repos.ArchivePolicies.Delete(user.ID); err != nil {
+            t.Fatalf("Delete: %v", err)
+        }
+    }
+    if policy := get(user.ID); policy != nil {
+        t.Errorf("Get after Delete = %+v", policy)
+    }
+    if listed(user.ID) != nil || listed(other.ID) == nil {
+        t.Error("GetAll after Delete does not list just the other user's policy")
+    }
+}
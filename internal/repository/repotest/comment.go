@@ -0,0 +1,157 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func testComment(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    task := newTask(t, repos, owner.ID, domain.Task{Title: "Discussed"})
+    other := newTask(t, repos, owner.ID, domain.Task{Title: "Quiet"})
+
+    var comments []*domain.Comment
+    for _, author := range []*domain.User{owner, bob} {
+        comment := &domain.Comment{TaskID: task.ID, UserID: author.ID, Body: "from " + author.Username}
+        if err := repos.Comments.Create(comment); err != nil {
+            t.Fatalf("Create: %v", err)
+        }
+        if comment.ID == 0 || comment.CreatedAt.IsZero() || comment.EditedAt != nil {
+            t.Errorf("Create set %+v", comment)
+        }
+        comments = append(comments, comment)
+    }
+
+    got, err := repos.Comments.GetByID(comments[1].ID, task.ID)
+    if err != nil {
+        t.Fatalf("GetByID: %v", err)
+    }
+    if got == nil || got.UserID != bob.ID || got.Username != bob.Username || got.Body != "from "+bob.Username {
+        t.Errorf("GetByID = %+v, want bob's comment with his username", got)
+    }
+    if got, err := repos.Comments.GetByID(comments[1].ID, other.ID); err != nil || got != nil {
+        t.Errorf("GetByID on another task = %+v, %v; want nil, nil", got, err)
+    }
+
+    // Only the author's edit is applied.
+    edit := *comments[1]
+    edit.Body = "edited"
+    if err := repos.Comments.Update(&edit); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if edit.EditedAt == nil {
+        t.Error("Update left EditedAt unset")
+    }
+    forged := edit
+    forged.UserID = owner.ID
+    forged.Body = "forged"
+    if err := repos.Comments.Update(&forged); !errors.Is(err, domain.ErrCommentNotFound) {
+        t.Errorf("Update by someone else: err = %v, want ErrCommentNotFound", err)
+    }
+
+    list, err := repos.Comments.GetByTaskID(task.ID)
+    if err != nil {
+        t.Fatalf("GetByTaskID: %v", err)
+    }
+    if len(list) != 2 || list[0].ID != comments[0].ID || list[1].Body != "edited" || list[1].EditedAt == nil {
+        t.Errorf("GetByTaskID = %+v, want both comments oldest first, the second edited", list)
+    }
+
+    if err := repos.Comments.Delete(comments[0].ID, other.ID); !errors.Is(err, domain.ErrCommentNotFound) {
+        t.Errorf("Delete on another task: err = %v, want ErrCommentNotFound", err)
+    }
+    if err := repos.Comments.Delete(comments[0].ID, task.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if list, err := repos.Comments.GetByTaskID(task.ID); err != nil || len(list) != 1 {
+        t.Errorf("GetByTaskID after Delete = %+v, %v", list, err)
+    }
+}
+
+func testAttachment(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    parent := newTask(t, repos, owner.ID, domain.Task{Title: "Parent"})
+    child := newTask(t, repos, owner.ID, domain.Task{Title: "Child", ParentID: &parent.ID, Position: 1})
+    unrelated := newTask(t, repos, owner.ID, domain.Task{Title: "Unrelated"})
+
+    create := func(taskID, userID int64, size int64) *domain.Attachment {
+        t.Helper()
+        attachment := &domain.Attachment{
+            TaskID:      taskID,
+            UserID:      userID,
+            Filename:    "notes.txt",
+            ContentType: "text/plain",
+            Size:        size,
+            StorageKey:  fmt.Sprintf("key-%d-%d", taskID, time.Now().UnixNano()),
+        }
+        if err := repos.Attachments.Create(attachment); err != nil {
+            t.Fatalf("Create: %v", err)
+        }
+        return attachment
+    }
+    first := create(parent.ID, owner.ID, 100)
+    second := create(parent.ID, bob.ID, 20)
+    onChild := create(child.ID, owner.ID, 3)
+    create(unrelated.ID, owner.ID, 4000)
+    if first.ID == 0 || first.CreatedAt.IsZero() {
+        t.Errorf("Create left the ID or CreatedAt unset: %+v", first)
+    }
+
+    got, err := repos.Attachments.GetByID(first.ID, parent.ID)
+    if err != nil {
+        t.Fatalf("GetByID: %v", err)
+    }
+    if got == nil || *got != (domain.Attachment{
+        ID: first.ID, TaskID: parent.ID, UserID: owner.ID, Filename: "notes.txt", ContentType: "text/plain",
+        Size: 100, StorageKey: first.StorageKey, CreatedAt: got.CreatedAt,
+    }) {
+        t.Errorf("GetByID = %+v, want %+v", got, first)
+    }
+    if got, err := repos.Attachments.GetByID(first.ID, child.ID); err != nil || got != nil {
+        t.Errorf("GetByID on another task = %+v, %v; want nil, nil", got, err)
+    }
+
+    ids := func(attachments []domain.Attachment, err error) []int64 {
+        t.Helper()
+        if err != nil {
+            t.Fatalf("listing attachments: %v", err)
+        }
+        var ids []int64
+        for _, attachment := range attachments {
+            ids = append(ids, attachment.ID)
+        }
+        return ids
+    }
+    if got := ids(repos.Attachments.GetByTaskID(parent.ID)); len(got) != 2 || got[0] != first.ID || got[1] != second.ID {
+        t.Errorf("GetByTaskID = %v, want [%d %d]", got, first.ID, second.ID)
+    }
+
+    // The tree takes in subtasks, also once they are in the trash.
+    if err := repos.Tasks.Delete(child.ID, owner.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if got := ids(repos.Attachments.GetByTaskTree(parent.ID)); len(got) != 3 || got[2] != onChild.ID {
+        t.Errorf("GetByTaskTree = %v, want [%d %d %d]", got, first.ID, second.ID, onChild.ID)
+    }
+
+    for user, want := range map[*domain.User]int64{owner: 4103, bob: 20} {
+        if usage, err := repos.Attachments.UsageByUserID(user.ID); err != nil || usage != want {
+            t.Errorf("UsageByUserID = %d, %v; want %d", usage, err, want)
+        }
+    }
+
+    if err := repos.Attachments.Delete(first.ID, child.ID); !errors.Is(err, domain.ErrAttachmentNotFound) {
+        t.Errorf("Delete on another task: err = %v, want ErrAttachmentNotFound", err)
+    }
+    if err := repos.Attachments.Delete(first.ID, parent.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if usage, _ := repos.Attachments.UsageByUserID(owner.ID); usage != 4003 {
+        t.Errorf("UsageByUserID after Delete = %d, want 4003", usage)
+    }
+}
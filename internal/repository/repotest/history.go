@@ -0,0 +1,114 @@
+package repotest
+
+import (
+	"sync"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+func newVersion(taskID, actorID int64, action domain.HistoryAction, title string) *domain.TaskVersion {
+    return &domain.TaskVersion{
+        TaskID:   taskID,
+        Action:   action,
+        ActorID:  actorID,
+        Changes:  []domain.FieldChange{{Field: "title", Old: "", New: title}},
+        Snapshot: domain.TaskSnapshot{Title: title, Priority: domain.PriorityHigh},
+    }
+}
+
+func testHistoryAppendAndGet(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    task := newTask(t, repos, user.ID, domain.Task{Title: "Tracked"})
+    other := newTask(t, repos, user.ID, domain.Task{Title: "Other"})
+
+    actions := []domain.HistoryAction{domain.HistoryCreated, domain.HistoryUpdated, domain.HistoryReverted}
+    for i, action := range actions {
+        entry := newVersion(task.ID, user.ID, action, string(action))
+        if err := repos.History.Append(entry); err != nil {
+            t.Fatalf("Append: %v", err)
+        }
+        if entry.ID == 0 || entry.Version != i+1 || entry.CreatedAt.IsZero() {
+            t.Errorf("Append %d set ID %d, version %d, created %v", i, entry.ID, entry.Version, entry.CreatedAt)
+        }
+    }
+
+    // Each task counts its own versions.
+    entry := newVersion(other.ID, user.ID, domain.HistoryCreated, "other")
+    if err := repos.History.Append(entry); err != nil {
+        t.Fatalf("Append: %v", err)
+    }
+    if entry.Version != 1 {
+        t.Errorf("first version of another task = %d, want 1", entry.Version)
+    }
+
+    versions, err := repos.History.GetByTaskID(task.ID)
+    if err != nil {
+        t.Fatalf("GetByTaskID: %v", err)
+    }
+    if len(versions) != len(actions) {
+        t.Fatalf("GetByTaskID returned %d versions, want %d", len(versions), len(actions))
+    }
+    for i, version := range versions {
+        if version.Version != i+1 || version.Action != actions[i] || version.ActorID != user.ID || version.TaskID != task.ID {
+            t.Errorf("version %d = %+v", i+1, version)
+        }
+        if version.Snapshot.Title != string(actions[i]) || version.Snapshot.Priority != domain.PriorityHigh {
+            t.Errorf("version %d snapshot = %+v", i+1, version.Snapshot)
+        }
+        if len(version.Changes) != 1 || version.Changes[0].Field != "title" || version.Changes[0].New != string(actions[i]) {
+            t.Errorf("version %d changes = %+v", i+1, version.Changes)
+        }
+    }
+
+    second, err := repos.History.GetVersion(task.ID, 2)
+    if err != nil {
+        t.Fatalf("GetVersion: %v", err)
+    }
+    if second == nil || second.Action != domain.HistoryUpdated || second.ID != versions[1].ID {
+        t.Errorf("GetVersion(2) = %+v", second)
+    }
+    for _, missing := range []int{0, 4} {
+        if entry, err := repos.History.GetVersion(task.ID, missing); err != nil || entry != nil {
+            t.Errorf("GetVersion(%d) = %+v, %v; want nil, nil", missing, entry, err)
+        }
+    }
+}
+
+func testHistoryConcurrentAppend(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    task := newTask(t, repos, user.ID, domain.Task{Title: "Busy"})
+
+    const writers, appends = 4, 5
+    var wg sync.WaitGroup
+    errs := make(chan error, writers*appends)
+    for w := 0; w < writers; w++ {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            for i := 0; i < appends; i++ {
+                if err := repos.History.Append(newVersion(task.ID, user.ID, domain.HistoryUpdated, "edit")); err != nil {
+                    errs <- err
+                }
+            }
+        }()
+    }
+    wg.Wait()
+    close(errs)
+    for err := range errs {
+        t.Errorf("concurrent Append: %v", err)
+    }
+
+    versions, err := repos.History.GetByTaskID(task.ID)
+    if err != nil {
+        t.Fatalf("GetByTaskID: %v", err)
+    }
+    if len(versions) != writers*appends {
+        t.Fatalf("%d versions stored, want %d", len(versions), writers*appends)
+    }
+    for i, version := range versions {
+        if version.Version != i+1 {
+            t.Errorf("versions are not numbered 1 to %d: position %d holds %d", writers*appends, i, version.Version)
+            break
+        }
+    }
+}
@@ -0,0 +1,136 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// newProject creates a project of userID.
+func newProject(t *testing.T, repos Repos, userID int64, name string) *domain.Project {
+    t.Helper()
+
+    project := &domain.Project{UserID: userID, Name: name}
+    if err := repos.Projects.Create(project); err != nil {
+        t.Fatalf("creating project %q: %v", name, err)
+    }
+
+    return project
+}
+
+func projectNames(projects []domain.Project) []string {
+    names := make([]string, len(projects))
+    for i, project := range projects {
+        names[i] = project.Name
+    }
+    return names
+}
+
+func testProjectCreateAndGet(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    other := newUser(t, repos)
+    garden := newProject(t, repos, user.ID, "Garden")
+    if garden.ID == 0 || garden.CreatedAt.IsZero() || garden.UpdatedAt.IsZero() {
+        t.Errorf("Create left the ID or timestamps unset: %+v", garden)
+    }
+    archivedAt := at(2030, time.February, 1, 12)
+    attic := &domain.Project{UserID: user.ID, Name: "Attic", Description: "Boxes", ArchivedAt: &archivedAt}
+    if err := repos.Projects.Create(attic); err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+
+    got, err := repos.Projects.GetByID(attic.ID, user.ID)
+    if err != nil {
+        t.Fatalf("GetByID: %v", err)
+    }
+    if got == nil || got.Name != "Attic" || got.Description != "Boxes" || got.UserID != user.ID ||
+        !sameTime(got.ArchivedAt, &archivedAt) {
+        t.Errorf("GetByID = %+v, want the project as created", got)
+    }
+    if got, err := repos.Projects.GetByID(garden.ID, other.ID); err != nil || got != nil {
+        t.Errorf("GetByID by another user = %+v, %v; want nil, nil", got, err)
+    }
+
+    for includeArchived, want := range map[bool][]string{false: {"Garden"}, true: {"Attic", "Garden"}} {
+        projects, err := repos.Projects.GetAllByUserID(user.ID, includeArchived)
+        if err != nil {
+            t.Fatalf("GetAllByUserID: %v", err)
+        }
+        if names := projectNames(projects); !equalStrings(names, want) {
+            t.Errorf("GetAllByUserID(archived %t) = %v, want %v", includeArchived, names, want)
+        }
+    }
+    if projects, err := repos.Projects.GetAllByUserID(other.ID, true); err != nil || len(projects) != 0 {
+        t.Errorf("another user lists %v, %v", projectNames(projects), err)
+    }
+}
+
+func testProjectUpdate(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    project := newProject(t, repos, user.ID, "Draft")
+
+    archivedAt := at(2030, time.March, 1, 8)
+    project.Name, project.Description, project.ArchivedAt = "Final", "Done planning", &archivedAt
+    if err := repos.Projects.Update(project); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    got, err := repos.Projects.GetByID(project.ID, user.ID)
+    if err != nil || got == nil {
+        t.Fatalf("GetByID = %+v, %v", got, err)
+    }
+    if got.Name != "Final" || got.Description != "Done planning" || !sameTime(got.ArchivedAt, &archivedAt) {
+        t.Errorf("project after Update = %+v", got)
+    }
+
+    stolen := *project
+    stolen.UserID = newUser(t, repos).ID
+    if err := repos.Projects.Update(&stolen); !errors.Is(err, domain.ErrProjectNotFound) {
+        t.Errorf("Update by another user: err = %v, want ErrProjectNotFound", err)
+    }
+}
+
+func testProjectDelete(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+
+    // Deleting a project alone leaves its tasks outside any project.
+    kept := newProject(t, repos, user.ID, "Kept tasks")
+    task := newTask(t, repos, user.ID, domain.Task{Title: "stays", ProjectID: &kept.ID})
+    if err := repos.Projects.Delete(kept.ID, user.ID, false); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if got, err := repos.Projects.GetByID(kept.ID, user.ID); err != nil || got != nil {
+        t.Errorf("GetByID after Delete = %+v, %v; want nil, nil", got, err)
+    }
+    if got := mustGetTask(t, repos, task.ID, user.ID); got.ProjectID != nil {
+        t.Errorf("task of a deleted project still in project %d", *got.ProjectID)
+    }
+
+    // Deleting it with its tasks trashes them and their subtasks, wherever
+    // those are.
+    dropped := newProject(t, repos, user.ID, "Dropped tasks")
+    parent := newTask(t, repos, user.ID, domain.Task{Title: "parent", ProjectID: &dropped.ID})
+    newTask(t, repos, user.ID, domain.Task{Title: "child", ParentID: &parent.ID, Position: 1})
+    newTask(t, repos, user.ID, domain.Task{Title: "elsewhere"})
+    if err := repos.Projects.Delete(dropped.ID, user.ID, true); err != nil {
+        t.Fatalf("Delete with tasks: %v", err)
+    }
+    if tasks := list(t, repos, user.ID, listAll()); !sameTitles(tasks, "elsewhere", "stays") {
+        t.Errorf("listed %v after deleting a project with its tasks, want [elsewhere stays]", titles(tasks))
+    }
+    trash, err := repos.Tasks.GetTrash(user.ID)
+    if err != nil {
+        t.Fatalf("GetTrash: %v", err)
+    }
+    if !sameTitles(trash, "parent") {
+        t.Errorf("GetTrash = %v, want [parent]", titles(trash))
+    }
+
+    if err := repos.Projects.Delete(dropped.ID, user.ID, false); !errors.Is(err, domain.ErrProjectNotFound) {
+        t.Errorf("deleting a deleted project: err = %v, want ErrProjectNotFound", err)
+    }
+    other := newProject(t, repos, user.ID, "Not yours")
+    if err := repos.Projects.Delete(other.ID, newUser(t, repos).ID, true); !errors.Is(err, domain.ErrProjectNotFound) {
+        t.Errorf("Delete by another user: err = %v, want ErrProjectNotFound", err)
+    }
+}
@@ -0,0 +1,145 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func newReminder(t *testing.T, repos Repos, taskID, userID int64, remindAt time.Time) *domain.Reminder {
+    t.Helper()
+
+    reminder := &domain.Reminder{TaskID: taskID, UserID: userID, RemindAt: remindAt}
+    if err := repos.Reminders.Create(reminder); err != nil {
+        t.Fatalf("creating reminder: %v", err)
+    }
+
+    return reminder
+}
+
+func reminders(t *testing.T, repos Repos, taskID, userID int64) []domain.Reminder {
+    t.Helper()
+
+    list, err := repos.Reminders.GetByTaskID(taskID, userID)
+    if err != nil {
+        t.Fatalf("GetByTaskID: %v", err)
+    }
+    return list
+}
+
+func testReminderCreateAndList(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    task := newTask(t, repos, owner.ID, domain.Task{Title: "Remembered"})
+
+    late := newReminder(t, repos, task.ID, owner.ID, at(2030, time.April, 2, 9))
+    early := newReminder(t, repos, task.ID, owner.ID, at(2030, time.April, 1, 9))
+    newReminder(t, repos, task.ID, bob.ID, at(2030, time.April, 1, 8))
+    if late.ID == 0 || late.CreatedAt.IsZero() {
+        t.Errorf("Create left the ID or CreatedAt unset: %+v", late)
+    }
+
+    // Reminders are personal: each user lists their own, soonest first.
+    list := reminders(t, repos, task.ID, owner.ID)
+    if len(list) != 2 || list[0].ID != early.ID || list[1].ID != late.ID {
+        t.Fatalf("GetByTaskID = %+v, want the early then the late reminder", list)
+    }
+    if r := list[0]; !r.RemindAt.Equal(early.RemindAt) || r.SentAt != nil || r.Attempts != 0 || r.LastError != "" {
+        t.Errorf("reminder = %+v, want it as created", r)
+    }
+
+    if err := repos.Reminders.Delete(early.ID, task.ID, bob.ID); !errors.Is(err, domain.ErrReminderNotFound) {
+        t.Errorf("Delete by another user: err = %v, want ErrReminderNotFound", err)
+    }
+    if err := repos.Reminders.Delete(early.ID, task.ID, owner.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if list := reminders(t, repos, task.ID, owner.ID); len(list) != 1 || list[0].ID != late.ID {
+        t.Errorf("GetByTaskID after Delete = %+v", list)
+    }
+}
+
+// testReminderProcessDue processes every due reminder, as the database may
+// hold some from other runs, and looks at the ones it created.
+func testReminderProcessDue(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    now := time.Now().UTC().Truncate(time.Second)
+    dueAt := now.Add(time.Hour)
+    task := newTask(t, repos, owner.ID, domain.Task{Title: "Due soon", DueAt: &dueAt})
+    trashed := newTask(t, repos, owner.ID, domain.Task{Title: "Trashed"})
+    unshared := newTask(t, repos, owner.ID, domain.Task{Title: "Unshared"})
+
+    due := newReminder(t, repos, task.ID, owner.ID, now.Add(-time.Minute))
+    failing := newReminder(t, repos, task.ID, owner.ID, now.Add(-2*time.Minute))
+    later := newReminder(t, repos, task.ID, owner.ID, now.Add(time.Hour))
+    inTrash := newReminder(t, repos, trashed.ID, owner.ID, now.Add(-time.Minute))
+    if err := repos.Tasks.Delete(trashed.ID, owner.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if err := repos.Shares.SaveTaskShare(unshared.ID, bob.ID, domain.PermissionViewer); err != nil {
+        t.Fatalf("SaveTaskShare: %v", err)
+    }
+    lostAccess := newReminder(t, repos, unshared.ID, bob.ID, now.Add(-time.Minute))
+    if err := repos.Shares.DeleteTaskShare(unshared.ID, bob.ID, nil); err != nil {
+        t.Fatalf("DeleteTaskShare: %v", err)
+    }
+
+    const maxAttempts = 2
+    delivered := map[int64]int{}
+    process := func() {
+        t.Helper()
+        err := repos.Reminders.ProcessDue(now, 1000, maxAttempts, time.Minute, func(n domain.Notification) error {
+            delivered[n.Reminder.ID]++
+            if n.Reminder.ID == due.ID && (n.TaskTitle != "Due soon" || !sameTime(n.TaskDueAt, &dueAt) || n.TaskDone) {
+                t.Errorf("notification = %+v, want the task's title and due date", n)
+            }
+            if n.Reminder.ID == failing.ID {
+                return errors.New("webhook down")
+            }
+            return nil
+        })
+        if err != nil {
+            t.Fatalf("ProcessDue: %v", err)
+        }
+    }
+
+    // The failing reminder is tried until it runs out of attempts; the
+    // delivered one is sent once.
+    for i := 0; i < maxAttempts+1; i++ {
+        process()
+    }
+    for _, want := range []struct {
+        reminder *domain.Reminder
+        name     string
+        count    int
+    }{
+        {due, "due", 1},
+        {failing, "failing", maxAttempts},
+        {later, "not yet due", 0},
+        {inTrash, "of a task in the trash", 0},
+        {lostAccess, "of a task no longer shared", 0},
+    } {
+        if got := delivered[want.reminder.ID]; got != want.count {
+            t.Errorf("reminder %s delivered %d times, want %d", want.name, got, want.count)
+        }
+    }
+
+    for _, r := range reminders(t, repos, task.ID, owner.ID) {
+        switch r.ID {
+        case due.ID:
+            if r.SentAt == nil || r.Attempts != 1 || r.LastError != "" {
+                t.Errorf("delivered reminder = %+v, want sent after one attempt", r)
+            }
+        case failing.ID:
+            if r.SentAt != nil || r.Attempts != maxAttempts || r.LastError != "webhook down" {
+                t.Errorf("failing reminder = %+v, want %d attempts and the error", r, maxAttempts)
+            }
+        case later.ID:
+            if r.SentAt != nil || r.Attempts != 0 {
+                t.Errorf("reminder not yet due = %+v", r)
+            }
+        }
+    }
+}
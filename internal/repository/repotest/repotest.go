@@ -0,0 +1,179 @@
+// Package repotest is the conformance suite the repositories of every
+// storage backend must pass, so that the backends stay interchangeable.
+package repotest
+
+import (
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/migrations"
+	"todo-app/internal/pkg/migrate"
+)
+
+// Repos are the repositories of one backend under test.
+type Repos struct {
+    Users           domain.UserRepository
+    Tasks           domain.TaskRepository
+    History         domain.TaskHistoryRepository
+    Projects        domain.ProjectRepository
+    Tags            domain.TagRepository
+    Shares          domain.ShareRepository
+    Assignments     domain.AssignmentRepository
+    Comments        domain.CommentRepository
+    Attachments     domain.AttachmentRepository
+    Reminders       domain.ReminderRepository
+    RefreshTokens   domain.RefreshTokenRepository
+    Revocations     domain.TokenRevocationRepository
+    ArchivePolicies domain.ArchivePolicyRepository
+}
+
+// Run runs the suite. open is called once per test for the repositories to
+// test. It may hand out the same database every time: the tests only look
+// at the users they create, whose names are unique across runs.
+func Run(t *testing.T, open func(t *testing.T) Repos) {
+    tests := []struct {
+        name string
+        run  func(t *testing.T, repos Repos)
+    }{
+        {"UserCreateAndGet", testUserCreateAndGet},
+        {"UserUsernameTaken", testUserUsernameTaken},
+        {"UserUpdatePassword", testUserUpdatePassword},
+        {"TaskCreateAndGet", testTaskCreateAndGet},
+        {"TaskUpdate", testTaskUpdate},
+        {"TaskOwnerScoping", testTaskOwnerScoping},
+        {"TaskSubtasks", testTaskSubtasks},
+        {"TaskListFilters", testTaskListFilters},
+        {"TaskListPaging", testTaskListPaging},
+        {"TaskArchiving", testTaskArchiving},
+        {"TaskTrash", testTaskTrash},
+        {"HistoryAppendAndGet", testHistoryAppendAndGet},
+        {"HistoryConcurrentAppend", testHistoryConcurrentAppend},
+        {"ProjectCreateAndGet", testProjectCreateAndGet},
+        {"ProjectUpdate", testProjectUpdate},
+        {"ProjectDelete", testProjectDelete},
+        {"TagCreateAndGet", testTagCreateAndGet},
+        {"TagAttach", testTagAttach},
+        {"ShareSaveAndList", testShareSaveAndList},
+        {"ShareDeleteUnassigns", testShareDeleteUnassigns},
+        {"ShareVisibility", testShareVisibility},
+        {"Assignment", testAssignment},
+        {"Comment", testComment},
+        {"Attachment", testAttachment},
+        {"ReminderCreateAndList", testReminderCreateAndList},
+        {"ReminderProcessDue", testReminderProcessDue},
+        {"RefreshToken", testRefreshToken},
+        {"TokenRevocation", testTokenRevocation},
+        {"ArchivePolicy", testArchivePolicy},
+    }
+
+    for _, test := range tests {
+        t.Run(test.name, func(t *testing.T) {
+            test.run(t, open(t))
+        })
+    }
+}
+
+// Migrate brings db up to date with the migrations of dialect, named as
+// migrations.For knows it.
+func Migrate(t *testing.T, db *sql.DB, dialect migrate.Dialect, name string) {
+    t.Helper()
+
+    files, err := migrations.For(name)
+    if err != nil {
+        t.Fatalf("loading %s migrations: %v", name, err)
+    }
+    migrator, err := migrate.New(db, dialect, files)
+    if err != nil {
+        t.Fatalf("loading %s migrations: %v", name, err)
+    }
+    if err := migrator.Up(); err != nil {
+        t.Fatalf("migrating %s: %v", name, err)
+    }
+}
+
+var userSeq int64
+
+// newUser creates a user whose name no earlier run has used.
+func newUser(t *testing.T, repos Repos) *domain.User {
+    t.Helper()
+
+    user := &domain.User{
+        Username: fmt.Sprintf("user%d-%d", time.Now().UnixNano(), atomic.AddInt64(&userSeq, 1)),
+        Password: "hash",
+    }
+    if err := repos.Users.Create(user); err != nil {
+        t.Fatalf("creating user: %v", err)
+    }
+
+    return user
+}
+
+// newTask creates task for userID, with a medium priority unless set.
+func newTask(t *testing.T, repos Repos, userID int64, task domain.Task) *domain.Task {
+    t.Helper()
+
+    task.UserID = userID
+    if task.Priority == domain.PriorityNone {
+        task.Priority = domain.PriorityMedium
+    }
+    if err := repos.Tasks.Create(&task); err != nil {
+        t.Fatalf("creating task %q: %v", task.Title, err)
+    }
+
+    return &task
+}
+
+// mustGetTask returns a task of userID that must exist.
+func mustGetTask(t *testing.T, repos Repos, id, userID int64) *domain.Task {
+    t.Helper()
+
+    task, err := repos.Tasks.GetByID(id, userID)
+    if err != nil {
+        t.Fatalf("getting task %d: %v", id, err)
+    }
+    if task == nil {
+        t.Fatalf("task %d not found", id)
+    }
+
+    return task
+}
+
+// titles returns the titles of tasks in order.
+func titles(tasks []domain.Task) []string {
+    names := make([]string, len(tasks))
+    for i, task := range tasks {
+        names[i] = task.Title
+    }
+    return names
+}
+
+func sameTitles(got []domain.Task, want ...string) bool {
+    return equalStrings(titles(got), want)
+}
+
+func equalStrings(a, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
+
+// at returns a time every backend stores without losing precision.
+func at(year int, month time.Month, day, hour int) time.Time {
+    return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func sameTime(a, b *time.Time) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return a.Equal(*b)
+}
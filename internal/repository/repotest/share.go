@@ -0,0 +1,219 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+// assign assigns a task of ownerID to assigneeID, as ownerID.
+func assign(t *testing.T, repos Repos, ownerID, taskID int64, assigneeID *int64) *domain.Assignment {
+    t.Helper()
+
+    current := mustGetTask(t, repos, taskID, ownerID)
+    assignment := &domain.Assignment{
+        TaskID:     taskID,
+        AssignedBy: ownerID,
+        FromUserID: current.AssigneeID,
+        ToUserID:   assigneeID,
+    }
+    if err := repos.Assignments.Assign(ownerID, assignment); err != nil {
+        t.Fatalf("Assign: %v", err)
+    }
+
+    return assignment
+}
+
+func testShareSaveAndList(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    carol := newUser(t, repos)
+    task := newTask(t, repos, owner.ID, domain.Task{Title: "Shared"})
+    project := newProject(t, repos, owner.ID, "Shared project")
+
+    kinds := []struct {
+        name   string
+        id     int64
+        save   func(id, userID int64, role domain.Permission) error
+        delete func(id, userID int64, unassign []domain.Assignment) error
+        list   func(id int64) ([]domain.Share, error)
+        roles  func(userID int64, ids []int64) (map[int64]domain.Permission, error)
+    }{
+        {"task", task.ID, repos.Shares.SaveTaskShare, repos.Shares.DeleteTaskShare, repos.Shares.GetTaskShares, repos.Shares.GetTaskRoles},
+        {"project", project.ID, repos.Shares.SaveProjectShare, repos.Shares.DeleteProjectShare, repos.Shares.GetProjectShares, repos.Shares.GetProjectRoles},
+    }
+
+    for _, kind := range kinds {
+        // Saving a share again replaces its role.
+        for _, share := range []struct {
+            userID int64
+            role   domain.Permission
+        }{{bob.ID, domain.PermissionViewer}, {carol.ID, domain.PermissionViewer}, {bob.ID, domain.PermissionEditor}} {
+            if err := kind.save(kind.id, share.userID, share.role); err != nil {
+                t.Fatalf("%s: saving share: %v", kind.name, err)
+            }
+        }
+
+        shares, err := kind.list(kind.id)
+        if err != nil {
+            t.Fatalf("%s: listing shares: %v", kind.name, err)
+        }
+        if len(shares) != 2 || shares[0].UserID != bob.ID || shares[0].Username != bob.Username ||
+            shares[0].Role != domain.PermissionEditor || shares[0].CreatedAt.IsZero() ||
+            shares[1].UserID != carol.ID || shares[1].Role != domain.PermissionViewer {
+            t.Errorf("%s: shares = %+v, want bob as editor then carol as viewer", kind.name, shares)
+        }
+
+        roles, err := kind.roles(bob.ID, []int64{kind.id, -1})
+        if err != nil {
+            t.Fatalf("%s: getting roles: %v", kind.name, err)
+        }
+        if len(roles) != 1 || roles[kind.id] != domain.PermissionEditor {
+            t.Errorf("%s: roles = %v, want editor on %d only", kind.name, roles, kind.id)
+        }
+        if roles, err := kind.roles(bob.ID, nil); err != nil || len(roles) != 0 {
+            t.Errorf("%s: roles of no IDs = %v, %v", kind.name, roles, err)
+        }
+
+        if err := kind.delete(kind.id, carol.ID, nil); err != nil {
+            t.Fatalf("%s: deleting share: %v", kind.name, err)
+        }
+        if err := kind.delete(kind.id, carol.ID, nil); !errors.Is(err, domain.ErrShareNotFound) {
+            t.Errorf("%s: deleting a deleted share: err = %v, want ErrShareNotFound", kind.name, err)
+        }
+        if shares, err := kind.list(kind.id); err != nil || len(shares) != 1 || shares[0].UserID != bob.ID {
+            t.Errorf("%s: shares after deleting one = %+v, %v", kind.name, shares, err)
+        }
+    }
+}
+
+func testShareDeleteUnassigns(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    assigned := newTask(t, repos, owner.ID, domain.Task{Title: "Assigned"})
+    unassigned := newTask(t, repos, owner.ID, domain.Task{Title: "Unassigned"})
+    for _, task := range []*domain.Task{assigned, unassigned} {
+        if err := repos.Shares.SaveTaskShare(task.ID, bob.ID, domain.PermissionEditor); err != nil {
+            t.Fatalf("SaveTaskShare: %v", err)
+        }
+    }
+    assign(t, repos, owner.ID, assigned.ID, &bob.ID)
+
+    for _, task := range []*domain.Task{assigned, unassigned} {
+        unassign := []domain.Assignment{{TaskID: task.ID, AssignedBy: owner.ID, FromUserID: &bob.ID}}
+        if err := repos.Shares.DeleteTaskShare(task.ID, bob.ID, unassign); err != nil {
+            t.Fatalf("DeleteTaskShare: %v", err)
+        }
+
+        // Only the task bob was still assigned records the change.
+        recorded := unassign[0]
+        if task == assigned && (recorded.ID == 0 || recorded.CreatedAt.IsZero()) {
+            t.Errorf("the unassignment of %q was not recorded: %+v", task.Title, recorded)
+        }
+        if task == unassigned && recorded.ID != 0 {
+            t.Errorf("an unassignment of %q was recorded though bob was not assigned: %+v", task.Title, recorded)
+        }
+
+        if got := mustGetTask(t, repos, task.ID, owner.ID); got.AssigneeID != nil {
+            t.Errorf("%q still assigned to %d after its share was removed", task.Title, *got.AssigneeID)
+        }
+        history, err := repos.Assignments.GetByTaskID(task.ID)
+        if err != nil {
+            t.Fatalf("GetByTaskID: %v", err)
+        }
+        if want := map[*domain.Task]int{assigned: 2, unassigned: 0}[task]; len(history) != want {
+            t.Errorf("%q has %d assignments, want %d", task.Title, len(history), want)
+        }
+    }
+}
+
+// testShareVisibility checks that a share of a task, of its parent or of
+// its project lets a user see it, and that nothing else does.
+func testShareVisibility(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    project := newProject(t, repos, owner.ID, "Shared project")
+    shared := newTask(t, repos, owner.ID, domain.Task{Title: "shared"})
+    parent := newTask(t, repos, owner.ID, domain.Task{Title: "shared parent"})
+    newTask(t, repos, owner.ID, domain.Task{Title: "subtask", ParentID: &parent.ID, Position: 1})
+    inProject := newTask(t, repos, owner.ID, domain.Task{Title: "in project", ProjectID: &project.ID})
+    newTask(t, repos, owner.ID, domain.Task{Title: "private"})
+
+    for _, share := range []func() error{
+        func() error { return repos.Shares.SaveTaskShare(shared.ID, bob.ID, domain.PermissionViewer) },
+        func() error { return repos.Shares.SaveTaskShare(parent.ID, bob.ID, domain.PermissionViewer) },
+        func() error { return repos.Shares.SaveProjectShare(project.ID, bob.ID, domain.PermissionViewer) },
+    } {
+        if err := share(); err != nil {
+            t.Fatalf("sharing: %v", err)
+        }
+    }
+
+    if tasks := list(t, repos, bob.ID, listAll()); !sameTitles(tasks, "in project", "shared", "shared parent", "subtask") {
+        t.Errorf("bob lists %v, want every task but the private one", titles(tasks))
+    }
+
+    if err := repos.Shares.DeleteProjectShare(project.ID, bob.ID, nil); err != nil {
+        t.Fatalf("DeleteProjectShare: %v", err)
+    }
+    if err := repos.Shares.DeleteTaskShare(parent.ID, bob.ID, nil); err != nil {
+        t.Fatalf("DeleteTaskShare: %v", err)
+    }
+    if tasks := list(t, repos, bob.ID, listAll()); !sameTitles(tasks, "shared") {
+        t.Errorf("bob lists %v after two shares were removed, want [shared]", titles(tasks))
+    }
+    if got, err := repos.Tasks.GetByID(inProject.ID, bob.ID); err != nil || got != nil {
+        t.Errorf("GetByID of a task no longer shared = %+v, %v; want nil, nil", got, err)
+    }
+}
+
+func testAssignment(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    bob := newUser(t, repos)
+    carol := newUser(t, repos)
+    task := newTask(t, repos, owner.ID, domain.Task{Title: "Chore"})
+
+    first := assign(t, repos, owner.ID, task.ID, &bob.ID)
+    if first.ID == 0 || first.CreatedAt.IsZero() {
+        t.Errorf("Assign left the ID or CreatedAt unset: %+v", first)
+    }
+    assign(t, repos, owner.ID, task.ID, &carol.ID)
+    if got := mustGetTask(t, repos, task.ID, owner.ID); got.AssigneeID == nil || *got.AssigneeID != carol.ID {
+        t.Errorf("assignee = %v, want carol", got.AssigneeID)
+    }
+    assign(t, repos, owner.ID, task.ID, nil)
+    if got := mustGetTask(t, repos, task.ID, owner.ID); got.AssigneeID != nil {
+        t.Errorf("assignee = %d after unassigning", *got.AssigneeID)
+    }
+
+    history, err := repos.Assignments.GetByTaskID(task.ID)
+    if err != nil {
+        t.Fatalf("GetByTaskID: %v", err)
+    }
+    if len(history) != 3 {
+        t.Fatalf("%d assignments, want 3: %+v", len(history), history)
+    }
+    same := func(a, b *int64) bool { return a == nil && b == nil || a != nil && b != nil && *a == *b }
+    for i, want := range []struct{ from, to *int64 }{{nil, &bob.ID}, {&bob.ID, &carol.ID}, {&carol.ID, nil}} {
+        if a := history[i]; !same(a.FromUserID, want.from) || !same(a.ToUserID, want.to) ||
+            a.AssignedBy != owner.ID || a.TaskID != task.ID {
+            t.Errorf("assignment %d = %+v", i+1, a)
+        }
+    }
+
+    // Only the owner can assign, and not a task in the trash.
+    err = repos.Assignments.Assign(bob.ID, &domain.Assignment{TaskID: task.ID, AssignedBy: bob.ID, ToUserID: &bob.ID})
+    if !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Assign by another user: err = %v, want ErrTaskNotFound", err)
+    }
+    if err := repos.Tasks.Delete(task.ID, owner.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    err = repos.Assignments.Assign(owner.ID, &domain.Assignment{TaskID: task.ID, AssignedBy: owner.ID, ToUserID: &bob.ID})
+    if !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Assign of a task in the trash: err = %v, want ErrTaskNotFound", err)
+    }
+    if history, _ := repos.Assignments.GetByTaskID(task.ID); len(history) != 3 {
+        t.Errorf("a refused Assign was recorded: %d assignments", len(history))
+    }
+}
@@ -0,0 +1,143 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+// newTag creates a tag of userID.
+func newTag(t *testing.T, repos Repos, userID int64, name string) *domain.Tag {
+    t.Helper()
+
+    tag := &domain.Tag{UserID: userID, Name: name}
+    if err := repos.Tags.Create(tag); err != nil {
+        t.Fatalf("creating tag %q: %v", name, err)
+    }
+
+    return tag
+}
+
+func tagNames(tags []domain.Tag) []string {
+    names := make([]string, len(tags))
+    for i, tag := range tags {
+        names[i] = tag.Name
+    }
+    return names
+}
+
+func testTagCreateAndGet(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    other := newUser(t, repos)
+    work := newTag(t, repos, user.ID, "work")
+    home := newTag(t, repos, user.ID, "home")
+    if work.ID == 0 || work.CreatedAt.IsZero() || work.UpdatedAt.IsZero() {
+        t.Errorf("Create left the ID or timestamps unset: %+v", work)
+    }
+
+    if err := repos.Tags.Create(&domain.Tag{UserID: user.ID, Name: "work"}); !errors.Is(err, domain.ErrTagExists) {
+        t.Errorf("creating a second tag %q: err = %v, want ErrTagExists", "work", err)
+    }
+    // Names are unique per user only.
+    newTag(t, repos, other.ID, "work")
+
+    got, err := repos.Tags.GetByID(work.ID, user.ID)
+    if err != nil || got == nil || got.Name != "work" || got.UserID != user.ID {
+        t.Errorf("GetByID = %+v, %v; want the tag as created", got, err)
+    }
+    if got, err := repos.Tags.GetByID(work.ID, other.ID); err != nil || got != nil {
+        t.Errorf("GetByID by another user = %+v, %v; want nil, nil", got, err)
+    }
+
+    tags, err := repos.Tags.GetAllByUserID(user.ID)
+    if err != nil {
+        t.Fatalf("GetAllByUserID: %v", err)
+    }
+    if names := tagNames(tags); !equalStrings(names, []string{"home", "work"}) {
+        t.Errorf("GetAllByUserID = %v, want [home work]", names)
+    }
+
+    home.Name = "work"
+    if err := repos.Tags.Update(home); !errors.Is(err, domain.ErrTagExists) {
+        t.Errorf("renaming to a taken name: err = %v, want ErrTagExists", err)
+    }
+    home.Name = "house"
+    if err := repos.Tags.Update(home); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if got, _ := repos.Tags.GetByID(home.ID, user.ID); got == nil || got.Name != "house" {
+        t.Errorf("tag after Update = %+v", got)
+    }
+    stolen := *home
+    stolen.UserID = other.ID
+    if err := repos.Tags.Update(&stolen); !errors.Is(err, domain.ErrTagNotFound) {
+        t.Errorf("Update by another user: err = %v, want ErrTagNotFound", err)
+    }
+
+    if err := repos.Tags.Delete(work.ID, other.ID); !errors.Is(err, domain.ErrTagNotFound) {
+        t.Errorf("Delete by another user: err = %v, want ErrTagNotFound", err)
+    }
+    if err := repos.Tags.Delete(work.ID, user.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if got, err := repos.Tags.GetByID(work.ID, user.ID); err != nil || got != nil {
+        t.Errorf("GetByID after Delete = %+v, %v; want nil, nil", got, err)
+    }
+}
+
+func testTagAttach(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    task := newTask(t, repos, user.ID, domain.Task{Title: "Tagged"})
+    bare := newTask(t, repos, user.ID, domain.Task{Title: "Bare"})
+    urgent := newTag(t, repos, user.ID, "urgent")
+    errand := newTag(t, repos, user.ID, "errand")
+
+    tagsOf := func(taskID int64) []string {
+        t.Helper()
+        tags, err := repos.Tags.GetByTaskIDs([]int64{task.ID, bare.ID})
+        if err != nil {
+            t.Fatalf("GetByTaskIDs: %v", err)
+        }
+        return tagNames(tags[taskID])
+    }
+
+    // Attaching twice is the same as once.
+    for _, tag := range []*domain.Tag{urgent, errand, urgent} {
+        if err := repos.Tags.AttachToTask(task.ID, tag.ID); err != nil {
+            t.Fatalf("AttachToTask: %v", err)
+        }
+    }
+    if names := tagsOf(task.ID); !equalStrings(names, []string{"errand", "urgent"}) {
+        t.Errorf("tags = %v, want [errand urgent]", names)
+    }
+    if names := tagsOf(bare.ID); len(names) != 0 {
+        t.Errorf("tags of an untagged task = %v", names)
+    }
+
+    filter := listAll()
+    filter.Tags, filter.TagMatch = []string{"urgent"}, domain.TagMatchAny
+    if tasks := list(t, repos, user.ID, filter); !sameTitles(tasks, "Tagged") {
+        t.Errorf("tasks tagged urgent = %v, want [Tagged]", titles(tasks))
+    }
+
+    for i := 0; i < 2; i++ {
+        if err := repos.Tags.DetachFromTask(task.ID, urgent.ID); err != nil {
+            t.Fatalf("DetachFromTask: %v", err)
+        }
+    }
+    if names := tagsOf(task.ID); !equalStrings(names, []string{"errand"}) {
+        t.Errorf("tags after DetachFromTask = %v, want [errand]", names)
+    }
+
+    // A deleted tag comes off its tasks.
+    if err := repos.Tags.Delete(errand.ID, user.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if names := tagsOf(task.ID); len(names) != 0 {
+        t.Errorf("tags after deleting the tag = %v", names)
+    }
+
+    if tags, err := repos.Tags.GetByTaskIDs(nil); err != nil || len(tags) != 0 {
+        t.Errorf("GetByTaskIDs(nil) = %v, %v", tags, err)
+    }
+}
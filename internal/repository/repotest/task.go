@@ -0,0 +1,429 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// listAll lists every task a user can see that is not archived, by title.
+func listAll() domain.TaskFilter {
+    return domain.TaskFilter{
+        Sort:     domain.TaskSortTitle,
+        Order:    domain.SortAsc,
+        Archived: domain.ArchivedExclude,
+        Limit:    100,
+    }
+}
+
+func list(t *testing.T, repos Repos, userID int64, filter domain.TaskFilter) []domain.Task {
+    t.Helper()
+
+    tasks, err := repos.Tasks.GetAllByUserID(userID, filter)
+    if err != nil {
+        t.Fatalf("GetAllByUserID: %v", err)
+    }
+    return tasks
+}
+
+func testTaskCreateAndGet(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    dueAt := at(2030, time.March, 14, 9)
+    created := newTask(t, repos, user.ID, domain.Task{
+        Title:        "Water the plants",
+        Description:  "The ones on the balcony",
+        Priority:     domain.PriorityHigh,
+        DueAt:        &dueAt,
+        Recurrence:   "FREQ=WEEKLY",
+        RecurrenceTZ: "Europe/Berlin",
+    })
+    if created.ID == 0 {
+        t.Fatal("Create left the ID unset")
+    }
+    if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+        t.Error("Create left the timestamps unset")
+    }
+
+    for name, get := range map[string]func() (*domain.Task, error){
+        "GetByID":  func() (*domain.Task, error) { return repos.Tasks.GetByID(created.ID, user.ID) },
+        "FindByID": func() (*domain.Task, error) { return repos.Tasks.FindByID(created.ID) },
+    } {
+        task, err := get()
+        if err != nil {
+            t.Fatalf("%s: %v", name, err)
+        }
+        if task == nil {
+            t.Fatalf("%s: task not found", name)
+        }
+        if task.UserID != user.ID || task.Title != created.Title || task.Description != created.Description ||
+            task.Priority != created.Priority || !sameTime(task.DueAt, &dueAt) || task.Done ||
+            task.Recurrence != created.Recurrence || task.RecurrenceTZ != created.RecurrenceTZ ||
+            task.ParentID != nil || task.ProjectID != nil || task.AssigneeID != nil ||
+            task.ArchivedAt != nil || task.DeletedAt != nil {
+            t.Errorf("%s = %+v, want the task as created", name, task)
+        }
+    }
+
+    missing, err := repos.Tasks.GetByID(-1, user.ID)
+    if err != nil || missing != nil {
+        t.Errorf("GetByID of an unknown task = %+v, %v; want nil, nil", missing, err)
+    }
+}
+
+func testTaskUpdate(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    task := newTask(t, repos, user.ID, domain.Task{Title: "Draft"})
+
+    completedAt := at(2030, time.January, 2, 15)
+    task.Title = "Final"
+    task.Description = "Now with details"
+    task.Done = true
+    task.CompletedAt = &completedAt
+    task.Priority = domain.PriorityUrgent
+    if err := repos.Tasks.Update(task); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+
+    stored := mustGetTask(t, repos, task.ID, user.ID)
+    if stored.Title != "Final" || stored.Description != "Now with details" || !stored.Done ||
+        !sameTime(stored.CompletedAt, &completedAt) || stored.Priority != domain.PriorityUrgent || stored.DueAt != nil {
+        t.Errorf("task after Update = %+v", stored)
+    }
+
+    other := newUser(t, repos)
+    stolen := *task
+    stolen.UserID = other.ID
+    stolen.Title = "Stolen"
+    if err := repos.Tasks.Update(&stolen); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Update by another user: err = %v, want ErrTaskNotFound", err)
+    }
+    if stored := mustGetTask(t, repos, task.ID, user.ID); stored.Title != "Final" {
+        t.Errorf("title = %q after another user's Update", stored.Title)
+    }
+}
+
+func testTaskOwnerScoping(t *testing.T, repos Repos) {
+    owner := newUser(t, repos)
+    other := newUser(t, repos)
+    task := newTask(t, repos, owner.ID, domain.Task{Title: "Private"})
+
+    if got, err := repos.Tasks.GetByID(task.ID, other.ID); err != nil || got != nil {
+        t.Errorf("GetByID by another user = %+v, %v; want nil, nil", got, err)
+    }
+    if tasks := list(t, repos, other.ID, listAll()); len(tasks) != 0 {
+        t.Errorf("another user lists %v", titles(tasks))
+    }
+    if err := repos.Tasks.Delete(task.ID, other.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Delete by another user: err = %v, want ErrTaskNotFound", err)
+    }
+    now := time.Now()
+    if err := repos.Tasks.SetArchivedAt(task.ID, other.ID, &now); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("SetArchivedAt by another user: err = %v, want ErrTaskNotFound", err)
+    }
+
+    if tasks := list(t, repos, owner.ID, listAll()); !sameTitles(tasks, "Private") {
+        t.Errorf("owner lists %v, want [Private]", titles(tasks))
+    }
+}
+
+func testTaskSubtasks(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    parent := newTask(t, repos, user.ID, domain.Task{Title: "Move house"})
+    lonely := newTask(t, repos, user.ID, domain.Task{Title: "Lonely"})
+
+    var subtasks []*domain.Task
+    for i, title := range []string{"Pack", "Load", "Unpack"} {
+        subtasks = append(subtasks, newTask(t, repos, user.ID, domain.Task{
+            Title:    title,
+            ParentID: &parent.ID,
+            Position: i + 1,
+            Done:     title == "Pack",
+        }))
+    }
+
+    got, err := repos.Tasks.GetSubtasks(parent.ID, user.ID)
+    if err != nil {
+        t.Fatalf("GetSubtasks: %v", err)
+    }
+    if !sameTitles(got, "Pack", "Load", "Unpack") {
+        t.Errorf("GetSubtasks = %v, want [Pack Load Unpack]", titles(got))
+    }
+
+    ids := []int64{subtasks[2].ID, subtasks[0].ID, subtasks[1].ID}
+    if err := repos.Tasks.ReorderSubtasks(parent.ID, user.ID, ids); err != nil {
+        t.Fatalf("ReorderSubtasks: %v", err)
+    }
+    got, err = repos.Tasks.GetSubtasks(parent.ID, user.ID)
+    if err != nil {
+        t.Fatalf("GetSubtasks: %v", err)
+    }
+    if !sameTitles(got, "Unpack", "Pack", "Load") {
+        t.Errorf("GetSubtasks after ReorderSubtasks = %v, want [Unpack Pack Load]", titles(got))
+    }
+
+    progress, err := repos.Tasks.GetSubtaskProgress([]int64{parent.ID, lonely.ID})
+    if err != nil {
+        t.Fatalf("GetSubtaskProgress: %v", err)
+    }
+    if want := (domain.SubtaskProgress{Done: 1, Total: 3}); progress[parent.ID] != want {
+        t.Errorf("progress = %+v, want %+v", progress[parent.ID], want)
+    }
+    if p, ok := progress[lonely.ID]; ok {
+        t.Errorf("progress of a task without subtasks = %+v, want none", p)
+    }
+
+    filter := listAll()
+    filter.TopLevel = true
+    if tasks := list(t, repos, user.ID, filter); !sameTitles(tasks, "Lonely", "Move house") {
+        t.Errorf("top-level tasks = %v, want [Lonely Move house]", titles(tasks))
+    }
+}
+
+func testTaskListFilters(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    early, late := at(2030, time.May, 1, 12), at(2030, time.June, 1, 12)
+    newTask(t, repos, user.ID, domain.Task{Title: "alpha", Done: true, Priority: domain.PriorityLow})
+    newTask(t, repos, user.ID, domain.Task{Title: "bravo", Priority: domain.PriorityHigh, DueAt: &late})
+    newTask(t, repos, user.ID, domain.Task{Title: "charlie", DueAt: &early})
+
+    done, open := true, false
+    tests := []struct {
+        name   string
+        adjust func(*domain.TaskFilter)
+        want   []string
+    }{
+        {"all", func(*domain.TaskFilter) {}, []string{"alpha", "bravo", "charlie"}},
+        {"done", func(f *domain.TaskFilter) { f.Done = &done }, []string{"alpha"}},
+        {"open", func(f *domain.TaskFilter) { f.Done = &open }, []string{"bravo", "charlie"}},
+        {"priorities", func(f *domain.TaskFilter) {
+            f.Priorities = []domain.Priority{domain.PriorityLow, domain.PriorityHigh}
+        }, []string{"alpha", "bravo"}},
+        {"title desc", func(f *domain.TaskFilter) { f.Order = domain.SortDesc }, []string{"charlie", "bravo", "alpha"}},
+        {"due asc", func(f *domain.TaskFilter) { f.Sort = domain.TaskSortDueAt }, []string{"charlie", "bravo", "alpha"}},
+        {"due desc", func(f *domain.TaskFilter) {
+            f.Sort, f.Order = domain.TaskSortDueAt, domain.SortDesc
+        }, []string{"bravo", "charlie", "alpha"}},
+        {"priority desc", func(f *domain.TaskFilter) {
+            f.Sort, f.Order = domain.TaskSortPriority, domain.SortDesc
+        }, []string{"bravo", "charlie", "alpha"}},
+        {"limit", func(f *domain.TaskFilter) { f.Limit = 2 }, []string{"alpha", "bravo"}},
+    }
+
+    for _, test := range tests {
+        filter := listAll()
+        test.adjust(&filter)
+        if tasks := list(t, repos, user.ID, filter); !sameTitles(tasks, test.want...) {
+            t.Errorf("%s: listed %v, want %v", test.name, titles(tasks), test.want)
+        }
+    }
+}
+
+func testTaskListPaging(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    due := at(2030, time.July, 1, 8)
+    // Four tasks without a due date, three of them tied on priority too,
+    // so small pages split the ties.
+    for i, priority := range []domain.Priority{
+        domain.PriorityLow, domain.PriorityHigh, domain.PriorityHigh, domain.PriorityNone, domain.PriorityLow,
+        domain.PriorityHigh, domain.PriorityLow, domain.PriorityHigh,
+    } {
+        task := domain.Task{Title: fmt.Sprintf("task %d", 8-i), Priority: priority}
+        if i%2 == 0 {
+            dueAt := due.Add(time.Duration(i/2) * time.Hour)
+            task.DueAt = &dueAt
+        }
+        // newTask would turn PriorityNone into medium.
+        task.UserID = user.ID
+        if err := repos.Tasks.Create(&task); err != nil {
+            t.Fatalf("creating task: %v", err)
+        }
+    }
+
+    for _, sort := range []domain.TaskSort{
+        domain.TaskSortCreatedAt, domain.TaskSortUpdatedAt, domain.TaskSortTitle, domain.TaskSortDueAt, domain.TaskSortPriority,
+    } {
+        for _, order := range []domain.SortOrder{domain.SortAsc, domain.SortDesc} {
+            filter := listAll()
+            filter.Sort, filter.Order = sort, order
+            want := titles(list(t, repos, user.ID, filter))
+
+            for limit := 1; limit <= 3; limit++ {
+                var paged []domain.Task
+                filter.Limit, filter.After = limit, nil
+                for page := 0; page < 10; page++ {
+                    tasks := list(t, repos, user.ID, filter)
+                    paged = append(paged, tasks...)
+                    if len(tasks) < filter.Limit {
+                        break
+                    }
+                    last := tasks[len(tasks)-1]
+                    filter.After = &domain.TaskCursor{
+                        Sort:      sort,
+                        Order:     order,
+                        ID:        last.ID,
+                        CreatedAt: &last.CreatedAt,
+                        UpdatedAt: &last.UpdatedAt,
+                        Title:     last.Title,
+                        DueAt:     last.DueAt,
+                        Priority:  last.Priority,
+                    }
+                }
+
+                if !sameTitles(paged, want...) {
+                    t.Errorf("%s %s by %d: paged listing %v, want %v", sort, order, limit, titles(paged), want)
+                }
+            }
+        }
+    }
+}
+
+func testTaskArchiving(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    kept := newTask(t, repos, user.ID, domain.Task{Title: "kept"})
+    shelved := newTask(t, repos, user.ID, domain.Task{Title: "shelved"})
+
+    archivedAt := at(2030, time.August, 1, 10)
+    if err := repos.Tasks.SetArchivedAt(shelved.ID, user.ID, &archivedAt); err != nil {
+        t.Fatalf("SetArchivedAt: %v", err)
+    }
+    if stored := mustGetTask(t, repos, shelved.ID, user.ID); !sameTime(stored.ArchivedAt, &archivedAt) {
+        t.Errorf("archived_at = %v, want %v", stored.ArchivedAt, archivedAt)
+    }
+
+    for archived, want := range map[domain.ArchivedFilter][]string{
+        domain.ArchivedExclude: {"kept"},
+        domain.ArchivedInclude: {"kept", "shelved"},
+        domain.ArchivedOnly:    {"shelved"},
+    } {
+        filter := listAll()
+        filter.Archived = archived
+        if tasks := list(t, repos, user.ID, filter); !sameTitles(tasks, want...) {
+            t.Errorf("archived %s: listed %v, want %v", archived, titles(tasks), want)
+        }
+    }
+
+    if err := repos.Tasks.SetArchivedAt(shelved.ID, user.ID, nil); err != nil {
+        t.Fatalf("SetArchivedAt nil: %v", err)
+    }
+    if stored := mustGetTask(t, repos, shelved.ID, user.ID); stored.ArchivedAt != nil {
+        t.Errorf("archived_at = %v after unarchiving", stored.ArchivedAt)
+    }
+
+    longAgo, recently := at(2020, time.January, 1, 0), time.Now().UTC().Truncate(time.Second)
+    kept.Done, kept.CompletedAt = true, &longAgo
+    if err := repos.Tasks.Update(kept); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    newTask(t, repos, user.ID, domain.Task{Title: "just done", Done: true, CompletedAt: &recently})
+
+    now := time.Now()
+    for i, want := range []int64{1, 0} {
+        archived, err := repos.Tasks.ArchiveCompleted(user.ID, now.AddDate(0, 0, -30), now)
+        if err != nil {
+            t.Fatalf("ArchiveCompleted: %v", err)
+        }
+        if archived != want {
+            t.Errorf("ArchiveCompleted call %d archived %d, want %d", i+1, archived, want)
+        }
+    }
+
+    filter := listAll()
+    filter.Archived = domain.ArchivedOnly
+    if tasks := list(t, repos, user.ID, filter); !sameTitles(tasks, "kept") {
+        t.Errorf("archived after ArchiveCompleted: %v, want [kept]", titles(tasks))
+    }
+}
+
+func testTaskTrash(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    parent := newTask(t, repos, user.ID, domain.Task{Title: "parent"})
+    child := newTask(t, repos, user.ID, domain.Task{Title: "child", ParentID: &parent.ID, Position: 1})
+    loose := newTask(t, repos, user.ID, domain.Task{Title: "loose", ParentID: &parent.ID, Position: 2})
+
+    trash := func() []domain.Task {
+        t.Helper()
+        tasks, err := repos.Tasks.GetTrash(user.ID)
+        if err != nil {
+            t.Fatalf("GetTrash: %v", err)
+        }
+        return tasks
+    }
+
+    // A subtask trashed on its own is listed with its parent, but it stays
+    // behind when the parent is restored.
+    if err := repos.Tasks.Delete(loose.ID, user.ID); err != nil {
+        t.Fatalf("Delete subtask: %v", err)
+    }
+    time.Sleep(10 * time.Millisecond)
+    if err := repos.Tasks.Delete(parent.ID, user.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    if got, err := repos.Tasks.GetByID(child.ID, user.ID); err != nil || got != nil {
+        t.Errorf("GetByID of a subtask trashed with its parent = %+v, %v; want nil, nil", got, err)
+    }
+    if tasks := list(t, repos, user.ID, listAll()); len(tasks) != 0 {
+        t.Errorf("listed %v with everything in the trash", titles(tasks))
+    }
+    if tasks := trash(); !sameTitles(tasks, "parent") {
+        t.Errorf("GetTrash = %v, want [parent]", titles(tasks))
+    }
+
+    trashed, err := repos.Tasks.GetTrashedByID(parent.ID, user.ID)
+    if err != nil {
+        t.Fatalf("GetTrashedByID: %v", err)
+    }
+    if trashed == nil || trashed.DeletedAt == nil {
+        t.Fatalf("GetTrashedByID = %+v, want the task with deleted_at", trashed)
+    }
+
+    expired, err := repos.Tasks.GetTrashedBefore(time.Now().Add(time.Minute), 1000)
+    if err != nil {
+        t.Fatalf("GetTrashedBefore: %v", err)
+    }
+    var mine []domain.Task
+    for _, task := range expired {
+        if task.UserID == user.ID {
+            mine = append(mine, task)
+        }
+    }
+    if !sameTitles(mine, "parent") {
+        t.Errorf("GetTrashedBefore = %v, want [parent]", titles(mine))
+    }
+    if expired, err := repos.Tasks.GetTrashedBefore(time.Now().Add(time.Minute), 1); err != nil || len(expired) != 1 {
+        t.Errorf("GetTrashedBefore with limit 1 = %d tasks, %v", len(expired), err)
+    }
+
+    if err := repos.Tasks.Restore(parent.ID, user.ID); err != nil {
+        t.Fatalf("Restore: %v", err)
+    }
+    if tasks := list(t, repos, user.ID, listAll()); !sameTitles(tasks, "child", "parent") {
+        t.Errorf("listed %v after Restore, want [child parent]", titles(tasks))
+    }
+    if tasks := trash(); !sameTitles(tasks, "loose") {
+        t.Errorf("GetTrash after Restore = %v, want [loose]", titles(tasks))
+    }
+    if err := repos.Tasks.Restore(parent.ID, user.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Restore of a task not in the trash: err = %v, want ErrTaskNotFound", err)
+    }
+
+    if err := repos.Tasks.Delete(parent.ID, user.ID); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if err := repos.Tasks.Purge(parent.ID, user.ID); err != nil {
+        t.Fatalf("Purge: %v", err)
+    }
+    if tasks := trash(); len(tasks) != 0 {
+        t.Errorf("GetTrash after Purge = %v", titles(tasks))
+    }
+    for _, id := range []int64{child.ID, loose.ID} {
+        if got, err := repos.Tasks.GetTrashedByID(id, user.ID); err != nil || got != nil {
+            t.Errorf("GetTrashedByID of a purged subtask = %+v, %v; want nil, nil", got, err)
+        }
+    }
+    if err := repos.Tasks.Purge(parent.ID, user.ID); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Purge of a purged task: err = %v, want ErrTaskNotFound", err)
+    }
+}
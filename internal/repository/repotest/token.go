@@ -0,0 +1,159 @@
+package repotest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func testRefreshToken(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    other := newUser(t, repos)
+    now := time.Now().UTC().Truncate(time.Second)
+
+    create := func(userID int64, family string, expiresAt time.Time) *domain.RefreshToken {
+        t.Helper()
+        token := &domain.RefreshToken{
+            UserID:    userID,
+            FamilyID:  family,
+            TokenHash: randomHex(t, 32),
+            ExpiresAt: expiresAt,
+        }
+        if err := repos.RefreshTokens.Create(token); err != nil {
+            t.Fatalf("Create: %v", err)
+        }
+        return token
+    }
+    get := func(hash string) *domain.RefreshToken {
+        t.Helper()
+        token, err := repos.RefreshTokens.GetByHash(hash)
+        if err != nil {
+            t.Fatalf("GetByHash: %v", err)
+        }
+        return token
+    }
+
+    familyA, familyB := randomHex(t, 16), randomHex(t, 16)
+    first := create(user.ID, familyA, now.Add(time.Hour))
+    if first.ID == 0 || first.CreatedAt.IsZero() {
+        t.Errorf("Create left the ID or CreatedAt unset: %+v", first)
+    }
+    stored := get(first.TokenHash)
+    if stored == nil || stored.ID != first.ID || stored.UserID != user.ID || stored.FamilyID != first.FamilyID ||
+        !stored.ExpiresAt.Equal(first.ExpiresAt) || stored.UsedAt != nil || stored.RevokedAt != nil {
+        t.Errorf("GetByHash = %+v, want the token as created", stored)
+    }
+    if missing := get(randomHex(t, 32)); missing != nil {
+        t.Errorf("GetByHash of an unknown hash = %+v", missing)
+    }
+
+    // A token is used once.
+    for i, want := range []bool{true, false} {
+        if used, err := repos.RefreshTokens.MarkUsed(first.ID, now); err != nil || used != want {
+            t.Errorf("MarkUsed call %d = %t, %v; want %t", i+1, used, err, want)
+        }
+    }
+    if stored := get(first.TokenHash); stored.UsedAt == nil || !stored.UsedAt.Equal(now) {
+        t.Errorf("used_at = %v, want %v", stored.UsedAt, now)
+    }
+
+    second := create(user.ID, familyA, now.Add(time.Hour))
+    create(user.ID, familyB, now.Add(time.Hour))
+    expired := create(user.ID, randomHex(t, 16), now.Add(-time.Hour))
+    families, err := repos.RefreshTokens.GetActiveFamilies(user.ID, now)
+    if err != nil {
+        t.Fatalf("GetActiveFamilies: %v", err)
+    }
+    if len(families) != 2 || !containsString(families, familyA) || !containsString(families, familyB) {
+        t.Errorf("GetActiveFamilies = %v, want families a and b", families)
+    }
+
+    // Revoking a family stops its unused tokens from being used.
+    if err := repos.RefreshTokens.RevokeFamily(familyA, now); err != nil {
+        t.Fatalf("RevokeFamily: %v", err)
+    }
+    if used, err := repos.RefreshTokens.MarkUsed(second.ID, now); err != nil || used {
+        t.Errorf("MarkUsed of a revoked token = %t, %v; want false", used, err)
+    }
+    if stored := get(second.TokenHash); stored.RevokedAt == nil {
+        t.Error("revoked_at unset after RevokeFamily")
+    }
+
+    otherToken := create(other.ID, randomHex(t, 16), now.Add(time.Hour))
+    if err := repos.RefreshTokens.RevokeByUserID(user.ID, now); err != nil {
+        t.Fatalf("RevokeByUserID: %v", err)
+    }
+    if families, err := repos.RefreshTokens.GetActiveFamilies(user.ID, now); err != nil || len(families) != 0 {
+        t.Errorf("GetActiveFamilies after RevokeByUserID = %v, %v", families, err)
+    }
+    if stored := get(otherToken.TokenHash); stored.RevokedAt != nil {
+        t.Error("RevokeByUserID revoked another user's token")
+    }
+
+    if deleted, err := repos.RefreshTokens.DeleteExpired(now); err != nil || deleted < 1 {
+        t.Errorf("DeleteExpired = %d, %v; want at least the expired token", deleted, err)
+    }
+    if get(expired.TokenHash) != nil || get(first.TokenHash) == nil {
+        t.Error("DeleteExpired did not delete just the expired tokens")
+    }
+}
+
+func testTokenRevocation(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    now := time.Now().UTC().Truncate(time.Second)
+    kept, expiring := randomHex(t, 16), randomHex(t, 16)
+
+    isRevoked := func(id string) bool {
+        t.Helper()
+        revoked, err := repos.Revocations.IsRevoked(id)
+        if err != nil {
+            t.Fatalf("IsRevoked: %v", err)
+        }
+        return revoked
+    }
+
+    // Revoking twice is the same as once.
+    for i := 0; i < 2; i++ {
+        if err := repos.Revocations.Revoke([]string{kept}, user.ID, now.Add(time.Hour)); err != nil {
+            t.Fatalf("Revoke: %v", err)
+        }
+    }
+    if err := repos.Revocations.Revoke([]string{expiring}, user.ID, now.Add(-time.Minute)); err != nil {
+        t.Fatalf("Revoke: %v", err)
+    }
+    if err := repos.Revocations.Revoke(nil, user.ID, now); err != nil {
+        t.Errorf("Revoke of no IDs: %v", err)
+    }
+    if !isRevoked(kept) || !isRevoked(expiring) || isRevoked(randomHex(t, 16)) {
+        t.Error("IsRevoked does not report exactly the revoked IDs")
+    }
+
+    if deleted, err := repos.Revocations.DeleteExpired(now); err != nil || deleted < 1 {
+        t.Errorf("DeleteExpired = %d, %v; want at least the expired revocation", deleted, err)
+    }
+    if !isRevoked(kept) || isRevoked(expiring) {
+        t.Error("DeleteExpired did not delete just the expired revocations")
+    }
+}
+
+// randomHex returns n random bytes in hex, the form of token IDs and hashes.
+func randomHex(t *testing.T, n int) string {
+    t.Helper()
+
+    b := make([]byte, n)
+    if _, err := rand.Read(b); err != nil {
+        t.Fatal(err)
+    }
+    return hex.EncodeToString(b)
+}
+
+func containsString(list []string, s string) bool {
+    for _, item := range list {
+        if item == s {
+            return true
+        }
+    }
+    return false
+}
@@ -0,0 +1,70 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+func testUserCreateAndGet(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+    if user.ID == 0 {
+        t.Fatal("Create left the ID unset")
+    }
+    if user.CreatedAt.IsZero() || user.UpdatedAt.IsZero() {
+        t.Error("Create left the timestamps unset")
+    }
+
+    byName, err := repos.Users.GetByUsername(user.Username)
+    if err != nil {
+        t.Fatalf("GetByUsername: %v", err)
+    }
+    if byName == nil || byName.ID != user.ID || byName.Password != user.Password {
+        t.Errorf("GetByUsername = %+v, want user %d", byName, user.ID)
+    }
+
+    byID, err := repos.Users.GetByID(user.ID)
+    if err != nil {
+        t.Fatalf("GetByID: %v", err)
+    }
+    if byID == nil || byID.Username != user.Username {
+        t.Errorf("GetByID = %+v, want %q", byID, user.Username)
+    }
+
+    missing, err := repos.Users.GetByUsername(user.Username + "-missing")
+    if err != nil || missing != nil {
+        t.Errorf("GetByUsername of an unknown name = %+v, %v; want nil, nil", missing, err)
+    }
+    missing, err = repos.Users.GetByID(-1)
+    if err != nil || missing != nil {
+        t.Errorf("GetByID of an unknown ID = %+v, %v; want nil, nil", missing, err)
+    }
+}
+
+func testUserUsernameTaken(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+
+    err := repos.Users.Create(&domain.User{Username: user.Username, Password: "other"})
+    if !errors.Is(err, domain.ErrUsernameTaken) {
+        t.Errorf("creating a second %q: err = %v, want ErrUsernameTaken", user.Username, err)
+    }
+}
+
+func testUserUpdatePassword(t *testing.T, repos Repos) {
+    user := newUser(t, repos)
+
+    if err := repos.Users.UpdatePassword(user.ID, "new hash"); err != nil {
+        t.Fatalf("UpdatePassword: %v", err)
+    }
+    stored, err := repos.Users.GetByID(user.ID)
+    if err != nil {
+        t.Fatalf("GetByID: %v", err)
+    }
+    if stored.Password != "new hash" {
+        t.Errorf("password = %q after UpdatePassword, want %q", stored.Password, "new hash")
+    }
+
+    if err := repos.Users.UpdatePassword(-1, "x"); !errors.Is(err, domain.ErrUserNotFound) {
+        t.Errorf("UpdatePassword of an unknown user: err = %v, want ErrUserNotFound", err)
+    }
+}
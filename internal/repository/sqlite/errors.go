@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"errors"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+func isDuplicateKey(err error) bool {
+    var sqliteErr *sqlite.Error
+    return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const projectColumns = `id, user_id, name, description, archived_at, created_at, updated_at`
+
+type sqliteProjectRepository struct {
+    db *sql.DB
+}
+
+func NewSqliteProjectRepository(db *sql.DB) domain.ProjectRepository {
+    return &sqliteProjectRepository{db}
+}
+
+func (r *sqliteProjectRepository) Create(project *domain.Project) error {
+    query := `
+        INSERT INTO projects (user_id, name, description, archived_at, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?)
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query,
+        project.UserID,
+        project.Name,
+        project.Description,
+        project.ArchivedAt,
+        now,
+        now,
+    )
+    if err != nil {
+        return fmt.Errorf("error creating project: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    project.ID = id
+    project.CreatedAt = now
+    project.UpdatedAt = now
+    return nil
+}
+
+func (r *sqliteProjectRepository) Update(project *domain.Project) error {
+    query := `
+        UPDATE projects
+        SET name = ?, description = ?, archived_at = ?, updated_at = ?
+        WHERE id = ? AND user_id = ?
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query,
+        project.Name,
+        project.Description,
+        project.ArchivedAt,
+        now,
+        project.ID,
+        project.UserID,
+    )
+    if err != nil {
+        return fmt.Errorf("error updating project: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrProjectNotFound
+    }
+
+    project.UpdatedAt = now
+    return nil
+}
+
+func (r *sqliteProjectRepository) Delete(id, userID int64, deleteTasks bool) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    if deleteTasks {
+        // Subtasks go with their parent even when they are in another
+        // project, so they are trashed first, while their parents still
+        // tell them apart.
+        now := time.Now().UTC()
+        _, err = tx.Exec(`
+            UPDATE tasks SET deleted_at = ?
+            WHERE deleted_at IS NULL AND parent_id IN (
+                SELECT id FROM tasks WHERE project_id = ? AND user_id = ? AND deleted_at IS NULL)`,
+            now, id, userID)
+        if err == nil {
+            _, err = tx.Exec(`UPDATE tasks SET deleted_at = ? WHERE project_id = ? AND user_id = ? AND deleted_at IS NULL`,
+                now, id, userID)
+        }
+    } else {
+        _, err = tx.Exec(`UPDATE tasks SET project_id = NULL, updated_at = ? WHERE project_id = ? AND user_id = ?`,
+            time.Now().UTC(), id, userID)
+    }
+    if err != nil {
+        return fmt.Errorf("error releasing project tasks: %w", err)
+    }
+
+    result, err := tx.Exec(`DELETE FROM projects WHERE id = ? AND user_id = ?`, id, userID)
+    if err != nil {
+        return fmt.Errorf("error deleting project: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrProjectNotFound
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing project delete: %w", err)
+    }
+
+    return nil
+}
+
+func (r *sqliteProjectRepository) GetByID(id, userID int64) (*domain.Project, error) {
+    query := `
+        SELECT ` + projectColumns + `
+        FROM projects
+        WHERE id = ? AND user_id = ?
+    `
+
+    project := &domain.Project{}
+    err := scanProject(r.db.QueryRow(query, id, userID), project)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting project: %w", err)
+    }
+
+    return project, nil
+}
+
+func (r *sqliteProjectRepository) GetAllByUserID(userID int64, includeArchived bool) ([]domain.Project, error) {
+    query := `
+        SELECT ` + projectColumns + `
+        FROM projects
+        WHERE user_id = ?`
+    if !includeArchived {
+        query += ` AND archived_at IS NULL`
+    }
+    query += `
+        ORDER BY name, id
+    `
+
+    rows, err := r.db.Query(query, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying projects: %w", err)
+    }
+    defer rows.Close()
+
+    var projects []domain.Project
+    for rows.Next() {
+        var project domain.Project
+        if err := scanProject(rows, &project); err != nil {
+            return nil, fmt.Errorf("error scanning project: %w", err)
+        }
+        projects = append(projects, project)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating projects: %w", err)
+    }
+
+    return projects, nil
+}
+
+// scanProject reads a row selected with projectColumns.
+func scanProject(row rowScanner, project *domain.Project) error {
+    return row.Scan(
+        &project.ID,
+        &project.UserID,
+        &project.Name,
+        &project.Description,
+        &project.ArchivedAt,
+        &project.CreatedAt,
+        &project.UpdatedAt,
+    )
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type sqliteRefreshTokenRepository struct {
+    db *sql.DB
+}
+
+func NewSqliteRefreshTokenRepository(db *sql.DB) domain.RefreshTokenRepository {
+    return &sqliteRefreshTokenRepository{db}
+}
+
+func (r *sqliteRefreshTokenRepository) Create(token *domain.RefreshToken) error {
+    query := `
+        INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+        VALUES (?, ?, ?, ?, ?)
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt.UTC(), now)
+    if err != nil {
+        return fmt.Errorf("error creating refresh token: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    token.ID = id
+    token.CreatedAt = now
+    return nil
+}
+
+func (r *sqliteRefreshTokenRepository) GetByHash(hash string) (*domain.RefreshToken, error) {
+    query := `
+        SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+        FROM refresh_tokens
+        WHERE token_hash = ?
+    `
+
+    token := &domain.RefreshToken{}
+    err := r.db.QueryRow(query, hash).Scan(
+        &token.ID,
+        &token.UserID,
+        &token.FamilyID,
+        &token.TokenHash,
+        &token.ExpiresAt,
+        &token.UsedAt,
+        &token.RevokedAt,
+        &token.CreatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting refresh token: %w", err)
+    }
+
+    return token, nil
+}
+
+func (r *sqliteRefreshTokenRepository) MarkUsed(id int64, usedAt time.Time) (bool, error) {
+    query := `UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL AND revoked_at IS NULL`
+
+    result, err := r.db.Exec(query, usedAt.UTC(), id)
+    if err != nil {
+        return false, fmt.Errorf("error marking refresh token used: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return false, fmt.Errorf("error getting rows affected: %w", err)
+    }
+
+    return affected == 1, nil
+}
+
+func (r *sqliteRefreshTokenRepository) RevokeFamily(familyID string, revokedAt time.Time) error {
+    query := `UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`
+
+    if _, err := r.db.Exec(query, revokedAt.UTC(), familyID); err != nil {
+        return fmt.Errorf("error revoking refresh tokens: %w", err)
+    }
+
+    return nil
+}
+
+func (r *sqliteRefreshTokenRepository) GetActiveFamilies(userID int64, now time.Time) ([]string, error) {
+    query := `
+        SELECT DISTINCT family_id
+        FROM refresh_tokens
+        WHERE user_id = ? AND used_at IS NULL AND revoked_at IS NULL AND expires_at > ?
+    `
+
+    rows, err := r.db.Query(query, userID, now.UTC())
+    if err != nil {
+        return nil, fmt.Errorf("error querying token families: %w", err)
+    }
+    defer rows.Close()
+
+    var families []string
+    for rows.Next() {
+        var family string
+        if err := rows.Scan(&family); err != nil {
+            return nil, fmt.Errorf("error scanning token family: %w", err)
+        }
+        families = append(families, family)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating token families: %w", err)
+    }
+
+    return families, nil
+}
+
+func (r *sqliteRefreshTokenRepository) RevokeByUserID(userID int64, revokedAt time.Time) error {
+    query := `UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL`
+
+    if _, err := r.db.Exec(query, revokedAt.UTC(), userID); err != nil {
+        return fmt.Errorf("error revoking refresh tokens: %w", err)
+    }
+
+    return nil
+}
+
+func (r *sqliteRefreshTokenRepository) DeleteExpired(before time.Time) (int64, error) {
+    result, err := r.db.Exec(`DELETE FROM refresh_tokens WHERE expires_at < ?`, before.UTC())
+    if err != nil {
+        return 0, fmt.Errorf("error deleting expired refresh tokens: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("error getting rows affected: %w", err)
+    }
+
+    return affected, nil
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const reminderColumns = `r.id, r.task_id, r.user_id, r.remind_at, r.sent_at, r.attempts, r.last_error, r.created_at`
+
+// reminderVisibleCondition matches the reminders whose user can still see
+// their task t, by the rules of visibleCondition, so a reminder is not sent
+// about a task its user has lost access to.
+const reminderVisibleCondition = `(t.user_id = r.user_id
+    OR EXISTS (SELECT 1 FROM task_shares s WHERE s.task_id = t.id AND s.user_id = r.user_id)
+    OR EXISTS (SELECT 1 FROM task_shares s WHERE s.task_id = t.parent_id AND s.user_id = r.user_id)
+    OR EXISTS (SELECT 1 FROM project_shares s WHERE s.project_id = t.project_id AND s.user_id = r.user_id))`
+
+type sqliteReminderRepository struct {
+    db *sql.DB
+}
+
+func NewSqliteReminderRepository(db *sql.DB) domain.ReminderRepository {
+    return &sqliteReminderRepository{db}
+}
+
+func (r *sqliteReminderRepository) Create(reminder *domain.Reminder) error {
+    query := `
+        INSERT INTO reminders (task_id, user_id, remind_at, created_at)
+        VALUES (?, ?, ?, ?)
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query, reminder.TaskID, reminder.UserID, reminder.RemindAt, now)
+    if err != nil {
+        return fmt.Errorf("error creating reminder: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    reminder.ID = id
+    reminder.CreatedAt = now
+    return nil
+}
+
+func (r *sqliteReminderRepository) Delete(id, taskID, userID int64) error {
+    query := `DELETE FROM reminders WHERE id = ? AND task_id = ? AND user_id = ?`
+
+    result, err := r.db.Exec(query, id, taskID, userID)
+    if err != nil {
+        return fmt.Errorf("error deleting reminder: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrReminderNotFound
+    }
+
+    return nil
+}
+
+func (r *sqliteReminderRepository) GetByTaskID(taskID, userID int64) ([]domain.Reminder, error) {
+    query := `
+        SELECT ` + reminderColumns + `
+        FROM reminders r
+        WHERE r.task_id = ? AND r.user_id = ?
+        ORDER BY r.remind_at, r.id
+    `
+
+    rows, err := r.db.Query(query, taskID, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying reminders: %w", err)
+    }
+    defer rows.Close()
+
+    var reminders []domain.Reminder
+    for rows.Next() {
+        var reminder domain.Reminder
+        if err := scanReminder(rows, &reminder); err != nil {
+            return nil, fmt.Errorf("error scanning reminder: %w", err)
+        }
+        reminders = append(reminders, reminder)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating reminders: %w", err)
+    }
+
+    return reminders, nil
+}
+
+// ProcessDue claims the due reminders in a short immediate transaction,
+// which holds the database's write lock so no other process claims the same
+// reminders, and delivers them once it is committed. Delivering within it
+// would keep every other query waiting on the notifier.
+func (r *sqliteReminderRepository) ProcessDue(now time.Time, limit, maxAttempts int, claimTimeout time.Duration, deliver func(domain.Notification) error) error {
+    notifications, err := r.claimDue(now, limit, maxAttempts, claimTimeout)
+    if err != nil {
+        return err
+    }
+
+    for _, notification := range notifications {
+        if err := r.recordDelivery(notification.Reminder.ID, deliver(notification)); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// claimDue counts an attempt against each due reminder and marks it
+// claimed at now.
+func (r *sqliteReminderRepository) claimDue(now time.Time, limit, maxAttempts int, claimTimeout time.Duration) ([]domain.Notification, error) {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return nil, fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := `
+        SELECT ` + reminderColumns + `, t.title, t.due_at, t.done
+        FROM reminders r
+        JOIN tasks t ON t.id = r.task_id
+        WHERE r.sent_at IS NULL AND r.remind_at <= ? AND r.attempts < ?
+            AND (r.claimed_at IS NULL OR r.claimed_at <= ?) AND t.deleted_at IS NULL
+            AND ` + reminderVisibleCondition + `
+        ORDER BY r.remind_at, r.id
+        LIMIT ?
+    `
+
+    notifications, err := scanNotifications(tx.Query(query, now, maxAttempts, now.Add(-claimTimeout), limit))
+    if err != nil {
+        return nil, err
+    }
+
+    for i := range notifications {
+        reminder := &notifications[i].Reminder
+        if _, err := tx.Exec(`UPDATE reminders SET attempts = attempts + 1, claimed_at = ? WHERE id = ?`, now, reminder.ID); err != nil {
+            return nil, fmt.Errorf("error claiming reminder: %w", err)
+        }
+        reminder.Attempts++
+    }
+
+    if err := tx.Commit(); err != nil {
+        return nil, fmt.Errorf("error committing reminder claims: %w", err)
+    }
+
+    return notifications, nil
+}
+
+// recordDelivery marks a claimed reminder sent, or records why delivering
+// it failed, and releases the claim.
+func (r *sqliteReminderRepository) recordDelivery(id int64, deliverErr error) error {
+    var err error
+    if deliverErr != nil {
+        _, err = r.db.Exec(`UPDATE reminders SET last_error = ?, claimed_at = NULL WHERE id = ?`,
+            deliverErr.Error(), id)
+    } else {
+        _, err = r.db.Exec(`UPDATE reminders SET sent_at = ?, last_error = '', claimed_at = NULL WHERE id = ?`,
+            time.Now().UTC(), id)
+    }
+    if err != nil {
+        return fmt.Errorf("error recording reminder delivery: %w", err)
+    }
+
+    return nil
+}
+
+// scanNotifications reads every row of a due reminder query before any is
+// claimed, as the transaction cannot run updates while rows are open.
+func scanNotifications(rows *sql.Rows, err error) ([]domain.Notification, error) {
+    if err != nil {
+        return nil, fmt.Errorf("error querying due reminders: %w", err)
+    }
+    defer rows.Close()
+
+    var notifications []domain.Notification
+    for rows.Next() {
+        var n domain.Notification
+        err := rows.Scan(
+            &n.Reminder.ID,
+            &n.Reminder.TaskID,
+            &n.Reminder.UserID,
+            &n.Reminder.RemindAt,
+            &n.Reminder.SentAt,
+            &n.Reminder.Attempts,
+            &n.Reminder.LastError,
+            &n.Reminder.CreatedAt,
+            &n.TaskTitle,
+            &n.TaskDueAt,
+            &n.TaskDone,
+        )
+        if err != nil {
+            return nil, fmt.Errorf("error scanning due reminder: %w", err)
+        }
+        notifications = append(notifications, n)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating due reminders: %w", err)
+    }
+
+    return notifications, nil
+}
+
+// scanReminder reads a row selected with reminderColumns.
+func scanReminder(row rowScanner, reminder *domain.Reminder) error {
+    return row.Scan(
+        &reminder.ID,
+        &reminder.TaskID,
+        &reminder.UserID,
+        &reminder.RemindAt,
+        &reminder.SentAt,
+        &reminder.Attempts,
+        &reminder.LastError,
+        &reminder.CreatedAt,
+    )
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"testing"
+	"todo-app/internal/pkg/migrate"
+	"todo-app/internal/pkg/sqlite"
+	"todo-app/internal/repository/repotest"
+)
+
+func TestConformance(t *testing.T) {
+    repotest.Run(t, func(t *testing.T) repotest.Repos {
+        db, err := sqlite.NewConnection(":memory:")
+        if err != nil {
+            t.Fatalf("opening sqlite: %v", err)
+        }
+        t.Cleanup(func() { db.Close() })
+        repotest.Migrate(t, db, migrate.SQLite, "sqlite")
+
+        return repotest.Repos{
+            Users:           NewSqliteUserRepository(db),
+            Tasks:           NewSqliteTaskRepository(db),
+            History:         NewSqliteTaskHistoryRepository(db),
+            Projects:        NewSqliteProjectRepository(db),
+            Tags:            NewSqliteTagRepository(db),
+            Shares:          NewSqliteShareRepository(db),
+            Assignments:     NewSqliteAssignmentRepository(db),
+            Comments:        NewSqliteCommentRepository(db),
+            Attachments:     NewSqliteAttachmentRepository(db),
+            Reminders:       NewSqliteReminderRepository(db),
+            RefreshTokens:   NewSqliteRefreshTokenRepository(db),
+            Revocations:     NewSqliteTokenRevocationRepository(db),
+            ArchivePolicies: NewSqliteArchivePolicyRepository(db),
+        }
+    })
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const tagColumns = `id, user_id, name, created_at, updated_at`
+
+type sqliteTagRepository struct {
+    db *sql.DB
+}
+
+func NewSqliteTagRepository(db *sql.DB) domain.TagRepository {
+    return &sqliteTagRepository{db}
+}
+
+func (r *sqliteTagRepository) Create(tag *domain.Tag) error {
+    query := `
+        INSERT INTO tags (user_id, name, created_at, updated_at)
+        VALUES (?, ?, ?, ?)
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query, tag.UserID, tag.Name, now, now)
+    if isDuplicateKey(err) {
+        return domain.ErrTagExists
+    }
+    if err != nil {
+        return fmt.Errorf("error creating tag: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    tag.ID = id
+    tag.CreatedAt = now
+    tag.UpdatedAt = now
+    return nil
+}
+
+func (r *sqliteTagRepository) Update(tag *domain.Tag) error {
+    query := `
+        UPDATE tags
+        SET name = ?, updated_at = ?
+        WHERE id = ? AND user_id = ?
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query, tag.Name, now, tag.ID, tag.UserID)
+    if isDuplicateKey(err) {
+        return domain.ErrTagExists
+    }
+    if err != nil {
+        return fmt.Errorf("error updating tag: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTagNotFound
+    }
+
+    tag.UpdatedAt = now
+    return nil
+}
+
+func (r *sqliteTagRepository) Delete(id, userID int64) error {
+    query := `DELETE FROM tags WHERE id = ? AND user_id = ?`
+
+    result, err := r.db.Exec(query, id, userID)
+    if err != nil {
+        return fmt.Errorf("error deleting tag: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTagNotFound
+    }
+
+    return nil
+}
+
+func (r *sqliteTagRepository) GetByID(id, userID int64) (*domain.Tag, error) {
+    query := `
+        SELECT ` + tagColumns + `
+        FROM tags
+        WHERE id = ? AND user_id = ?
+    `
+
+    tag := &domain.Tag{}
+    err := scanTag(r.db.QueryRow(query, id, userID), tag)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting tag: %w", err)
+    }
+
+    return tag, nil
+}
+
+func (r *sqliteTagRepository) GetAllByUserID(userID int64) ([]domain.Tag, error) {
+    query := `
+        SELECT ` + tagColumns + `
+        FROM tags
+        WHERE user_id = ?
+        ORDER BY name, id
+    `
+
+    rows, err := r.db.Query(query, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying tags: %w", err)
+    }
+    defer rows.Close()
+
+    var tags []domain.Tag
+    for rows.Next() {
+        var tag domain.Tag
+        if err := scanTag(rows, &tag); err != nil {
+            return nil, fmt.Errorf("error scanning tag: %w", err)
+        }
+        tags = append(tags, tag)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating tags: %w", err)
+    }
+
+    return tags, nil
+}
+
+func (r *sqliteTagRepository) AttachToTask(taskID, tagID int64) error {
+    query := `INSERT OR IGNORE INTO task_tags (task_id, tag_id) VALUES (?, ?)`
+
+    if _, err := r.db.Exec(query, taskID, tagID); err != nil {
+        return fmt.Errorf("error attaching tag: %w", err)
+    }
+
+    return nil
+}
+
+func (r *sqliteTagRepository) DetachFromTask(taskID, tagID int64) error {
+    query := `DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?`
+
+    if _, err := r.db.Exec(query, taskID, tagID); err != nil {
+        return fmt.Errorf("error detaching tag: %w", err)
+    }
+
+    return nil
+}
+
+func (r *sqliteTagRepository) GetByTaskIDs(taskIDs []int64) (map[int64][]domain.Tag, error) {
+    tags := make(map[int64][]domain.Tag)
+    if len(taskIDs) == 0 {
+        return tags, nil
+    }
+
+    placeholders := make([]string, len(taskIDs))
+    args := make([]interface{}, len(taskIDs))
+    for i, id := range taskIDs {
+        placeholders[i] = "?"
+        args[i] = id
+    }
+
+    query := `
+        SELECT tt.task_id, t.id, t.user_id, t.name, t.created_at, t.updated_at
+        FROM task_tags tt
+        JOIN tags t ON t.id = tt.tag_id
+        WHERE tt.task_id IN (` + strings.Join(placeholders, ", ") + `)
+        ORDER BY t.name, t.id
+    `
+
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying task tags: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var taskID int64
+        var tag domain.Tag
+        err := rows.Scan(&taskID, &tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
+        if err != nil {
+            return nil, fmt.Errorf("error scanning task tag: %w", err)
+        }
+        tags[taskID] = append(tags[taskID], tag)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating task tags: %w", err)
+    }
+
+    return tags, nil
+}
+
+// scanTag reads a row selected with tagColumns.
+func scanTag(row rowScanner, tag *domain.Tag) error {
+    return row.Scan(&tag.ID, &tag.UserID, &tag.Name, &tag.CreatedAt, &tag.UpdatedAt)
+}
@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"todo-app/internal/domain"
+)
+
+const taskColumns = `id, user_id, project_id, parent_id, position, title, description, done, priority, due_at, completed_at, recurrence, recurrence_tz, assignee_id, archived_at, deleted_at, created_at, updated_at`
+
+type rowScanner interface {
+    Scan(dest ...interface{}) error
+}
+
+// scanTask reads a row selected with taskColumns.
+func scanTask(row rowScanner, task *domain.Task) error {
+    return row.Scan(
+        &task.ID,
+        &task.UserID,
+        &task.ProjectID,
+        &task.ParentID,
+        &task.Position,
+        &task.Title,
+        &task.Description,
+        &task.Done,
+        &task.Priority,
+        &task.DueAt,
+        &task.CompletedAt,
+        &task.Recurrence,
+        &task.RecurrenceTZ,
+        &task.AssigneeID,
+        &task.ArchivedAt,
+        &task.DeletedAt,
+        &task.CreatedAt,
+        &task.UpdatedAt,
+    )
+}
+
+// sortKey is one column of a listing's ORDER BY. Nullable columns sort
+// their NULLs last in either direction. value is the cursor task's value for
+// the column, nil for NULL.
+type sortKey struct {
+    column   string
+    desc     bool
+    nullable bool
+    value    interface{}
+}
+
+// taskSortKeys maps filter onto its ORDER BY columns, ending with id so the
+// ordering is total. Column names come from here only, never from input.
+func taskSortKeys(filter domain.TaskFilter) []sortKey {
+    desc := filter.Order == domain.SortDesc
+
+    var last domain.Task
+    if filter.After != nil {
+        last = filter.After.Last()
+    }
+
+    var keys []sortKey
+    switch filter.Sort {
+    case domain.TaskSortUpdatedAt:
+        keys = append(keys, sortKey{column: "updated_at", desc: desc, value: last.UpdatedAt})
+    case domain.TaskSortTitle:
+        keys = append(keys, sortKey{column: "title", desc: desc, value: last.Title})
+    case domain.TaskSortDueAt:
+        keys = append(keys,
+            dueAtKey(last, desc),
+            sortKey{column: "priority", desc: true, value: last.Priority})
+    case domain.TaskSortPriority:
+        keys = append(keys,
+            sortKey{column: "priority", desc: desc, value: last.Priority},
+            dueAtKey(last, false))
+    default:
+        keys = append(keys, sortKey{column: "created_at", desc: desc, value: last.CreatedAt})
+    }
+
+    return append(keys, sortKey{column: "id", desc: desc, value: last.ID})
+}
+
+func dueAtKey(last domain.Task, desc bool) sortKey {
+    key := sortKey{column: "due_at", desc: desc, nullable: true}
+    if last.DueAt != nil {
+        key.value = *last.DueAt
+    }
+    return key
+}
+
+// taskFilterClause builds the WHERE clause selecting the tasks visible to
+// userID that match filter, including the keyset condition for the
+// requested page. Tasks in the trash are never visible. Times are stored as
+// UTC text, so every bound time is converted to UTC for the comparisons to
+// hold.
+func taskFilterClause(userID int64, filter domain.TaskFilter) (string, []interface{}) {
+    conditions := []string{"deleted_at IS NULL", visibleCondition}
+    args := []interface{}{userID, userID, userID, userID}
+
+    add := func(condition string, values ...interface{}) {
+        conditions = append(conditions, condition)
+        args = append(args, values...)
+    }
+
+    if filter.TopLevel {
+        add("parent_id IS NULL")
+    }
+    if filter.ProjectID != nil {
+        add("project_id = ?", *filter.ProjectID)
+    }
+    if filter.AssigneeID != nil {
+        add("assignee_id = ?", *filter.AssigneeID)
+    }
+    if filter.Done != nil {
+        add("done = ?", *filter.Done)
+    }
+    switch filter.Archived {
+    case domain.ArchivedExclude:
+        add("archived_at IS NULL")
+    case domain.ArchivedOnly:
+        add("archived_at IS NOT NULL")
+    }
+    if len(filter.Priorities) > 0 {
+        placeholders := make([]string, len(filter.Priorities))
+        values := make([]interface{}, len(filter.Priorities))
+        for i, priority := range filter.Priorities {
+            placeholders[i] = "?"
+            values[i] = priority
+        }
+        add("priority IN ("+strings.Join(placeholders, ", ")+")", values...)
+    }
+    if len(filter.Tags) > 0 {
+        condition, values := tagCondition(filter.Tags, filter.TagMatch)
+        add(condition, values...)
+    }
+    if filter.CreatedAfter != nil {
+        add("created_at > ?", filter.CreatedAfter.UTC())
+    }
+    if filter.CreatedBefore != nil {
+        add("created_at < ?", filter.CreatedBefore.UTC())
+    }
+    if filter.UpdatedAfter != nil {
+        add("updated_at > ?", filter.UpdatedAfter.UTC())
+    }
+    if filter.UpdatedBefore != nil {
+        add("updated_at < ?", filter.UpdatedBefore.UTC())
+    }
+    if filter.DueFrom != nil {
+        add("due_at >= ?", filter.DueFrom.UTC())
+    }
+    if filter.DueBefore != nil {
+        add("due_at < ?", filter.DueBefore.UTC())
+    }
+
+    if filter.After != nil {
+        condition, values := keysetCondition(taskSortKeys(filter))
+        add(condition, values...)
+    }
+
+    return strings.Join(conditions, " AND "), args
+}
+
+// visibleCondition matches the tasks a user owns or was given through a share
+// of the task, its parent or its project. It takes the user ID four times.
+const visibleCondition = `(user_id = ?
+    OR id IN (SELECT task_id FROM task_shares WHERE user_id = ?)
+    OR parent_id IN (SELECT task_id FROM task_shares WHERE user_id = ?)
+    OR project_id IN (SELECT project_id FROM project_shares WHERE user_id = ?))`
+
+// tagCondition matches tasks carrying any of names, or all of them when match
+// is TagMatchAll.
+func tagCondition(names []string, match domain.TagMatch) (string, []interface{}) {
+    placeholders := make([]string, len(names))
+    values := make([]interface{}, len(names))
+    for i, name := range names {
+        placeholders[i] = "?"
+        values[i] = name
+    }
+
+    subquery := "SELECT tt.task_id FROM task_tags tt JOIN tags t ON t.id = tt.tag_id" +
+        " WHERE t.name IN (" + strings.Join(placeholders, ", ") + ")"
+    if match == domain.TagMatchAll {
+        subquery += " GROUP BY tt.task_id HAVING COUNT(DISTINCT t.id) = ?"
+        values = append(values, len(names))
+    }
+
+    return "id IN (" + subquery + ")", values
+}
+
+func taskOrderClause(filter domain.TaskFilter) string {
+    var terms []string
+    for _, key := range taskSortKeys(filter) {
+        direction := "ASC"
+        if key.desc {
+            direction = "DESC"
+        }
+        if key.nullable {
+            terms = append(terms, key.column+" IS NULL")
+        }
+        terms = append(terms, key.column+" "+direction)
+    }
+
+    return strings.Join(terms, ", ")
+}
+
+// keysetCondition matches rows ordered strictly after the cursor values in
+// keys: for some key the row is after the cursor, and it ties on every key
+// before that one.
+func keysetCondition(keys []sortKey) (string, []interface{}) {
+    var alternatives []string
+    var args []interface{}
+
+    var ties []string
+    var tieArgs []interface{}
+
+    for _, key := range keys {
+        comparison := ">"
+        if key.desc {
+            comparison = "<"
+        }
+
+        // Nothing sorts after NULL on a nulls-last key.
+        if !key.nullable || key.value != nil {
+            after := fmt.Sprintf("%s %s ?", key.column, comparison)
+            if key.nullable {
+                after = fmt.Sprintf("(%s OR %s IS NULL)", after, key.column)
+            }
+
+            alternatives = append(alternatives, "("+strings.Join(append(ties, after), " AND ")+")")
+            args = append(args, tieArgs...)
+            args = append(args, key.value)
+        }
+
+        if key.value == nil {
+            ties = append(ties, key.column+" IS NULL")
+        } else {
+            ties = append(ties, key.column+" = ?")
+            tieArgs = append(tieArgs, key.value)
+        }
+    }
+
+    return "(" + strings.Join(alternatives, " OR ") + ")", args
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+const taskVersionColumns = `id, task_id, version, action, actor_id, changes, snapshot, created_at`
+
+type sqliteTaskHistoryRepository struct {
+    db *sql.DB
+}
+
+func NewSqliteTaskHistoryRepository(db *sql.DB) domain.TaskHistoryRepository {
+    return &sqliteTaskHistoryRepository{db}
+}
+
+// maxAppendAttempts bounds how often Append numbers an entry again after
+// losing the race for a version.
+const maxAppendAttempts = 10
+
+// Append numbers the entry in the same statement that stores it. Appends
+// racing for the same task can pick the same version: the unique key on
+// (task_id, version) refuses the second, which tries again with the next
+// version.
+func (r *sqliteTaskHistoryRepository) Append(entry *domain.TaskVersion) error {
+    changes, snapshot, err := encodeTaskVersion(entry)
+    if err != nil {
+        return err
+    }
+
+    for attempt := 1; ; attempt++ {
+        err = r.append(entry, changes, snapshot)
+        if err == nil || !isDuplicateKey(err) || attempt == maxAppendAttempts {
+            return err
+        }
+    }
+}
+
+func (r *sqliteTaskHistoryRepository) append(entry *domain.TaskVersion, changes, snapshot string) error {
+    query := `
+        INSERT INTO task_history (task_id, version, action, actor_id, changes, snapshot, created_at)
+        SELECT ?, COALESCE(MAX(version), 0) + 1, ?, ?, ?, ?, ?
+        FROM task_history
+        WHERE task_id = ?
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query,
+        entry.TaskID,
+        entry.Action,
+        entry.ActorID,
+        changes,
+        snapshot,
+        now,
+        entry.TaskID,
+    )
+    if err != nil {
+        return fmt.Errorf("error appending task history: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    err = r.db.QueryRow(`SELECT version FROM task_history WHERE id = ?`, id).Scan(&entry.Version)
+    if err != nil {
+        return fmt.Errorf("error getting task version: %w", err)
+    }
+
+    entry.ID = id
+    entry.CreatedAt = now
+    return nil
+}
+
+func (r *sqliteTaskHistoryRepository) GetByTaskID(taskID int64) ([]domain.TaskVersion, error) {
+    query := `
+        SELECT ` + taskVersionColumns + `
+        FROM task_history
+        WHERE task_id = ?
+        ORDER BY version
+    `
+
+    rows, err := r.db.Query(query, taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying task history: %w", err)
+    }
+    defer rows.Close()
+
+    var versions []domain.TaskVersion
+    for rows.Next() {
+        var version domain.TaskVersion
+        if err := scanTaskVersion(rows, &version); err != nil {
+            return nil, fmt.Errorf("error scanning task version: %w", err)
+        }
+        versions = append(versions, version)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating task history: %w", err)
+    }
+
+    return versions, nil
+}
+
+func (r *sqliteTaskHistoryRepository) GetVersion(taskID int64, version int) (*domain.TaskVersion, error) {
+    query := `
+        SELECT ` + taskVersionColumns + `
+        FROM task_history
+        WHERE task_id = ? AND version = ?
+    `
+
+    entry := &domain.TaskVersion{}
+    err := scanTaskVersion(r.db.QueryRow(query, taskID, version), entry)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting task version: %w", err)
+    }
+
+    return entry, nil
+}
+
+// encodeTaskVersion returns the JSON stored for the changes and snapshot of
+// entry.
+func encodeTaskVersion(entry *domain.TaskVersion) (string, string, error) {
+    changes, err := json.Marshal(entry.Changes)
+    if err != nil {
+        return "", "", fmt.Errorf("error encoding task changes: %w", err)
+    }
+    snapshot, err := json.Marshal(entry.Snapshot)
+    if err != nil {
+        return "", "", fmt.Errorf("error encoding task snapshot: %w", err)
+    }
+
+    return string(changes), string(snapshot), nil
+}
+
+// scanTaskVersion reads a row selected with taskVersionColumns.
+func scanTaskVersion(row rowScanner, entry *domain.TaskVersion) error {
+    var changes, snapshot string
+    err := row.Scan(
+        &entry.ID,
+        &entry.TaskID,
+        &entry.Version,
+        &entry.Action,
+        &entry.ActorID,
+        &changes,
+        &snapshot,
+        &entry.CreatedAt,
+    )
+    if err != nil {
+        return err
+    }
+
+    if err := json.Unmarshal([]byte(changes), &entry.Changes); err != nil {
+        return fmt.Errorf("error decoding task changes: %w", err)
+    }
+    if err := json.Unmarshal([]byte(snapshot), &entry.Snapshot); err != nil {
+        return fmt.Errorf("error decoding task snapshot: %w", err)
+    }
+
+    return nil
+}
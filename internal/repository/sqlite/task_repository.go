@@ -0,0 +1,464 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type sqliteTaskRepository struct {
+    db *sql.DB
+}
+
+func NewSqliteTaskRepository(db *sql.DB) domain.TaskRepository {
+    return &sqliteTaskRepository{db}
+}
+
+func (r *sqliteTaskRepository) Create(task *domain.Task) error {
+    query := `
+        INSERT INTO tasks (user_id, project_id, parent_id, position, title, description, done, priority, due_at, completed_at, recurrence, recurrence_tz, assignee_id, created_at, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query,
+        task.UserID,
+        task.ProjectID,
+        task.ParentID,
+        task.Position,
+        task.Title,
+        task.Description,
+        task.Done,
+        task.Priority,
+        task.DueAt,
+        task.CompletedAt,
+        task.Recurrence,
+        task.RecurrenceTZ,
+        task.AssigneeID,
+        now,
+        now,
+    )
+    if err != nil {
+        return fmt.Errorf("error creating task: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    task.ID = id
+    task.CreatedAt = now
+    task.UpdatedAt = now
+    return nil
+}
+
+func (r *sqliteTaskRepository) Update(task *domain.Task) error {
+    query := `
+        UPDATE tasks 
+        SET project_id = ?, title = ?, description = ?, done = ?, priority = ?, due_at = ?, completed_at = ?, recurrence = ?, recurrence_tz = ?, updated_at = ?
+        WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+    `
+
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query,
+        task.ProjectID,
+        task.Title,
+        task.Description,
+        task.Done,
+        task.Priority,
+        task.DueAt,
+        task.CompletedAt,
+        task.Recurrence,
+        task.RecurrenceTZ,
+        now,
+        task.ID,
+        task.UserID,
+    )
+    if err != nil {
+        return fmt.Errorf("error updating task: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTaskNotFound
+    }
+
+    task.UpdatedAt = now
+    return nil
+}
+
+func (r *sqliteTaskRepository) Delete(id, userID int64) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    now := time.Now().UTC()
+    result, err := tx.Exec(`UPDATE tasks SET deleted_at = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`,
+        now, id, userID)
+    if err != nil {
+        return fmt.Errorf("error deleting task: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTaskNotFound
+    }
+
+    _, err = tx.Exec(`UPDATE tasks SET deleted_at = ? WHERE parent_id = ? AND deleted_at IS NULL`, now, id)
+    if err != nil {
+        return fmt.Errorf("error deleting subtasks: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing task deletion: %w", err)
+    }
+
+    return nil
+}
+
+func (r *sqliteTaskRepository) GetByID(id, userID int64) (*domain.Task, error) {
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE id = ? AND user_id = ? AND deleted_at IS NULL
+    `
+
+    task := &domain.Task{}
+    err := scanTask(r.db.QueryRow(query, id, userID), task)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting task: %w", err)
+    }
+
+    return task, nil
+}
+
+func (r *sqliteTaskRepository) FindByID(id int64) (*domain.Task, error) {
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE id = ? AND deleted_at IS NULL
+    `
+
+    task := &domain.Task{}
+    err := scanTask(r.db.QueryRow(query, id), task)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting task: %w", err)
+    }
+
+    return task, nil
+}
+
+func (r *sqliteTaskRepository) GetAllByUserID(userID int64, filter domain.TaskFilter) ([]domain.Task, error) {
+    where, args := taskFilterClause(userID, filter)
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE ` + where + `
+        ORDER BY ` + taskOrderClause(filter) + `
+        LIMIT ?
+    `
+    args = append(args, filter.Limit)
+
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying tasks: %w", err)
+    }
+    defer rows.Close()
+
+    var tasks []domain.Task
+    for rows.Next() {
+        var task domain.Task
+        if err := scanTask(rows, &task); err != nil {
+            return nil, fmt.Errorf("error scanning task: %w", err)
+        }
+        tasks = append(tasks, task)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating tasks: %w", err)
+    }
+
+    return tasks, nil
+}
+
+func (r *sqliteTaskRepository) GetSubtasks(parentID, userID int64) ([]domain.Task, error) {
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE parent_id = ? AND user_id = ? AND deleted_at IS NULL
+        ORDER BY position, id
+    `
+
+    rows, err := r.db.Query(query, parentID, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error querying subtasks: %w", err)
+    }
+    defer rows.Close()
+
+    var tasks []domain.Task
+    for rows.Next() {
+        var task domain.Task
+        if err := scanTask(rows, &task); err != nil {
+            return nil, fmt.Errorf("error scanning subtask: %w", err)
+        }
+        tasks = append(tasks, task)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating subtasks: %w", err)
+    }
+
+    return tasks, nil
+}
+
+func (r *sqliteTaskRepository) ReorderSubtasks(parentID, userID int64, ids []int64) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    query := `UPDATE tasks SET position = ? WHERE id = ? AND parent_id = ? AND user_id = ? AND deleted_at IS NULL`
+    for position, id := range ids {
+        if _, err := tx.Exec(query, position+1, id, parentID, userID); err != nil {
+            return fmt.Errorf("error reordering subtasks: %w", err)
+        }
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing subtask order: %w", err)
+    }
+
+    return nil
+}
+
+func (r *sqliteTaskRepository) GetSubtaskProgress(taskIDs []int64) (map[int64]domain.SubtaskProgress, error) {
+    progress := make(map[int64]domain.SubtaskProgress)
+    if len(taskIDs) == 0 {
+        return progress, nil
+    }
+
+    placeholders := make([]string, len(taskIDs))
+    args := make([]interface{}, len(taskIDs))
+    for i, id := range taskIDs {
+        placeholders[i] = "?"
+        args[i] = id
+    }
+
+    query := `
+        SELECT parent_id, COUNT(*), COALESCE(SUM(CASE WHEN done THEN 1 ELSE 0 END), 0)
+        FROM tasks
+        WHERE parent_id IN (` + strings.Join(placeholders, ", ") + `) AND deleted_at IS NULL
+        GROUP BY parent_id
+    `
+
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying subtask progress: %w", err)
+    }
+    defer rows.Close()
+
+    for rows.Next() {
+        var parentID int64
+        var p domain.SubtaskProgress
+        if err := rows.Scan(&parentID, &p.Total, &p.Done); err != nil {
+            return nil, fmt.Errorf("error scanning subtask progress: %w", err)
+        }
+        progress[parentID] = p
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating subtask progress: %w", err)
+    }
+
+    return progress, nil
+}
+
+func (r *sqliteTaskRepository) GetByProjectID(projectID, userID int64) ([]domain.Task, error) {
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE project_id = ? AND user_id = ? AND deleted_at IS NULL
+        ORDER BY id
+    `
+
+    return r.queryTasks(query, projectID, userID)
+}
+
+func (r *sqliteTaskRepository) SetArchivedAt(id, userID int64, archivedAt *time.Time) error {
+    query := `UPDATE tasks SET archived_at = ?, updated_at = ? WHERE id = ? AND user_id = ? AND deleted_at IS NULL`
+
+    result, err := r.db.Exec(query, archivedAt, time.Now().UTC(), id, userID)
+    if err != nil {
+        return fmt.Errorf("error archiving task: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTaskNotFound
+    }
+
+    return nil
+}
+
+func (r *sqliteTaskRepository) ArchiveCompleted(userID int64, completedBefore, now time.Time) (int64, error) {
+    query := `
+        UPDATE tasks
+        SET archived_at = ?, updated_at = ?
+        WHERE user_id = ? AND done = ? AND completed_at < ? AND archived_at IS NULL AND deleted_at IS NULL
+    `
+
+    result, err := r.db.Exec(query, now.UTC(), now.UTC(), userID, true, completedBefore.UTC())
+    if err != nil {
+        return 0, fmt.Errorf("error archiving completed tasks: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return 0, fmt.Errorf("error getting rows affected: %w", err)
+    }
+
+    return affected, nil
+}
+
+// trashedTopCondition keeps the tasks in the trash that are not subtasks of
+// another task in the trash.
+const trashedTopCondition = `deleted_at IS NOT NULL
+    AND (parent_id IS NULL OR parent_id NOT IN (SELECT id FROM tasks WHERE deleted_at IS NOT NULL))`
+
+func (r *sqliteTaskRepository) GetTrash(userID int64) ([]domain.Task, error) {
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE user_id = ? AND ` + trashedTopCondition + `
+        ORDER BY deleted_at DESC, id DESC
+    `
+
+    return r.queryTasks(query, userID)
+}
+
+func (r *sqliteTaskRepository) GetTrashedByID(id, userID int64) (*domain.Task, error) {
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL
+    `
+
+    task := &domain.Task{}
+    err := scanTask(r.db.QueryRow(query, id, userID), task)
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting task: %w", err)
+    }
+
+    return task, nil
+}
+
+func (r *sqliteTaskRepository) Restore(id, userID int64) error {
+    tx, err := r.db.Begin()
+    if err != nil {
+        return fmt.Errorf("error starting transaction: %w", err)
+    }
+    defer tx.Rollback()
+
+    var deletedAt time.Time
+    err = tx.QueryRow(`SELECT deleted_at FROM tasks WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL`,
+        id, userID).Scan(&deletedAt)
+    if err == sql.ErrNoRows {
+        return domain.ErrTaskNotFound
+    }
+    if err != nil {
+        return fmt.Errorf("error getting task: %w", err)
+    }
+
+    _, err = tx.Exec(`UPDATE tasks SET deleted_at = NULL WHERE id = ? OR (parent_id = ? AND deleted_at = ?)`,
+        id, id, deletedAt)
+    if err != nil {
+        return fmt.Errorf("error restoring task: %w", err)
+    }
+
+    if err := tx.Commit(); err != nil {
+        return fmt.Errorf("error committing task restore: %w", err)
+    }
+
+    return nil
+}
+
+// Purge leaves the subtasks, and the rows hanging off every task, to the
+// foreign keys.
+func (r *sqliteTaskRepository) Purge(id, userID int64) error {
+    result, err := r.db.Exec(`DELETE FROM tasks WHERE id = ? AND user_id = ? AND deleted_at IS NOT NULL`, id, userID)
+    if err != nil {
+        return fmt.Errorf("error purging task: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrTaskNotFound
+    }
+
+    return nil
+}
+
+func (r *sqliteTaskRepository) GetTrashedBefore(before time.Time, limit int) ([]domain.Task, error) {
+    query := `
+        SELECT ` + taskColumns + `
+        FROM tasks
+        WHERE deleted_at < ? AND ` + trashedTopCondition + `
+        ORDER BY deleted_at, id
+        LIMIT ?
+    `
+
+    return r.queryTasks(query, before.UTC(), limit)
+}
+
+func (r *sqliteTaskRepository) queryTasks(query string, args ...interface{}) ([]domain.Task, error) {
+    rows, err := r.db.Query(query, args...)
+    if err != nil {
+        return nil, fmt.Errorf("error querying tasks: %w", err)
+    }
+    defer rows.Close()
+
+    var tasks []domain.Task
+    for rows.Next() {
+        var task domain.Task
+        if err := scanTask(rows, &task); err != nil {
+            return nil, fmt.Errorf("error scanning task: %w", err)
+        }
+        tasks = append(tasks, task)
+    }
+
+    if err = rows.Err(); err != nil {
+        return nil, fmt.Errorf("error iterating tasks: %w", err)
+    }
+
+    return tasks, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type sqliteUserRepository struct {
+    db *sql.DB
+}
+
+func NewSqliteUserRepository(db *sql.DB) domain.UserRepository {
+    return &sqliteUserRepository{db}
+}
+
+func (r *sqliteUserRepository) Create(user *domain.User) error {
+    query := `
+        INSERT INTO users (username, password, created_at, updated_at)
+        VALUES (?, ?, ?, ?)
+    `
+    
+    now := time.Now().UTC()
+    result, err := r.db.Exec(query, 
+        user.Username,
+        user.Password,
+        now,
+        now,
+    )
+    if isDuplicateKey(err) {
+        return domain.ErrUsernameTaken
+    }
+    if err != nil {
+        return fmt.Errorf("error creating user: %w", err)
+    }
+
+    id, err := result.LastInsertId()
+    if err != nil {
+        return fmt.Errorf("error getting last insert id: %w", err)
+    }
+
+    user.ID = id
+    user.CreatedAt = now
+    user.UpdatedAt = now
+    return nil
+}
+
+func (r *sqliteUserRepository) GetByUsername(username string) (*domain.User, error) {
+    query := `
+        SELECT id, username, password, created_at, updated_at
+        FROM users
+        WHERE username = ?
+    `
+
+    user := &domain.User{}
+    err := r.db.QueryRow(query, username).Scan(
+        &user.ID,
+        &user.Username,
+        &user.Password,
+        &user.CreatedAt,
+        &user.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting user by username: %w", err)
+    }
+
+    return user, nil
+}
+
+func (r *sqliteUserRepository) GetByID(id int64) (*domain.User, error) {
+    query := `
+        SELECT id, username, password, created_at, updated_at
+        FROM users
+        WHERE id = ?
+    `
+
+    user := &domain.User{}
+    err := r.db.QueryRow(query, id).Scan(
+        &user.ID,
+        &user.Username,
+        &user.Password,
+        &user.CreatedAt,
+        &user.UpdatedAt,
+    )
+
+    if err == sql.ErrNoRows {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("error getting user by id: %w", err)
+    }
+
+    return user, nil
+}
+
+func (r *sqliteUserRepository) UpdatePassword(id int64, password string) error {
+    query := `UPDATE users SET password = ?, updated_at = ? WHERE id = ?`
+
+    result, err := r.db.Exec(query, password, time.Now().UTC(), id)
+    if err != nil {
+        return fmt.Errorf("error updating password: %w", err)
+    }
+
+    affected, err := result.RowsAffected()
+    if err != nil {
+        return fmt.Errorf("error getting rows affected: %w", err)
+    }
+    if affected == 0 {
+        return domain.ErrUserNotFound
+    }
+
+    return nil
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// maxArchiveDays bounds older_than_days and the after_days of a policy to
+// ten years.
+const maxArchiveDays = 3650
+
+var (
+    errInvalidArchiveDays = domain.ValidationError("older_than_days must be between 0 and 3650")
+    errInvalidAfterDays   = domain.ValidationError("after_days must be between 0 and 3650")
+)
+
+type archivePolicyUsecase struct {
+    policyRepo domain.ArchivePolicyRepository
+    taskRepo   domain.TaskRepository
+}
+
+func NewArchivePolicyUsecase(
+    policyRepo domain.ArchivePolicyRepository,
+    taskRepo domain.TaskRepository,
+) domain.ArchivePolicyUsecase {
+    return &archivePolicyUsecase{
+        policyRepo: policyRepo,
+        taskRepo:   taskRepo,
+    }
+}
+
+func (u *archivePolicyUsecase) Get(userID int64) (*domain.ArchivePolicy, error) {
+    policy, err := u.policyRepo.Get(userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting archive policy: %w", err)
+    }
+    if policy == nil {
+        return &domain.ArchivePolicy{UserID: userID}, nil
+    }
+
+    return policy, nil
+}
+
+// Set stores no policy at all for an afterDays of 0, so ApplyAll only sees
+// the users who asked for automatic archiving.
+func (u *archivePolicyUsecase) Set(userID int64, afterDays int) (*domain.ArchivePolicy, error) {
+    if afterDays < 0 || afterDays > maxArchiveDays {
+        return nil, errInvalidAfterDays
+    }
+
+    policy := &domain.ArchivePolicy{UserID: userID, AfterDays: afterDays}
+    if afterDays == 0 {
+        if err := u.policyRepo.Delete(userID); err != nil {
+            return nil, fmt.Errorf("error deleting archive policy: %w", err)
+        }
+        policy.UpdatedAt = time.Now().UTC()
+        return policy, nil
+    }
+
+    if err := u.policyRepo.Save(policy); err != nil {
+        return nil, fmt.Errorf("error saving archive policy: %w", err)
+    }
+
+    return policy, nil
+}
+
+// ApplyAll carries on past a user whose tasks fail to archive, so one bad
+// policy does not hold up the others, and reports every failure.
+func (u *archivePolicyUsecase) ApplyAll(ctx context.Context) error {
+    policies, err := u.policyRepo.GetAll()
+    if err != nil {
+        return fmt.Errorf("error getting archive policies: %w", err)
+    }
+
+    now := time.Now().UTC()
+    var errs []error
+    for _, policy := range policies {
+        if err := ctx.Err(); err != nil {
+            return err
+        }
+        if _, err := archiveCompleted(u.taskRepo, policy.UserID, policy.AfterDays, now); err != nil {
+            errs = append(errs, fmt.Errorf("error applying archive policy of user %d: %w", policy.UserID, err))
+        }
+    }
+
+    return errors.Join(errs...)
+}
+
+// archiveCompleted archives the tasks of userID completed more than days
+// days before now.
+func archiveCompleted(taskRepo domain.TaskRepository, userID int64, days int, now time.Time) (int64, error) {
+    before := now.AddDate(0, 0, -days)
+    archived, err := taskRepo.ArchiveCompleted(userID, before, now)
+    if err != nil {
+        return 0, fmt.Errorf("error archiving completed tasks: %w", err)
+    }
+
+    return archived, nil
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	memoryrepo "todo-app/internal/repository/memory"
+)
+
+// newCompletedTask creates a task of userID completed daysAgo days ago.
+func (f *taskFixture) newCompletedTask(t *testing.T, userID int64, title string, daysAgo int) *domain.Task {
+    t.Helper()
+
+    completedAt := time.Now().UTC().AddDate(0, 0, -daysAgo)
+    return f.newTask(t, userID, domain.TaskInput{Title: title, Done: true, CompletedAt: &completedAt})
+}
+
+// archivedTitles lists the titles of the archived tasks of userID.
+func (f *taskFixture) archivedTitles(t *testing.T, userID int64) []string {
+    t.Helper()
+
+    page, err := f.tasks.GetAllByUserID(userID, domain.TaskQuery{Archived: domain.ArchivedOnly, Sort: domain.TaskSortTitle})
+    if err != nil {
+        t.Fatalf("GetAllByUserID: %v", err)
+    }
+    return taskTitles(page.Tasks)
+}
+
+func TestArchivePolicy(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    policies := NewArchivePolicyUsecase(memoryrepo.NewMemoryArchivePolicyRepository(f.store), f.taskRepo)
+
+    f.newCompletedTask(t, alice, "Done long ago", 10)
+    f.newCompletedTask(t, alice, "Done lately", 2)
+    f.newTask(t, alice, domain.TaskInput{Title: "Still open"})
+    f.newCompletedTask(t, bob, "Bob's old task", 30)
+
+    for _, days := range []int{-1, maxArchiveDays + 1} {
+        if _, err := policies.Set(alice, days); !errors.Is(err, errInvalidAfterDays) {
+            t.Errorf("Set(%d): err = %v, want errInvalidAfterDays", days, err)
+        }
+    }
+    if _, err := policies.Set(alice, 7); err != nil {
+        t.Fatalf("Set: %v", err)
+    }
+    if policy, err := policies.Get(alice); err != nil || policy.AfterDays != 7 {
+        t.Errorf("Get = %+v, %v; want after 7 days", policy, err)
+    }
+
+    // Only the tasks of users with a policy, completed long enough ago, are
+    // archived.
+    if err := policies.ApplyAll(context.Background()); err != nil {
+        t.Fatalf("ApplyAll: %v", err)
+    }
+    if got := f.archivedTitles(t, alice); !equalTitles(got, []string{"Done long ago"}) {
+        t.Errorf("alice's archived tasks = %v, want [Done long ago]", got)
+    }
+    if got := f.archivedTitles(t, bob); len(got) != 0 {
+        t.Errorf("bob has no policy but archived tasks %v", got)
+    }
+    page, err := f.tasks.GetAllByUserID(alice, domain.TaskQuery{Sort: domain.TaskSortTitle})
+    if err != nil {
+        t.Fatalf("GetAllByUserID: %v", err)
+    }
+    if got := taskTitles(page.Tasks); !equalTitles(got, []string{"Done lately", "Still open"}) {
+        t.Errorf("alice lists %v, want the archived task left out", got)
+    }
+
+    // Setting zero turns automatic archiving off.
+    if _, err := policies.Set(alice, 0); err != nil {
+        t.Fatalf("Set(0): %v", err)
+    }
+    if policy, err := policies.Get(alice); err != nil || policy.AfterDays != 0 {
+        t.Errorf("Get after Set(0) = %+v, %v", policy, err)
+    }
+    f.newCompletedTask(t, alice, "Done before the policy was lifted", 20)
+    if err := policies.ApplyAll(context.Background()); err != nil {
+        t.Fatalf("ApplyAll: %v", err)
+    }
+    if got := f.archivedTitles(t, alice); !equalTitles(got, []string{"Done long ago"}) {
+        t.Errorf("alice's archived tasks without a policy = %v", got)
+    }
+}
+
+func TestArchiveCompleted(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    f.newCompletedTask(t, alice, "Done last month", 30)
+    f.newCompletedTask(t, alice, "Done last week", 7)
+    f.newCompletedTask(t, alice, "Done today", 0)
+
+    for _, days := range []int{-1, maxArchiveDays + 1} {
+        if _, err := f.tasks.ArchiveCompleted(alice, days); !errors.Is(err, errInvalidArchiveDays) {
+            t.Errorf("ArchiveCompleted(%d): err = %v, want errInvalidArchiveDays", days, err)
+        }
+    }
+
+    archived, err := f.tasks.ArchiveCompleted(alice, 14)
+    if err != nil || archived != 1 {
+        t.Fatalf("ArchiveCompleted(14) = %d, %v; want 1", archived, err)
+    }
+    // Archiving again counts only the newly archived tasks.
+    archived, err = f.tasks.ArchiveCompleted(alice, 1)
+    if err != nil || archived != 1 {
+        t.Fatalf("ArchiveCompleted(1) = %d, %v; want 1", archived, err)
+    }
+    if got := f.archivedTitles(t, alice); !equalTitles(got, []string{"Done last month", "Done last week"}) {
+        t.Errorf("archived tasks = %v", got)
+    }
+
+    // An unarchived task is listed again, and not archived again before it
+    // is next past the cutoff.
+    page, err := f.tasks.GetAllByUserID(alice, domain.TaskQuery{Archived: domain.ArchivedOnly, Sort: domain.TaskSortTitle})
+    if err != nil {
+        t.Fatalf("GetAllByUserID: %v", err)
+    }
+    if err := f.tasks.Unarchive(page.Tasks[1].ID, alice); err != nil {
+        t.Fatalf("Unarchive: %v", err)
+    }
+    if got := f.archivedTitles(t, alice); !equalTitles(got, []string{"Done last month"}) {
+        t.Errorf("archived tasks after Unarchive = %v", got)
+    }
+    if archived, err := f.tasks.ArchiveCompleted(alice, 14); err != nil || archived != 0 {
+        t.Errorf("ArchiveCompleted(14) after Unarchive = %d, %v; want 0", archived, err)
+    }
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"fmt"
+	"todo-app/internal/domain"
+)
+
+type assignmentUsecase struct {
+    assignmentRepo domain.AssignmentRepository
+    access         taskAccess
+    history        taskHistory
+}
+
+func NewAssignmentUsecase(
+    assignmentRepo domain.AssignmentRepository,
+    taskRepo domain.TaskRepository,
+    shareRepo domain.ShareRepository,
+    historyRepo domain.TaskHistoryRepository,
+) domain.AssignmentUsecase {
+    return &assignmentUsecase{
+        assignmentRepo: assignmentRepo,
+        access:         taskAccess{taskRepo, shareRepo},
+        history:        taskHistory{historyRepo, taskRepo},
+    }
+}
+
+func (u *assignmentUsecase) Assign(taskID, userID int64, assigneeID *int64) error {
+    task, err := u.access.get(taskID, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+    if sameID(task.AssigneeID, assigneeID) {
+        return nil
+    }
+
+    if assigneeID != nil {
+        // The same rules that decide what the caller may do decide whether
+        // the assignee can see the task.
+        tasks := []domain.Task{*task}
+        if err := u.access.resolve(*assigneeID, tasks); err != nil {
+            return err
+        }
+        if tasks[0].Permission == "" {
+            return domain.ErrAssigneeNoAccess
+        }
+    }
+
+    assignment := &domain.Assignment{
+        TaskID:     taskID,
+        AssignedBy: userID,
+        FromUserID: task.AssigneeID,
+        ToUserID:   assigneeID,
+    }
+
+    if err := u.assignmentRepo.Assign(task.UserID, assignment); err != nil {
+        return fmt.Errorf("error assigning task: %w", err)
+    }
+
+    return u.history.updated(userID, task, domain.HistoryUpdated)
+}
+
+func (u *assignmentUsecase) GetHistory(taskID, userID int64) ([]domain.Assignment, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    assignments, err := u.assignmentRepo.GetByTaskID(taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting assignments: %w", err)
+    }
+    if assignments == nil {
+        assignments = []domain.Assignment{}
+    }
+
+    return assignments, nil
+}
@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"todo-app/internal/domain"
+	"unicode"
+	"unicode/utf8"
+)
+
+const maxAttachmentFilenameLength = 255
+
+type attachmentUsecase struct {
+    attachmentRepo domain.AttachmentRepository
+    blobs          domain.BlobStore
+    access         taskAccess
+    // maxSize bounds a single upload and quota the total a user may upload,
+    // both in bytes.
+    maxSize int64
+    quota   int64
+}
+
+func NewAttachmentUsecase(
+    attachmentRepo domain.AttachmentRepository,
+    taskRepo domain.TaskRepository,
+    shareRepo domain.ShareRepository,
+    blobs domain.BlobStore,
+    maxSize int64,
+    quota int64,
+) domain.AttachmentUsecase {
+    return &attachmentUsecase{
+        attachmentRepo: attachmentRepo,
+        blobs:          blobs,
+        access:         taskAccess{taskRepo, shareRepo},
+        maxSize:        maxSize,
+        quota:          quota,
+    }
+}
+
+// Upload reads the whole content before storing it, so its size and type are
+// known up front and nothing is written for an upload that is refused. Two
+// uploads racing each other can overshoot the quota by at most one file.
+func (u *attachmentUsecase) Upload(ctx context.Context, taskID, userID int64, filename string, content io.Reader) (*domain.Attachment, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionEditor); err != nil {
+        return nil, err
+    }
+
+    data, err := io.ReadAll(io.LimitReader(content, u.maxSize+1))
+    if err != nil {
+        return nil, fmt.Errorf("error reading attachment: %w", err)
+    }
+    if len(data) == 0 {
+        return nil, domain.ErrAttachmentEmpty
+    }
+    if int64(len(data)) > u.maxSize {
+        return nil, domain.ErrAttachmentTooLarge
+    }
+
+    usage, err := u.attachmentRepo.UsageByUserID(userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting attachment usage: %w", err)
+    }
+    if usage+int64(len(data)) > u.quota {
+        return nil, domain.ErrAttachmentQuota
+    }
+
+    key, err := newBlobKey(taskID)
+    if err != nil {
+        return nil, err
+    }
+
+    attachment := &domain.Attachment{
+        TaskID:      taskID,
+        UserID:      userID,
+        Filename:    cleanFilename(filename),
+        ContentType: http.DetectContentType(data),
+        Size:        int64(len(data)),
+        StorageKey:  key,
+    }
+
+    if err := u.blobs.Put(ctx, key, attachment.ContentType, bytes.NewReader(data), attachment.Size); err != nil {
+        return nil, fmt.Errorf("error storing attachment content: %w", err)
+    }
+
+    if err := u.attachmentRepo.Create(attachment); err != nil {
+        // Nothing refers to the blob yet; do not leave it behind.
+        u.blobs.Delete(ctx, key)
+        return nil, fmt.Errorf("error creating attachment: %w", err)
+    }
+
+    return attachment, nil
+}
+
+func (u *attachmentUsecase) Open(ctx context.Context, id, taskID, userID int64) (*domain.Attachment, io.ReadCloser, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, nil, err
+    }
+
+    attachment, err := u.get(id, taskID)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    content, err := u.blobs.Get(ctx, attachment.StorageKey)
+    if err != nil {
+        return nil, nil, fmt.Errorf("error reading attachment content: %w", err)
+    }
+
+    return attachment, content, nil
+}
+
+// Delete removes the content before the row, so a failure part way leaves
+// an attachment that can still be deleted rather than an unreferenced blob.
+func (u *attachmentUsecase) Delete(ctx context.Context, id, taskID, userID int64) error {
+    if _, err := u.access.get(taskID, userID, domain.PermissionEditor); err != nil {
+        return err
+    }
+
+    attachment, err := u.get(id, taskID)
+    if err != nil {
+        return err
+    }
+
+    if err := u.blobs.Delete(ctx, attachment.StorageKey); err != nil {
+        return fmt.Errorf("error deleting attachment content: %w", err)
+    }
+
+    if err := u.attachmentRepo.Delete(id, taskID); err != nil {
+        return fmt.Errorf("error deleting attachment: %w", err)
+    }
+
+    return nil
+}
+
+func (u *attachmentUsecase) GetByTaskID(taskID, userID int64) ([]domain.Attachment, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    attachments, err := u.attachmentRepo.GetByTaskID(taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting attachments: %w", err)
+    }
+    if attachments == nil {
+        attachments = []domain.Attachment{}
+    }
+
+    return attachments, nil
+}
+
+func (u *attachmentUsecase) get(id, taskID int64) (*domain.Attachment, error) {
+    attachment, err := u.attachmentRepo.GetByID(id, taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting attachment: %w", err)
+    }
+    if attachment == nil {
+        return nil, domain.ErrAttachmentNotFound
+    }
+
+    return attachment, nil
+}
+
+// newBlobKey returns an unguessable key for new content of a task.
+func newBlobKey(taskID int64) (string, error) {
+    random := make([]byte, 16)
+    if _, err := rand.Read(random); err != nil {
+        return "", fmt.Errorf("error generating attachment key: %w", err)
+    }
+
+    return fmt.Sprintf("tasks/%d/%s", taskID, hex.EncodeToString(random)), nil
+}
+
+// cleanFilename keeps the base name of what the client sent, without
+// control characters or anything that would need escaping in a header, and
+// trims it to the length the schema allows.
+func cleanFilename(filename string) string {
+    filename = path.Base(strings.ReplaceAll(filename, `\`, "/"))
+    filename = strings.Map(func(r rune) rune {
+        if unicode.IsControl(r) || r == '"' {
+            return -1
+        }
+        return r
+    }, filename)
+    filename = strings.TrimSpace(filename)
+
+    if filename == "" || filename == "." || filename == "/" {
+        return "attachment"
+    }
+    if utf8.RuneCountInString(filename) > maxAttachmentFilenameLength {
+        filename = string([]rune(filename)[:maxAttachmentFilenameLength])
+    }
+    return filename
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"todo-app/internal/domain"
+	"unicode/utf8"
+)
+
+const maxCommentBodyLength = 10000
+
+type commentUsecase struct {
+    commentRepo domain.CommentRepository
+    access      taskAccess
+}
+
+func NewCommentUsecase(
+    commentRepo domain.CommentRepository,
+    taskRepo domain.TaskRepository,
+    shareRepo domain.ShareRepository,
+) domain.CommentUsecase {
+    return &commentUsecase{
+        commentRepo: commentRepo,
+        access:      taskAccess{taskRepo, shareRepo},
+    }
+}
+
+// Create adds a comment by userID. Anyone who can see a task can take part
+// in its discussion, viewers included.
+func (u *commentUsecase) Create(taskID, userID int64, body string) (*domain.Comment, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    body, err := normalizeCommentBody(body)
+    if err != nil {
+        return nil, err
+    }
+
+    comment := &domain.Comment{
+        TaskID: taskID,
+        UserID: userID,
+        Body:   body,
+    }
+
+    if err := u.commentRepo.Create(comment); err != nil {
+        return nil, fmt.Errorf("error creating comment: %w", err)
+    }
+
+    return u.get(comment.ID, taskID)
+}
+
+func (u *commentUsecase) Update(id, taskID, userID int64, body string) (*domain.Comment, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    comment, err := u.get(id, taskID)
+    if err != nil {
+        return nil, err
+    }
+    if comment.UserID != userID {
+        return nil, domain.ErrCommentAuthorOnly
+    }
+
+    comment.Body, err = normalizeCommentBody(body)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := u.commentRepo.Update(comment); err != nil {
+        return nil, fmt.Errorf("error updating comment: %w", err)
+    }
+
+    return comment, nil
+}
+
+func (u *commentUsecase) Delete(id, taskID, userID int64) error {
+    task, err := u.access.get(taskID, userID, domain.PermissionViewer)
+    if err != nil {
+        return err
+    }
+
+    comment, err := u.get(id, taskID)
+    if err != nil {
+        return err
+    }
+    // The owner moderates the discussion on their task.
+    if comment.UserID != userID && task.Permission != domain.PermissionOwner {
+        return domain.ErrCommentAuthorOnly
+    }
+
+    if err := u.commentRepo.Delete(id, taskID); err != nil {
+        return fmt.Errorf("error deleting comment: %w", err)
+    }
+
+    return nil
+}
+
+func (u *commentUsecase) GetByTaskID(taskID, userID int64) ([]domain.Comment, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    comments, err := u.commentRepo.GetByTaskID(taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting comments: %w", err)
+    }
+    if comments == nil {
+        comments = []domain.Comment{}
+    }
+
+    return comments, nil
+}
+
+func (u *commentUsecase) get(id, taskID int64) (*domain.Comment, error) {
+    comment, err := u.commentRepo.GetByID(id, taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting comment: %w", err)
+    }
+    if comment == nil {
+        return nil, domain.ErrCommentNotFound
+    }
+
+    return comment, nil
+}
+
+// normalizeCommentBody trims surrounding space and enforces the length the
+// schema allows.
+func normalizeCommentBody(body string) (string, error) {
+    body = strings.TrimSpace(body)
+    if body == "" {
+        return "", domain.ErrCommentBodyRequired
+    }
+    if utf8.RuneCountInString(body) > maxCommentBodyLength {
+        return "", domain.ErrCommentBodyTooLong
+    }
+    return body, nil
+}
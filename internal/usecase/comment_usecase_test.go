@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"todo-app/internal/domain"
+	memoryrepo "todo-app/internal/repository/memory"
+)
+
+func TestCommentThread(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob, carol := f.newUser(t, "alice"), f.newUser(t, "bob"), f.newUser(t, "carol")
+    comments := NewCommentUsecase(memoryrepo.NewMemoryCommentRepository(f.store), f.taskRepo, f.shareRepo)
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Choose a paint colour"})
+    f.shareTask(t, task.ID, alice, "bob", domain.PermissionViewer)
+
+    first, err := comments.Create(task.ID, alice, "  Blue or green? ")
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    if first.Body != "Blue or green?" || first.Username != "alice" || first.EditedAt != nil {
+        t.Errorf("comment = %+v, want the trimmed body by alice", first)
+    }
+    // Viewers take part in the discussion too.
+    reply, err := comments.Create(task.ID, bob, "Green")
+    if err != nil {
+        t.Fatalf("Create by a viewer: %v", err)
+    }
+
+    for _, tc := range []struct {
+        body string
+        err  error
+    }{
+        {" \n", domain.ErrCommentBodyRequired},
+        {strings.Repeat("x", maxCommentBodyLength+1), domain.ErrCommentBodyTooLong},
+    } {
+        if _, err := comments.Create(task.ID, alice, tc.body); !errors.Is(err, tc.err) {
+            t.Errorf("Create of a %d-byte body: err = %v, want %v", len(tc.body), err, tc.err)
+        }
+    }
+    if _, err := comments.Create(task.ID, carol, "Red!"); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Create by a stranger: err = %v, want ErrTaskNotFound", err)
+    }
+    if _, err := comments.GetByTaskID(task.ID, carol); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("GetByTaskID by a stranger: err = %v, want ErrTaskNotFound", err)
+    }
+
+    // Only the author edits a comment.
+    if _, err := comments.Update(reply.ID, task.ID, alice, "Blue"); !errors.Is(err, domain.ErrCommentAuthorOnly) {
+        t.Errorf("Update by the task owner: err = %v, want ErrCommentAuthorOnly", err)
+    }
+    edited, err := comments.Update(reply.ID, task.ID, bob, "Sage green")
+    if err != nil {
+        t.Fatalf("Update by the author: %v", err)
+    }
+    if edited.Body != "Sage green" || edited.EditedAt == nil {
+        t.Errorf("edited comment = %+v", edited)
+    }
+
+    list, err := comments.GetByTaskID(task.ID, bob)
+    if err != nil {
+        t.Fatalf("GetByTaskID: %v", err)
+    }
+    if len(list) != 2 || list[0].ID != first.ID || list[1].ID != reply.ID || list[1].Body != "Sage green" {
+        t.Errorf("thread = %+v, want alice's comment then bob's edited reply", list)
+    }
+
+    // A comment is only found on its own task.
+    other := f.newTask(t, alice, domain.TaskInput{Title: "Other"})
+    if _, err := comments.Update(first.ID, other.ID, alice, "Moved"); !errors.Is(err, domain.ErrCommentNotFound) {
+        t.Errorf("Update through another task: err = %v, want ErrCommentNotFound", err)
+    }
+}
+
+func TestDeleteComment(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob, carol := f.newUser(t, "alice"), f.newUser(t, "bob"), f.newUser(t, "carol")
+    comments := NewCommentUsecase(memoryrepo.NewMemoryCommentRepository(f.store), f.taskRepo, f.shareRepo)
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Choose a paint colour"})
+    f.shareTask(t, task.ID, alice, "bob", domain.PermissionEditor)
+    f.shareTask(t, task.ID, alice, "carol", domain.PermissionEditor)
+
+    create := func(userID int64, body string) *domain.Comment {
+        t.Helper()
+        comment, err := comments.Create(task.ID, userID, body)
+        if err != nil {
+            t.Fatalf("Create: %v", err)
+        }
+        return comment
+    }
+    bobs, carols := create(bob, "Green"), create(carol, "Blue")
+
+    // Editors delete their own comments only; the owner moderates.
+    if err := comments.Delete(bobs.ID, task.ID, carol); !errors.Is(err, domain.ErrCommentAuthorOnly) {
+        t.Errorf("Delete by another editor: err = %v, want ErrCommentAuthorOnly", err)
+    }
+    if err := comments.Delete(bobs.ID, task.ID, bob); err != nil {
+        t.Errorf("Delete by the author: %v", err)
+    }
+    if err := comments.Delete(carols.ID, task.ID, alice); err != nil {
+        t.Errorf("Delete by the task owner: %v", err)
+    }
+    if err := comments.Delete(carols.ID, task.ID, alice); !errors.Is(err, domain.ErrCommentNotFound) {
+        t.Errorf("deleting a deleted comment: err = %v, want ErrCommentNotFound", err)
+    }
+    if list, err := comments.GetByTaskID(task.ID, alice); err != nil || len(list) != 0 {
+        t.Errorf("thread after deleting both = %+v, %v", list, err)
+    }
+}
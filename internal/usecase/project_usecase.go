@@ -0,0 +1,182 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"todo-app/internal/domain"
+)
+
+type projectUsecase struct {
+    projectRepo domain.ProjectRepository
+    taskRepo    domain.TaskRepository
+    history     taskHistory
+}
+
+func NewProjectUsecase(
+    projectRepo domain.ProjectRepository,
+    taskRepo domain.TaskRepository,
+    historyRepo domain.TaskHistoryRepository,
+) domain.ProjectUsecase {
+    return &projectUsecase{
+        projectRepo: projectRepo,
+        taskRepo:    taskRepo,
+        history:     taskHistory{historyRepo, taskRepo},
+    }
+}
+
+func (u *projectUsecase) Create(userID int64, input domain.ProjectInput) (*domain.Project, error) {
+    name := strings.TrimSpace(input.Name)
+    if name == "" {
+        return nil, domain.ErrProjectNameRequired
+    }
+
+    project := &domain.Project{
+        UserID:      userID,
+        Name:        name,
+        Description: input.Description,
+    }
+
+    if err := u.projectRepo.Create(project); err != nil {
+        return nil, fmt.Errorf("error creating project: %w", err)
+    }
+
+    return project, nil
+}
+
+func (u *projectUsecase) Update(id, userID int64, input domain.ProjectInput) error {
+    project, err := u.GetByID(id, userID)
+    if err != nil {
+        return err
+    }
+
+    name := strings.TrimSpace(input.Name)
+    if name == "" {
+        return domain.ErrProjectNameRequired
+    }
+
+    project.Name = name
+    project.Description = input.Description
+
+    if err := u.projectRepo.Update(project); err != nil {
+        return fmt.Errorf("error updating project: %w", err)
+    }
+
+    return nil
+}
+
+// Delete records what happens to each task of the project in its history:
+// it is either deleted or moved out of the project.
+func (u *projectUsecase) Delete(id, userID int64, deleteTasks bool) error {
+    tasks, err := u.taskRepo.GetByProjectID(id, userID)
+    if err != nil {
+        return fmt.Errorf("error getting project tasks: %w", err)
+    }
+    if deleteTasks {
+        if tasks, err = u.withSubtasks(tasks, userID); err != nil {
+            return err
+        }
+    }
+
+    if err := u.projectRepo.Delete(id, userID, deleteTasks); err != nil {
+        return fmt.Errorf("error deleting project: %w", err)
+    }
+
+    for i := range tasks {
+        if deleteTasks {
+            err = u.history.deleted(userID, &tasks[i])
+        } else {
+            err = u.history.updated(userID, &tasks[i], domain.HistoryUpdated)
+        }
+        if err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// withSubtasks adds to tasks the subtasks of its tasks that are in another
+// project, which are deleted along with their parent.
+func (u *projectUsecase) withSubtasks(tasks []domain.Task, userID int64) ([]domain.Task, error) {
+    listed := make(map[int64]bool, len(tasks))
+    for _, task := range tasks {
+        listed[task.ID] = true
+    }
+
+    all := tasks
+    for _, task := range tasks {
+        if task.ParentID != nil {
+            continue
+        }
+        subtasks, err := u.taskRepo.GetSubtasks(task.ID, userID)
+        if err != nil {
+            return nil, fmt.Errorf("error getting subtasks: %w", err)
+        }
+        for _, subtask := range subtasks {
+            if !listed[subtask.ID] {
+                all = append(all, subtask)
+            }
+        }
+    }
+
+    return all, nil
+}
+
+func (u *projectUsecase) GetByID(id, userID int64) (*domain.Project, error) {
+    project, err := u.projectRepo.GetByID(id, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting project: %w", err)
+    }
+    if project == nil {
+        return nil, domain.ErrProjectNotFound
+    }
+
+    return project, nil
+}
+
+func (u *projectUsecase) GetAllByUserID(userID int64, includeArchived bool) ([]domain.Project, error) {
+    projects, err := u.projectRepo.GetAllByUserID(userID, includeArchived)
+    if err != nil {
+        return nil, fmt.Errorf("error getting projects: %w", err)
+    }
+
+    return projects, nil
+}
+
+func (u *projectUsecase) Archive(id, userID int64) error {
+    project, err := u.GetByID(id, userID)
+    if err != nil {
+        return err
+    }
+    if project.ArchivedAt != nil {
+        return nil
+    }
+
+    now := time.Now().UTC()
+    project.ArchivedAt = &now
+
+    if err := u.projectRepo.Update(project); err != nil {
+        return fmt.Errorf("error archiving project: %w", err)
+    }
+
+    return nil
+}
+
+func (u *projectUsecase) Unarchive(id, userID int64) error {
+    project, err := u.GetByID(id, userID)
+    if err != nil {
+        return err
+    }
+    if project.ArchivedAt == nil {
+        return nil
+    }
+
+    project.ArchivedAt = nil
+
+    if err := u.projectRepo.Update(project); err != nil {
+        return fmt.Errorf("error unarchiving project: %w", err)
+    }
+
+    return nil
+}
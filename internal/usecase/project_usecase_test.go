@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+// lastAction returns the action of the newest history entry of a task,
+// read from the repository so that it works for tasks in the trash.
+func (f *taskFixture) lastAction(t *testing.T, taskID int64) domain.HistoryAction {
+    t.Helper()
+
+    versions, err := f.historyRepo.GetByTaskID(taskID)
+    if err != nil {
+        t.Fatalf("GetByTaskID: %v", err)
+    }
+    if len(versions) == 0 {
+        t.Fatalf("task %d has no history", taskID)
+    }
+    return versions[len(versions)-1].Action
+}
+
+func TestArchivedProjectTakesNoNewTasks(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    projects := NewProjectUsecase(f.projectRepo, f.taskRepo, f.historyRepo)
+    project := f.newProject(t, alice, "Garden")
+    inside := f.newTask(t, alice, domain.TaskInput{Title: "Mow the lawn", ProjectID: &project.ID})
+    outside := f.newTask(t, alice, domain.TaskInput{Title: "Buy seeds"})
+
+    if err := projects.Archive(project.ID, alice); err != nil {
+        t.Fatalf("Archive: %v", err)
+    }
+    if list, err := projects.GetAllByUserID(alice, false); err != nil || len(list) != 0 {
+        t.Errorf("GetAllByUserID without archived = %+v, %v", list, err)
+    }
+
+    if err := f.tasks.Create(alice, domain.TaskInput{Title: "Prune", ProjectID: &project.ID}); !errors.Is(err, domain.ErrProjectArchived) {
+        t.Errorf("Create in an archived project: err = %v, want ErrProjectArchived", err)
+    }
+    if err := f.tasks.MoveToProject(outside.ID, alice, &project.ID); !errors.Is(err, domain.ErrProjectArchived) {
+        t.Errorf("MoveToProject into an archived project: err = %v, want ErrProjectArchived", err)
+    }
+    // A task already there can still be worked on, and moved out.
+    if err := f.tasks.Update(inside.ID, alice, domain.TaskInput{Title: "Mow the lawn twice", ProjectID: &project.ID}); err != nil {
+        t.Errorf("Update of a task in an archived project: %v", err)
+    }
+    if err := f.tasks.MoveToProject(inside.ID, alice, nil); err != nil {
+        t.Errorf("MoveToProject out of an archived project: %v", err)
+    }
+
+    if err := projects.Unarchive(project.ID, alice); err != nil {
+        t.Fatalf("Unarchive: %v", err)
+    }
+    if err := f.tasks.MoveToProject(outside.ID, alice, &project.ID); err != nil {
+        t.Errorf("MoveToProject after Unarchive: %v", err)
+    }
+}
+
+func TestProjectsArePrivate(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    projects := NewProjectUsecase(f.projectRepo, f.taskRepo, f.historyRepo)
+    project := f.newProject(t, alice, "Garden")
+    task := f.newTask(t, bob, domain.TaskInput{Title: "Bob's chore"})
+
+    if _, err := projects.Create(alice, domain.ProjectInput{Name: "  "}); !errors.Is(err, domain.ErrProjectNameRequired) {
+        t.Errorf("Create without a name: err = %v, want ErrProjectNameRequired", err)
+    }
+    if _, err := projects.GetByID(project.ID, bob); !errors.Is(err, domain.ErrProjectNotFound) {
+        t.Errorf("GetByID by another user: err = %v, want ErrProjectNotFound", err)
+    }
+    if err := f.tasks.MoveToProject(task.ID, bob, &project.ID); !errors.Is(err, domain.ErrProjectNotFound) {
+        t.Errorf("MoveToProject into another user's project: err = %v, want ErrProjectNotFound", err)
+    }
+    if _, err := f.tasks.ListByProject(bob, project.ID, domain.TaskQuery{}); !errors.Is(err, domain.ErrProjectNotFound) {
+        t.Errorf("ListByProject by another user: err = %v, want ErrProjectNotFound", err)
+    }
+
+    // Sharing the project lets bob list it.
+    f.newTask(t, alice, domain.TaskInput{Title: "Mow the lawn", ProjectID: &project.ID})
+    f.shareProject(t, project.ID, alice, "bob", domain.PermissionViewer)
+    page, err := f.tasks.ListByProject(bob, project.ID, domain.TaskQuery{})
+    if err != nil {
+        t.Fatalf("ListByProject: %v", err)
+    }
+    if got := taskTitles(page.Tasks); !equalTitles(got, []string{"Mow the lawn"}) {
+        t.Errorf("bob lists %v in the shared project", got)
+    }
+}
+
+func TestDeleteProject(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    projects := NewProjectUsecase(f.projectRepo, f.taskRepo, f.historyRepo)
+
+    // Without its tasks, they stay, out of any project.
+    kept := f.newProject(t, alice, "Kept")
+    stays := f.newTask(t, alice, domain.TaskInput{Title: "Stays", ProjectID: &kept.ID})
+    if err := projects.Delete(kept.ID, alice, false); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if got := f.getTask(t, stays.ID, alice); got.ProjectID != nil {
+        t.Errorf("task still in deleted project %d", *got.ProjectID)
+    }
+    if action := f.lastAction(t, stays.ID); action != domain.HistoryUpdated {
+        t.Errorf("history of the task moved out records %q, want updated", action)
+    }
+
+    // With its tasks, they go to the trash with their subtasks, even those
+    // in another project.
+    dropped := f.newProject(t, alice, "Dropped")
+    other := f.newProject(t, alice, "Other")
+    parent := f.newTask(t, alice, domain.TaskInput{Title: "Parent", ProjectID: &dropped.ID})
+    subtask := f.newSubtask(t, parent.ID, alice, "Subtask")
+    if err := f.tasks.MoveToProject(subtask.ID, alice, &other.ID); err != nil {
+        t.Fatalf("MoveToProject: %v", err)
+    }
+    if err := projects.Delete(dropped.ID, alice, true); err != nil {
+        t.Fatalf("Delete with tasks: %v", err)
+    }
+    for _, task := range []*domain.Task{parent, subtask} {
+        if _, err := f.tasks.GetByID(task.ID, alice); !errors.Is(err, domain.ErrTaskNotFound) {
+            t.Errorf("%q: err = %v after its project was deleted, want ErrTaskNotFound", task.Title, err)
+        }
+        if action := f.lastAction(t, task.ID); action != domain.HistoryDeleted {
+            t.Errorf("history of %q records %q, want deleted", task.Title, action)
+        }
+    }
+    trash, err := f.taskRepo.GetTrash(alice)
+    if err != nil {
+        t.Fatalf("GetTrash: %v", err)
+    }
+    if got := taskTitles(trash); !equalTitles(got, []string{"Parent"}) {
+        t.Errorf("trash = %v, want the parent with its subtask", got)
+    }
+}
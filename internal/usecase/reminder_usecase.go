@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+	"unicode/utf8"
+)
+
+const (
+    // reminderBatchSize bounds how many reminders one DispatchDue call sends;
+    // the rest wait for the next run.
+    reminderBatchSize = 100
+    // maxReminderAttempts is how often a failing reminder is tried before
+    // the scheduler gives up on it.
+    maxReminderAttempts = 5
+    // reminderClaimTimeout is how long a claimed reminder is left to the
+    // instance delivering it. It outlasts a batch of deliveries that all
+    // run into the webhook timeout.
+    reminderClaimTimeout = 30 * time.Minute
+    // maxReminderErrorLength matches the width of reminders.last_error.
+    maxReminderErrorLength = 1024
+)
+
+var errNegativeMinutesBefore = domain.ValidationError("minutes_before must not be negative")
+
+type reminderUsecase struct {
+    reminderRepo domain.ReminderRepository
+    access       taskAccess
+    notifier     domain.Notifier
+}
+
+func NewReminderUsecase(
+    reminderRepo domain.ReminderRepository,
+    taskRepo domain.TaskRepository,
+    shareRepo domain.ShareRepository,
+    notifier domain.Notifier,
+) domain.ReminderUsecase {
+    return &reminderUsecase{
+        reminderRepo: reminderRepo,
+        access:       taskAccess{taskRepo, shareRepo},
+        notifier:     notifier,
+    }
+}
+
+func (u *reminderUsecase) Create(taskID, userID int64, input domain.ReminderInput) (*domain.Reminder, error) {
+    // Reminders are personal, so reading the task is enough to set one.
+    task, err := u.access.get(taskID, userID, domain.PermissionViewer)
+    if err != nil {
+        return nil, err
+    }
+
+    var remindAt time.Time
+    switch {
+    case (input.RemindAt == nil) == (input.MinutesBefore == nil):
+        return nil, domain.ErrReminderTimeRequired
+    case input.RemindAt != nil:
+        remindAt = input.RemindAt.UTC()
+    case *input.MinutesBefore < 0:
+        return nil, errNegativeMinutesBefore
+    case task.DueAt == nil:
+        return nil, domain.ErrReminderNeedsDueAt
+    default:
+        remindAt = task.DueAt.UTC().Add(-time.Duration(*input.MinutesBefore) * time.Minute)
+    }
+
+    reminder := &domain.Reminder{
+        TaskID:   taskID,
+        UserID:   userID,
+        RemindAt: remindAt,
+    }
+
+    if err := u.reminderRepo.Create(reminder); err != nil {
+        return nil, fmt.Errorf("error creating reminder: %w", err)
+    }
+
+    return reminder, nil
+}
+
+func (u *reminderUsecase) Delete(id, taskID, userID int64) error {
+    if err := u.reminderRepo.Delete(id, taskID, userID); err != nil {
+        return fmt.Errorf("error deleting reminder: %w", err)
+    }
+
+    return nil
+}
+
+func (u *reminderUsecase) GetByTaskID(taskID, userID int64) ([]domain.Reminder, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    reminders, err := u.reminderRepo.GetByTaskID(taskID, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting reminders: %w", err)
+    }
+    if reminders == nil {
+        reminders = []domain.Reminder{}
+    }
+
+    return reminders, nil
+}
+
+// DispatchDue sends up to reminderBatchSize due reminders. Reminders of
+// tasks that are already done count as sent without notifying anyone.
+// Delivery is at least once: a reminder whose claim runs out before its
+// outcome is recorded is sent again.
+func (u *reminderUsecase) DispatchDue(ctx context.Context) error {
+    err := u.reminderRepo.ProcessDue(time.Now().UTC(), reminderBatchSize, maxReminderAttempts, reminderClaimTimeout,
+        func(notification domain.Notification) error {
+            if notification.TaskDone {
+                return nil
+            }
+            if err := u.notifier.Notify(ctx, notification); err != nil {
+                return errors.New(truncate(err.Error(), maxReminderErrorLength))
+            }
+            return nil
+        })
+    if err != nil {
+        return fmt.Errorf("error dispatching reminders: %w", err)
+    }
+
+    return nil
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+    if len(s) <= n {
+        return s
+    }
+    for n > 0 && !utf8.RuneStart(s[n]) {
+        n--
+    }
+    return s[:n]
+}
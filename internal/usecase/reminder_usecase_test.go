@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/pkg/migrate"
+	"todo-app/internal/pkg/sqlite"
+	memoryrepo "todo-app/internal/repository/memory"
+	"todo-app/internal/repository/repotest"
+	sqliterepo "todo-app/internal/repository/sqlite"
+)
+
+// countingNotifier records the reminders it is asked to deliver.
+type countingNotifier struct {
+    mu   sync.Mutex
+    sent map[int64]int
+}
+
+func (n *countingNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+    n.mu.Lock()
+    defer n.mu.Unlock()
+    n.sent[notification.Reminder.ID]++
+    return nil
+}
+
+type reminderRepos struct {
+    user     domain.UserRepository
+    task     domain.TaskRepository
+    share    domain.ShareRepository
+    reminder domain.ReminderRepository
+}
+
+func reminderBackends(t *testing.T) map[string]reminderRepos {
+    store := memoryrepo.NewStore()
+
+    db, err := sqlite.NewConnection(":memory:")
+    if err != nil {
+        t.Fatalf("opening sqlite: %v", err)
+    }
+    t.Cleanup(func() { db.Close() })
+
+    repotest.Migrate(t, db, migrate.SQLite, "sqlite")
+
+    return map[string]reminderRepos{
+        "memory": {
+            user:     memoryrepo.NewMemoryUserRepository(store),
+            task:     memoryrepo.NewMemoryTaskRepository(store),
+            share:    memoryrepo.NewMemoryShareRepository(store),
+            reminder: memoryrepo.NewMemoryReminderRepository(store),
+        },
+        "sqlite": {
+            user:     sqliterepo.NewSqliteUserRepository(db),
+            task:     sqliterepo.NewSqliteTaskRepository(db),
+            share:    sqliterepo.NewSqliteShareRepository(db),
+            reminder: sqliterepo.NewSqliteReminderRepository(db),
+        },
+    }
+}
+
+func TestDispatchDueSendsEachReminderOnce(t *testing.T) {
+    for name, repos := range reminderBackends(t) {
+        t.Run(name, func(t *testing.T) {
+            user := &domain.User{Username: "alice", Password: "x"}
+            if err := repos.user.Create(user); err != nil {
+                t.Fatalf("creating user: %v", err)
+            }
+            task := &domain.Task{UserID: user.ID, Title: "Water the plants", Priority: domain.PriorityMedium}
+            if err := repos.task.Create(task); err != nil {
+                t.Fatalf("creating task: %v", err)
+            }
+
+            now := time.Now().UTC()
+            due := &domain.Reminder{TaskID: task.ID, UserID: user.ID, RemindAt: now.Add(-time.Minute)}
+            later := &domain.Reminder{TaskID: task.ID, UserID: user.ID, RemindAt: now.Add(time.Hour)}
+            for _, reminder := range []*domain.Reminder{due, later} {
+                if err := repos.reminder.Create(reminder); err != nil {
+                    t.Fatalf("creating reminder: %v", err)
+                }
+            }
+
+            notifier := &countingNotifier{sent: make(map[int64]int)}
+            reminders := NewReminderUsecase(repos.reminder, repos.task, repos.share, notifier)
+            for i := 0; i < 2; i++ {
+                if err := reminders.DispatchDue(context.Background()); err != nil {
+                    t.Fatalf("DispatchDue %d: %v", i+1, err)
+                }
+            }
+
+            if n := notifier.sent[due.ID]; n != 1 {
+                t.Errorf("due reminder sent %d times, want 1", n)
+            }
+            if n := notifier.sent[later.ID]; n != 0 {
+                t.Errorf("reminder not due yet sent %d times", n)
+            }
+
+            stored, err := repos.reminder.GetByTaskID(task.ID, user.ID)
+            if err != nil {
+                t.Fatalf("getting reminders: %v", err)
+            }
+            for _, reminder := range stored {
+                if reminder.ID == due.ID && (reminder.SentAt == nil || reminder.Attempts != 1) {
+                    t.Errorf("due reminder stored as sent_at=%v attempts=%d, want sent after 1 attempt",
+                        reminder.SentAt, reminder.Attempts)
+                }
+            }
+        })
+    }
+}
+
+func TestDispatchDueSkipsTasksTheUserLostAccessTo(t *testing.T) {
+    for name, repos := range reminderBackends(t) {
+        t.Run(name, func(t *testing.T) {
+            alice := &domain.User{Username: "alice", Password: "x"}
+            bob := &domain.User{Username: "bob", Password: "x"}
+            for _, user := range []*domain.User{alice, bob} {
+                if err := repos.user.Create(user); err != nil {
+                    t.Fatalf("creating user: %v", err)
+                }
+            }
+            task := &domain.Task{UserID: alice.ID, Title: "Water the plants", Priority: domain.PriorityMedium}
+            if err := repos.task.Create(task); err != nil {
+                t.Fatalf("creating task: %v", err)
+            }
+            if err := repos.share.SaveTaskShare(task.ID, bob.ID, domain.PermissionViewer); err != nil {
+                t.Fatalf("sharing task: %v", err)
+            }
+
+            reminder := &domain.Reminder{TaskID: task.ID, UserID: bob.ID, RemindAt: time.Now().UTC().Add(-time.Minute)}
+            if err := repos.reminder.Create(reminder); err != nil {
+                t.Fatalf("creating reminder: %v", err)
+            }
+            if err := repos.share.DeleteTaskShare(task.ID, bob.ID, nil); err != nil {
+                t.Fatalf("unsharing task: %v", err)
+            }
+
+            notifier := &countingNotifier{sent: make(map[int64]int)}
+            reminders := NewReminderUsecase(repos.reminder, repos.task, repos.share, notifier)
+            if err := reminders.DispatchDue(context.Background()); err != nil {
+                t.Fatalf("DispatchDue: %v", err)
+            }
+            if n := notifier.sent[reminder.ID]; n != 0 {
+                t.Errorf("reminder of a task no longer shared sent %d times", n)
+            }
+        })
+    }
+}
@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"fmt"
+	"todo-app/internal/domain"
+)
+
+type shareUsecase struct {
+    shareRepo   domain.ShareRepository
+    userRepo    domain.UserRepository
+    projectRepo domain.ProjectRepository
+    access      taskAccess
+    history     taskHistory
+}
+
+func NewShareUsecase(
+    shareRepo domain.ShareRepository,
+    userRepo domain.UserRepository,
+    taskRepo domain.TaskRepository,
+    projectRepo domain.ProjectRepository,
+    historyRepo domain.TaskHistoryRepository,
+) domain.ShareUsecase {
+    return &shareUsecase{
+        shareRepo:   shareRepo,
+        userRepo:    userRepo,
+        projectRepo: projectRepo,
+        access:      taskAccess{taskRepo, shareRepo},
+        history:     taskHistory{historyRepo, taskRepo},
+    }
+}
+
+func (u *shareUsecase) ShareTask(taskID, ownerID int64, username string, role domain.Permission) (*domain.Share, error) {
+    if _, err := u.access.get(taskID, ownerID, domain.PermissionOwner); err != nil {
+        return nil, err
+    }
+
+    share, err := u.newShare(ownerID, username, role)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := u.shareRepo.SaveTaskShare(taskID, share.UserID, share.Role); err != nil {
+        return nil, fmt.Errorf("error sharing task: %w", err)
+    }
+
+    shares, err := u.shareRepo.GetTaskShares(taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting task shares: %w", err)
+    }
+
+    return findShare(shares, share.UserID), nil
+}
+
+func (u *shareUsecase) UnshareTask(taskID, callerID, userID int64) error {
+    need := domain.PermissionOwner
+    if callerID == userID {
+        need = domain.PermissionViewer
+    }
+    task, err := u.access.get(taskID, callerID, need)
+    if err != nil {
+        return err
+    }
+
+    // A share of a task covers its subtasks too.
+    tasks, err := u.access.taskRepo.GetSubtasks(taskID, task.UserID)
+    if err != nil {
+        return fmt.Errorf("error getting subtasks: %w", err)
+    }
+    tasks = append(tasks, *task)
+
+    lost, unassign, err := u.unassignments(callerID, userID, tasks, taskID, 0)
+    if err != nil {
+        return err
+    }
+
+    if err := u.shareRepo.DeleteTaskShare(taskID, userID, unassign); err != nil {
+        return fmt.Errorf("error unsharing task: %w", err)
+    }
+
+    return u.recordUnassigned(callerID, lost, unassign)
+}
+
+func (u *shareUsecase) GetTaskShares(taskID, userID int64) ([]domain.Share, error) {
+    if _, err := u.access.get(taskID, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    shares, err := u.shareRepo.GetTaskShares(taskID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting task shares: %w", err)
+    }
+    if shares == nil {
+        shares = []domain.Share{}
+    }
+
+    return shares, nil
+}
+
+func (u *shareUsecase) ShareProject(projectID, ownerID int64, username string, role domain.Permission) (*domain.Share, error) {
+    permission, err := u.projectPermission(projectID, ownerID)
+    if err != nil {
+        return nil, err
+    }
+    if permission != domain.PermissionOwner {
+        return nil, domain.ErrProjectOwnerOnly
+    }
+
+    share, err := u.newShare(ownerID, username, role)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := u.shareRepo.SaveProjectShare(projectID, share.UserID, share.Role); err != nil {
+        return nil, fmt.Errorf("error sharing project: %w", err)
+    }
+
+    shares, err := u.shareRepo.GetProjectShares(projectID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting project shares: %w", err)
+    }
+
+    return findShare(shares, share.UserID), nil
+}
+
+func (u *shareUsecase) UnshareProject(projectID, callerID, userID int64) error {
+    permission, err := u.projectPermission(projectID, callerID)
+    if err != nil {
+        return err
+    }
+    if permission != domain.PermissionOwner && callerID != userID {
+        return domain.ErrProjectOwnerOnly
+    }
+
+    tasks, err := u.assignedTasks(userID, projectID)
+    if err != nil {
+        return err
+    }
+
+    lost, unassign, err := u.unassignments(callerID, userID, tasks, 0, projectID)
+    if err != nil {
+        return err
+    }
+
+    if err := u.shareRepo.DeleteProjectShare(projectID, userID, unassign); err != nil {
+        return fmt.Errorf("error unsharing project: %w", err)
+    }
+
+    return u.recordUnassigned(callerID, lost, unassign)
+}
+
+func (u *shareUsecase) GetProjectShares(projectID, userID int64) ([]domain.Share, error) {
+    if _, err := u.projectPermission(projectID, userID); err != nil {
+        return nil, err
+    }
+
+    shares, err := u.shareRepo.GetProjectShares(projectID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting project shares: %w", err)
+    }
+    if shares == nil {
+        shares = []domain.Share{}
+    }
+
+    return shares, nil
+}
+
+// assignedTasks returns every task in projectID that userID can see and is
+// assigned, archived ones included.
+func (u *shareUsecase) assignedTasks(userID, projectID int64) ([]domain.Task, error) {
+    filter := domain.TaskFilter{
+        ProjectID:  &projectID,
+        AssigneeID: &userID,
+        Archived:   domain.ArchivedInclude,
+        Sort:       domain.TaskSortCreatedAt,
+        Order:      domain.SortAsc,
+        Limit:      maxTaskLimit,
+    }
+
+    var tasks []domain.Task
+    for {
+        page, err := u.access.taskRepo.GetAllByUserID(userID, filter)
+        if err != nil {
+            return nil, fmt.Errorf("error getting assigned tasks: %w", err)
+        }
+        tasks = append(tasks, page...)
+        if len(page) < filter.Limit {
+            return tasks, nil
+        }
+
+        last := page[len(page)-1]
+        filter.After = &domain.TaskCursor{
+            Sort:      filter.Sort,
+            Order:     filter.Order,
+            ID:        last.ID,
+            CreatedAt: &last.CreatedAt,
+        }
+    }
+}
+
+// unassignments picks the tasks of tasks that are assigned to userID and
+// that they could no longer see without their share of taskID or projectID,
+// and returns them with the assignments that take them off userID.
+func (u *shareUsecase) unassignments(callerID, userID int64, tasks []domain.Task, taskID, projectID int64) ([]domain.Task, []domain.Assignment, error) {
+    if err := u.access.resolveWithout(userID, tasks, taskID, projectID); err != nil {
+        return nil, nil, err
+    }
+
+    var lost []domain.Task
+    var unassign []domain.Assignment
+    for _, task := range tasks {
+        if task.Permission != "" || !sameID(task.AssigneeID, &userID) {
+            continue
+        }
+        lost = append(lost, task)
+        unassign = append(unassign, domain.Assignment{
+            TaskID:     task.ID,
+            AssignedBy: callerID,
+            FromUserID: &userID,
+        })
+    }
+
+    return lost, unassign, nil
+}
+
+// recordUnassigned adds the tasks the repository unassigned to their task
+// history, leaving out those that were reassigned in the meantime.
+func (u *shareUsecase) recordUnassigned(callerID int64, lost []domain.Task, unassign []domain.Assignment) error {
+    for i := range lost {
+        if unassign[i].ID == 0 {
+            continue
+        }
+        if err := u.history.updated(callerID, &lost[i], domain.HistoryUpdated); err != nil {
+            return err
+        }
+    }
+
+    return nil
+}
+
+// projectPermission returns what userID may do with a project, reporting a
+// project they cannot see as not found.
+func (u *shareUsecase) projectPermission(projectID, userID int64) (domain.Permission, error) {
+    project, err := u.projectRepo.GetByID(projectID, userID)
+    if err != nil {
+        return "", fmt.Errorf("error getting project: %w", err)
+    }
+    if project != nil {
+        return domain.PermissionOwner, nil
+    }
+
+    roles, err := u.shareRepo.GetProjectRoles(userID, []int64{projectID})
+    if err != nil {
+        return "", fmt.Errorf("error getting project shares: %w", err)
+    }
+    if roles[projectID] == "" {
+        return "", domain.ErrProjectNotFound
+    }
+
+    return roles[projectID], nil
+}
+
+// newShare validates a share of ownerID's with the user called username. An
+// empty role means viewer. The share comes back without CreatedAt, which
+// only the stored share has.
+func (u *shareUsecase) newShare(ownerID int64, username string, role domain.Permission) (*domain.Share, error) {
+    if role == "" {
+        role = domain.PermissionViewer
+    }
+    if role != domain.PermissionViewer && role != domain.PermissionEditor {
+        return nil, domain.ErrInvalidShareRole
+    }
+
+    user, err := u.userRepo.GetByUsername(username)
+    if err != nil {
+        return nil, fmt.Errorf("error getting user: %w", err)
+    }
+    if user == nil {
+        return nil, domain.ErrUserNotFound
+    }
+    if user.ID == ownerID {
+        return nil, domain.ErrShareWithOwner
+    }
+
+    return &domain.Share{
+        UserID:   user.ID,
+        Username: user.Username,
+        Role:     role,
+    }, nil
+}
+
+// findShare picks userID's share out of shares, as stored.
+func findShare(shares []domain.Share, userID int64) *domain.Share {
+    for i := range shares {
+        if shares[i].UserID == userID {
+            return &shares[i]
+        }
+    }
+    return nil
+}
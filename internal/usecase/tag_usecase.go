@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"fmt"
+	"strings"
+	"todo-app/internal/domain"
+	"unicode/utf8"
+)
+
+const maxTagNameLength = 64
+
+type tagUsecase struct {
+    tagRepo domain.TagRepository
+}
+
+func NewTagUsecase(tagRepo domain.TagRepository) domain.TagUsecase {
+    return &tagUsecase{
+        tagRepo: tagRepo,
+    }
+}
+
+func (u *tagUsecase) Create(userID int64, name string) (*domain.Tag, error) {
+    name, err := normalizeTagName(name)
+    if err != nil {
+        return nil, err
+    }
+
+    tag := &domain.Tag{
+        UserID: userID,
+        Name:   name,
+    }
+
+    if err := u.tagRepo.Create(tag); err != nil {
+        return nil, fmt.Errorf("error creating tag: %w", err)
+    }
+
+    return tag, nil
+}
+
+func (u *tagUsecase) Update(id, userID int64, name string) error {
+    name, err := normalizeTagName(name)
+    if err != nil {
+        return err
+    }
+
+    tag := &domain.Tag{
+        ID:     id,
+        UserID: userID,
+        Name:   name,
+    }
+
+    if err := u.tagRepo.Update(tag); err != nil {
+        return fmt.Errorf("error updating tag: %w", err)
+    }
+
+    return nil
+}
+
+func (u *tagUsecase) Delete(id, userID int64) error {
+    if err := u.tagRepo.Delete(id, userID); err != nil {
+        return fmt.Errorf("error deleting tag: %w", err)
+    }
+
+    return nil
+}
+
+func (u *tagUsecase) GetByID(id, userID int64) (*domain.Tag, error) {
+    tag, err := u.tagRepo.GetByID(id, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting tag: %w", err)
+    }
+    if tag == nil {
+        return nil, domain.ErrTagNotFound
+    }
+
+    return tag, nil
+}
+
+func (u *tagUsecase) GetAllByUserID(userID int64) ([]domain.Tag, error) {
+    tags, err := u.tagRepo.GetAllByUserID(userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting tags: %w", err)
+    }
+
+    return tags, nil
+}
+
+// normalizeTagName trims surrounding space so " work" and "work" are the same
+// tag, and enforces the length the schema allows.
+func normalizeTagName(name string) (string, error) {
+    name = strings.TrimSpace(name)
+    if name == "" {
+        return "", domain.ErrTagNameRequired
+    }
+    if utf8.RuneCountInString(name) > maxTagNameLength {
+        return "", domain.ErrTagNameTooLong
+    }
+    return name, nil
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+func TestTagNames(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    tags := NewTagUsecase(f.tagRepo)
+
+    work, err := tags.Create(alice, "  work ")
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    if work.Name != "work" {
+        t.Errorf("tag name = %q, want the surrounding space trimmed", work.Name)
+    }
+
+    for _, tc := range []struct {
+        name string
+        err  error
+    }{
+        {"work", domain.ErrTagExists},
+        {" ", domain.ErrTagNameRequired},
+        {strings.Repeat("é", maxTagNameLength+1), domain.ErrTagNameTooLong},
+    } {
+        if _, err := tags.Create(alice, tc.name); !errors.Is(err, tc.err) {
+            t.Errorf("Create(%q): err = %v, want %v", tc.name, err, tc.err)
+        }
+    }
+    // The length is counted in characters, not bytes.
+    if _, err := tags.Create(alice, strings.Repeat("é", maxTagNameLength)); err != nil {
+        t.Errorf("Create of a name of %d characters: %v", maxTagNameLength, err)
+    }
+    // Tags are personal, so another user may use the same name.
+    if _, err := tags.Create(bob, "work"); err != nil {
+        t.Errorf("Create by another user: %v", err)
+    }
+
+    home, err := tags.Create(alice, "home")
+    if err != nil {
+        t.Fatalf("Create: %v", err)
+    }
+    if err := tags.Update(home.ID, alice, " work"); !errors.Is(err, domain.ErrTagExists) {
+        t.Errorf("renaming onto a taken name: err = %v, want ErrTagExists", err)
+    }
+    if err := tags.Update(home.ID, bob, "chores"); !errors.Is(err, domain.ErrTagNotFound) {
+        t.Errorf("Update by another user: err = %v, want ErrTagNotFound", err)
+    }
+    if _, err := tags.GetByID(home.ID, bob); !errors.Is(err, domain.ErrTagNotFound) {
+        t.Errorf("GetByID by another user: err = %v, want ErrTagNotFound", err)
+    }
+}
+
+func TestTaggingTasks(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    tags := NewTagUsecase(f.tagRepo)
+    newTag := func(userID int64, name string) *domain.Tag {
+        t.Helper()
+        tag, err := tags.Create(userID, name)
+        if err != nil {
+            t.Fatalf("creating tag %q: %v", name, err)
+        }
+        return tag
+    }
+    work, urgent := newTag(alice, "work"), newTag(alice, "urgent")
+    bobs := newTag(bob, "bob's")
+
+    report := f.newTask(t, alice, domain.TaskInput{Title: "Report"})
+    slides := f.newTask(t, alice, domain.TaskInput{Title: "Slides"})
+    f.newTask(t, alice, domain.TaskInput{Title: "Groceries"})
+    for _, tagging := range []struct {
+        task *domain.Task
+        tag  *domain.Tag
+    }{{report, work}, {report, urgent}, {slides, work}} {
+        if err := f.tasks.AddTag(tagging.task.ID, alice, tagging.tag.ID); err != nil {
+            t.Fatalf("AddTag: %v", err)
+        }
+    }
+
+    if got := f.getTask(t, report.ID, alice); len(got.Tags) != 2 {
+        t.Errorf("report has tags %+v, want work and urgent", got.Tags)
+    }
+    listTagged := func(match domain.TagMatch, names ...string) []string {
+        t.Helper()
+        page, err := f.tasks.GetAllByUserID(alice, domain.TaskQuery{Tags: names, TagMatch: match, Sort: domain.TaskSortTitle})
+        if err != nil {
+            t.Fatalf("GetAllByUserID: %v", err)
+        }
+        return taskTitles(page.Tasks)
+    }
+    if got := listTagged(domain.TagMatchAny, "urgent", "work"); !equalTitles(got, []string{"Report", "Slides"}) {
+        t.Errorf("tasks tagged urgent or work = %v", got)
+    }
+    if got := listTagged(domain.TagMatchAll, "urgent", "work"); !equalTitles(got, []string{"Report"}) {
+        t.Errorf("tasks tagged urgent and work = %v", got)
+    }
+
+    // Only the owner tags a task, and only with their own tags.
+    f.shareTask(t, report.ID, alice, "bob", domain.PermissionEditor)
+    if err := f.tasks.AddTag(report.ID, bob, bobs.ID); !errors.Is(err, domain.ErrTaskOwnerOnly) {
+        t.Errorf("AddTag by an editor: err = %v, want ErrTaskOwnerOnly", err)
+    }
+    if err := f.tasks.AddTag(report.ID, alice, bobs.ID); !errors.Is(err, domain.ErrTagNotFound) {
+        t.Errorf("AddTag with another user's tag: err = %v, want ErrTagNotFound", err)
+    }
+
+    if err := f.tasks.RemoveTag(report.ID, alice, urgent.ID); err != nil {
+        t.Fatalf("RemoveTag: %v", err)
+    }
+    if got := listTagged(domain.TagMatchAll, "urgent", "work"); len(got) != 0 {
+        t.Errorf("tasks tagged urgent and work after untagging = %v", got)
+    }
+
+    // Deleting a tag takes it off its tasks.
+    if err := tags.Delete(work.ID, alice); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    if got := f.getTask(t, slides.ID, alice); len(got.Tags) != 0 {
+        t.Errorf("slides still tagged %+v after the tag was deleted", got.Tags)
+    }
+}
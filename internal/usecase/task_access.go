@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"fmt"
+	"todo-app/internal/domain"
+)
+
+// taskAccess works out what a user may do with a task: anything when they
+// own it, otherwise the broadest role a share of the task, its parent task
+// or its project gives them. The repositories only ever see the owner's ID,
+// so a usecase that checks access through here keeps their user scoping as
+// a second line of defence.
+type taskAccess struct {
+    taskRepo  domain.TaskRepository
+    shareRepo domain.ShareRepository
+}
+
+// get returns the task if userID holds at least need on it. A task the user
+// cannot see at all is reported as not found, so its existence does not
+// leak.
+func (a taskAccess) get(id, userID int64, need domain.Permission) (*domain.Task, error) {
+    task, err := a.taskRepo.FindByID(id)
+    if err != nil {
+        return nil, fmt.Errorf("error getting task: %w", err)
+    }
+    if task == nil {
+        return nil, domain.ErrTaskNotFound
+    }
+
+    tasks := []domain.Task{*task}
+    if err := a.resolve(userID, tasks); err != nil {
+        return nil, err
+    }
+    task = &tasks[0]
+
+    switch {
+    case task.Permission == "":
+        return nil, domain.ErrTaskNotFound
+    case task.Permission.Allows(need):
+        return task, nil
+    case need == domain.PermissionOwner:
+        return nil, domain.ErrTaskOwnerOnly
+    default:
+        return nil, domain.ErrTaskReadOnly
+    }
+}
+
+// resolve fills in the Permission of every task for userID, leaving it empty
+// on tasks the user may not see.
+func (a taskAccess) resolve(userID int64, tasks []domain.Task) error {
+    return a.resolveWithout(userID, tasks, 0, 0)
+}
+
+// resolveWithout is resolve as if userID's share of the task taskID or of
+// the project projectID were already gone; zero leaves that kind of share
+// alone.
+func (a taskAccess) resolveWithout(userID int64, tasks []domain.Task, taskID, projectID int64) error {
+    var taskIDs, projectIDs []int64
+    for _, task := range tasks {
+        if task.UserID == userID {
+            continue
+        }
+        taskIDs = append(taskIDs, task.ID)
+        if task.ParentID != nil {
+            taskIDs = append(taskIDs, *task.ParentID)
+        }
+        if task.ProjectID != nil {
+            projectIDs = append(projectIDs, *task.ProjectID)
+        }
+    }
+
+    taskRoles, err := a.shareRepo.GetTaskRoles(userID, taskIDs)
+    if err != nil {
+        return fmt.Errorf("error getting task shares: %w", err)
+    }
+    projectRoles, err := a.shareRepo.GetProjectRoles(userID, projectIDs)
+    if err != nil {
+        return fmt.Errorf("error getting project shares: %w", err)
+    }
+    delete(taskRoles, taskID)
+    delete(projectRoles, projectID)
+
+    for i, task := range tasks {
+        if task.UserID == userID {
+            tasks[i].Permission = domain.PermissionOwner
+            continue
+        }
+
+        permission := taskRoles[task.ID]
+        if task.ParentID != nil {
+            permission = permission.Max(taskRoles[*task.ParentID])
+        }
+        if task.ProjectID != nil {
+            permission = permission.Max(projectRoles[*task.ProjectID])
+        }
+        tasks[i].Permission = permission
+    }
+
+    return nil
+}
+
+// visible drops the tasks resolve left without a permission.
+func visible(tasks []domain.Task) []domain.Task {
+    kept := tasks[:0]
+    for _, task := range tasks {
+        if task.Permission != "" {
+            kept = append(kept, task)
+        }
+    }
+    return kept
+}
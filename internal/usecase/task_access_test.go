@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"todo-app/internal/domain"
+)
+
+// shareProject shares a project of ownerID with username as role.
+func (f *taskFixture) shareProject(t *testing.T, projectID, ownerID int64, username string, role domain.Permission) {
+    t.Helper()
+
+    shares := NewShareUsecase(f.shareRepo, f.userRepo, f.taskRepo, f.projectRepo, f.historyRepo)
+    if _, err := shares.ShareProject(projectID, ownerID, username, role); err != nil {
+        t.Fatalf("sharing project %d with %s: %v", projectID, username, err)
+    }
+}
+
+func TestTaskAccessThroughShares(t *testing.T) {
+    // Each way of sharing a task with bob, returning the task shared.
+    via := map[string]func(t *testing.T, f *taskFixture, alice int64, role domain.Permission) *domain.Task{
+        "task share": func(t *testing.T, f *taskFixture, alice int64, role domain.Permission) *domain.Task {
+            task := f.newTask(t, alice, domain.TaskInput{Title: "Shared"})
+            f.shareTask(t, task.ID, alice, "bob", role)
+            return task
+        },
+        "parent share": func(t *testing.T, f *taskFixture, alice int64, role domain.Permission) *domain.Task {
+            parent := f.newTask(t, alice, domain.TaskInput{Title: "Shared parent"})
+            f.shareTask(t, parent.ID, alice, "bob", role)
+            return f.newSubtask(t, parent.ID, alice, "Subtask")
+        },
+        "project share": func(t *testing.T, f *taskFixture, alice int64, role domain.Permission) *domain.Task {
+            project := f.newProject(t, alice, "Shared project")
+            f.shareProject(t, project.ID, alice, "bob", role)
+            return f.newTask(t, alice, domain.TaskInput{Title: "In project", ProjectID: &project.ID})
+        },
+    }
+
+    for name, share := range via {
+        for _, role := range []domain.Permission{domain.PermissionViewer, domain.PermissionEditor} {
+            t.Run(name+" as "+string(role), func(t *testing.T) {
+                f := newTaskFixture(t)
+                alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+                task := share(t, f, alice, role)
+
+                if got := f.getTask(t, task.ID, alice); got.Permission != domain.PermissionOwner {
+                    t.Errorf("alice's permission = %q, want owner", got.Permission)
+                }
+                if got := f.getTask(t, task.ID, bob); got.Permission != role {
+                    t.Errorf("bob's permission = %q, want %q", got.Permission, role)
+                }
+
+                err := f.tasks.Update(task.ID, bob, domain.TaskInput{Title: "Renamed by bob", ProjectID: task.ProjectID})
+                switch {
+                case role == domain.PermissionEditor && err != nil:
+                    t.Errorf("Update by an editor: %v", err)
+                case role == domain.PermissionViewer && !errors.Is(err, domain.ErrTaskReadOnly):
+                    t.Errorf("Update by a viewer: err = %v, want ErrTaskReadOnly", err)
+                }
+                if err := f.tasks.Complete(task.ID, bob, false); role == domain.PermissionViewer && !errors.Is(err, domain.ErrTaskReadOnly) {
+                    t.Errorf("Complete by a viewer: err = %v, want ErrTaskReadOnly", err)
+                }
+
+                // Whatever the share, deleting stays with the owner.
+                if err := f.tasks.Delete(task.ID, bob, true); !errors.Is(err, domain.ErrTaskOwnerOnly) {
+                    t.Errorf("Delete by bob: err = %v, want ErrTaskOwnerOnly", err)
+                }
+                if err := f.tasks.Delete(task.ID, alice, true); err != nil {
+                    t.Errorf("Delete by alice: %v", err)
+                }
+            })
+        }
+    }
+}
+
+func TestTaskAccessTakesBroadestRole(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    project := f.newProject(t, alice, "Shared project")
+    parent := f.newTask(t, alice, domain.TaskInput{Title: "Parent", ProjectID: &project.ID})
+    subtask := f.newSubtask(t, parent.ID, alice, "Subtask")
+
+    f.shareTask(t, subtask.ID, alice, "bob", domain.PermissionViewer)
+    f.shareProject(t, project.ID, alice, "bob", domain.PermissionViewer)
+    if got := f.getTask(t, subtask.ID, bob); got.Permission != domain.PermissionViewer {
+        t.Fatalf("bob's permission = %q, want viewer", got.Permission)
+    }
+
+    // An editor share of the parent outranks the viewer shares.
+    f.shareTask(t, parent.ID, alice, "bob", domain.PermissionEditor)
+    if got := f.getTask(t, subtask.ID, bob); got.Permission != domain.PermissionEditor {
+        t.Errorf("bob's permission = %q, want editor", got.Permission)
+    }
+}
+
+// TestTaskAccessHidesUnsharedTasks checks that a task the user cannot see
+// is not found, whatever they try, while one they can only see is
+// read-only.
+func TestTaskAccessHidesUnsharedTasks(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob, carol := f.newUser(t, "alice"), f.newUser(t, "bob"), f.newUser(t, "carol")
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Shared with bob"})
+    f.shareTask(t, task.ID, alice, "bob", domain.PermissionViewer)
+
+    for _, attempt := range []struct {
+        name string
+        do   func(userID int64) error
+    }{
+        {"GetByID", func(userID int64) error { _, err := f.tasks.GetByID(task.ID, userID); return err }},
+        {"Update", func(userID int64) error { return f.tasks.Update(task.ID, userID, domain.TaskInput{Title: "x"}) }},
+        {"Complete", func(userID int64) error { return f.tasks.Complete(task.ID, userID, false) }},
+        {"AddSubtask", func(userID int64) error {
+            return f.tasks.AddSubtask(task.ID, userID, domain.TaskInput{Title: "x"})
+        }},
+    } {
+        if err := attempt.do(carol); !errors.Is(err, domain.ErrTaskNotFound) {
+            t.Errorf("%s by a stranger: err = %v, want ErrTaskNotFound", attempt.name, err)
+        }
+        if attempt.name == "GetByID" {
+            if err := attempt.do(bob); err != nil {
+                t.Errorf("GetByID by a viewer: %v", err)
+            }
+        } else if err := attempt.do(bob); !errors.Is(err, domain.ErrTaskReadOnly) {
+            t.Errorf("%s by a viewer: err = %v, want ErrTaskReadOnly", attempt.name, err)
+        }
+    }
+
+    if _, err := f.tasks.GetByID(task.ID+1000, alice); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("GetByID of a missing task: err = %v, want ErrTaskNotFound", err)
+    }
+    page, err := f.tasks.GetAllByUserID(carol, domain.TaskQuery{})
+    if err != nil {
+        t.Fatalf("GetAllByUserID: %v", err)
+    }
+    if len(page.Tasks) != 0 {
+        t.Errorf("a stranger lists %d tasks", len(page.Tasks))
+    }
+}
+
+func TestEditorCannotMoveTaskBetweenProjects(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    home := f.newProject(t, alice, "Home")
+    work := f.newProject(t, alice, "Work")
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Fix the shelf", ProjectID: &home.ID})
+    f.shareTask(t, task.ID, alice, "bob", domain.PermissionEditor)
+
+    for name, projectID := range map[string]*int64{"into another project": &work.ID, "out of its project": nil} {
+        err := f.tasks.Update(task.ID, bob, domain.TaskInput{Title: "Fix the shelf", ProjectID: projectID})
+        if !errors.Is(err, domain.ErrTaskOwnerOnly) {
+            t.Errorf("Update moving the task %s: err = %v, want ErrTaskOwnerOnly", name, err)
+        }
+    }
+    if err := f.tasks.MoveToProject(task.ID, bob, &work.ID); !errors.Is(err, domain.ErrTaskOwnerOnly) {
+        t.Errorf("MoveToProject by an editor: err = %v, want ErrTaskOwnerOnly", err)
+    }
+    if err := f.tasks.AddSubtask(task.ID, bob, domain.TaskInput{Title: "Buy screws", ProjectID: &work.ID}); !errors.Is(err, domain.ErrTaskOwnerOnly) {
+        t.Errorf("AddSubtask into another project by an editor: err = %v, want ErrTaskOwnerOnly", err)
+    }
+    if got := f.getTask(t, task.ID, alice); got.ProjectID == nil || *got.ProjectID != home.ID {
+        t.Fatalf("task moved to project %v by an editor", got.ProjectID)
+    }
+
+    // Leaving the project as it is, the editor may change the rest.
+    if err := f.tasks.Update(task.ID, bob, domain.TaskInput{Title: "Fix the shelf properly", ProjectID: &home.ID}); err != nil {
+        t.Errorf("Update keeping the project: %v", err)
+    }
+    // The owner may move it.
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{Title: "Fix the shelf properly", ProjectID: &work.ID}); err != nil {
+        t.Errorf("Update by the owner: %v", err)
+    }
+    if got := f.getTask(t, task.ID, alice); got.Title != "Fix the shelf properly" || got.ProjectID == nil || *got.ProjectID != work.ID {
+        t.Errorf("task = %+v, want renamed and in Work", got)
+    }
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// taskHistory records the changes usecases make to tasks. Repositories do
+// not know who is acting, so every usecase that changes a task records it
+// here after the change is stored. The state after a change is read back
+// from the repository, so the history holds what was actually stored.
+type taskHistory struct {
+    historyRepo domain.TaskHistoryRepository
+    taskRepo    domain.TaskRepository
+}
+
+func (h taskHistory) created(actorID, taskID int64) error {
+    return h.record(actorID, taskID, domain.HistoryCreated, domain.TaskSnapshot{})
+}
+
+// updated records the change from before as action, skipping changes that
+// left every tracked field as it was.
+func (h taskHistory) updated(actorID int64, before *domain.Task, action domain.HistoryAction) error {
+    return h.record(actorID, before.ID, action, snapshotOf(before))
+}
+
+func (h taskHistory) deleted(actorID int64, task *domain.Task) error {
+    return h.marker(actorID, task, domain.HistoryDeleted)
+}
+
+func (h taskHistory) restored(actorID int64, task *domain.Task) error {
+    return h.marker(actorID, task, domain.HistoryRestored)
+}
+
+// marker records an action that changes none of the tracked fields, with
+// the state of task at the time.
+func (h taskHistory) marker(actorID int64, task *domain.Task, action domain.HistoryAction) error {
+    entry := &domain.TaskVersion{
+        TaskID:   task.ID,
+        Action:   action,
+        ActorID:  actorID,
+        Changes:  []domain.FieldChange{},
+        Snapshot: snapshotOf(task),
+    }
+
+    if err := h.historyRepo.Append(entry); err != nil {
+        return fmt.Errorf("error recording task history: %w", err)
+    }
+
+    return nil
+}
+
+func (h taskHistory) record(actorID, taskID int64, action domain.HistoryAction, before domain.TaskSnapshot) error {
+    task, err := h.taskRepo.FindByID(taskID)
+    if err != nil {
+        return fmt.Errorf("error getting task: %w", err)
+    }
+    if task == nil {
+        return domain.ErrTaskNotFound
+    }
+
+    after := snapshotOf(task)
+    changes := diffSnapshots(before, after)
+    if len(changes) == 0 && action != domain.HistoryCreated {
+        return nil
+    }
+
+    entry := &domain.TaskVersion{
+        TaskID:   taskID,
+        Action:   action,
+        ActorID:  actorID,
+        Changes:  changes,
+        Snapshot: after,
+    }
+
+    if err := h.historyRepo.Append(entry); err != nil {
+        return fmt.Errorf("error recording task history: %w", err)
+    }
+
+    return nil
+}
+
+// snapshotOf takes the tracked fields of task. Times are cut to the
+// microseconds every backend stores, so a time still held in memory compares
+// equal to its stored copy.
+func snapshotOf(task *domain.Task) domain.TaskSnapshot {
+    return domain.TaskSnapshot{
+        ProjectID:    task.ProjectID,
+        Title:        task.Title,
+        Description:  task.Description,
+        Done:         task.Done,
+        Priority:     task.Priority,
+        DueAt:        storedTime(task.DueAt),
+        CompletedAt:  storedTime(task.CompletedAt),
+        Recurrence:   task.Recurrence,
+        RecurrenceTZ: task.RecurrenceTZ,
+        AssigneeID:   task.AssigneeID,
+    }
+}
+
+// diffSnapshots lists the fields that differ between before and after, in
+// a fixed order. Diffing against the zero snapshot lists every field that
+// is set.
+func diffSnapshots(before, after domain.TaskSnapshot) []domain.FieldChange {
+    changes := []domain.FieldChange{}
+    add := func(field string, old, new interface{}) {
+        changes = append(changes, domain.FieldChange{Field: field, Old: old, New: new})
+    }
+
+    if !sameID(before.ProjectID, after.ProjectID) {
+        add("project_id", before.ProjectID, after.ProjectID)
+    }
+    if before.Title != after.Title {
+        add("title", before.Title, after.Title)
+    }
+    if before.Description != after.Description {
+        add("description", before.Description, after.Description)
+    }
+    if before.Done != after.Done {
+        add("done", before.Done, after.Done)
+    }
+    if before.Priority != after.Priority {
+        add("priority", before.Priority, after.Priority)
+    }
+    if !sameTime(before.DueAt, after.DueAt) {
+        add("due_at", before.DueAt, after.DueAt)
+    }
+    if !sameTime(before.CompletedAt, after.CompletedAt) {
+        add("completed_at", before.CompletedAt, after.CompletedAt)
+    }
+    if before.Recurrence != after.Recurrence {
+        add("recurrence", before.Recurrence, after.Recurrence)
+    }
+    if before.RecurrenceTZ != after.RecurrenceTZ {
+        add("recurrence_tz", before.RecurrenceTZ, after.RecurrenceTZ)
+    }
+    if !sameID(before.AssigneeID, after.AssigneeID) {
+        add("assignee_id", before.AssigneeID, after.AssigneeID)
+    }
+
+    return changes
+}
+
+func storedTime(t *time.Time) *time.Time {
+    if t == nil {
+        return nil
+    }
+    stored := t.UTC().Truncate(time.Microsecond)
+    return &stored
+}
+
+func sameTime(a, b *time.Time) bool {
+    if a == nil || b == nil {
+        return a == nil && b == nil
+    }
+    return a.Equal(*b)
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+    project, otherProject := int64(1), int64(2)
+    due := time.Date(2026, time.March, 1, 9, 0, 0, 0, time.UTC)
+    sameDue := due.In(time.FixedZone("CET", 3600))
+    before := domain.TaskSnapshot{
+        ProjectID: &project,
+        Title:     "Old",
+        Priority:  domain.PriorityLow,
+        DueAt:     &due,
+    }
+
+    tests := []struct {
+        name   string
+        change func(s *domain.TaskSnapshot)
+        want   []string
+    }{
+        {"nothing", func(s *domain.TaskSnapshot) {}, []string{}},
+        {"same instant in another zone", func(s *domain.TaskSnapshot) { s.DueAt = &sameDue }, []string{}},
+        {"equal project by value", func(s *domain.TaskSnapshot) { p := project; s.ProjectID = &p }, []string{}},
+        {"title", func(s *domain.TaskSnapshot) { s.Title = "New" }, []string{"title"}},
+        {"project moved", func(s *domain.TaskSnapshot) { s.ProjectID = &otherProject }, []string{"project_id"}},
+        {"project cleared", func(s *domain.TaskSnapshot) { s.ProjectID = nil }, []string{"project_id"}},
+        {"due cleared", func(s *domain.TaskSnapshot) { s.DueAt = nil }, []string{"due_at"}},
+        {
+            "several, in field order",
+            func(s *domain.TaskSnapshot) {
+                s.AssigneeID = &project
+                s.Recurrence = "FREQ=DAILY"
+                s.Done = true
+                s.Title = "New"
+                s.Priority = domain.PriorityHigh
+            },
+            []string{"title", "done", "priority", "recurrence", "assignee_id"},
+        },
+    }
+
+    for _, test := range tests {
+        after := before
+        test.change(&after)
+
+        changes := diffSnapshots(before, after)
+        fields := []string{}
+        for _, change := range changes {
+            fields = append(fields, change.Field)
+        }
+        if !reflect.DeepEqual(fields, test.want) {
+            t.Errorf("%s: changed fields %v, want %v", test.name, fields, test.want)
+        }
+    }
+
+    // Each change carries the old and new values.
+    after := before
+    after.Title = "New"
+    after.DueAt = nil
+    changes := diffSnapshots(before, after)
+    if len(changes) != 2 || changes[0].Old != "Old" || changes[0].New != "New" ||
+        changes[1].Old != before.DueAt || changes[1].New != (*time.Time)(nil) {
+        t.Errorf("changes = %+v", changes)
+    }
+
+    // Against the zero snapshot, as on create, every set field is listed.
+    if got := len(diffSnapshots(domain.TaskSnapshot{}, before)); got != 4 {
+        t.Errorf("diff from nothing lists %d fields, want 4", got)
+    }
+}
+
+// history returns the versions of a task as userID sees them.
+func (f *taskFixture) history(t *testing.T, taskID, userID int64) []domain.TaskVersion {
+    t.Helper()
+
+    versions, err := f.tasks.GetHistory(taskID, userID)
+    if err != nil {
+        t.Fatalf("GetHistory: %v", err)
+    }
+    return versions
+}
+
+func changedFields(version domain.TaskVersion) []string {
+    fields := []string{}
+    for _, change := range version.Changes {
+        fields = append(fields, change.Field)
+    }
+    return fields
+}
+
+func TestHistoryRecordsChangedFields(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Draft", Priority: domain.PriorityLow})
+    f.shareTask(t, task.ID, alice, "bob", domain.PermissionEditor)
+
+    // Bob changes two fields; saving the same values again is not a version.
+    input := domain.TaskInput{Title: "Final", Description: "Reviewed", Priority: domain.PriorityLow, KeepDueAt: true, KeepRecurrence: true}
+    for i := 0; i < 2; i++ {
+        if err := f.tasks.Update(task.ID, bob, input); err != nil {
+            t.Fatalf("Update: %v", err)
+        }
+    }
+
+    versions := f.history(t, task.ID, alice)
+    if len(versions) != 2 {
+        t.Fatalf("%d versions, want created and one update: %+v", len(versions), versions)
+    }
+    created, updated := versions[0], versions[1]
+    if created.Version != 1 || created.Action != domain.HistoryCreated || created.ActorID != alice {
+        t.Errorf("first version = %+v", created)
+    }
+    if updated.Version != 2 || updated.Action != domain.HistoryUpdated || updated.ActorID != bob {
+        t.Errorf("second version = %+v", updated)
+    }
+    if fields := changedFields(updated); !reflect.DeepEqual(fields, []string{"title", "description"}) {
+        t.Errorf("second version changed %v, want title and description", fields)
+    }
+    if change := updated.Changes[0]; change.Old != "Draft" || change.New != "Final" {
+        t.Errorf("title change = %+v", change)
+    }
+    if updated.Snapshot.Title != "Final" || updated.Snapshot.Description != "Reviewed" {
+        t.Errorf("snapshot after the update = %+v", updated.Snapshot)
+    }
+}
+
+func TestRevert(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    due := time.Now().UTC().Add(48 * time.Hour).Truncate(time.Second)
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Plan", Description: "v1", Priority: domain.PriorityMedium, DueAt: &due})
+
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{Title: "Plan B", Description: "v2", Priority: domain.PriorityHigh}); err != nil {
+        t.Fatal(err)
+    }
+
+    if err := f.tasks.Revert(task.ID, alice, 1); err != nil {
+        t.Fatalf("Revert: %v", err)
+    }
+    reverted := f.getTask(t, task.ID, alice)
+    if reverted.Title != "Plan" || reverted.Description != "v1" || reverted.Priority != domain.PriorityMedium ||
+        reverted.DueAt == nil || !reverted.DueAt.Equal(due) {
+        t.Errorf("after reverting to version 1: %+v", reverted)
+    }
+
+    versions := f.history(t, task.ID, alice)
+    last := versions[len(versions)-1]
+    if len(versions) != 3 || last.Action != domain.HistoryReverted ||
+        len(diffSnapshots(last.Snapshot, versions[0].Snapshot)) != 0 {
+        t.Errorf("history after Revert: %+v", versions)
+    }
+    if fields := changedFields(last); !reflect.DeepEqual(fields, []string{"title", "description", "priority", "due_at"}) {
+        t.Errorf("revert changed %v", fields)
+    }
+
+    // Reverting to the state it is already in records nothing.
+    if err := f.tasks.Revert(task.ID, alice, 1); err != nil {
+        t.Fatalf("Revert: %v", err)
+    }
+    if got := len(f.history(t, task.ID, alice)); got != 3 {
+        t.Errorf("a no-op revert added a version: %d versions", got)
+    }
+
+    if err := f.tasks.Revert(task.ID, alice, 9); !errors.Is(err, domain.ErrTaskVersionNotFound) {
+        t.Errorf("Revert to a missing version: err = %v, want ErrTaskVersionNotFound", err)
+    }
+}
+
+func TestRevertFollowsUpdateRules(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob, carol := f.newUser(t, "alice"), f.newUser(t, "bob"), f.newUser(t, "carol")
+    project := f.newProject(t, alice, "Home")
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Paint", ProjectID: &project.ID})
+    if err := f.tasks.MoveToProject(task.ID, alice, nil); err != nil {
+        t.Fatal(err)
+    }
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{Title: "Paint the fence"}); err != nil {
+        t.Fatal(err)
+    }
+    f.shareTask(t, task.ID, alice, "bob", domain.PermissionEditor)
+    f.shareTask(t, task.ID, alice, "carol", domain.PermissionViewer)
+
+    // A viewer cannot revert.
+    if err := f.tasks.Revert(task.ID, carol, 1); !errors.Is(err, domain.ErrTaskReadOnly) {
+        t.Errorf("Revert by a viewer: err = %v, want ErrTaskReadOnly", err)
+    }
+
+    // An editor gets the title back but cannot move the task into the
+    // owner's project.
+    if err := f.tasks.Revert(task.ID, bob, 1); err != nil {
+        t.Fatalf("Revert by an editor: %v", err)
+    }
+    got := f.getTask(t, task.ID, alice)
+    if got.Title != "Paint" || got.ProjectID != nil {
+        t.Errorf("after an editor's revert: title %q, project %v", got.Title, got.ProjectID)
+    }
+
+    // The owner's revert restores the project too.
+    if err := f.tasks.Revert(task.ID, alice, 1); err != nil {
+        t.Fatalf("Revert by the owner: %v", err)
+    }
+    if got := f.getTask(t, task.ID, alice); got.ProjectID == nil || *got.ProjectID != project.ID {
+        t.Errorf("after the owner's revert: project %v, want %d", got.ProjectID, project.ID)
+    }
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"todo-app/internal/domain"
+)
+
+const (
+    defaultTaskLimit = 50
+    maxTaskLimit     = 100
+    maxDueWithinDays = 365
+)
+
+var (
+    errInvalidLimit    = domain.ValidationError("limit must be between 1 and 100")
+    errInvalidSort     = domain.ValidationError("sort must be one of created_at, updated_at, title, due_at, priority")
+    errInvalidOrder    = domain.ValidationError("order must be asc or desc")
+    errInvalidCursor   = domain.ValidationError("invalid cursor")
+    errInvalidDays     = domain.ValidationError("days must be between 0 and 365")
+    errInvalidMatch    = domain.ValidationError("tag_match must be any or all")
+    errInvalidArchived = domain.ValidationError("archived must be exclude, include or only")
+)
+
+// newTaskFilter validates a listing request and fills in defaults. Titles
+// list A to Z and due dates soonest first by default; priorities list most
+// urgent first and timestamps newest first.
+func newTaskFilter(query domain.TaskQuery) (domain.TaskFilter, error) {
+    filter := domain.TaskFilter{
+        Done:          query.Done,
+        TopLevel:      !query.IncludeSubtasks,
+        ProjectID:     query.ProjectID,
+        AssigneeID:    query.AssigneeID,
+        Archived:      query.Archived,
+        Priorities:    query.Priorities,
+        Tags:          query.Tags,
+        TagMatch:      query.TagMatch,
+        CreatedAfter:  query.CreatedAfter,
+        CreatedBefore: query.CreatedBefore,
+        UpdatedAfter:  query.UpdatedAfter,
+        UpdatedBefore: query.UpdatedBefore,
+        DueFrom:       query.DueFrom,
+        DueBefore:     query.DueBefore,
+        Sort:          query.Sort,
+        Order:         query.Order,
+        Limit:         query.Limit,
+    }
+
+    if filter.Limit == 0 {
+        filter.Limit = defaultTaskLimit
+    }
+    if filter.Limit < 1 || filter.Limit > maxTaskLimit {
+        return filter, errInvalidLimit
+    }
+
+    switch filter.TagMatch {
+    case "":
+        filter.TagMatch = domain.TagMatchAny
+    case domain.TagMatchAny, domain.TagMatchAll:
+    default:
+        return filter, errInvalidMatch
+    }
+
+    switch filter.Archived {
+    case "":
+        filter.Archived = domain.ArchivedExclude
+    case domain.ArchivedExclude, domain.ArchivedInclude, domain.ArchivedOnly:
+    default:
+        return filter, errInvalidArchived
+    }
+
+    switch filter.Sort {
+    case "":
+        filter.Sort = domain.TaskSortCreatedAt
+    case domain.TaskSortCreatedAt, domain.TaskSortUpdatedAt, domain.TaskSortTitle,
+        domain.TaskSortDueAt, domain.TaskSortPriority:
+    default:
+        return filter, errInvalidSort
+    }
+
+    switch filter.Order {
+    case "":
+        filter.Order = domain.SortDesc
+        if filter.Sort == domain.TaskSortTitle || filter.Sort == domain.TaskSortDueAt {
+            filter.Order = domain.SortAsc
+        }
+    case domain.SortAsc, domain.SortDesc:
+    default:
+        return filter, errInvalidOrder
+    }
+
+    if query.Cursor != "" {
+        cursor, err := decodeTaskCursor(query.Cursor)
+        if err != nil || cursor.Sort != filter.Sort || cursor.Order != filter.Order {
+            return filter, errInvalidCursor
+        }
+        filter.After = cursor
+    }
+
+    return filter, nil
+}
+
+// encodeTaskCursor returns the opaque cursor for the page following task.
+func encodeTaskCursor(filter domain.TaskFilter, task domain.Task) (string, error) {
+    cursor := domain.TaskCursor{
+        Sort:  filter.Sort,
+        Order: filter.Order,
+        ID:    task.ID,
+    }
+
+    switch filter.Sort {
+    case domain.TaskSortCreatedAt:
+        cursor.CreatedAt = utcTime(&task.CreatedAt)
+    case domain.TaskSortUpdatedAt:
+        cursor.UpdatedAt = utcTime(&task.UpdatedAt)
+    case domain.TaskSortTitle:
+        cursor.Title = task.Title
+    case domain.TaskSortDueAt, domain.TaskSortPriority:
+        cursor.DueAt = utcTime(task.DueAt)
+        cursor.Priority = task.Priority
+    }
+
+    payload, err := json.Marshal(cursor)
+    if err != nil {
+        return "", err
+    }
+
+    return base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+func decodeTaskCursor(encoded string) (*domain.TaskCursor, error) {
+    payload, err := base64.RawURLEncoding.DecodeString(encoded)
+    if err != nil {
+        return nil, err
+    }
+
+    cursor := &domain.TaskCursor{}
+    if err := json.Unmarshal(payload, cursor); err != nil {
+        return nil, err
+    }
+
+    // The time sorts cannot do without their key.
+    if cursor.Sort == domain.TaskSortCreatedAt && cursor.CreatedAt == nil ||
+        cursor.Sort == domain.TaskSortUpdatedAt && cursor.UpdatedAt == nil {
+        return nil, errInvalidCursor
+    }
+    cursor.CreatedAt = utcTime(cursor.CreatedAt)
+    cursor.UpdatedAt = utcTime(cursor.UpdatedAt)
+    cursor.DueAt = utcTime(cursor.DueAt)
+
+    return cursor, nil
+}
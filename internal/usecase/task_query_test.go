@@ -0,0 +1,260 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+)
+
+func TestNewTaskFilterDefaults(t *testing.T) {
+    filter, err := newTaskFilter(domain.TaskQuery{})
+    if err != nil {
+        t.Fatalf("newTaskFilter: %v", err)
+    }
+    if filter.Limit != defaultTaskLimit || filter.Sort != domain.TaskSortCreatedAt || filter.Order != domain.SortDesc ||
+        filter.TagMatch != domain.TagMatchAny || filter.Archived != domain.ArchivedExclude || !filter.TopLevel || filter.After != nil {
+        t.Errorf("default filter = %+v", filter)
+    }
+
+    for sort, order := range map[domain.TaskSort]domain.SortOrder{
+        domain.TaskSortCreatedAt: domain.SortDesc,
+        domain.TaskSortUpdatedAt: domain.SortDesc,
+        domain.TaskSortTitle:     domain.SortAsc,
+        domain.TaskSortDueAt:     domain.SortAsc,
+        domain.TaskSortPriority:  domain.SortDesc,
+    } {
+        filter, err := newTaskFilter(domain.TaskQuery{Sort: sort})
+        if err != nil {
+            t.Fatalf("newTaskFilter sorting by %s: %v", sort, err)
+        }
+        if filter.Order != order {
+            t.Errorf("sorting by %s defaults to %s, want %s", sort, filter.Order, order)
+        }
+    }
+}
+
+func TestNewTaskFilterValidates(t *testing.T) {
+    for _, tc := range []struct {
+        name  string
+        query domain.TaskQuery
+        err   error
+    }{
+        {"smallest limit", domain.TaskQuery{Limit: 1}, nil},
+        {"largest limit", domain.TaskQuery{Limit: maxTaskLimit}, nil},
+        {"negative limit", domain.TaskQuery{Limit: -1}, errInvalidLimit},
+        {"limit too large", domain.TaskQuery{Limit: maxTaskLimit + 1}, errInvalidLimit},
+        {"unknown sort", domain.TaskQuery{Sort: "position"}, errInvalidSort},
+        {"unknown order", domain.TaskQuery{Order: "up"}, errInvalidOrder},
+        {"archived only", domain.TaskQuery{Archived: domain.ArchivedOnly}, nil},
+        {"unknown archived", domain.TaskQuery{Archived: "maybe"}, errInvalidArchived},
+        {"all tags", domain.TaskQuery{TagMatch: domain.TagMatchAll}, nil},
+        {"unknown tag_match", domain.TaskQuery{TagMatch: "some"}, errInvalidMatch},
+        {"cursor not base64", domain.TaskQuery{Cursor: "not a cursor!"}, errInvalidCursor},
+    } {
+        _, err := newTaskFilter(tc.query)
+        if !errors.Is(err, tc.err) {
+            t.Errorf("%s: err = %v, want %v", tc.name, err, tc.err)
+        }
+        if tc.err != nil && !errors.Is(err, domain.ErrValidation) {
+            t.Errorf("%s: err = %v is not a validation error", tc.name, err)
+        }
+    }
+}
+
+func TestTaskCursorRoundTrip(t *testing.T) {
+    berlin, err := time.LoadLocation("Europe/Berlin")
+    if err != nil {
+        t.Fatal(err)
+    }
+    created := time.Date(2030, time.May, 1, 10, 0, 0, 123000000, berlin)
+    due := created.Add(48 * time.Hour)
+    task := domain.Task{
+        ID:        42,
+        Title:     "Paint the fence",
+        Priority:  domain.PriorityHigh,
+        DueAt:     &due,
+        CreatedAt: created,
+        UpdatedAt: created.Add(time.Hour),
+    }
+
+    for _, sort := range []domain.TaskSort{
+        domain.TaskSortCreatedAt, domain.TaskSortUpdatedAt, domain.TaskSortTitle, domain.TaskSortDueAt, domain.TaskSortPriority,
+    } {
+        filter := domain.TaskFilter{Sort: sort, Order: domain.SortAsc}
+        encoded, err := encodeTaskCursor(filter, task)
+        if err != nil {
+            t.Fatalf("%s: encodeTaskCursor: %v", sort, err)
+        }
+        cursor, err := decodeTaskCursor(encoded)
+        if err != nil {
+            t.Fatalf("%s: decodeTaskCursor: %v", sort, err)
+        }
+        if cursor.Sort != sort || cursor.Order != domain.SortAsc || cursor.ID != task.ID {
+            t.Errorf("%s: cursor = %+v", sort, cursor)
+        }
+
+        // The cursor carries just the keys of its sort, in UTC.
+        want := domain.TaskCursor{Sort: sort, Order: domain.SortAsc, ID: task.ID}
+        switch sort {
+        case domain.TaskSortCreatedAt:
+            want.CreatedAt = utcTime(&task.CreatedAt)
+        case domain.TaskSortUpdatedAt:
+            want.UpdatedAt = utcTime(&task.UpdatedAt)
+        case domain.TaskSortTitle:
+            want.Title = task.Title
+        default:
+            want.DueAt, want.Priority = utcTime(task.DueAt), task.Priority
+        }
+        if !sameCursorTime(cursor.CreatedAt, want.CreatedAt) || !sameCursorTime(cursor.UpdatedAt, want.UpdatedAt) ||
+            !sameCursorTime(cursor.DueAt, want.DueAt) || cursor.Title != want.Title || cursor.Priority != want.Priority {
+            t.Errorf("%s: cursor = %+v, want %+v", sort, cursor, want)
+        }
+    }
+
+    // A task without a due date pages on by its priority and ID alone.
+    undated := task
+    undated.DueAt = nil
+    encoded, err := encodeTaskCursor(domain.TaskFilter{Sort: domain.TaskSortDueAt, Order: domain.SortAsc}, undated)
+    if err != nil {
+        t.Fatalf("encodeTaskCursor: %v", err)
+    }
+    if cursor, err := decodeTaskCursor(encoded); err != nil || cursor.DueAt != nil || cursor.Priority != task.Priority {
+        t.Errorf("cursor of a task without a due date = %+v, %v", cursor, err)
+    }
+}
+
+// sameCursorTime compares times as they survive the cursor's JSON: in UTC,
+// to the nanosecond.
+func sameCursorTime(a, b *time.Time) bool {
+    if a == nil || b == nil {
+        return a == nil && b == nil
+    }
+    return a.Equal(*b) && a.Location() == time.UTC
+}
+
+func TestDecodeTaskCursorRejectsGarbage(t *testing.T) {
+    encode := func(payload string) string { return base64.RawURLEncoding.EncodeToString([]byte(payload)) }
+
+    for name, encoded := range map[string]string{
+        "not base64":                   "%%%",
+        "not JSON":                     encode("title,asc,1"),
+        "created_at without its key":   encode(`{"s":"created_at","o":"desc","id":1}`),
+        "updated_at without its key":   encode(`{"s":"updated_at","o":"desc","id":1}`),
+        "a time that is not a time":    encode(`{"s":"created_at","o":"desc","id":1,"c":"yesterday"}`),
+        "an ID that is not an integer": encode(`{"s":"title","o":"asc","id":"one"}`),
+    } {
+        if cursor, err := decodeTaskCursor(encoded); err == nil {
+            t.Errorf("%s: decoded %+v", name, cursor)
+        }
+    }
+}
+
+func TestNewTaskFilterRejectsCursorOfAnotherListing(t *testing.T) {
+    task := domain.Task{ID: 7, Title: "Call the plumber", CreatedAt: time.Now()}
+    encoded, err := encodeTaskCursor(domain.TaskFilter{Sort: domain.TaskSortTitle, Order: domain.SortAsc}, task)
+    if err != nil {
+        t.Fatalf("encodeTaskCursor: %v", err)
+    }
+
+    filter, err := newTaskFilter(domain.TaskQuery{Sort: domain.TaskSortTitle, Cursor: encoded})
+    if err != nil {
+        t.Fatalf("newTaskFilter with its own cursor: %v", err)
+    }
+    if filter.After == nil || filter.After.ID != task.ID || filter.After.Title != task.Title {
+        t.Errorf("filter.After = %+v, want the cursor's task", filter.After)
+    }
+
+    for name, query := range map[string]domain.TaskQuery{
+        "another sort":  {Sort: domain.TaskSortDueAt, Order: domain.SortAsc},
+        "another order": {Sort: domain.TaskSortTitle, Order: domain.SortDesc},
+        "the defaults":  {},
+    } {
+        query.Cursor = encoded
+        if _, err := newTaskFilter(query); !errors.Is(err, errInvalidCursor) {
+            t.Errorf("cursor used with %s: err = %v, want errInvalidCursor", name, err)
+        }
+    }
+}
+
+// TestPagingAcrossTasksWithoutDueDate pages through a listing sorted on due
+// dates, where the tasks without one tie on it across page boundaries and
+// have to be told apart by priority and ID.
+func TestPagingAcrossTasksWithoutDueDate(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    due := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+    for i, priority := range []domain.Priority{
+        domain.PriorityHigh, domain.PriorityLow, domain.PriorityLow, domain.PriorityHigh,
+        domain.PriorityLow, domain.PriorityNone, domain.PriorityLow,
+    } {
+        input := domain.TaskInput{Title: fmt.Sprintf("task %d", i), Priority: priority}
+        // Two dated tasks, sharing their due date.
+        if i < 2 {
+            input.DueAt = &due
+        }
+        f.newTask(t, alice, input)
+    }
+
+    for _, sort := range []domain.TaskSort{domain.TaskSortDueAt, domain.TaskSortPriority} {
+        for _, order := range []domain.SortOrder{domain.SortAsc, domain.SortDesc} {
+            query := domain.TaskQuery{Sort: sort, Order: order}
+            all, err := f.tasks.GetAllByUserID(alice, query)
+            if err != nil {
+                t.Fatalf("GetAllByUserID: %v", err)
+            }
+            if len(all.Tasks) != 7 || all.NextCursor != "" {
+                t.Fatalf("%s %s: one page of %d tasks, next cursor %q", sort, order, len(all.Tasks), all.NextCursor)
+            }
+            // Undated tasks come last whichever the order.
+            if sort == domain.TaskSortDueAt && (all.Tasks[0].DueAt == nil || all.Tasks[1].DueAt == nil) {
+                t.Errorf("%s %s: the dated tasks are not listed first: %v", sort, order, taskTitles(all.Tasks))
+            }
+
+            for limit := 1; limit <= 3; limit++ {
+                query.Limit, query.Cursor = limit, ""
+                var paged []domain.Task
+                for pages := 0; ; pages++ {
+                    if pages > len(all.Tasks) {
+                        t.Fatalf("%s %s by %d: paging does not end", sort, order, limit)
+                    }
+                    page, err := f.tasks.GetAllByUserID(alice, query)
+                    if err != nil {
+                        t.Fatalf("%s %s by %d: %v", sort, order, limit, err)
+                    }
+                    paged = append(paged, page.Tasks...)
+                    if page.NextCursor == "" {
+                        break
+                    }
+                    query.Cursor = page.NextCursor
+                }
+
+                if got, want := taskTitles(paged), taskTitles(all.Tasks); !equalTitles(got, want) {
+                    t.Errorf("%s %s by %d: paged %v, want %v", sort, order, limit, got, want)
+                }
+            }
+        }
+    }
+}
+
+func taskTitles(tasks []domain.Task) []string {
+    titles := make([]string, len(tasks))
+    for i, task := range tasks {
+        titles[i] = task.Title
+    }
+    return titles
+}
+
+func equalTitles(a, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
@@ -2,101 +2,853 @@ package usecase
 
 import (
 	"fmt"
+	"time"
 	"todo-app/internal/domain"
+	"todo-app/internal/pkg/rrule"
 )
 
 type taskUsecase struct {
-    taskRepo domain.TaskRepository
+    taskRepo    domain.TaskRepository
+    tagRepo     domain.TagRepository
+    projectRepo domain.ProjectRepository
+    shareRepo   domain.ShareRepository
+    historyRepo domain.TaskHistoryRepository
+    access      taskAccess
+    history     taskHistory
 }
 
-func NewTaskUsecase(taskRepo domain.TaskRepository) domain.TaskUsecase {
+func NewTaskUsecase(
+    taskRepo domain.TaskRepository,
+    tagRepo domain.TagRepository,
+    projectRepo domain.ProjectRepository,
+    shareRepo domain.ShareRepository,
+    historyRepo domain.TaskHistoryRepository,
+) domain.TaskUsecase {
     return &taskUsecase{
-        taskRepo: taskRepo,
+        taskRepo:    taskRepo,
+        tagRepo:     tagRepo,
+        projectRepo: projectRepo,
+        shareRepo:   shareRepo,
+        historyRepo: historyRepo,
+        access:      taskAccess{taskRepo, shareRepo},
+        history:     taskHistory{historyRepo, taskRepo},
     }
 }
 
-func (u *taskUsecase) Create(userID int64, title, description string) error {
-    if title == "" {
-        return fmt.Errorf("title is required")
+func (u *taskUsecase) Create(userID int64, input domain.TaskInput) error {
+    task, err := u.newTask(userID, input)
+    if err != nil {
+        return err
     }
 
-    task := &domain.Task{
-        UserID:      userID,
-        Title:       title,
-        Description: description,
-        Done:        false,
+    if err := u.taskRepo.Create(task); err != nil {
+        return fmt.Errorf("error creating task: %w", err)
     }
 
-    if err := u.taskRepo.Create(task); err != nil {
-        return fmt.Errorf("error creating task: %v", err)
+    return u.history.created(userID, task.ID)
+}
+
+// newTask validates input and builds the task Create would store.
+func (u *taskUsecase) newTask(userID int64, input domain.TaskInput) (*domain.Task, error) {
+    if input.Title == "" {
+        return nil, domain.ErrTaskTitleRequired
+    }
+    if err := u.checkProject(userID, input.ProjectID, nil); err != nil {
+        return nil, err
+    }
+    rule, tz, err := recurrence(input)
+    if err != nil {
+        return nil, err
     }
 
-    return nil
+    return &domain.Task{
+        UserID:       userID,
+        ProjectID:    input.ProjectID,
+        Title:        input.Title,
+        Description:  input.Description,
+        Done:         input.CompletedAt != nil,
+        Priority:     input.Priority,
+        DueAt:        utcTime(input.DueAt),
+        CompletedAt:  utcTime(input.CompletedAt),
+        Recurrence:   rule,
+        RecurrenceTZ: tz,
+    }, nil
 }
 
-func (u *taskUsecase) Update(id, userID int64, title, description string, done bool) error {
-    // Check if task exists and belongs to user
-    existingTask, err := u.taskRepo.GetByID(id, userID)
+func (u *taskUsecase) Update(id, userID int64, input domain.TaskInput) error {
+    existingTask, err := u.access.get(id, userID, domain.PermissionEditor)
     if err != nil {
-        return fmt.Errorf("error getting task: %v", err)
+        return err
+    }
+
+    return u.update(existingTask, userID, input, domain.HistoryUpdated)
+}
+
+// update applies input to existingTask on behalf of userID, recording the
+// change in the task's history as action.
+func (u *taskUsecase) update(existingTask *domain.Task, userID int64, input domain.TaskInput, action domain.HistoryAction) error {
+    id := existingTask.ID
+    if input.CompletedAt != nil && !input.Done {
+        return domain.ErrTaskCompletedNotDone
+    }
+    // Projects are the owner's, so only they may move the task between them.
+    if existingTask.Permission != domain.PermissionOwner && !sameID(input.ProjectID, existingTask.ProjectID) {
+        return domain.ErrTaskOwnerOnly
+    }
+    if err := u.checkProject(existingTask.UserID, input.ProjectID, existingTask.ProjectID); err != nil {
+        return err
+    }
+    if input.Done && !existingTask.Done {
+        if err := u.checkNoOpenSubtasks(id); err != nil {
+            return err
+        }
+    }
+    if input.KeepDueAt {
+        input.DueAt = existingTask.DueAt
     }
-    if existingTask == nil {
-        return fmt.Errorf("task not found or unauthorized")
+    if input.KeepRecurrence {
+        input.Recurrence = existingTask.Recurrence
+        input.RecurrenceTZ = existingTask.RecurrenceTZ
+    }
+    rule, tz, err := recurrence(input)
+    if err != nil {
+        return err
     }
 
+    title := input.Title
     if title == "" {
         title = existingTask.Title
     }
 
     task := &domain.Task{
-        ID:          id,
-        UserID:      userID,
-        Title:       title,
-        Description: description,
-        Done:        done,
+        ID:           id,
+        UserID:       existingTask.UserID,
+        ProjectID:    input.ProjectID,
+        Title:        title,
+        Description:  input.Description,
+        Done:         input.Done,
+        Priority:     input.Priority,
+        DueAt:        utcTime(input.DueAt),
+        CompletedAt:  completedAt(existingTask, input),
+        Recurrence:   rule,
+        RecurrenceTZ: tz,
+        // Not changed here, but the next occurrence inherits it.
+        AssigneeID: existingTask.AssigneeID,
     }
 
     if err := u.taskRepo.Update(task); err != nil {
-        return fmt.Errorf("error updating task: %v", err)
+        return fmt.Errorf("error updating task: %w", err)
+    }
+    if err := u.history.updated(userID, existingTask, action); err != nil {
+        return err
+    }
+
+    if task.Done && !existingTask.Done {
+        return u.scheduleNext(userID, task)
     }
 
     return nil
 }
 
-func (u *taskUsecase) Delete(id, userID int64) error {
-    // Check if task exists and belongs to user
-    existingTask, err := u.taskRepo.GetByID(id, userID)
+func (u *taskUsecase) Delete(id, userID int64, cascade bool) error {
+    task, err := u.access.get(id, userID, domain.PermissionOwner)
     if err != nil {
-        return fmt.Errorf("error getting task: %v", err)
+        return err
     }
-    if existingTask == nil {
-        return fmt.Errorf("task not found or unauthorized")
+
+    if !cascade {
+        if err := u.checkNoOpenSubtasks(id); err != nil {
+            return err
+        }
+    }
+
+    // The subtasks go to the trash with their parent, so they get history
+    // entries too.
+    subtasks, err := u.taskRepo.GetSubtasks(id, userID)
+    if err != nil {
+        return fmt.Errorf("error getting subtasks: %w", err)
     }
 
     if err := u.taskRepo.Delete(id, userID); err != nil {
-        return fmt.Errorf("error deleting task: %v", err)
+        return fmt.Errorf("error deleting task: %w", err)
     }
 
-    return nil
+    for i := range subtasks {
+        if err := u.history.deleted(userID, &subtasks[i]); err != nil {
+            return err
+        }
+    }
+    return u.history.deleted(userID, task)
 }
 
 func (u *taskUsecase) GetByID(id, userID int64) (*domain.Task, error) {
-    task, err := u.taskRepo.GetByID(id, userID)
+    task, err := u.access.get(id, userID, domain.PermissionViewer)
+    if err != nil {
+        return nil, err
+    }
+
+    tasks := []domain.Task{*task}
+    if err := u.loadDetails(tasks); err != nil {
+        return nil, err
+    }
+
+    return &tasks[0], nil
+}
+
+func (u *taskUsecase) GetAllByUserID(userID int64, query domain.TaskQuery) (*domain.TaskPage, error) {
+    filter, err := newTaskFilter(query)
+    if err != nil {
+        return nil, err
+    }
+
+    // Fetch one extra task to learn whether another page follows.
+    limit := filter.Limit
+    filter.Limit++
+
+    tasks, err := u.taskRepo.GetAllByUserID(userID, filter)
+    if err != nil {
+        return nil, fmt.Errorf("error getting tasks: %w", err)
+    }
+
+    page := &domain.TaskPage{}
+    if len(tasks) > limit {
+        tasks = tasks[:limit]
+        page.NextCursor, err = encodeTaskCursor(filter, tasks[limit-1])
+        if err != nil {
+            return nil, fmt.Errorf("error encoding cursor: %w", err)
+        }
+    }
+
+    // The repository already lists only visible tasks; checking again keeps
+    // the access rules in one place.
+    if err := u.access.resolve(userID, tasks); err != nil {
+        return nil, err
+    }
+    page.Tasks = visible(tasks)
+    if len(page.Tasks) == 0 {
+        page.Tasks = []domain.Task{}
+    }
+
+    if err := u.loadDetails(page.Tasks); err != nil {
+        return nil, err
+    }
+
+    return page, nil
+}
+
+func (u *taskUsecase) Complete(id, userID int64, cascade bool) error {
+    task, err := u.access.get(id, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+    if task.Done {
+        return nil
+    }
+
+    subtasks, err := u.taskRepo.GetSubtasks(id, task.UserID)
     if err != nil {
-        return nil, fmt.Errorf("error getting task: %v", err)
+        return fmt.Errorf("error getting subtasks: %w", err)
     }
-    if task == nil {
-        return nil, fmt.Errorf("task not found or unauthorized")
+
+    now := time.Now().UTC()
+    for _, subtask := range subtasks {
+        if subtask.Done {
+            continue
+        }
+        if !cascade {
+            return domain.ErrTaskHasOpenSubtasks
+        }
+        if err := u.markDone(userID, &subtask, now); err != nil {
+            return err
+        }
+    }
+
+    if err := u.markDone(userID, task, now); err != nil {
+        return err
     }
 
-    return task, nil
+    return u.scheduleNext(userID, task)
 }
 
-func (u *taskUsecase) GetAllByUserID(userID int64) ([]domain.Task, error) {
-    tasks, err := u.taskRepo.GetAllByUserID(userID)
+func (u *taskUsecase) Reopen(id, userID int64) error {
+    task, err := u.access.get(id, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+    if !task.Done {
+        return nil
+    }
+
+    before := *task
+    task.Done = false
+    task.CompletedAt = nil
+    if err := u.taskRepo.Update(task); err != nil {
+        return fmt.Errorf("error reopening task: %w", err)
+    }
+
+    return u.history.updated(userID, &before, domain.HistoryUpdated)
+}
+
+// Archive leaves the task as it is apart from hiding it from listings, so
+// it is not recorded in the history.
+func (u *taskUsecase) Archive(id, userID int64) error {
+    task, err := u.access.get(id, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+    if task.ArchivedAt != nil {
+        return nil
+    }
+
+    now := time.Now().UTC()
+    if err := u.taskRepo.SetArchivedAt(task.ID, task.UserID, &now); err != nil {
+        return fmt.Errorf("error archiving task: %w", err)
+    }
+
+    return nil
+}
+
+func (u *taskUsecase) Unarchive(id, userID int64) error {
+    task, err := u.access.get(id, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+    if task.ArchivedAt == nil {
+        return nil
+    }
+
+    if err := u.taskRepo.SetArchivedAt(task.ID, task.UserID, nil); err != nil {
+        return fmt.Errorf("error unarchiving task: %w", err)
+    }
+
+    return nil
+}
+
+func (u *taskUsecase) ArchiveCompleted(userID int64, olderThanDays int) (int64, error) {
+    if olderThanDays < 0 || olderThanDays > maxArchiveDays {
+        return 0, errInvalidArchiveDays
+    }
+
+    return archiveCompleted(u.taskRepo, userID, olderThanDays, time.Now().UTC())
+}
+
+func (u *taskUsecase) markDone(userID int64, task *domain.Task, now time.Time) error {
+    before := *task
+    task.Done = true
+    task.CompletedAt = &now
+    if err := u.taskRepo.Update(task); err != nil {
+        return fmt.Errorf("error completing task: %w", err)
+    }
+
+    return u.history.updated(userID, &before, domain.HistoryUpdated)
+}
+
+func (u *taskUsecase) AddSubtask(parentID, userID int64, input domain.TaskInput) error {
+    parent, err := u.access.get(parentID, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+    if parent.ParentID != nil {
+        return domain.ErrSubtaskNested
+    }
+    if input.Recurrence != "" {
+        return domain.ErrSubtaskRecurrence
+    }
+
+    // Subtasks live in their parent's project unless told otherwise.
+    if input.ProjectID == nil {
+        input.ProjectID = parent.ProjectID
+    }
+    if parent.Permission != domain.PermissionOwner && !sameID(input.ProjectID, parent.ProjectID) {
+        return domain.ErrTaskOwnerOnly
+    }
+
+    // Subtasks belong to their parent's owner, whoever adds them.
+    task, err := u.newTask(parent.UserID, input)
+    if err != nil {
+        return err
+    }
+
+    siblings, err := u.taskRepo.GetSubtasks(parentID, parent.UserID)
+    if err != nil {
+        return fmt.Errorf("error getting subtasks: %w", err)
+    }
+
+    task.ParentID = &parentID
+    task.Position = 1
+    if len(siblings) > 0 {
+        task.Position = siblings[len(siblings)-1].Position + 1
+    }
+
+    if err := u.taskRepo.Create(task); err != nil {
+        return fmt.Errorf("error creating subtask: %w", err)
+    }
+
+    return u.history.created(userID, task.ID)
+}
+
+func (u *taskUsecase) GetSubtasks(parentID, userID int64) ([]domain.Task, error) {
+    _, subtasks, err := u.getSubtasks(parentID, userID, domain.PermissionViewer)
+    if err != nil {
+        return nil, err
+    }
+
+    if err := u.access.resolve(userID, subtasks); err != nil {
+        return nil, err
+    }
+    if err := u.loadDetails(subtasks); err != nil {
+        return nil, err
+    }
+
+    return subtasks, nil
+}
+
+func (u *taskUsecase) ReorderSubtasks(parentID, userID int64, ids []int64) error {
+    parent, subtasks, err := u.getSubtasks(parentID, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+
+    if len(ids) != len(subtasks) {
+        return domain.ErrSubtaskOrder
+    }
+    remaining := make(map[int64]bool, len(subtasks))
+    for _, subtask := range subtasks {
+        remaining[subtask.ID] = true
+    }
+    for _, id := range ids {
+        if !remaining[id] {
+            return domain.ErrSubtaskOrder
+        }
+        delete(remaining, id)
+    }
+
+    if err := u.taskRepo.ReorderSubtasks(parentID, parent.UserID, ids); err != nil {
+        return fmt.Errorf("error reordering subtasks: %w", err)
+    }
+
+    return nil
+}
+
+// getSubtasks returns a task userID holds at least need on, along with its
+// subtasks.
+func (u *taskUsecase) getSubtasks(parentID, userID int64, need domain.Permission) (*domain.Task, []domain.Task, error) {
+    parent, err := u.access.get(parentID, userID, need)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    subtasks, err := u.taskRepo.GetSubtasks(parentID, parent.UserID)
+    if err != nil {
+        return nil, nil, fmt.Errorf("error getting subtasks: %w", err)
+    }
+    if subtasks == nil {
+        subtasks = []domain.Task{}
+    }
+
+    return parent, subtasks, nil
+}
+
+// maxSkippedOccurrences bounds how many past occurrences scheduleNext skips,
+// so a due date far in the past cannot keep it busy. Past the bound the next
+// occurrence is created overdue, and completing it carries on from there.
+const maxSkippedOccurrences = 1000
+
+// scheduleNext creates the next occurrence of a recurring task that has just
+// been completed. Occurrences already in the past are skipped, so finishing
+// a chore late does not leave a backlog of overdue copies. The series moves
+// to the new task: the completed one stops recurring, so reopening and
+// completing it again does not create a second copy. Both changes are
+// recorded as made by userID.
+func (u *taskUsecase) scheduleNext(userID int64, task *domain.Task) error {
+    if task.Recurrence == "" || task.DueAt == nil {
+        return nil
+    }
+
+    rule, err := rrule.Parse(task.Recurrence)
+    if err != nil {
+        return fmt.Errorf("error parsing stored recurrence: %w", err)
+    }
+    loc, err := time.LoadLocation(task.RecurrenceTZ)
     if err != nil {
-        return nil, fmt.Errorf("error getting tasks: %v", err)
+        return fmt.Errorf("error loading recurrence time zone: %w", err)
     }
 
-    return tasks, nil
-}
\ No newline at end of file
+    now := time.Now()
+    next, rest, ok := rule.Next(task.DueAt.In(loc))
+    for skipped := 0; ok && !next.After(now) && skipped < maxSkippedOccurrences; skipped++ {
+        next, rest, ok = rest.Next(next)
+    }
+
+    before := *task
+    tz := task.RecurrenceTZ
+    task.Recurrence = ""
+    task.RecurrenceTZ = ""
+    if err := u.taskRepo.Update(task); err != nil {
+        return fmt.Errorf("error ending task recurrence: %w", err)
+    }
+    if err := u.history.updated(userID, &before, domain.HistoryUpdated); err != nil {
+        return err
+    }
+    if !ok {
+        return nil
+    }
+
+    dueAt := next.UTC()
+    occurrence := &domain.Task{
+        UserID:       task.UserID,
+        ProjectID:    task.ProjectID,
+        Title:        task.Title,
+        Description:  task.Description,
+        Priority:     task.Priority,
+        DueAt:        &dueAt,
+        Recurrence:   rest.String(),
+        RecurrenceTZ: tz,
+        AssigneeID:   task.AssigneeID,
+    }
+    if err := u.taskRepo.Create(occurrence); err != nil {
+        return fmt.Errorf("error creating next occurrence: %w", err)
+    }
+    if err := u.history.created(userID, occurrence.ID); err != nil {
+        return err
+    }
+
+    tags, err := u.tagRepo.GetByTaskIDs([]int64{task.ID})
+    if err != nil {
+        return fmt.Errorf("error getting task tags: %w", err)
+    }
+    for _, tag := range tags[task.ID] {
+        if err := u.tagRepo.AttachToTask(occurrence.ID, tag.ID); err != nil {
+            return fmt.Errorf("error copying task tags: %w", err)
+        }
+    }
+
+    shares, err := u.shareRepo.GetTaskShares(task.ID)
+    if err != nil {
+        return fmt.Errorf("error getting task shares: %w", err)
+    }
+    for _, share := range shares {
+        if err := u.shareRepo.SaveTaskShare(occurrence.ID, share.UserID, share.Role); err != nil {
+            return fmt.Errorf("error copying task shares: %w", err)
+        }
+    }
+
+    return nil
+}
+
+func (u *taskUsecase) checkNoOpenSubtasks(id int64) error {
+    progress, err := u.taskRepo.GetSubtaskProgress([]int64{id})
+    if err != nil {
+        return fmt.Errorf("error getting subtask progress: %w", err)
+    }
+
+    if p := progress[id]; p.Done < p.Total {
+        return domain.ErrTaskHasOpenSubtasks
+    }
+
+    return nil
+}
+
+func (u *taskUsecase) MoveToProject(id, userID int64, projectID *int64) error {
+    task, err := u.access.get(id, userID, domain.PermissionOwner)
+    if err != nil {
+        return err
+    }
+
+    if err := u.checkProject(userID, projectID, task.ProjectID); err != nil {
+        return err
+    }
+
+    before := *task
+    task.ProjectID = projectID
+    if err := u.taskRepo.Update(task); err != nil {
+        return fmt.Errorf("error moving task: %w", err)
+    }
+
+    return u.history.updated(userID, &before, domain.HistoryUpdated)
+}
+
+func (u *taskUsecase) GetHistory(id, userID int64) ([]domain.TaskVersion, error) {
+    if _, err := u.access.get(id, userID, domain.PermissionViewer); err != nil {
+        return nil, err
+    }
+
+    versions, err := u.historyRepo.GetByTaskID(id)
+    if err != nil {
+        return nil, fmt.Errorf("error getting task history: %w", err)
+    }
+    if versions == nil {
+        versions = []domain.TaskVersion{}
+    }
+
+    return versions, nil
+}
+
+// Revert goes through the same checks as Update, so reverting cannot do
+// anything an update could not.
+func (u *taskUsecase) Revert(id, userID int64, version int) error {
+    task, err := u.access.get(id, userID, domain.PermissionEditor)
+    if err != nil {
+        return err
+    }
+
+    entry, err := u.historyRepo.GetVersion(id, version)
+    if err != nil {
+        return fmt.Errorf("error getting task version: %w", err)
+    }
+    if entry == nil {
+        return domain.ErrTaskVersionNotFound
+    }
+
+    snapshot := entry.Snapshot
+    projectID := snapshot.ProjectID
+    if task.Permission != domain.PermissionOwner {
+        projectID = task.ProjectID
+    }
+
+    return u.update(task, userID, domain.TaskInput{
+        ProjectID:    projectID,
+        Title:        snapshot.Title,
+        Description:  snapshot.Description,
+        Done:         snapshot.Done,
+        Priority:     snapshot.Priority,
+        DueAt:        snapshot.DueAt,
+        CompletedAt:  snapshot.CompletedAt,
+        Recurrence:   snapshot.Recurrence,
+        RecurrenceTZ: snapshot.RecurrenceTZ,
+    }, domain.HistoryReverted)
+}
+
+// ListByProject lists the tasks of a project the user owns or that was shared
+// with them.
+func (u *taskUsecase) ListByProject(userID, projectID int64, query domain.TaskQuery) (*domain.TaskPage, error) {
+    project, err := u.projectRepo.GetByID(projectID, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting project: %w", err)
+    }
+    if project == nil {
+        roles, err := u.shareRepo.GetProjectRoles(userID, []int64{projectID})
+        if err != nil {
+            return nil, fmt.Errorf("error getting project shares: %w", err)
+        }
+        if roles[projectID] == "" {
+            return nil, domain.ErrProjectNotFound
+        }
+    }
+
+    query.ProjectID = &projectID
+    return u.GetAllByUserID(userID, query)
+}
+
+func (u *taskUsecase) ListAssignedTo(userID int64, query domain.TaskQuery) (*domain.TaskPage, error) {
+    query.AssigneeID = &userID
+    query.IncludeSubtasks = true
+    return u.GetAllByUserID(userID, query)
+}
+
+// checkProject makes sure a task of userID may be placed in projectID. The
+// project must belong to the user, and must not be archived unless the task
+// is already in it.
+func (u *taskUsecase) checkProject(userID int64, projectID, currentID *int64) error {
+    if projectID == nil {
+        return nil
+    }
+
+    project, err := u.projectRepo.GetByID(*projectID, userID)
+    if err != nil {
+        return fmt.Errorf("error getting project: %w", err)
+    }
+    if project == nil {
+        return domain.ErrProjectNotFound
+    }
+
+    if project.ArchivedAt != nil && (currentID == nil || *currentID != *projectID) {
+        return domain.ErrProjectArchived
+    }
+
+    return nil
+}
+
+func (u *taskUsecase) AddTag(id, userID, tagID int64) error {
+    if err := u.checkTaskAndTag(id, userID, tagID); err != nil {
+        return err
+    }
+
+    if err := u.tagRepo.AttachToTask(id, tagID); err != nil {
+        return fmt.Errorf("error attaching tag: %w", err)
+    }
+
+    return nil
+}
+
+func (u *taskUsecase) RemoveTag(id, userID, tagID int64) error {
+    if err := u.checkTaskAndTag(id, userID, tagID); err != nil {
+        return err
+    }
+
+    if err := u.tagRepo.DetachFromTask(id, tagID); err != nil {
+        return fmt.Errorf("error detaching tag: %w", err)
+    }
+
+    return nil
+}
+
+// checkTaskAndTag makes sure both the task and the tag belong to userID.
+// Tags are personal, so collaborators cannot tag a shared task.
+func (u *taskUsecase) checkTaskAndTag(id, userID, tagID int64) error {
+    if _, err := u.access.get(id, userID, domain.PermissionOwner); err != nil {
+        return err
+    }
+
+    tag, err := u.tagRepo.GetByID(tagID, userID)
+    if err != nil {
+        return fmt.Errorf("error getting tag: %w", err)
+    }
+    if tag == nil {
+        return domain.ErrTagNotFound
+    }
+
+    return nil
+}
+
+// loadDetails fills in the Tags and Progress of every task, with one
+// repository call each.
+func (u *taskUsecase) loadDetails(tasks []domain.Task) error {
+    if len(tasks) == 0 {
+        return nil
+    }
+
+    ids := make([]int64, len(tasks))
+    for i, task := range tasks {
+        ids[i] = task.ID
+    }
+
+    tags, err := u.tagRepo.GetByTaskIDs(ids)
+    if err != nil {
+        return fmt.Errorf("error getting task tags: %w", err)
+    }
+
+    progress, err := u.taskRepo.GetSubtaskProgress(ids)
+    if err != nil {
+        return fmt.Errorf("error getting subtask progress: %w", err)
+    }
+
+    for i := range tasks {
+        tasks[i].Tags = tags[tasks[i].ID]
+        if tasks[i].Tags == nil {
+            tasks[i].Tags = []domain.Tag{}
+        }
+        if p, ok := progress[tasks[i].ID]; ok {
+            tasks[i].Progress = &p
+        }
+    }
+
+    return nil
+}
+
+func (u *taskUsecase) ListOverdue(userID int64, query domain.TaskQuery) (*domain.TaskPage, error) {
+    now := time.Now()
+    query.DueBefore = &now
+    return u.listDue(userID, query)
+}
+
+func (u *taskUsecase) ListDueToday(userID int64, loc *time.Location, query domain.TaskQuery) (*domain.TaskPage, error) {
+    start := startOfDay(time.Now().In(loc))
+    end := start.AddDate(0, 0, 1)
+    query.DueFrom = &start
+    query.DueBefore = &end
+    return u.listDue(userID, query)
+}
+
+func (u *taskUsecase) ListDueWithin(userID int64, days int, loc *time.Location, query domain.TaskQuery) (*domain.TaskPage, error) {
+    if days < 0 || days > maxDueWithinDays {
+        return nil, errInvalidDays
+    }
+
+    now := time.Now().In(loc)
+    end := startOfDay(now).AddDate(0, 0, days+1)
+    query.DueFrom = &now
+    query.DueBefore = &end
+    return u.listDue(userID, query)
+}
+
+// listDue lists by due date, soonest first, and hides completed tasks unless
+// the client asked for them. Subtasks are included since they carry their
+// own due dates.
+func (u *taskUsecase) listDue(userID int64, query domain.TaskQuery) (*domain.TaskPage, error) {
+    query.IncludeSubtasks = true
+    if query.Done == nil {
+        open := false
+        query.Done = &open
+    }
+    if query.Sort == "" {
+        query.Sort = domain.TaskSortDueAt
+    }
+
+    return u.GetAllByUserID(userID, query)
+}
+
+// recurrence validates the recurrence of input, returning the rule in
+// canonical form and the time zone it is worked out in.
+func recurrence(input domain.TaskInput) (string, string, error) {
+    if input.Recurrence == "" {
+        return "", "", nil
+    }
+    if input.DueAt == nil {
+        return "", "", domain.ErrRecurrenceNeedsDueAt
+    }
+
+    rule, err := rrule.Parse(input.Recurrence)
+    if err != nil {
+        return "", "", domain.ValidationError("invalid recurrence: " + err.Error())
+    }
+
+    tz := input.RecurrenceTZ
+    if tz == "" {
+        tz = "UTC"
+    }
+    if _, err := time.LoadLocation(tz); err != nil {
+        return "", "", domain.ErrInvalidRecurrenceTZ
+    }
+
+    return rule.String(), tz, nil
+}
+
+// completedAt works out the completion time an update leaves task with.
+func completedAt(existing *domain.Task, input domain.TaskInput) *time.Time {
+    switch {
+    case !input.Done:
+        return nil
+    case input.CompletedAt != nil:
+        return utcTime(input.CompletedAt)
+    case existing.CompletedAt != nil:
+        return existing.CompletedAt
+    default:
+        now := time.Now().UTC()
+        return &now
+    }
+}
+
+// sameID reports whether two optional IDs are equal.
+func sameID(a, b *int64) bool {
+    if a == nil || b == nil {
+        return a == b
+    }
+    return *a == *b
+}
+
+// startOfDay returns midnight of t's day in t's location.
+func startOfDay(t time.Time) time.Time {
+    year, month, day := t.Date()
+    return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// utcTime normalises client-supplied instants so every backend stores and
+// compares them the same way.
+func utcTime(t *time.Time) *time.Time {
+    if t == nil {
+        return nil
+    }
+    utc := t.UTC()
+    return &utc
+}
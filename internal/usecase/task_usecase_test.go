@@ -0,0 +1,421 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	memoryrepo "todo-app/internal/repository/memory"
+)
+
+// taskFixture holds a task usecase over a fresh memory store, with the
+// repositories the other usecases of a test are built from.
+type taskFixture struct {
+    store          *memoryrepo.Store
+    userRepo       domain.UserRepository
+    taskRepo       domain.TaskRepository
+    tagRepo        domain.TagRepository
+    projectRepo    domain.ProjectRepository
+    shareRepo      domain.ShareRepository
+    historyRepo    domain.TaskHistoryRepository
+    assignmentRepo domain.AssignmentRepository
+    tasks          domain.TaskUsecase
+}
+
+func newTaskFixture(t *testing.T) *taskFixture {
+    t.Helper()
+
+    store := memoryrepo.NewStore()
+    f := &taskFixture{
+        store:          store,
+        userRepo:       memoryrepo.NewMemoryUserRepository(store),
+        taskRepo:       memoryrepo.NewMemoryTaskRepository(store),
+        tagRepo:        memoryrepo.NewMemoryTagRepository(store),
+        projectRepo:    memoryrepo.NewMemoryProjectRepository(store),
+        shareRepo:      memoryrepo.NewMemoryShareRepository(store),
+        historyRepo:    memoryrepo.NewMemoryTaskHistoryRepository(store),
+        assignmentRepo: memoryrepo.NewMemoryAssignmentRepository(store),
+    }
+    f.tasks = NewTaskUsecase(f.taskRepo, f.tagRepo, f.projectRepo, f.shareRepo, f.historyRepo)
+    return f
+}
+
+func (f *taskFixture) newUser(t *testing.T, username string) int64 {
+    t.Helper()
+
+    user := &domain.User{Username: username, Password: "x"}
+    if err := f.userRepo.Create(user); err != nil {
+        t.Fatalf("creating %s: %v", username, err)
+    }
+    return user.ID
+}
+
+// newTask creates a task through the usecase and returns it as its owner
+// sees it.
+func (f *taskFixture) newTask(t *testing.T, userID int64, input domain.TaskInput) *domain.Task {
+    t.Helper()
+
+    if err := f.tasks.Create(userID, input); err != nil {
+        t.Fatalf("creating %q: %v", input.Title, err)
+    }
+    return f.lastTask(t, userID)
+}
+
+// newSubtask adds a subtask through the usecase and returns it.
+func (f *taskFixture) newSubtask(t *testing.T, parentID, userID int64, title string) *domain.Task {
+    t.Helper()
+
+    if err := f.tasks.AddSubtask(parentID, userID, domain.TaskInput{Title: title}); err != nil {
+        t.Fatalf("adding subtask %q: %v", title, err)
+    }
+    return f.lastTask(t, userID)
+}
+
+// lastTask returns the task userID can see that was created last.
+func (f *taskFixture) lastTask(t *testing.T, userID int64) *domain.Task {
+    t.Helper()
+
+    page, err := f.tasks.GetAllByUserID(userID, domain.TaskQuery{
+        Limit:           1,
+        Sort:            domain.TaskSortCreatedAt,
+        Order:           domain.SortDesc,
+        IncludeSubtasks: true,
+        Archived:        domain.ArchivedInclude,
+    })
+    if err != nil || len(page.Tasks) == 0 {
+        t.Fatalf("finding the last task: %v", err)
+    }
+    return f.getTask(t, page.Tasks[0].ID, userID)
+}
+
+func (f *taskFixture) getTask(t *testing.T, id, userID int64) *domain.Task {
+    t.Helper()
+
+    task, err := f.tasks.GetByID(id, userID)
+    if err != nil {
+        t.Fatalf("getting task %d: %v", id, err)
+    }
+    return task
+}
+
+// nextOccurrence returns the open task following a completed recurring one.
+func (f *taskFixture) nextOccurrence(t *testing.T, userID int64, completed *domain.Task) *domain.Task {
+    t.Helper()
+
+    next := f.lastTask(t, userID)
+    if next.ID == completed.ID || next.Done || next.Title != completed.Title {
+        t.Fatalf("no next occurrence of %q: last task is %+v", completed.Title, next)
+    }
+    return next
+}
+
+func TestUpdateKeepsOmittedSchedule(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    due := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+    task := f.newTask(t, alice, domain.TaskInput{
+        Title:        "Water the plants",
+        DueAt:        &due,
+        Recurrence:   "FREQ=WEEKLY",
+        RecurrenceTZ: "Europe/Berlin",
+    })
+
+    // A client that only knows about the title and done flag.
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{
+        Title:          "Water all the plants",
+        KeepDueAt:      true,
+        KeepRecurrence: true,
+    }); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    updated := f.getTask(t, task.ID, alice)
+    if updated.Title != "Water all the plants" || updated.DueAt == nil || !updated.DueAt.Equal(due) ||
+        updated.Recurrence != "FREQ=WEEKLY" || updated.RecurrenceTZ != "Europe/Berlin" {
+        t.Fatalf("after Update: %+v, want the due date and recurrence kept", updated)
+    }
+
+    // Completing it the same way moves the series on to the next occurrence.
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{
+        Title:          "Water all the plants",
+        Done:           true,
+        KeepDueAt:      true,
+        KeepRecurrence: true,
+    }); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    completed := f.getTask(t, task.ID, alice)
+    if !completed.Done || completed.DueAt == nil || !completed.DueAt.Equal(due) || completed.Recurrence != "" {
+        t.Errorf("completed task: %+v, want done with its due date and no recurrence", completed)
+    }
+    // A week on in Berlin, which is not always 168 hours.
+    berlin, err := time.LoadLocation("Europe/Berlin")
+    if err != nil {
+        t.Fatal(err)
+    }
+    next := f.nextOccurrence(t, alice, completed)
+    if next.DueAt == nil || !next.DueAt.Equal(due.In(berlin).AddDate(0, 0, 7)) ||
+        next.Recurrence != "FREQ=WEEKLY" || next.RecurrenceTZ != "Europe/Berlin" {
+        t.Errorf("next occurrence: due %v, recurrence %q in %q", next.DueAt, next.Recurrence, next.RecurrenceTZ)
+    }
+}
+
+func TestUpdateReplacesGivenSchedule(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    due := time.Now().UTC().Add(24 * time.Hour).Truncate(time.Second)
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Report", DueAt: &due, Recurrence: "FREQ=MONTHLY"})
+
+    // An empty recurrence stops the series and leaves the due date.
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{Title: "Report", KeepDueAt: true}); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if got := f.getTask(t, task.ID, alice); got.Recurrence != "" || got.RecurrenceTZ != "" || got.DueAt == nil {
+        t.Errorf("after stopping the recurrence: %+v", got)
+    }
+
+    // A new due date with the recurrence left out keeps the recurrence.
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{Title: "Report", DueAt: &due, Recurrence: "FREQ=DAILY"}); err != nil {
+        t.Fatal(err)
+    }
+    later := due.Add(time.Hour)
+    if err := f.tasks.Update(task.ID, alice, domain.TaskInput{Title: "Report", DueAt: &later, KeepRecurrence: true}); err != nil {
+        t.Fatalf("Update: %v", err)
+    }
+    if got := f.getTask(t, task.ID, alice); got.Recurrence != "FREQ=DAILY" || !got.DueAt.Equal(later) {
+        t.Errorf("after moving the due date: %+v", got)
+    }
+
+    // Removing the due date of a recurring task is refused, not silently
+    // dropping the recurrence.
+    err := f.tasks.Update(task.ID, alice, domain.TaskInput{Title: "Report", KeepRecurrence: true})
+    if !errors.Is(err, domain.ErrRecurrenceNeedsDueAt) {
+        t.Errorf("Update removing the due date: err = %v, want ErrRecurrenceNeedsDueAt", err)
+    }
+}
+
+func TestCompletingRecurringTaskSkipsPastOccurrences(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    now := time.Now().UTC()
+    due := now.AddDate(0, 0, -10).Truncate(time.Second)
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Stretch", DueAt: &due, Recurrence: "FREQ=DAILY;COUNT=30"})
+
+    if err := f.tasks.Complete(task.ID, alice, false); err != nil {
+        t.Fatalf("Complete: %v", err)
+    }
+    next := f.nextOccurrence(t, alice, task)
+    if next.DueAt == nil || !next.DueAt.After(now) || next.DueAt.After(now.Add(24*time.Hour)) {
+        t.Errorf("next occurrence due %v, want within a day from now", next.DueAt)
+    }
+    // The skipped occurrences count against COUNT.
+    if next.Recurrence != "FREQ=DAILY;COUNT=19" {
+        t.Errorf("next occurrence recurs %q, want FREQ=DAILY;COUNT=19", next.Recurrence)
+    }
+}
+
+func TestCompletingLongOverdueRecurringTaskIsBounded(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    due := time.Date(1990, time.January, 1, 9, 0, 0, 0, time.UTC)
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Ancient chore", DueAt: &due, Recurrence: "FREQ=DAILY"})
+
+    if err := f.tasks.Complete(task.ID, alice, false); err != nil {
+        t.Fatalf("Complete: %v", err)
+    }
+
+    // The search gives up after maxSkippedOccurrences, leaving the next
+    // occurrence overdue rather than stepping through decades of days.
+    next := f.nextOccurrence(t, alice, task)
+    if want := due.AddDate(0, 0, maxSkippedOccurrences+1); next.DueAt == nil || !next.DueAt.Equal(want) {
+        t.Errorf("next occurrence due %v, want %v", next.DueAt, want)
+    }
+
+    // Completing that one carries on from where it left off.
+    if err := f.tasks.Complete(next.ID, alice, false); err != nil {
+        t.Fatalf("Complete: %v", err)
+    }
+    if want := due.AddDate(0, 0, 2*(maxSkippedOccurrences+1)); !f.nextOccurrence(t, alice, next).DueAt.Equal(want) {
+        t.Errorf("occurrence after that not due %v", want)
+    }
+}
+
+// shareTask shares a task of ownerID with username as role.
+func (f *taskFixture) shareTask(t *testing.T, taskID, ownerID int64, username string, role domain.Permission) {
+    t.Helper()
+
+    shares := NewShareUsecase(f.shareRepo, f.userRepo, f.taskRepo, f.projectRepo, f.historyRepo)
+    if _, err := shares.ShareTask(taskID, ownerID, username, role); err != nil {
+        t.Fatalf("sharing task %d with %s: %v", taskID, username, err)
+    }
+}
+
+func (f *taskFixture) assign(t *testing.T, taskID, userID, assigneeID int64) {
+    t.Helper()
+
+    assignments := NewAssignmentUsecase(f.assignmentRepo, f.taskRepo, f.shareRepo, f.historyRepo)
+    if err := assignments.Assign(taskID, userID, &assigneeID); err != nil {
+        t.Fatalf("assigning task %d to %d: %v", taskID, assigneeID, err)
+    }
+}
+
+func TestNextOccurrenceKeepsAssignee(t *testing.T) {
+    complete := map[string]func(f *taskFixture, task *domain.Task, userID int64) error{
+        "Update": func(f *taskFixture, task *domain.Task, userID int64) error {
+            return f.tasks.Update(task.ID, userID, domain.TaskInput{
+                Title:          task.Title,
+                Done:           true,
+                KeepDueAt:      true,
+                KeepRecurrence: true,
+            })
+        },
+        "Complete": func(f *taskFixture, task *domain.Task, userID int64) error {
+            return f.tasks.Complete(task.ID, userID, false)
+        },
+    }
+
+    for name, complete := range complete {
+        t.Run(name, func(t *testing.T) {
+            f := newTaskFixture(t)
+            alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+            due := time.Now().UTC().Add(time.Hour).Truncate(time.Second)
+            task := f.newTask(t, alice, domain.TaskInput{Title: "Take out the bins", DueAt: &due, Recurrence: "FREQ=WEEKLY"})
+            f.shareTask(t, task.ID, alice, "bob", domain.PermissionEditor)
+            f.assign(t, task.ID, alice, bob)
+
+            // Bob does the chore and marks it done himself.
+            if err := complete(f, f.getTask(t, task.ID, bob), bob); err != nil {
+                t.Fatalf("completing: %v", err)
+            }
+
+            completed := f.getTask(t, task.ID, alice)
+            if completed.AssigneeID == nil || *completed.AssigneeID != bob {
+                t.Errorf("completed task assigned to %v, want bob", completed.AssigneeID)
+            }
+            next := f.nextOccurrence(t, alice, completed)
+            if next.AssigneeID == nil || *next.AssigneeID != bob {
+                t.Errorf("next occurrence assigned to %v, want bob", next.AssigneeID)
+            }
+            // The share comes along, so bob can still see what he is
+            // assigned to.
+            if got := f.getTask(t, next.ID, bob); got.Permission != domain.PermissionEditor {
+                t.Errorf("bob's permission on the next occurrence = %q", got.Permission)
+            }
+        })
+    }
+}
+
+func (f *taskFixture) newProject(t *testing.T, userID int64, name string) *domain.Project {
+    t.Helper()
+
+    projects := NewProjectUsecase(f.projectRepo, f.taskRepo, f.historyRepo)
+    project, err := projects.Create(userID, domain.ProjectInput{Name: name})
+    if err != nil {
+        t.Fatalf("creating project %q: %v", name, err)
+    }
+    return project
+}
+
+func TestCompleteRefusesOpenSubtasks(t *testing.T) {
+    f := newTaskFixture(t)
+    alice := f.newUser(t, "alice")
+    parent := f.newTask(t, alice, domain.TaskInput{Title: "Move house"})
+    boxes := f.newSubtask(t, parent.ID, alice, "Pack boxes")
+    f.newSubtask(t, parent.ID, alice, "Book a van")
+
+    if err := f.tasks.Complete(parent.ID, alice, false); !errors.Is(err, domain.ErrTaskHasOpenSubtasks) {
+        t.Errorf("Complete: err = %v, want ErrTaskHasOpenSubtasks", err)
+    }
+    if err := f.tasks.Update(parent.ID, alice, domain.TaskInput{Title: "Move house", Done: true}); !errors.Is(err, domain.ErrTaskHasOpenSubtasks) {
+        t.Errorf("Update marking it done: err = %v, want ErrTaskHasOpenSubtasks", err)
+    }
+    if err := f.tasks.Delete(parent.ID, alice, false); !errors.Is(err, domain.ErrTaskHasOpenSubtasks) {
+        t.Errorf("Delete: err = %v, want ErrTaskHasOpenSubtasks", err)
+    }
+
+    // One subtask done is not enough.
+    if err := f.tasks.Complete(boxes.ID, alice, false); err != nil {
+        t.Fatalf("Complete of a subtask: %v", err)
+    }
+    got := f.getTask(t, parent.ID, alice)
+    if got.Done || got.Progress == nil || got.Progress.Done != 1 || got.Progress.Total != 2 {
+        t.Fatalf("parent = done %t, progress %+v; want open at 1 of 2", got.Done, got.Progress)
+    }
+    if err := f.tasks.Complete(parent.ID, alice, false); !errors.Is(err, domain.ErrTaskHasOpenSubtasks) {
+        t.Errorf("Complete with one open subtask: err = %v, want ErrTaskHasOpenSubtasks", err)
+    }
+
+    // Cascading completes the rest along with the parent.
+    if err := f.tasks.Complete(parent.ID, alice, true); err != nil {
+        t.Fatalf("Complete with cascade: %v", err)
+    }
+    got = f.getTask(t, parent.ID, alice)
+    if !got.Done || got.Progress == nil || got.Progress.Done != 2 || got.Progress.Total != 2 {
+        t.Errorf("parent = done %t, progress %+v; want done at 2 of 2", got.Done, got.Progress)
+    }
+}
+
+func TestAddSubtask(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    parent := f.newTask(t, alice, domain.TaskInput{Title: "Plan the trip"})
+    child := f.newSubtask(t, parent.ID, alice, "Book flights")
+
+    if child.ParentID == nil || *child.ParentID != parent.ID || child.Position != 1 {
+        t.Errorf("subtask = parent %v, position %d", child.ParentID, child.Position)
+    }
+    if err := f.tasks.AddSubtask(child.ID, alice, domain.TaskInput{Title: "Pick seats"}); !errors.Is(err, domain.ErrSubtaskNested) {
+        t.Errorf("AddSubtask to a subtask: err = %v, want ErrSubtaskNested", err)
+    }
+    due := time.Now().UTC().Add(time.Hour)
+    err := f.tasks.AddSubtask(parent.ID, alice, domain.TaskInput{Title: "Pack", DueAt: &due, Recurrence: "FREQ=DAILY"})
+    if !errors.Is(err, domain.ErrSubtaskRecurrence) {
+        t.Errorf("AddSubtask of a recurring task: err = %v, want ErrSubtaskRecurrence", err)
+    }
+
+    // A subtask an editor adds belongs to the parent's owner.
+    f.shareTask(t, parent.ID, alice, "bob", domain.PermissionEditor)
+    added := f.newSubtask(t, parent.ID, bob, "Book a hotel")
+    if added.UserID != alice || added.Position != 2 {
+        t.Errorf("subtask added by an editor = owner %d, position %d; want alice's, at 2", added.UserID, added.Position)
+    }
+}
+
+func TestReorderSubtasks(t *testing.T) {
+    f := newTaskFixture(t)
+    alice, bob := f.newUser(t, "alice"), f.newUser(t, "bob")
+    parent := f.newTask(t, alice, domain.TaskInput{Title: "Bake a cake"})
+    a := f.newSubtask(t, parent.ID, alice, "Mix")
+    b := f.newSubtask(t, parent.ID, alice, "Bake")
+    c := f.newSubtask(t, parent.ID, alice, "Ice")
+    stranger := f.newTask(t, alice, domain.TaskInput{Title: "Not a subtask"})
+
+    // The IDs must be exactly the parent's subtasks, each once.
+    for name, ids := range map[string][]int64{
+        "none":           nil,
+        "one missing":    {c.ID, a.ID},
+        "one repeated":   {c.ID, a.ID, a.ID},
+        "another task":   {c.ID, a.ID, stranger.ID},
+        "one too many":   {c.ID, a.ID, b.ID, stranger.ID},
+        "the parent too": {c.ID, a.ID, b.ID, parent.ID},
+    } {
+        if err := f.tasks.ReorderSubtasks(parent.ID, alice, ids); !errors.Is(err, domain.ErrSubtaskOrder) {
+            t.Errorf("reordering with %s: err = %v, want ErrSubtaskOrder", name, err)
+        }
+    }
+
+    f.shareTask(t, parent.ID, alice, "bob", domain.PermissionViewer)
+    if err := f.tasks.ReorderSubtasks(parent.ID, bob, []int64{c.ID, a.ID, b.ID}); !errors.Is(err, domain.ErrTaskReadOnly) {
+        t.Errorf("ReorderSubtasks by a viewer: err = %v, want ErrTaskReadOnly", err)
+    }
+
+    if err := f.tasks.ReorderSubtasks(parent.ID, alice, []int64{c.ID, a.ID, b.ID}); err != nil {
+        t.Fatalf("ReorderSubtasks: %v", err)
+    }
+    subtasks, err := f.tasks.GetSubtasks(parent.ID, bob)
+    if err != nil {
+        t.Fatalf("GetSubtasks: %v", err)
+    }
+    if got := taskTitles(subtasks); !equalTitles(got, []string{"Ice", "Mix", "Bake"}) {
+        t.Errorf("subtasks = %v, want [Ice Mix Bake]", got)
+    }
+}
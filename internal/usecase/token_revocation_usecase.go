@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// maxCachedRevocations bounds how many answers the cache holds, since
+// every access token checked adds its ID and its session's. The database
+// stays the authority, so an answer dropped early costs a query and
+// nothing else.
+const maxCachedRevocations = 10000
+
+// revocationEntry is a cached answer to whether a token ID is revoked,
+// good until until.
+type revocationEntry struct {
+    revoked bool
+    until   time.Time
+}
+
+type tokenRevocationUsecase struct {
+    revocationRepo domain.TokenRevocationRepository
+    // cacheTTL is how long an ID found not to be revoked is taken at its
+    // word, which is how long another instance may take to notice it was
+    // revoked. A revoked ID is cached until it expires, since it never
+    // comes back.
+    cacheTTL time.Duration
+
+    mu    sync.Mutex
+    cache map[string]revocationEntry
+}
+
+func NewTokenRevocationUsecase(
+    revocationRepo domain.TokenRevocationRepository,
+    cacheTTL time.Duration,
+) domain.TokenRevocationUsecase {
+    return &tokenRevocationUsecase{
+        revocationRepo: revocationRepo,
+        cacheTTL:       cacheTTL,
+        cache:          make(map[string]revocationEntry),
+    }
+}
+
+func (u *tokenRevocationUsecase) Revoke(ids []string, userID int64, expiresAt time.Time) error {
+    if err := u.revocationRepo.Revoke(ids, userID, expiresAt.UTC()); err != nil {
+        return fmt.Errorf("error revoking tokens: %w", err)
+    }
+
+    u.mu.Lock()
+    defer u.mu.Unlock()
+    for _, id := range ids {
+        u.store(id, revocationEntry{revoked: true, until: expiresAt})
+    }
+
+    return nil
+}
+
+// IsRevoked skips empty IDs, which tokens issued before they carried
+// them have.
+func (u *tokenRevocationUsecase) IsRevoked(ids ...string) (bool, error) {
+    now := time.Now()
+    for _, id := range ids {
+        if id == "" {
+            continue
+        }
+
+        revoked, ok := u.cached(id, now)
+        if !ok {
+            var err error
+            revoked, err = u.revocationRepo.IsRevoked(id)
+            if err != nil {
+                return false, fmt.Errorf("error checking token revocation: %w", err)
+            }
+            if !revoked && u.cacheTTL > 0 {
+                u.mu.Lock()
+                u.store(id, revocationEntry{until: now.Add(u.cacheTTL)})
+                u.mu.Unlock()
+            }
+        }
+        if revoked {
+            return true, nil
+        }
+    }
+
+    return false, nil
+}
+
+func (u *tokenRevocationUsecase) cached(id string, now time.Time) (revoked, ok bool) {
+    u.mu.Lock()
+    defer u.mu.Unlock()
+
+    entry, ok := u.cache[id]
+    if !ok {
+        return false, false
+    }
+    if !now.Before(entry.until) {
+        delete(u.cache, id)
+        return false, false
+    }
+    return entry.revoked, true
+}
+
+// store caches entry for id, first evicting an entry at random when the
+// cache is full. The caller holds mu.
+func (u *tokenRevocationUsecase) store(id string, entry revocationEntry) {
+    if _, ok := u.cache[id]; !ok && len(u.cache) >= maxCachedRevocations {
+        for evicted := range u.cache {
+            delete(u.cache, evicted)
+            break
+        }
+    }
+    u.cache[id] = entry
+}
+
+// PurgeExpired also drops the cached answers that are stale.
+func (u *tokenRevocationUsecase) PurgeExpired(ctx context.Context) error {
+    now := time.Now()
+
+    u.mu.Lock()
+    for id, entry := range u.cache {
+        if !now.Before(entry.until) {
+            delete(u.cache, id)
+        }
+    }
+    u.mu.Unlock()
+
+    if _, err := u.revocationRepo.DeleteExpired(now.UTC()); err != nil {
+        return fmt.Errorf("error purging revoked tokens: %w", err)
+    }
+
+    return nil
+}
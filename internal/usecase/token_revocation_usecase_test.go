@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	memoryrepo "todo-app/internal/repository/memory"
+)
+
+func TestRevocationCacheIsBounded(t *testing.T) {
+    revocations := NewTokenRevocationUsecase(memoryrepo.NewMemoryTokenRevocationRepository(memoryrepo.NewStore()), time.Hour).(*tokenRevocationUsecase)
+
+    for i := 0; i < maxCachedRevocations+100; i++ {
+        if revoked, err := revocations.IsRevoked(fmt.Sprintf("jti-%d", i), fmt.Sprintf("sid-%d", i)); err != nil || revoked {
+            t.Fatalf("IsRevoked = %v, %v", revoked, err)
+        }
+    }
+    if err := revocations.Revoke([]string{"revoked"}, 1, time.Now().Add(time.Hour)); err != nil {
+        t.Fatal(err)
+    }
+
+    revocations.mu.Lock()
+    size := len(revocations.cache)
+    revocations.mu.Unlock()
+    if size > maxCachedRevocations {
+        t.Errorf("cache holds %d entries, want at most %d", size, maxCachedRevocations)
+    }
+
+    // Whatever was evicted, the answers stay right.
+    if revoked, err := revocations.IsRevoked("jti-0", "revoked"); err != nil || !revoked {
+        t.Errorf("IsRevoked of a revoked ID = %v, %v", revoked, err)
+    }
+}
+
+func TestRevocationCacheDropsStaleEntries(t *testing.T) {
+    store := memoryrepo.NewStore()
+    repo := memoryrepo.NewMemoryTokenRevocationRepository(store)
+    revocations := NewTokenRevocationUsecase(repo, time.Hour).(*tokenRevocationUsecase)
+
+    if revoked, _ := revocations.IsRevoked("jti"); revoked {
+        t.Fatal("a fresh ID is revoked")
+    }
+
+    // Another instance revokes the ID; the cached answer holds until it
+    // goes stale.
+    if err := repo.Revoke([]string{"jti"}, 1, time.Now().Add(time.Hour)); err != nil {
+        t.Fatal(err)
+    }
+    if revoked, _ := revocations.IsRevoked("jti"); revoked {
+        t.Error("the cached answer was not used")
+    }
+
+    revocations.mu.Lock()
+    revocations.cache["jti"] = revocationEntry{until: time.Now().Add(-time.Second)}
+    revocations.cache["other"] = revocationEntry{until: time.Now().Add(-time.Second)}
+    revocations.mu.Unlock()
+    if revoked, _ := revocations.IsRevoked("jti"); !revoked {
+        t.Error("a stale cached answer was used")
+    }
+
+    if err := revocations.PurgeExpired(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    revocations.mu.Lock()
+    _, ok := revocations.cache["other"]
+    revocations.mu.Unlock()
+    if ok {
+        t.Error("PurgeExpired kept a stale entry")
+    }
+}
@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"todo-app/internal/domain"
+)
+
+// trashPurgeBatchSize bounds how many tasks one PurgeExpired call purges; the
+// rest wait for the next run.
+const trashPurgeBatchSize = 100
+
+// trashUsecase works on the owner's tasks only. Collaborators lose sight of a
+// task once it is in the trash, like they would if it were deleted.
+type trashUsecase struct {
+    taskRepo       domain.TaskRepository
+    tagRepo        domain.TagRepository
+    attachmentRepo domain.AttachmentRepository
+    blobs          domain.BlobStore
+    history        taskHistory
+    // retention is how long a task stays in the trash before PurgeExpired
+    // removes it.
+    retention time.Duration
+}
+
+func NewTrashUsecase(
+    taskRepo domain.TaskRepository,
+    tagRepo domain.TagRepository,
+    attachmentRepo domain.AttachmentRepository,
+    historyRepo domain.TaskHistoryRepository,
+    blobs domain.BlobStore,
+    retention time.Duration,
+) domain.TrashUsecase {
+    return &trashUsecase{
+        taskRepo:       taskRepo,
+        tagRepo:        tagRepo,
+        attachmentRepo: attachmentRepo,
+        blobs:          blobs,
+        history:        taskHistory{historyRepo, taskRepo},
+        retention:      retention,
+    }
+}
+
+func (u *trashUsecase) List(userID int64) ([]domain.Task, error) {
+    tasks, err := u.taskRepo.GetTrash(userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting trash: %w", err)
+    }
+    if len(tasks) == 0 {
+        return []domain.Task{}, nil
+    }
+
+    ids := make([]int64, len(tasks))
+    for i, task := range tasks {
+        ids[i] = task.ID
+    }
+
+    tags, err := u.tagRepo.GetByTaskIDs(ids)
+    if err != nil {
+        return nil, fmt.Errorf("error getting task tags: %w", err)
+    }
+
+    for i := range tasks {
+        tasks[i].Permission = domain.PermissionOwner
+        tasks[i].Tags = tags[tasks[i].ID]
+        if tasks[i].Tags == nil {
+            tasks[i].Tags = []domain.Tag{}
+        }
+    }
+
+    return tasks, nil
+}
+
+// Restore refuses a subtask whose parent is still in the trash, as it would
+// come back without a parent to show under.
+func (u *trashUsecase) Restore(id, userID int64) error {
+    task, err := u.getTrashed(id, userID)
+    if err != nil {
+        return err
+    }
+
+    if task.ParentID != nil {
+        parent, err := u.taskRepo.GetTrashedByID(*task.ParentID, userID)
+        if err != nil {
+            return fmt.Errorf("error getting parent task: %w", err)
+        }
+        if parent != nil {
+            return domain.ErrTaskParentTrashed
+        }
+    }
+
+    if err := u.taskRepo.Restore(id, userID); err != nil {
+        return fmt.Errorf("error restoring task: %w", err)
+    }
+
+    // The subtasks that came back with their parent get history entries
+    // too; the ones still in the trash are not listed.
+    subtasks, err := u.taskRepo.GetSubtasks(id, userID)
+    if err != nil {
+        return fmt.Errorf("error getting subtasks: %w", err)
+    }
+    for i := range subtasks {
+        if err := u.history.restored(userID, &subtasks[i]); err != nil {
+            return err
+        }
+    }
+
+    restored, err := u.taskRepo.GetByID(id, userID)
+    if err != nil {
+        return fmt.Errorf("error getting task: %w", err)
+    }
+    if restored == nil {
+        return domain.ErrTaskNotFound
+    }
+
+    return u.history.restored(userID, restored)
+}
+
+func (u *trashUsecase) Purge(ctx context.Context, id, userID int64) error {
+    task, err := u.getTrashed(id, userID)
+    if err != nil {
+        return err
+    }
+
+    return u.purge(ctx, task)
+}
+
+// PurgeExpired purges up to trashPurgeBatchSize expired tasks, stopping at
+// the first that fails so it is retried on the next run.
+func (u *trashUsecase) PurgeExpired(ctx context.Context) error {
+    before := time.Now().UTC().Add(-u.retention)
+    tasks, err := u.taskRepo.GetTrashedBefore(before, trashPurgeBatchSize)
+    if err != nil {
+        return fmt.Errorf("error getting expired trash: %w", err)
+    }
+
+    for i := range tasks {
+        if err := u.purge(ctx, &tasks[i]); err != nil {
+            return fmt.Errorf("error purging task %d: %w", tasks[i].ID, err)
+        }
+    }
+
+    return nil
+}
+
+// purge deletes the content of the attachments of task and its subtasks
+// before the rows, so a failure part way leaves a task that can still be
+// purged rather than unreferenced blobs. The history of the tasks is kept.
+func (u *trashUsecase) purge(ctx context.Context, task *domain.Task) error {
+    attachments, err := u.attachmentRepo.GetByTaskTree(task.ID)
+    if err != nil {
+        return fmt.Errorf("error getting attachments: %w", err)
+    }
+
+    for _, attachment := range attachments {
+        if err := u.blobs.Delete(ctx, attachment.StorageKey); err != nil {
+            return fmt.Errorf("error deleting attachment content: %w", err)
+        }
+    }
+
+    if err := u.taskRepo.Purge(task.ID, task.UserID); err != nil {
+        return fmt.Errorf("error purging task: %w", err)
+    }
+
+    return nil
+}
+
+func (u *trashUsecase) getTrashed(id, userID int64) (*domain.Task, error) {
+    task, err := u.taskRepo.GetTrashedByID(id, userID)
+    if err != nil {
+        return nil, fmt.Errorf("error getting task: %w", err)
+    }
+    if task == nil {
+        return nil, domain.ErrTaskNotFound
+    }
+
+    return task, nil
+}
@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	memoryrepo "todo-app/internal/repository/memory"
+)
+
+// recordingBlobStore records the keys it is asked to delete, failing while
+// err is set.
+type recordingBlobStore struct {
+    deleted []string
+    err     error
+}
+
+func (s *recordingBlobStore) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+    return nil
+}
+
+func (s *recordingBlobStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+    return nil, errors.New("not stored")
+}
+
+func (s *recordingBlobStore) Delete(ctx context.Context, key string) error {
+    if s.err != nil {
+        return s.err
+    }
+    s.deleted = append(s.deleted, key)
+    return nil
+}
+
+type trashFixture struct {
+    *taskFixture
+    attachmentRepo domain.AttachmentRepository
+    blobs          *recordingBlobStore
+}
+
+func newTrashFixture(t *testing.T) *trashFixture {
+    t.Helper()
+
+    f := newTaskFixture(t)
+    return &trashFixture{
+        taskFixture:    f,
+        attachmentRepo: memoryrepo.NewMemoryAttachmentRepository(f.store),
+        blobs:          &recordingBlobStore{},
+    }
+}
+
+func (f *trashFixture) trash(retention time.Duration) domain.TrashUsecase {
+    return NewTrashUsecase(f.taskRepo, f.tagRepo, f.attachmentRepo, f.historyRepo, f.blobs, retention)
+}
+
+func (f *trashFixture) attach(t *testing.T, taskID, userID int64, key string) {
+    t.Helper()
+
+    attachment := &domain.Attachment{TaskID: taskID, UserID: userID, Filename: key, StorageKey: key}
+    if err := f.attachmentRepo.Create(attachment); err != nil {
+        t.Fatalf("creating attachment: %v", err)
+    }
+}
+
+func (f *trashFixture) trashTitles(t *testing.T, userID int64) []string {
+    t.Helper()
+
+    tasks, err := f.trash(time.Hour).List(userID)
+    if err != nil {
+        t.Fatalf("List: %v", err)
+    }
+    return taskTitles(tasks)
+}
+
+func TestRestoreBringsBackSubtasks(t *testing.T) {
+    f := newTrashFixture(t)
+    alice := f.newUser(t, "alice")
+    trash := f.trash(time.Hour)
+    parent := f.newTask(t, alice, domain.TaskInput{Title: "Move house"})
+    subtask := f.newSubtask(t, parent.ID, alice, "Pack boxes")
+
+    if err := f.tasks.Delete(parent.ID, alice, true); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+    // The trash lists the parent, which holds its subtask.
+    if got := f.trashTitles(t, alice); !equalTitles(got, []string{"Move house"}) {
+        t.Errorf("trash = %v, want just the parent", got)
+    }
+
+    if err := trash.Restore(subtask.ID, alice); !errors.Is(err, domain.ErrTaskParentTrashed) {
+        t.Errorf("Restore of the subtask alone: err = %v, want ErrTaskParentTrashed", err)
+    }
+    if err := trash.Restore(parent.ID, f.newUser(t, "bob")); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Restore by another user: err = %v, want ErrTaskNotFound", err)
+    }
+
+    if err := trash.Restore(parent.ID, alice); err != nil {
+        t.Fatalf("Restore: %v", err)
+    }
+    for _, task := range []*domain.Task{parent, subtask} {
+        f.getTask(t, task.ID, alice)
+        if action := f.lastAction(t, task.ID); action != domain.HistoryRestored {
+            t.Errorf("history of %q records %q, want restored", task.Title, action)
+        }
+    }
+    if got := f.trashTitles(t, alice); len(got) != 0 {
+        t.Errorf("trash after Restore = %v", got)
+    }
+    if err := trash.Restore(parent.ID, alice); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Restore of a task not in the trash: err = %v, want ErrTaskNotFound", err)
+    }
+}
+
+func TestPurgeDeletesContentBeforeRows(t *testing.T) {
+    f := newTrashFixture(t)
+    alice := f.newUser(t, "alice")
+    trash := f.trash(time.Hour)
+    parent := f.newTask(t, alice, domain.TaskInput{Title: "Tax return"})
+    subtask := f.newSubtask(t, parent.ID, alice, "Receipts")
+    f.attach(t, parent.ID, alice, "tasks/parent.pdf")
+    f.attach(t, subtask.ID, alice, "tasks/receipt.jpg")
+
+    if err := trash.Purge(context.Background(), parent.ID, alice); !errors.Is(err, domain.ErrTaskNotFound) {
+        t.Errorf("Purge of a task not in the trash: err = %v, want ErrTaskNotFound", err)
+    }
+    if err := f.tasks.Delete(parent.ID, alice, true); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    // While the content cannot be deleted, the task stays in the trash with
+    // its attachments, to be purged again.
+    f.blobs.err = errors.New("storage down")
+    if err := trash.Purge(context.Background(), parent.ID, alice); !errors.Is(err, f.blobs.err) {
+        t.Fatalf("Purge with storage down: err = %v, want the storage error", err)
+    }
+    if got := f.trashTitles(t, alice); !equalTitles(got, []string{"Tax return"}) {
+        t.Errorf("trash after a failed Purge = %v", got)
+    }
+    if attachments, err := f.attachmentRepo.GetByTaskTree(parent.ID); err != nil || len(attachments) != 2 {
+        t.Errorf("attachments after a failed Purge = %+v, %v", attachments, err)
+    }
+
+    f.blobs.err = nil
+    if err := trash.Purge(context.Background(), parent.ID, alice); err != nil {
+        t.Fatalf("Purge: %v", err)
+    }
+    if !equalTitles(f.blobs.deleted, []string{"tasks/parent.pdf", "tasks/receipt.jpg"}) {
+        t.Errorf("deleted content = %v, want both attachments'", f.blobs.deleted)
+    }
+    if got := f.trashTitles(t, alice); len(got) != 0 {
+        t.Errorf("trash after Purge = %v", got)
+    }
+    for _, task := range []*domain.Task{parent, subtask} {
+        if got, err := f.taskRepo.GetTrashedByID(task.ID, alice); err != nil || got != nil {
+            t.Errorf("%q after Purge = %+v, %v", task.Title, got, err)
+        }
+        // The history outlives the task.
+        if action := f.lastAction(t, task.ID); action != domain.HistoryDeleted {
+            t.Errorf("history of %q ends with %q, want deleted", task.Title, action)
+        }
+    }
+}
+
+func TestPurgeExpired(t *testing.T) {
+    f := newTrashFixture(t)
+    alice := f.newUser(t, "alice")
+    task := f.newTask(t, alice, domain.TaskInput{Title: "Old news"})
+    f.attach(t, task.ID, alice, "tasks/old.txt")
+    if err := f.tasks.Delete(task.ID, alice, false); err != nil {
+        t.Fatalf("Delete: %v", err)
+    }
+
+    // Within the retention period nothing goes.
+    if err := f.trash(time.Hour).PurgeExpired(context.Background()); err != nil {
+        t.Fatalf("PurgeExpired: %v", err)
+    }
+    if got := f.trashTitles(t, alice); !equalTitles(got, []string{"Old news"}) || len(f.blobs.deleted) != 0 {
+        t.Errorf("PurgeExpired within retention left trash %v and deleted %v", got, f.blobs.deleted)
+    }
+
+    // A negative retention makes every task in the trash expired.
+    f.blobs.err = errors.New("storage down")
+    if err := f.trash(-time.Minute).PurgeExpired(context.Background()); !errors.Is(err, f.blobs.err) {
+        t.Errorf("PurgeExpired with storage down: err = %v, want the storage error", err)
+    }
+    f.blobs.err = nil
+    if err := f.trash(-time.Minute).PurgeExpired(context.Background()); err != nil {
+        t.Fatalf("PurgeExpired: %v", err)
+    }
+    if got := f.trashTitles(t, alice); len(got) != 0 || !equalTitles(f.blobs.deleted, []string{"tasks/old.txt"}) {
+        t.Errorf("PurgeExpired left trash %v and deleted %v", got, f.blobs.deleted)
+    }
+}
@@ -1,19 +1,40 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 	"todo-app/internal/domain"
 	"todo-app/internal/pkg/auth"
 	"todo-app/internal/pkg/security"
 )
 
 type userUsecase struct {
-    userRepo domain.UserRepository
+    userRepo         domain.UserRepository
+    refreshTokenRepo domain.RefreshTokenRepository
+    revocations      domain.TokenRevocationUsecase
+    tokens           *auth.TokenService
+    // refreshTTL is how long a refresh token can be exchanged for. Each
+    // refresh starts it over, so a user who keeps coming back stays logged
+    // in.
+    refreshTTL time.Duration
 }
 
-func NewUserUsecase(userRepo domain.UserRepository) domain.UserUsecase {
+func NewUserUsecase(
+    userRepo domain.UserRepository,
+    refreshTokenRepo domain.RefreshTokenRepository,
+    revocations domain.TokenRevocationUsecase,
+    tokens *auth.TokenService,
+    refreshTTL time.Duration,
+) domain.UserUsecase {
     return &userUsecase{
-        userRepo: userRepo,
+        userRepo:         userRepo,
+        refreshTokenRepo: refreshTokenRepo,
+        revocations:      revocations,
+        tokens:           tokens,
+        refreshTTL:       refreshTTL,
     }
 }
 
@@ -21,16 +42,16 @@ func (u *userUsecase) Register(username, password string) error {
     // Check if username already exists
     existingUser, err := u.userRepo.GetByUsername(username)
     if err != nil {
-        return fmt.Errorf("error checking username: %v", err)
+        return fmt.Errorf("error checking username: %w", err)
     }
     if existingUser != nil {
-        return fmt.Errorf("username already exists")
+        return domain.ErrUsernameTaken
     }
 
     // Hash password
     hashedPassword, err := security.HashPassword(password)
     if err != nil {
-        return fmt.Errorf("error hashing password: %v", err)
+        return fmt.Errorf("error hashing password: %w", err)
     }
 
     // Create user
@@ -40,36 +61,212 @@ func (u *userUsecase) Register(username, password string) error {
     }
 
     if err := u.userRepo.Create(user); err != nil {
-        return fmt.Errorf("error creating user: %v", err)
+        return fmt.Errorf("error creating user: %w", err)
     }
 
     return nil
 }
 
-func (u *userUsecase) Login(username, password string) (string, error) {
+func (u *userUsecase) Login(username, password string) (*domain.TokenPair, error) {
     // Get user by username
     user, err := u.userRepo.GetByUsername(username)
     if err != nil {
-        return "", fmt.Errorf("error getting user: %v", err)
+        return nil, fmt.Errorf("error getting user: %w", err)
     }
     if user == nil {
-        return "", fmt.Errorf("invalid username or password")
+        // Take as long as a wrong password would, so the response time
+        // does not tell which usernames exist.
+        security.VerifyPassword(dummyPasswordHash(), password)
+        return nil, domain.ErrInvalidCredentials
     }
 
     // Verify password
     valid, err := security.VerifyPassword(user.Password, password)
     if err != nil {
-        return "", fmt.Errorf("error verifying password: %v", err)
+        return nil, fmt.Errorf("error verifying password: %w", err)
     }
     if !valid {
-        return "", fmt.Errorf("invalid username or password")
+        return nil, domain.ErrInvalidCredentials
     }
 
-    // Generate JWT token
-    token, err := auth.GenerateToken(user.ID, user.Username)
+    // Upgrade a legacy hash, or one made at an older cost, now that the
+    // password is at hand. Failing to is no reason to refuse the login.
+    if security.NeedsRehash(user.Password) {
+        if err := u.rehashPassword(user.ID, password); err != nil {
+            log.Printf("error rehashing password of user %d: %v", user.ID, err)
+        }
+    }
+
+    family, err := auth.NewTokenFamily()
+    if err != nil {
+        return nil, err
+    }
+
+    return u.issueTokens(user, family)
+}
+
+var (
+    dummyHashOnce sync.Once
+    dummyHash     string
+)
+
+// dummyPasswordHash returns a hash of no one's password, made at the current
+// cost the first time it is needed.
+func dummyPasswordHash() string {
+    dummyHashOnce.Do(func() {
+        hash, err := security.HashPassword("not anyone's password")
+        if err != nil {
+            log.Printf("error making dummy password hash: %v", err)
+            return
+        }
+        dummyHash = hash
+    })
+    return dummyHash
+}
+
+// Refresh revokes the whole family of a refresh token that was used
+// before: either it was stolen and the thief or the user has moved on
+// with its successor, and there is no telling which one is asking.
+func (u *userUsecase) Refresh(refreshToken string) (*domain.TokenPair, error) {
+    stored, err := u.refreshTokenRepo.GetByHash(auth.HashRefreshToken(refreshToken))
+    if err != nil {
+        return nil, fmt.Errorf("error getting refresh token: %w", err)
+    }
+    now := time.Now().UTC()
+    if stored == nil || stored.RevokedAt != nil || !now.Before(stored.ExpiresAt) {
+        return nil, domain.ErrInvalidRefreshToken
+    }
+
+    reused := stored.UsedAt != nil
+    if !reused {
+        marked, err := u.refreshTokenRepo.MarkUsed(stored.ID, now)
+        if err != nil {
+            return nil, fmt.Errorf("error using refresh token: %w", err)
+        }
+        // Another refresh got there first with the same token.
+        reused = !marked
+    }
+    if reused {
+        // The access tokens already issued to the family are as suspect
+        // as its refresh tokens.
+        if err := u.revocations.Revoke([]string{stored.FamilyID}, stored.UserID, now.Add(u.tokens.TTL())); err != nil {
+            return nil, err
+        }
+        if err := u.refreshTokenRepo.RevokeFamily(stored.FamilyID, now); err != nil {
+            return nil, fmt.Errorf("error revoking refresh tokens: %w", err)
+        }
+        return nil, domain.ErrInvalidRefreshToken
+    }
+
+    user, err := u.userRepo.GetByID(stored.UserID)
     if err != nil {
-        return "", fmt.Errorf("error generating token: %v", err)
+        return nil, fmt.Errorf("error getting user: %w", err)
+    }
+    if user == nil {
+        return nil, domain.ErrInvalidRefreshToken
+    }
+
+    return u.issueTokens(user, stored.FamilyID)
+}
+
+// Logout revokes the access token it is called with and the session it
+// belongs to, so neither that token nor any other issued to the session,
+// nor its refresh token, is accepted again.
+func (u *userUsecase) Logout(userID int64, tokenID, sessionID string, expiresAt time.Time) error {
+    now := time.Now().UTC()
+
+    // The session ID has to outlive the last access token issued to the
+    // session, which is at most one TTL old.
+    until := now.Add(u.tokens.TTL())
+    if expiresAt.After(until) {
+        until = expiresAt
+    }
+
+    var ids []string
+    for _, id := range []string{tokenID, sessionID} {
+        if id != "" {
+            ids = append(ids, id)
+        }
+    }
+    if len(ids) > 0 {
+        if err := u.revocations.Revoke(ids, userID, until); err != nil {
+            return err
+        }
     }
 
-    return token, nil
-}
\ No newline at end of file
+    if sessionID != "" {
+        if err := u.refreshTokenRepo.RevokeFamily(sessionID, now); err != nil {
+            return fmt.Errorf("error revoking refresh tokens: %w", err)
+        }
+    }
+
+    return nil
+}
+
+// LogoutEverywhere revokes every session of a user: the access tokens of
+// the sessions that can still be refreshed, and all refresh tokens.
+func (u *userUsecase) LogoutEverywhere(userID int64) error {
+    now := time.Now().UTC()
+
+    sessions, err := u.refreshTokenRepo.GetActiveFamilies(userID, now)
+    if err != nil {
+        return fmt.Errorf("error getting sessions: %w", err)
+    }
+    if len(sessions) > 0 {
+        if err := u.revocations.Revoke(sessions, userID, now.Add(u.tokens.TTL())); err != nil {
+            return err
+        }
+    }
+
+    if err := u.refreshTokenRepo.RevokeByUserID(userID, now); err != nil {
+        return fmt.Errorf("error revoking refresh tokens: %w", err)
+    }
+
+    return nil
+}
+
+func (u *userUsecase) PurgeExpiredTokens(ctx context.Context) error {
+    if _, err := u.refreshTokenRepo.DeleteExpired(time.Now().UTC()); err != nil {
+        return fmt.Errorf("error purging refresh tokens: %w", err)
+    }
+
+    return u.revocations.PurgeExpired(ctx)
+}
+
+func (u *userUsecase) rehashPassword(userID int64, password string) error {
+    hashedPassword, err := security.HashPassword(password)
+    if err != nil {
+        return fmt.Errorf("error hashing password: %w", err)
+    }
+
+    return u.userRepo.UpdatePassword(userID, hashedPassword)
+}
+
+// issueTokens gives user a new access token, and a refresh token in family.
+func (u *userUsecase) issueTokens(user *domain.User, family string) (*domain.TokenPair, error) {
+    accessToken, err := u.tokens.GenerateToken(user.ID, user.Username, family)
+    if err != nil {
+        return nil, fmt.Errorf("error generating token: %w", err)
+    }
+
+    refreshToken, hash, err := auth.NewRefreshToken()
+    if err != nil {
+        return nil, err
+    }
+
+    stored := &domain.RefreshToken{
+        UserID:    user.ID,
+        FamilyID:  family,
+        TokenHash: hash,
+        ExpiresAt: time.Now().UTC().Add(u.refreshTTL),
+    }
+    if err := u.refreshTokenRepo.Create(stored); err != nil {
+        return nil, fmt.Errorf("error storing refresh token: %w", err)
+    }
+
+    return &domain.TokenPair{
+        AccessToken:  accessToken,
+        RefreshToken: refreshToken,
+        ExpiresIn:    int64(u.tokens.TTL().Seconds()),
+    }, nil
+}
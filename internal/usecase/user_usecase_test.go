@@ -0,0 +1,355 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"todo-app/internal/domain"
+	"todo-app/internal/pkg/auth"
+	"todo-app/internal/pkg/security"
+	memoryrepo "todo-app/internal/repository/memory"
+)
+
+type userFixture struct {
+    users         domain.UserUsecase
+    userRepo      domain.UserRepository
+    refreshTokens domain.RefreshTokenRepository
+    revocations   domain.TokenRevocationUsecase
+    tokens        *auth.TokenService
+}
+
+func newUserFixture(t *testing.T) *userFixture {
+    t.Helper()
+
+    // Hashing at the production cost would only make the tests slow.
+    if err := security.SetParams(security.Params{Memory: 64, Iterations: 1, Parallelism: 1}); err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { security.SetParams(security.DefaultParams) })
+
+    key, err := auth.GenerateKey("test")
+    if err != nil {
+        t.Fatal(err)
+    }
+    keys, err := auth.NewKeySet([]auth.Key{key}, "")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    store := memoryrepo.NewStore()
+    f := &userFixture{
+        userRepo:      memoryrepo.NewMemoryUserRepository(store),
+        refreshTokens: memoryrepo.NewMemoryRefreshTokenRepository(store),
+        revocations:   NewTokenRevocationUsecase(memoryrepo.NewMemoryTokenRevocationRepository(store), time.Minute),
+        tokens:        auth.NewTokenService(keys, 15*time.Minute),
+    }
+    f.users = NewUserUsecase(f.userRepo, f.refreshTokens, f.revocations, f.tokens, 24*time.Hour)
+    return f
+}
+
+func (f *userFixture) storedHash(t *testing.T, username string) string {
+    t.Helper()
+
+    user, err := f.userRepo.GetByUsername(username)
+    if err != nil || user == nil {
+        t.Fatalf("getting %s: %v", username, err)
+    }
+    return user.Password
+}
+
+func TestLoginUpgradesLegacyHash(t *testing.T) {
+    f := newUserFixture(t)
+
+    salt := []byte("0123456789abcdef")
+    sum := sha256.Sum256(append([]byte("hunter2"), salt...))
+    legacy := base64.StdEncoding.EncodeToString(append(salt, sum[:]...))
+    if err := f.userRepo.Create(&domain.User{Username: "alice", Password: legacy}); err != nil {
+        t.Fatal(err)
+    }
+
+    if _, err := f.users.Login("alice", "wrong"); !errors.Is(err, domain.ErrInvalidCredentials) {
+        t.Fatalf("Login with a wrong password: err = %v", err)
+    }
+    if hash := f.storedHash(t, "alice"); hash != legacy {
+        t.Errorf("a failed login rewrote the hash to %q", hash)
+    }
+
+    if _, err := f.users.Login("alice", "hunter2"); err != nil {
+        t.Fatalf("Login: %v", err)
+    }
+    hash := f.storedHash(t, "alice")
+    if !strings.HasPrefix(hash, "$argon2id$") || security.NeedsRehash(hash) {
+        t.Errorf("stored hash after login = %q, want argon2id at the current cost", hash)
+    }
+
+    // The upgraded hash is what the next login checks.
+    if _, err := f.users.Login("alice", "hunter2"); err != nil {
+        t.Errorf("Login after the upgrade: %v", err)
+    }
+    if again := f.storedHash(t, "alice"); again != hash {
+        t.Error("a hash at the current cost was rewritten")
+    }
+}
+
+func TestLoginUpgradesHashCost(t *testing.T) {
+    f := newUserFixture(t)
+    if err := f.users.Register("bob", "s3cret"); err != nil {
+        t.Fatal(err)
+    }
+    old := f.storedHash(t, "bob")
+
+    if err := security.SetParams(security.Params{Memory: 128, Iterations: 2, Parallelism: 1}); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := f.users.Login("bob", "s3cret"); err != nil {
+        t.Fatalf("Login: %v", err)
+    }
+    if hash := f.storedHash(t, "bob"); hash == old || !strings.Contains(hash, "$m=128,t=2,p=1$") {
+        t.Errorf("stored hash after login = %q, want it rehashed at the new cost", hash)
+    }
+}
+
+func TestLoginUnknownUser(t *testing.T) {
+    f := newUserFixture(t)
+
+    if _, err := f.users.Login("nobody", "pw"); !errors.Is(err, domain.ErrInvalidCredentials) {
+        t.Errorf("Login of an unknown user: err = %v, want ErrInvalidCredentials", err)
+    }
+    // The unknown user was checked against a real hash.
+    if !strings.HasPrefix(dummyPasswordHash(), "$argon2id$") {
+        t.Errorf("dummy hash = %q", dummyPasswordHash())
+    }
+}
+
+func (f *userFixture) login(t *testing.T, username string) *domain.TokenPair {
+    t.Helper()
+
+    if err := f.users.Register(username, "pw"); err != nil {
+        t.Fatal(err)
+    }
+    pair, err := f.users.Login(username, "pw")
+    if err != nil {
+        t.Fatalf("Login: %v", err)
+    }
+    return pair
+}
+
+func (f *userFixture) claims(t *testing.T, accessToken string) *auth.Claims {
+    t.Helper()
+
+    claims, err := f.tokens.ValidateToken(accessToken)
+    if err != nil {
+        t.Fatalf("ValidateToken: %v", err)
+    }
+    return claims
+}
+
+func (f *userFixture) storedRefreshToken(t *testing.T, refreshToken string) *domain.RefreshToken {
+    t.Helper()
+
+    stored, err := f.refreshTokens.GetByHash(auth.HashRefreshToken(refreshToken))
+    if err != nil || stored == nil {
+        t.Fatalf("GetByHash: %+v, %v", stored, err)
+    }
+    return stored
+}
+
+func TestRefreshRotatesToken(t *testing.T) {
+    f := newUserFixture(t)
+    login := f.login(t, "alice")
+
+    refreshed, err := f.users.Refresh(login.RefreshToken)
+    if err != nil {
+        t.Fatalf("Refresh: %v", err)
+    }
+    if refreshed.RefreshToken == login.RefreshToken || refreshed.AccessToken == login.AccessToken {
+        t.Fatal("Refresh handed back the tokens it was given")
+    }
+    if refreshed.ExpiresIn != int64((15 * time.Minute).Seconds()) {
+        t.Errorf("ExpiresIn = %d", refreshed.ExpiresIn)
+    }
+
+    // The refresh token is stored by its hash only, and the one exchanged
+    // is marked used.
+    old := f.storedRefreshToken(t, login.RefreshToken)
+    if old.UsedAt == nil || old.TokenHash == login.RefreshToken {
+        t.Errorf("exchanged token stored as %+v", old)
+    }
+
+    if _, err := f.users.Refresh(login.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+        t.Errorf("Refresh with the exchanged token: err = %v, want ErrInvalidRefreshToken", err)
+    }
+    if _, err := f.users.Refresh("never issued"); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+        t.Errorf("Refresh with an unknown token: err = %v, want ErrInvalidRefreshToken", err)
+    }
+}
+
+func TestRefreshKeepsFamily(t *testing.T) {
+    f := newUserFixture(t)
+    login := f.login(t, "alice")
+    family := f.claims(t, login.AccessToken).SessionID
+    if family == "" {
+        t.Fatal("the access token of a login has no session ID")
+    }
+
+    // Each refresh carries the family forward, in the access token and the
+    // stored refresh token alike.
+    pair := login
+    for i := 0; i < 3; i++ {
+        next, err := f.users.Refresh(pair.RefreshToken)
+        if err != nil {
+            t.Fatalf("Refresh %d: %v", i, err)
+        }
+        claims := f.claims(t, next.AccessToken)
+        if claims.SessionID != family || claims.Username != "alice" {
+            t.Errorf("refresh %d: claims = %+v, want session %s", i, claims, family)
+        }
+        if stored := f.storedRefreshToken(t, next.RefreshToken); stored.FamilyID != family || stored.UsedAt != nil {
+            t.Errorf("refresh %d: stored = %+v, want an unused token of family %s", i, stored, family)
+        }
+        pair = next
+    }
+
+    // Another login starts a family of its own.
+    again, err := f.users.Login("alice", "pw")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if f.claims(t, again.AccessToken).SessionID == family {
+        t.Error("a second login joined the first one's family")
+    }
+}
+
+func TestRefreshRejectsExpiredToken(t *testing.T) {
+    f := newUserFixture(t)
+    f.users = NewUserUsecase(f.userRepo, f.refreshTokens, f.revocations, f.tokens, -time.Minute)
+    login := f.login(t, "alice")
+
+    if _, err := f.users.Refresh(login.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+        t.Errorf("Refresh with an expired token: err = %v, want ErrInvalidRefreshToken", err)
+    }
+}
+
+// assertRevoked checks whether the access token is refused as revoked.
+func (f *userFixture) assertRevoked(t *testing.T, accessToken string, want bool) {
+    t.Helper()
+
+    claims := f.claims(t, accessToken)
+    revoked, err := f.revocations.IsRevoked(claims.ID, claims.SessionID)
+    if err != nil {
+        t.Fatalf("IsRevoked: %v", err)
+    }
+    if revoked != want {
+        t.Errorf("access token of session %s revoked = %v, want %v", claims.SessionID, revoked, want)
+    }
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+    f := newUserFixture(t)
+    login := f.login(t, "alice")
+    other, err := f.users.Login("alice", "pw")
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    successor, err := f.users.Refresh(login.RefreshToken)
+    if err != nil {
+        t.Fatalf("Refresh: %v", err)
+    }
+
+    // The exchanged token comes back: whoever holds the successor may be
+    // the thief, so the whole family goes.
+    if _, err := f.users.Refresh(login.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+        t.Fatalf("Refresh with a reused token: err = %v, want ErrInvalidRefreshToken", err)
+    }
+    if _, err := f.users.Refresh(successor.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+        t.Errorf("Refresh with the successor of a reused token: err = %v, want ErrInvalidRefreshToken", err)
+    }
+    if stored := f.storedRefreshToken(t, successor.RefreshToken); stored.RevokedAt == nil {
+        t.Error("the successor was not revoked")
+    }
+
+    // The access tokens of the session go with it, the one issued at login
+    // included.
+    f.assertRevoked(t, login.AccessToken, true)
+    f.assertRevoked(t, successor.AccessToken, true)
+
+    // The user's other session is untouched.
+    f.assertRevoked(t, other.AccessToken, false)
+    if _, err := f.users.Refresh(other.RefreshToken); err != nil {
+        t.Errorf("Refresh of another session: %v", err)
+    }
+}
+
+func TestLogout(t *testing.T) {
+    f := newUserFixture(t)
+    login := f.login(t, "alice")
+    other, err := f.users.Login("alice", "pw")
+    if err != nil {
+        t.Fatal(err)
+    }
+    refreshed, err := f.users.Refresh(login.RefreshToken)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    claims := f.claims(t, refreshed.AccessToken)
+    if err := f.users.Logout(claims.UserID, claims.ID, claims.SessionID, time.Unix(claims.ExpiresAt, 0)); err != nil {
+        t.Fatalf("Logout: %v", err)
+    }
+
+    // Every access token of the session is refused, not only the one
+    // logged out with, and so is its refresh token.
+    f.assertRevoked(t, refreshed.AccessToken, true)
+    f.assertRevoked(t, login.AccessToken, true)
+    if _, err := f.users.Refresh(refreshed.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+        t.Errorf("Refresh after Logout: err = %v, want ErrInvalidRefreshToken", err)
+    }
+
+    f.assertRevoked(t, other.AccessToken, false)
+    if _, err := f.users.Refresh(other.RefreshToken); err != nil {
+        t.Errorf("Refresh of another session after Logout: %v", err)
+    }
+}
+
+func TestLogoutEverywhere(t *testing.T) {
+    f := newUserFixture(t)
+    first := f.login(t, "alice")
+    second, err := f.users.Login("alice", "pw")
+    if err != nil {
+        t.Fatal(err)
+    }
+    bob := f.login(t, "bob")
+
+    // A session that has moved on to a new refresh token is covered too.
+    second, err = f.users.Refresh(second.RefreshToken)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    if err := f.users.LogoutEverywhere(f.claims(t, first.AccessToken).UserID); err != nil {
+        t.Fatalf("LogoutEverywhere: %v", err)
+    }
+
+    for _, pair := range []*domain.TokenPair{first, second} {
+        f.assertRevoked(t, pair.AccessToken, true)
+        if _, err := f.users.Refresh(pair.RefreshToken); !errors.Is(err, domain.ErrInvalidRefreshToken) {
+            t.Errorf("Refresh after LogoutEverywhere: err = %v, want ErrInvalidRefreshToken", err)
+        }
+    }
+
+    f.assertRevoked(t, bob.AccessToken, false)
+    if _, err := f.users.Refresh(bob.RefreshToken); err != nil {
+        t.Errorf("Refresh of another user after LogoutEverywhere: %v", err)
+    }
+
+    // Logging in again works as before.
+    again, err := f.users.Login("alice", "pw")
+    if err != nil {
+        t.Fatalf("Login after LogoutEverywhere: %v", err)
+    }
+    f.assertRevoked(t, again.AccessToken, false)
+}